@@ -8,9 +8,37 @@ import (
 	"os"
 	"os/signal"
 	"story-book/internal/config"
+	"story-book/internal/invoice"
 	"story-book/internal/middlewares"
+	"story-book/internal/pricing"
+	"story-book/internal/services/addressservice"
+	"story-book/internal/services/alertservice"
+	"story-book/internal/services/auditservice"
+	"story-book/internal/services/authorservice"
 	"story-book/internal/services/bookservice"
+	"story-book/internal/services/collectionservice"
+	"story-book/internal/services/currencyservice"
+	"story-book/internal/services/deliveryservice"
+	"story-book/internal/services/ebookservice"
+	"story-book/internal/services/exportservice"
+	"story-book/internal/services/giftcardservice"
+	"story-book/internal/services/importservice"
+	"story-book/internal/services/invoiceservice"
+	"story-book/internal/services/orderservice"
+	"story-book/internal/services/paymentservice"
+	"story-book/internal/services/preorderservice"
+	"story-book/internal/services/priceservice"
+	"story-book/internal/services/promoservice"
+	"story-book/internal/services/publisherservice"
+	"story-book/internal/services/recommendservice"
+	"story-book/internal/services/returnservice"
+	"story-book/internal/services/reviewservice"
+	"story-book/internal/services/seriesservice"
+	"story-book/internal/services/shelfservice"
+	"story-book/internal/services/taxservice"
+	"story-book/internal/services/trashservice"
 	"story-book/internal/services/userservice"
+	"story-book/internal/storage"
 	"story-book/package/databases/postgres"
 	"story-book/package/services/encryptservice"
 	"story-book/package/services/jwtservice"
@@ -30,7 +58,9 @@ func Run(cfg *config.Config) error {
 	e := echo.New()
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
+	e.Use(middleware.RequestID())
 	e.Use(middleware.RequestLogger())
+	e.Use(middlewares.ActorMiddleware())
 
 	db, err := postgres.InitDB(cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Username, cfg.Postgres.Password, cfg.Postgres.Database)
 	if err != nil {
@@ -42,16 +72,153 @@ func Run(cfg *config.Config) error {
 	validateService := validateservice.NewValidationService(cfg.MinPasswordSize)
 
 	authMiddleware := middlewares.AuthMiddleware(jwtService)
+	optionalAuthMiddleware := middlewares.OptionalAuthMiddleware(jwtService)
+
+	auditRepository := auditservice.NewAuditRepository(db)
+	auditService := auditservice.NewAuditService(auditRepository)
+	auditHandler := auditservice.NewAuditHandler(auditService)
 
 	userRepository := userservice.NewUserRepository(db)
-	userService := userservice.NewUserService(userRepository, jwtService, encryptService, validateService)
+	userService := userservice.NewUserService(userRepository, jwtService, encryptService, validateService, auditService)
 	userHandler := userservice.NewUserHandler(userService)
 
+	pricingEngine := pricing.NewEngine(cfg.BaseCurrency, cfg.PriceRounding)
+
+	promoRepository := promoservice.NewPromoRepository(db)
+	promoService := promoservice.NewPromoService(promoRepository, pricingEngine)
+	promoHandler := promoservice.NewPromoHandler(promoService)
+
+	currencyRepository := currencyservice.NewCurrencyRepository(db)
+	currencyService := currencyservice.NewCurrencyService(currencyRepository, promoService, cfg.BaseCurrency, cfg.PriceRounding)
+	currencyHandler := currencyservice.NewCurrencyHandler(currencyService)
+
+	if cfg.RatesFile != "" {
+		loaded, err := currencyService.LoadRates(context.Background(), cfg.RatesFile)
+		if err != nil {
+			return err
+		}
+		log.Printf("Loaded %d exchange rates from %s", loaded, cfg.RatesFile)
+	}
+
+	taxRepository := taxservice.NewTaxRepository(db)
+	taxService := taxservice.NewTaxService(taxRepository, currencyService, cfg.Tax.Region, cfg.PriceRounding, cfg.Tax.RulesFile)
+	taxHandler := taxservice.NewTaxHandler(taxService)
+
+	if cfg.Tax.RulesFile != "" {
+		loaded, err := taxService.LoadRules(context.Background())
+		if err != nil {
+			return err
+		}
+		log.Printf("Loaded %d tax rules from %s", loaded, cfg.Tax.RulesFile)
+	}
+
+	alertRepository := alertservice.NewAlertRepository(db)
+	alertService := alertservice.NewAlertService(alertRepository, promoService, alertservice.NewLogNotifier(), cfg.PublicUrl)
+	alertHandler := alertservice.NewAlertHandler(alertService)
+
+	addressRepository := addressservice.NewAddressRepository(db)
+	addressService := addressservice.NewAddressService(addressRepository)
+	addressHandler := addressservice.NewAddressHandler(addressService)
+
+	deliveryRepository := deliveryservice.NewDeliveryRepository(db)
+	deliveryService := deliveryservice.NewDeliveryService(deliveryRepository, promoService, cfg.BaseCurrency)
+	deliveryHandler := deliveryservice.NewDeliveryHandler(deliveryService)
+
+	orderRepository := orderservice.NewOrderRepository(db)
+	orderService := orderservice.NewOrderService(orderRepository, promoService, currencyService, deliveryService, addressService, taxService, cfg.PriceRounding)
+	orderHandler := orderservice.NewOrderHandler(orderService)
+
+	preorderRepository := preorderservice.NewPreorderRepository(db)
+	preorderService := preorderservice.NewPreorderService(preorderRepository, orderService)
+	preorderHandler := preorderservice.NewPreorderHandler(preorderService)
+
 	bookRepository := bookservice.NewBookRepository(db)
-	bookService := bookservice.NewBookService(bookRepository)
-	bookHandler := bookservice.NewBookHandler(bookService)
+	bookService := bookservice.NewBookService(bookRepository, auditService, alertService, preorderService)
+	bookHandler := bookservice.NewBookHandler(bookService, taxService)
+
+	authorRepository := authorservice.NewAuthorRepository(db)
+	authorService := authorservice.NewAuthorService(authorRepository)
+	authorHandler := authorservice.NewAuthorHandler(authorService)
+
+	publisherRepository := publisherservice.NewPublisherRepository(db)
+	publisherService := publisherservice.NewPublisherService(publisherRepository)
+	publisherHandler := publisherservice.NewPublisherHandler(publisherService)
+
+	seriesRepository := seriesservice.NewSeriesRepository(db)
+	seriesService := seriesservice.NewSeriesService(seriesRepository)
+	seriesHandler := seriesservice.NewSeriesHandler(seriesService)
 
-	registerRoutes(e, authMiddleware, userHandler, bookHandler)
+	priceRepository := priceservice.NewPriceRepository(db)
+	priceService := priceservice.NewPriceService(priceRepository, auditService)
+	priceHandler := priceservice.NewPriceHandler(priceService)
+
+	reviewRepository := reviewservice.NewReviewRepository(db)
+	reviewService := reviewservice.NewReviewService(reviewRepository)
+	reviewHandler := reviewservice.NewReviewHandler(reviewService)
+
+	shelfRepository := shelfservice.NewShelfRepository(db)
+	shelfService := shelfservice.NewShelfService(shelfRepository)
+	shelfHandler := shelfservice.NewShelfHandler(shelfService)
+
+	recommendRepository := recommendservice.NewRecommendRepository(db)
+	recommendService := recommendservice.NewRecommendService(recommendRepository, cfg.Recommendations.CacheTTL)
+	recommendHandler := recommendservice.NewRecommendHandler(recommendService)
+
+	collectionRepository := collectionservice.NewCollectionRepository(db)
+	collectionService := collectionservice.NewCollectionService(collectionRepository)
+	collectionHandler := collectionservice.NewCollectionHandler(collectionService)
+
+	paymentProvider, err := paymentservice.NewProvider(cfg.Payments.Provider, cfg.Payments.WebhookSecret, cfg.PublicUrl)
+	if err != nil {
+		return err
+	}
+	fakeGateway, _ := paymentProvider.(*paymentservice.FakeGateway)
+	paymentRepository := paymentservice.NewPaymentRepository(db, cfg.Ebooks.DownloadLimit)
+	paymentService := paymentservice.NewPaymentService(paymentRepository, orderService, paymentProvider)
+	paymentHandler := paymentservice.NewPaymentHandler(paymentService, fakeGateway)
+
+	giftCardRepository := giftcardservice.NewGiftCardRepository(db)
+	giftCardService := giftcardservice.NewGiftCardService(giftCardRepository, paymentService, orderService, cfg.GiftCards.Validity)
+	giftCardHandler := giftcardservice.NewGiftCardHandler(giftCardService)
+
+	returnRepository := returnservice.NewReturnRepository(db)
+	returnService := returnservice.NewReturnService(returnRepository, paymentService, giftCardService)
+	returnHandler := returnservice.NewReturnHandler(returnService)
+
+	invoiceRenderer := invoice.NewRenderer(cfg.Invoices.Font, cfg.Invoices.FontBold)
+	invoiceStore, err := invoiceservice.NewFileStore(cfg.Invoices.Dir)
+	if err != nil {
+		return err
+	}
+	invoiceRepository := invoiceservice.NewInvoiceRepository(db)
+	invoiceService := invoiceservice.NewInvoiceService(invoiceRepository, invoiceRenderer, invoiceStore, taxService, invoice.Store{
+		Name:    cfg.Store.Name,
+		Address: cfg.Store.Address,
+		TaxId:   cfg.Store.TaxId,
+	})
+	invoiceHandler := invoiceservice.NewInvoiceHandler(invoiceService)
+
+	importRepository := importservice.NewImportRepository(db)
+	importService := importservice.NewImportService(importRepository, bookService)
+	importHandler := importservice.NewImportHandler(importService)
+
+	exportRepository := exportservice.NewExportRepository(db)
+	exportService := exportservice.NewExportService(exportRepository, taxService, pricingEngine, cfg.Tax.Region, cfg.BaseCurrency, cfg.Store.Name)
+	exportHandler := exportservice.NewExportHandler(exportService)
+
+	ebookStorage, err := storage.NewFileSystem(cfg.Ebooks.Dir)
+	if err != nil {
+		return err
+	}
+	ebookRepository := ebookservice.NewEbookRepository(db)
+	ebookService := ebookservice.NewEbookService(ebookRepository, ebookStorage, cfg.Ebooks.SigningKey, cfg.PublicUrl, cfg.Ebooks.LinkTTL, cfg.Ebooks.DownloadLimit)
+	ebookHandler := ebookservice.NewEbookHandler(ebookService)
+
+	trashRepository := trashservice.NewTrashRepository(db)
+	trashService := trashservice.NewTrashService(trashRepository, auditService, cfg.Trash.Retention)
+	trashHandler := trashservice.NewTrashHandler(trashService)
+
+	registerRoutes(e, authMiddleware, optionalAuthMiddleware, userHandler, bookHandler, authorHandler, publisherHandler, seriesHandler, priceHandler, promoHandler, currencyHandler, reviewHandler, shelfHandler, alertHandler, recommendHandler, collectionHandler, orderHandler, paymentHandler, giftCardHandler, returnHandler, addressHandler, deliveryHandler, preorderHandler, invoiceHandler, taxHandler, importHandler, exportHandler, ebookHandler, trashHandler, auditHandler)
 
 	server := &http.Server{
 		Addr:    ":" + cfg.BackendPort,
@@ -65,6 +232,13 @@ func Run(cfg *config.Config) error {
 	)
 	defer stop()
 
+	go trashservice.RunPurger(ctx, trashService, cfg.Trash.PurgeInterval)
+	go priceservice.RunScheduler(ctx, priceService, cfg.PriceSchedulerInterval)
+	go alertservice.RunWorker(ctx, alertService, cfg.AlertInterval)
+	go recommendservice.RunBuilder(ctx, recommendService, cfg.Recommendations.Interval)
+	go paymentservice.RunReconciler(ctx, paymentService, cfg.Payments.ReconcileInterval)
+	go importservice.RunWorker(ctx, importService, cfg.Imports.Interval)
+
 	go func(db *gorm.DB) {
 		log.Printf("Backend started on :%s", cfg.BackendPort)
 		if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -95,8 +269,34 @@ func Run(cfg *config.Config) error {
 
 func registerRoutes(e *echo.Echo,
 	authMiddleware echo.MiddlewareFunc,
+	optionalAuthMiddleware echo.MiddlewareFunc,
 	userHandler *userservice.UserHandler,
 	bookHandler *bookservice.BookHandler,
+	authorHandler *authorservice.AuthorHandler,
+	publisherHandler *publisherservice.PublisherHandler,
+	seriesHandler *seriesservice.SeriesHandler,
+	priceHandler *priceservice.PriceHandler,
+	promoHandler *promoservice.PromoHandler,
+	currencyHandler *currencyservice.CurrencyHandler,
+	reviewHandler *reviewservice.ReviewHandler,
+	shelfHandler *shelfservice.ShelfHandler,
+	alertHandler *alertservice.AlertHandler,
+	recommendHandler *recommendservice.RecommendHandler,
+	collectionHandler *collectionservice.CollectionHandler,
+	orderHandler *orderservice.OrderHandler,
+	paymentHandler *paymentservice.PaymentHandler,
+	giftCardHandler *giftcardservice.GiftCardHandler,
+	returnHandler *returnservice.ReturnHandler,
+	addressHandler *addressservice.AddressHandler,
+	deliveryHandler *deliveryservice.DeliveryHandler,
+	preorderHandler *preorderservice.PreorderHandler,
+	invoiceHandler *invoiceservice.InvoiceHandler,
+	taxHandler *taxservice.TaxHandler,
+	importHandler *importservice.ImportHandler,
+	exportHandler *exportservice.ExportHandler,
+	ebookHandler *ebookservice.EbookHandler,
+	trashHandler *trashservice.TrashHandler,
+	auditHandler *auditservice.AuditHandler,
 ) {
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
@@ -108,6 +308,7 @@ func registerRoutes(e *echo.Echo,
 
 	users := e.Group("/users", authMiddleware)
 	users.GET("/me", userHandler.ReadSelf)
+	users.GET("/me/recommendations", recommendHandler.ReadForUser)
 	users.GET("/:id", userHandler.ReadUser)
 	users.PUT("/me", userHandler.UpdateUser)
 	users.PATCH("/me/password", userHandler.ChangePassword)
@@ -115,8 +316,189 @@ func registerRoutes(e *echo.Echo,
 
 	books := e.Group("/books")
 	books.POST("", bookHandler.CreateBook, authMiddleware)
-	books.GET("", bookHandler.ReadBooks)
-	books.GET("/:id", bookHandler.ReadBook)
+	books.GET("", bookHandler.ReadBooks, optionalAuthMiddleware)
+	books.GET("/:id", bookHandler.ReadBook, optionalAuthMiddleware)
+	books.GET("/isbn/:isbn", bookHandler.ReadBookByIsbn, optionalAuthMiddleware)
 	books.PUT("/:id", bookHandler.UpdateBook, authMiddleware)
 	books.DELETE("/:id", bookHandler.DeleteBook, authMiddleware)
+	books.GET("/:id/prices", priceHandler.ReadTimeline)
+	books.GET("/:id/related", recommendHandler.ReadRelated)
+	books.POST("/:id/prices/scheduled", priceHandler.SchedulePriceChange, authMiddleware)
+	books.DELETE("/:id/prices/scheduled/:changeId", priceHandler.CancelScheduledChange, authMiddleware)
+	books.PUT("/:id/currency-prices/:currency", currencyHandler.SetBookPrice, authMiddleware)
+	books.DELETE("/:id/currency-prices/:currency", currencyHandler.DeleteBookPrice, authMiddleware)
+	books.GET("/:id/reviews", reviewHandler.ReadReviews, optionalAuthMiddleware)
+	books.POST("/:id/reviews", reviewHandler.CreateReview, authMiddleware)
+	books.POST("/:id/stock-receipts", preorderHandler.ReceiveStock, authMiddleware)
+	books.GET("/:id/ebook-files", ebookHandler.ReadFiles, authMiddleware)
+	books.PUT("/:id/ebook-files/:format", ebookHandler.UploadFile, authMiddleware)
+	books.DELETE("/:id/ebook-files/:format", ebookHandler.DeleteFile, authMiddleware)
+
+	e.GET("/isbn/:isbn", bookHandler.ConvertIsbn)
+
+	reviews := e.Group("/reviews", authMiddleware)
+	reviews.PUT("/:id", reviewHandler.UpdateReview)
+	reviews.DELETE("/:id", reviewHandler.DeleteReview)
+	reviews.PATCH("/:id/visibility", reviewHandler.SetVisibility)
+
+	e.GET("/shelves/shared/:slug", shelfHandler.ReadPublicShelf)
+
+	shelves := e.Group("/shelves", authMiddleware)
+	shelves.GET("", shelfHandler.ReadShelves)
+	shelves.POST("", shelfHandler.CreateShelf)
+	shelves.GET("/:id", shelfHandler.ReadShelf)
+	shelves.PUT("/:id", shelfHandler.UpdateShelf)
+	shelves.DELETE("/:id", shelfHandler.DeleteShelf)
+	shelves.POST("/:id/books", shelfHandler.AddBook)
+	shelves.PUT("/:id/books/order", shelfHandler.ReorderBooks)
+	shelves.DELETE("/:id/books/:bookId", shelfHandler.RemoveBook)
+
+	books.POST("/:id/alerts", alertHandler.Subscribe, authMiddleware)
+
+	e.GET("/alerts/unsubscribe/:token", alertHandler.UnsubscribeByToken)
+
+	alerts := e.Group("/alerts", authMiddleware)
+	alerts.GET("", alertHandler.ReadAlerts)
+	alerts.DELETE("/:id", alertHandler.Unsubscribe)
+
+	e.GET("/lists/new-arrivals", collectionHandler.ReadNewArrivals)
+	e.GET("/lists/bestsellers", collectionHandler.ReadBestsellers)
+
+	authors := e.Group("/authors")
+	authors.GET("", authorHandler.ReadAuthors)
+	authors.GET("/:id", authorHandler.ReadAuthor)
+	authors.POST("", authorHandler.CreateAuthor, authMiddleware)
+	authors.PUT("/:id", authorHandler.UpdateAuthor, authMiddleware)
+	authors.DELETE("/:id", authorHandler.DeleteAuthor, authMiddleware)
+	authors.POST("/:id/merge", authorHandler.MergeAuthors, authMiddleware)
+
+	publishers := e.Group("/publishers")
+	publishers.GET("", publisherHandler.ReadPublishers)
+	publishers.GET("/:id", publisherHandler.ReadPublisher)
+	publishers.POST("", publisherHandler.CreatePublisher, authMiddleware)
+	publishers.PUT("/:id", publisherHandler.UpdatePublisher, authMiddleware)
+	publishers.DELETE("/:id", publisherHandler.DeletePublisher, authMiddleware)
+	publishers.POST("/:id/merge", publisherHandler.MergePublishers, authMiddleware)
+
+	series := e.Group("/series")
+	series.GET("", seriesHandler.ReadAllSeries)
+	series.GET("/:id", seriesHandler.ReadSeries)
+	series.POST("", seriesHandler.CreateSeries, authMiddleware)
+	series.PUT("/:id", seriesHandler.UpdateSeries, authMiddleware)
+	series.DELETE("/:id", seriesHandler.DeleteSeries, authMiddleware)
+	series.PUT("/:id/volumes", seriesHandler.ReorderVolumes, authMiddleware)
+
+	works := e.Group("/works")
+	works.GET("/:id", seriesHandler.ReadWork)
+	works.POST("", seriesHandler.CreateWork, authMiddleware)
+	works.PUT("/:id", seriesHandler.UpdateWork, authMiddleware)
+	works.DELETE("/:id", seriesHandler.DeleteWork, authMiddleware)
+
+	collections := e.Group("/collections")
+	collections.GET("", collectionHandler.ReadCollections, optionalAuthMiddleware)
+	collections.GET("/:slug", collectionHandler.ReadCollection, optionalAuthMiddleware)
+	collections.POST("", collectionHandler.CreateCollection, authMiddleware)
+	collections.PUT("/:slug", collectionHandler.UpdateCollection, authMiddleware)
+	collections.DELETE("/:slug", collectionHandler.DeleteCollection, authMiddleware)
+
+	e.POST("/payments/webhooks/:provider", paymentHandler.Webhook)
+
+	payments := e.Group("/payments", authMiddleware)
+	payments.POST("", paymentHandler.CreatePayment)
+	payments.GET("/:id", paymentHandler.ReadPayment)
+	payments.POST("/:id/capture", paymentHandler.Capture)
+	payments.POST("/:id/refund", paymentHandler.Refund)
+	payments.POST("/fake/:providerId/confirm", paymentHandler.FakeConfirm)
+
+	giftCards := e.Group("/gift-cards", authMiddleware)
+	giftCards.POST("", giftCardHandler.IssueCard)
+	giftCards.POST("/purchase", giftCardHandler.PurchaseCard)
+	giftCards.GET("/:code", giftCardHandler.ReadCard)
+	giftCards.POST("/:code/redeem", giftCardHandler.Redeem)
+
+	e.GET("/store-credit", giftCardHandler.ReadStoreCredit, authMiddleware)
+
+	returns := e.Group("/returns", authMiddleware)
+	returns.POST("", returnHandler.CreateReturn)
+	returns.GET("", returnHandler.ReadReturns)
+	returns.GET("/:id", returnHandler.ReadReturn)
+	returns.POST("/:id/approve", returnHandler.Approve)
+	returns.POST("/:id/reject", returnHandler.Reject)
+	returns.POST("/:id/refund", returnHandler.RetryRefund)
+
+	addresses := e.Group("/addresses", authMiddleware)
+	addresses.GET("", addressHandler.ReadAddresses)
+	addresses.POST("", addressHandler.CreateAddress)
+	addresses.GET("/:id", addressHandler.ReadAddress)
+	addresses.PUT("/:id", addressHandler.UpdateAddress)
+	addresses.DELETE("/:id", addressHandler.DeleteAddress)
+	addresses.PUT("/:id/default", addressHandler.SetDefault)
+
+	delivery := e.Group("/delivery")
+	delivery.GET("/methods", deliveryHandler.ReadMethods, optionalAuthMiddleware)
+	delivery.POST("/methods", deliveryHandler.CreateMethod, authMiddleware)
+	delivery.PUT("/methods/:id", deliveryHandler.UpdateMethod, authMiddleware)
+	delivery.DELETE("/methods/:id", deliveryHandler.DeleteMethod, authMiddleware)
+	delivery.POST("/quote", deliveryHandler.QuoteDelivery)
+
+	preorders := e.Group("/preorders", authMiddleware)
+	preorders.POST("", preorderHandler.CreatePreorder)
+	preorders.GET("", preorderHandler.ReadPreorders)
+	preorders.GET("/:id", preorderHandler.ReadPreorder)
+	preorders.POST("/:id/cancel", preorderHandler.CancelPreorder)
+	preorders.POST("/:id/ship", preorderHandler.ShipPreorder)
+
+	orders := e.Group("/orders", authMiddleware)
+	orders.POST("", orderHandler.CreateOrder)
+	orders.POST("/quote", orderHandler.QuoteOrder)
+	orders.POST("/gift-card", orderHandler.CreateGiftCardOrder)
+	orders.GET("", orderHandler.ReadOrders)
+	orders.GET("/:id", orderHandler.ReadOrder)
+	orders.POST("/:id/cancel", orderHandler.CancelOrder)
+	orders.GET("/:id/invoice.pdf", invoiceHandler.ReadInvoice)
+
+	e.GET("/tax/rules", taxHandler.ReadRules)
+
+	e.GET("/currencies", currencyHandler.ReadRates)
+
+	promo := e.Group("/promo", authMiddleware)
+	promo.POST("/campaigns", promoHandler.CreateCampaign)
+	promo.GET("/campaigns", promoHandler.ReadCampaigns)
+	promo.GET("/campaigns/:id", promoHandler.ReadCampaign)
+	promo.PUT("/campaigns/:id", promoHandler.UpdateCampaign)
+	promo.DELETE("/campaigns/:id", promoHandler.DeleteCampaign)
+	promo.POST("/codes", promoHandler.CreatePromoCode)
+	promo.GET("/codes", promoHandler.ReadPromoCodes)
+	promo.DELETE("/codes/:id", promoHandler.DeletePromoCode)
+	promo.POST("/quote", promoHandler.Quote)
+
+	imports := e.Group("/imports", authMiddleware)
+	imports.POST("", importHandler.CreateImport)
+	imports.GET("/:id", importHandler.ReadImport)
+	imports.GET("/:id/errors.csv", importHandler.ReadImportErrors)
+
+	exports := e.Group("/exports", authMiddleware)
+	exports.GET("/books", exportHandler.ExportBooks)
+
+	e.GET("/ebooks/download/:id/:format", ebookHandler.Download)
+	e.POST("/ebooks/purchases", ebookHandler.GrantPurchase, authMiddleware)
+
+	library := e.Group("/library", authMiddleware)
+	library.GET("", ebookHandler.ReadLibrary)
+	library.POST("/:id/links", ebookHandler.CreateLink)
+
+	trash := e.Group("/trash", authMiddleware)
+	trash.GET("/books", trashHandler.ReadDeletedBooks)
+	trash.GET("/users", trashHandler.ReadDeletedUsers)
+	trash.POST("/books/:id/restore", trashHandler.RestoreBook)
+	trash.POST("/users/:id/restore", trashHandler.RestoreUser)
+	trash.DELETE("/books/:id", trashHandler.DeleteBook)
+	trash.DELETE("/users/:id", trashHandler.DeleteUser)
+
+	admin := e.Group("/admin", authMiddleware)
+	admin.GET("/audit", auditHandler.ReadRecords)
+	admin.PUT("/rates/:currency", currencyHandler.SetRate)
+	admin.DELETE("/rates/:currency", currencyHandler.DeleteRate)
+	admin.POST("/tax-rules/reload", taxHandler.ReloadRules)
+	admin.POST("/ebooks/identify", ebookHandler.IdentifyCopy)
 }
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"story-book/internal/config"
+	"story-book/internal/pricing"
+	"story-book/internal/services/exportservice"
+	"story-book/internal/services/taxservice"
+	"story-book/package/databases/postgres"
+	"syscall"
+	"time"
+)
+
+// Export writes the catalogue to a file or standard output, as
+// GET /exports/books does:
+//
+//	go run main.go export -format onix -since 2026-10-01T00:00:00+03:00 -out feed.xml
+func Export(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", exportservice.FormatCsv, "csv, jsonl or onix")
+	sinceStr := flags.String("since", "", "export only books changed since this RFC 3339 timestamp")
+	out := flags.String("out", "", "write to this file instead of standard output")
+	authorId := flags.String("author-id", "", "only books credited to this author")
+	publisherId := flags.String("publisher-id", "", "only books of this publisher")
+	bookFormat := flags.String("book-format", "", "only books of this format: hardcover, paperback, ebook or audiobook")
+	language := flags.String("language", "", "only books in this ISO 639-1 language")
+	inStock := flags.Bool("in-stock", false, "only books in stock")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	filter := exportservice.Filter{
+		AuthorId:    *authorId,
+		PublisherId: *publisherId,
+		Format:      *bookFormat,
+		Language:    *language,
+		InStock:     *inStock,
+	}
+	if *sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, *sinceStr)
+		if err != nil {
+			return fmt.Errorf("export: -since: %w", exportservice.ErrInvalidSince)
+		}
+		filter.Since = &since
+	}
+
+	db, err := postgres.InitDB(cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Username, cfg.Postgres.Password, cfg.Postgres.Database)
+	if err != nil {
+		return err
+	}
+	defer postgres.CloseDB(db)
+
+	exportService := exportservice.NewExportService(
+		exportservice.NewExportRepository(db),
+		taxservice.NewTaxRepository(db),
+		pricing.NewEngine(cfg.BaseCurrency, cfg.PriceRounding),
+		cfg.Tax.Region,
+		cfg.BaseCurrency,
+		cfg.Store.Name,
+	)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	count, err := exportService.Export(ctx, *format, filter, w)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("exported %d books; next since %s", count, exportservice.NextSince(start).Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"story-book/internal/config"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"story-book/internal/services/alertservice"
+	"story-book/internal/services/auditservice"
+	"story-book/internal/services/bookservice"
+	"story-book/internal/services/importservice"
+	"story-book/internal/services/preorderservice"
+	"story-book/internal/services/promoservice"
+	"story-book/package/databases/postgres"
+	"syscall"
+)
+
+// Import loads a CSV or XLSX file into the catalogue from the command line,
+// with the same matching and checks as POST /imports:
+//
+//	go run main.go import -file prices.xlsx -mapping '{"cost": "Цена"}' -dry-run -errors errors.csv
+func Import(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "CSV or XLSX file to import")
+	mappingJson := flags.String("mapping", "", `JSON object of book fields to column headers, e.g. {"title": "Название"}`)
+	dryRun := flags.Bool("dry-run", false, "only check the rows, change nothing")
+	errorsFile := flags.String("errors", "", "write row errors as CSV to this file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("import: -file is required")
+	}
+
+	mapping := map[string]string{}
+	if *mappingJson != "" {
+		if err := json.Unmarshal([]byte(*mappingJson), &mapping); err != nil {
+			return fmt.Errorf("import: -mapping: %w", err)
+		}
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	db, err := postgres.InitDB(cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Username, cfg.Postgres.Password, cfg.Postgres.Database)
+	if err != nil {
+		return err
+	}
+	defer postgres.CloseDB(db)
+
+	auditService := auditservice.NewAuditService(auditservice.NewAuditRepository(db))
+	promoService := promoservice.NewPromoService(promoservice.NewPromoRepository(db), pricing.NewEngine(cfg.BaseCurrency, cfg.PriceRounding))
+	alertService := alertservice.NewAlertService(alertservice.NewAlertRepository(db), promoService, alertservice.NewLogNotifier(), cfg.PublicUrl)
+	// Importing only announces pre-order delays, which places no orders.
+	preorderService := preorderservice.NewPreorderService(preorderservice.NewPreorderRepository(db), nil)
+	bookService := bookservice.NewBookService(bookservice.NewBookRepository(db), auditService, alertService, preorderService)
+	importService := importservice.NewImportService(importservice.NewImportRepository(db), bookService)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	job, err := importService.ImportNow(ctx, &entities.ImportJob{
+		FileName: filepath.Base(*file),
+		Data:     data,
+		DryRun:   *dryRun,
+	}, mapping, func(job *entities.ImportJob) {
+		log.Printf("processed %d of %d rows", job.ProcessedRows, job.TotalRows)
+	})
+	if err != nil {
+		return err
+	}
+
+	if job.Status == importservice.StatusFailed {
+		return fmt.Errorf("import %s failed: %s", job.Id, *job.Error)
+	}
+
+	verb := "imported"
+	if job.DryRun {
+		verb = "checked"
+	}
+	log.Printf("import %s %s: %d created, %d updated, %d failed", job.Id, verb, job.CreatedRows, job.UpdatedRows, job.FailedRows)
+
+	if *errorsFile == "" || job.FailedRows == 0 {
+		return nil
+	}
+
+	rowErrors, err := importService.ReadErrors(ctx, job.Id)
+	if err != nil {
+		return err
+	}
+	report, err := importservice.WriteErrors(rowErrors)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(*errorsFile, report, 0o644); err != nil {
+		return err
+	}
+	log.Printf("row errors written to %s", *errorsFile)
+
+	return nil
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"story-book/internal/pricing"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -24,9 +26,62 @@ type Config struct {
 		RefreshDuration time.Duration
 	}
 
-	BackendPort     string
-	SaltLength      int
-	MinPasswordSize int
+	Trash struct {
+		Retention     time.Duration
+		PurgeInterval time.Duration
+	}
+
+	Recommendations struct {
+		Interval time.Duration
+		CacheTTL time.Duration
+	}
+
+	Payments struct {
+		Provider          string
+		WebhookSecret     string
+		ReconcileInterval time.Duration
+	}
+
+	GiftCards struct {
+		Validity time.Duration
+	}
+
+	Store struct {
+		Name    string
+		Address string
+		TaxId   string
+	}
+
+	Tax struct {
+		Region    string
+		RulesFile string
+	}
+
+	Invoices struct {
+		Dir      string
+		Font     string
+		FontBold string
+	}
+	Imports struct {
+		Interval time.Duration
+	}
+
+	Ebooks struct {
+		Dir           string
+		SigningKey    string
+		LinkTTL       time.Duration
+		DownloadLimit int
+	}
+
+	BackendPort            string
+	SaltLength             int
+	MinPasswordSize        int
+	PriceSchedulerInterval time.Duration
+	PriceRounding          pricing.Rounding
+	BaseCurrency           string
+	RatesFile              string
+	PublicUrl              string
+	AlertInterval          time.Duration
 }
 
 func Load() *Config {
@@ -68,5 +123,102 @@ func Load() *Config {
 	}
 	cfg.JWT.RefreshDuration = refresh
 
+	retention, err := time.ParseDuration(os.Getenv("TRASH_RETENTION"))
+	if err != nil || retention <= 0 {
+		log.Fatal("invalid TRASH_RETENTION")
+	}
+	cfg.Trash.Retention = retention
+	purgeInterval, err := time.ParseDuration(os.Getenv("TRASH_PURGE_INTERVAL"))
+	if err != nil || purgeInterval <= 0 {
+		log.Fatal("invalid TRASH_PURGE_INTERVAL")
+	}
+	cfg.Trash.PurgeInterval = purgeInterval
+
+	priceSchedulerInterval, err := time.ParseDuration(os.Getenv("PRICE_SCHEDULER_INTERVAL"))
+	if err != nil || priceSchedulerInterval <= 0 {
+		log.Fatal("invalid PRICE_SCHEDULER_INTERVAL")
+	}
+	cfg.PriceSchedulerInterval = priceSchedulerInterval
+
+	priceRounding, err := pricing.ParseRounding(os.Getenv("PRICE_ROUNDING"))
+	if err != nil {
+		log.Fatal("invalid PRICE_ROUNDING")
+	}
+	cfg.PriceRounding = priceRounding
+
+	cfg.BaseCurrency = strings.ToUpper(os.Getenv("BASE_CURRENCY"))
+	if len(cfg.BaseCurrency) != 3 {
+		log.Fatal("invalid BASE_CURRENCY")
+	}
+	cfg.RatesFile = os.Getenv("RATES_FILE")
+
+	cfg.PublicUrl = os.Getenv("PUBLIC_URL")
+	alertInterval, err := time.ParseDuration(os.Getenv("ALERT_INTERVAL"))
+	if err != nil || alertInterval <= 0 {
+		log.Fatal("invalid ALERT_INTERVAL")
+	}
+	cfg.AlertInterval = alertInterval
+
+	recommendationInterval, err := time.ParseDuration(os.Getenv("RECOMMENDATION_INTERVAL"))
+	if err != nil || recommendationInterval <= 0 {
+		log.Fatal("invalid RECOMMENDATION_INTERVAL")
+	}
+	cfg.Recommendations.Interval = recommendationInterval
+	recommendationCacheTTL, err := time.ParseDuration(os.Getenv("RECOMMENDATION_CACHE_TTL"))
+	if err != nil {
+		log.Fatal("invalid RECOMMENDATION_CACHE_TTL")
+	}
+	cfg.Recommendations.CacheTTL = recommendationCacheTTL
+
+	cfg.Payments.Provider = os.Getenv("PAYMENT_PROVIDER")
+	cfg.Payments.WebhookSecret = os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if cfg.Payments.WebhookSecret == "" {
+		log.Fatal("invalid PAYMENT_WEBHOOK_SECRET")
+	}
+	reconcileInterval, err := time.ParseDuration(os.Getenv("PAYMENT_RECONCILE_INTERVAL"))
+	if err != nil || reconcileInterval <= 0 {
+		log.Fatal("invalid PAYMENT_RECONCILE_INTERVAL")
+	}
+	cfg.Payments.ReconcileInterval = reconcileInterval
+
+	giftCardValidity, err := time.ParseDuration(os.Getenv("GIFT_CARD_VALIDITY"))
+	if err != nil {
+		log.Fatal("invalid GIFT_CARD_VALIDITY")
+	}
+	cfg.GiftCards.Validity = giftCardValidity
+
+	cfg.Store.Name = os.Getenv("STORE_NAME")
+	cfg.Store.Address = os.Getenv("STORE_ADDRESS")
+	cfg.Store.TaxId = os.Getenv("STORE_TAX_ID")
+
+	cfg.Tax.Region = strings.ToUpper(os.Getenv("TAX_REGION"))
+	cfg.Tax.RulesFile = os.Getenv("TAX_RULES_FILE")
+
+	cfg.Invoices.Dir = os.Getenv("INVOICE_DIR")
+	cfg.Invoices.Font = os.Getenv("INVOICE_FONT")
+	cfg.Invoices.FontBold = os.Getenv("INVOICE_FONT_BOLD")
+
+	importInterval, err := time.ParseDuration(os.Getenv("IMPORT_INTERVAL"))
+	if err != nil || importInterval <= 0 {
+		log.Fatal("invalid IMPORT_INTERVAL")
+	}
+	cfg.Imports.Interval = importInterval
+
+	cfg.Ebooks.Dir = os.Getenv("EBOOK_DIR")
+	cfg.Ebooks.SigningKey = os.Getenv("EBOOK_SIGNING_KEY")
+	if cfg.Ebooks.SigningKey == "" {
+		log.Fatal("invalid EBOOK_SIGNING_KEY")
+	}
+	ebookLinkTTL, err := time.ParseDuration(os.Getenv("EBOOK_LINK_TTL"))
+	if err != nil {
+		log.Fatal("invalid EBOOK_LINK_TTL")
+	}
+	cfg.Ebooks.LinkTTL = ebookLinkTTL
+	ebookDownloadLimit, err := strconv.Atoi(os.Getenv("EBOOK_DOWNLOAD_LIMIT"))
+	if err != nil || ebookDownloadLimit < 1 {
+		log.Fatal("invalid EBOOK_DOWNLOAD_LIMIT")
+	}
+	cfg.Ebooks.DownloadLimit = ebookDownloadLimit
+
 	return cfg
 }
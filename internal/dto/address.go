@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type AddressRequest struct {
+	Label      *string `json:"label"`
+	Recipient  string  `json:"recipient"`
+	Phone      string  `json:"phone"`
+	Country    string  `json:"country"`
+	City       string  `json:"city"`
+	PostalCode string  `json:"postal_code"`
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2"`
+	IsDefault  bool    `json:"is_default"`
+}
+
+type AddressResponse struct {
+	Id         string    `json:"id"`
+	Label      string    `json:"label,omitempty"`
+	Recipient  string    `json:"recipient"`
+	Phone      string    `json:"phone"`
+	Country    string    `json:"country"`
+	City       string    `json:"city"`
+	PostalCode string    `json:"postal_code"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2,omitempty"`
+	IsDefault  bool      `json:"is_default"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
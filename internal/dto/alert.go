@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type AlertRequest struct {
+	Kind      string   `json:"kind"`
+	Threshold *float64 `json:"threshold"`
+}
+
+type AlertResponse struct {
+	Id        string    `json:"id"`
+	BookId    string    `json:"book_id"`
+	Kind      string    `json:"kind"`
+	Threshold float64   `json:"threshold,omitempty"`
+	Armed     bool      `json:"armed"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditRecordResponse struct {
+	Id         string          `json:"id"`
+	ActorId    string          `json:"actor_id,omitempty"`
+	ActorRole  string          `json:"actor_role,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityId   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	Ip         string          `json:"ip,omitempty"`
+	RequestId  string          `json:"request_id,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
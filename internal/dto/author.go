@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+type AuthorRequest struct {
+	Name      string   `json:"name"`
+	Biography *string  `json:"biography"`
+	Photo     *string  `json:"photo"`
+	Aliases   []string `json:"aliases"`
+}
+
+type AuthorResponse struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Biography string    `json:"biography,omitempty"`
+	Photo     string    `json:"photo,omitempty"`
+	Aliases   []string  `json:"aliases"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PublisherRequest struct {
+	Name        string   `json:"name"`
+	Description *string  `json:"description"`
+	Logo        *string  `json:"logo"`
+	Aliases     []string `json:"aliases"`
+}
+
+type PublisherResponse struct {
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Logo        string    `json:"logo,omitempty"`
+	Aliases     []string  `json:"aliases"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type MergeRequest struct {
+	// SourceId is the duplicate that is merged into the target and deleted.
+	SourceId string `json:"source_id"`
+}
+
+type BookAuthorRequest struct {
+	AuthorId string `json:"author_id"`
+	// Role is author, translator or illustrator; author by default.
+	Role string `json:"role"`
+}
+
+type BookAuthorResponse struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
@@ -1,30 +1,88 @@
 package dto
 
+import "story-book/internal/pricing"
+
 type BookRequest struct {
-	Title       string  `json:"title"`
-	Author      string  `json:"author"`
-	Year        int     `json:"year"`
-	Cost        float64 `json:"cost"`
-	Discount    *int    `json:"discount"`
-	Publisher   string  `json:"publisher"`
+	Title string `json:"title"`
+	// Isbn is an ISBN-10 or ISBN-13, with or without hyphens.
+	Isbn      *string `json:"isbn"`
+	Author    string  `json:"author"`
+	Year      int     `json:"year"`
+	Cost      float64 `json:"cost"`
+	Discount  *int    `json:"discount"`
+	Publisher string  `json:"publisher"`
+	// Authors link the book to author entities and replace Author. Without
+	// them Author is matched by name or alias, creating the author if needed.
+	Authors []BookAuthorRequest `json:"authors"`
+	// PublisherId links the book to a publisher and replaces Publisher.
+	PublisherId *string `json:"publisher_id"`
 	Description *string `json:"description"`
 	Amount      int     `json:"amount"`
-	Image       *string `json:"image"`
+	WeightGrams *int    `json:"weight_grams"`
+	WidthMm     *int    `json:"width_mm"`
+	HeightMm    *int    `json:"height_mm"`
+	DepthMm     *int    `json:"depth_mm"`
+	TaxCategory string  `json:"tax_category"`
+	// WorkId makes the book an edition of an existing work. Without it a new
+	// work with the book's title is created.
+	WorkId *string `json:"work_id"`
+	// Format is hardcover, paperback, ebook or audiobook.
+	Format *string `json:"format"`
+	// Language is a two-letter ISO 639-1 code.
+	Language *string `json:"language"`
+	Pages    *int    `json:"pages"`
+	// ReleaseDate is the announced publication date, YYYY-MM-DD.
+	ReleaseDate *string `json:"release_date"`
+	// Preorder opens the book for pre-orders while it is out of stock.
+	Preorder *bool   `json:"preorder"`
+	Image    *string `json:"image"`
 }
 
 type BookResponse struct {
-	Id          string  `json:"id"`
-	Title       string  `json:"title"`
-	Author      string  `json:"author"`
-	Year        int     `json:"year"`
-	Cost        float64 `json:"cost"`
-	Discount    int     `json:"discount,omitempty"`
-	Publisher   string  `json:"publisher"`
-	Description string  `json:"description,omitempty"`
-	Amount      int     `json:"amount"`
-	Image       string  `json:"image,omitempty"`
+	Id             string               `json:"id"`
+	Title          string               `json:"title"`
+	Isbn13         string               `json:"isbn_13,omitempty"`
+	Isbn10         string               `json:"isbn_10,omitempty"`
+	Author         string               `json:"author"`
+	Year           int                  `json:"year"`
+	Cost           float64              `json:"cost"`
+	Discount       int                  `json:"discount,omitempty"`
+	Currency       string               `json:"currency"`
+	Price          pricing.Money        `json:"price" swaggertype:"number"`
+	DiscountAmount pricing.Money        `json:"discount_amount" swaggertype:"number"`
+	FinalPrice     pricing.Money        `json:"final_price" swaggertype:"number"`
+	Publisher      string               `json:"publisher"`
+	PublisherId    string               `json:"publisher_id,omitempty"`
+	Authors        []BookAuthorResponse `json:"authors"`
+	Description    string               `json:"description,omitempty"`
+	Amount         int                  `json:"amount"`
+	WeightGrams    int                  `json:"weight_grams,omitempty"`
+	WidthMm        int                  `json:"width_mm,omitempty"`
+	HeightMm       int                  `json:"height_mm,omitempty"`
+	DepthMm        int                  `json:"depth_mm,omitempty"`
+	TaxCategory    string               `json:"tax_category"`
+	Tax            *TaxResponse         `json:"tax,omitempty"`
+	WorkId         string               `json:"work_id"`
+	Format         string               `json:"format,omitempty"`
+	Language       string               `json:"language,omitempty"`
+	Pages          int                  `json:"pages,omitempty"`
+	ReleaseDate    string               `json:"release_date,omitempty"`
+	Preorder       bool                 `json:"preorder"`
+	Rating         float64              `json:"rating"`
+	ReviewCount    int                  `json:"review_count"`
+	Image          string               `json:"image,omitempty"`
+	// Editions are the other books of the same work and Series the place of
+	// the work in its series. Both are shown for a single book only.
+	Editions []EditionResponse   `json:"editions,omitempty"`
+	Series   *BookSeriesResponse `json:"series,omitempty"`
 }
 
 type BookListResponse struct {
 	Books []BookResponse `json:"books"`
 }
+
+type IsbnResponse struct {
+	Isbn13 string `json:"isbn_13"`
+	// Isbn10 is empty for ISBN-13 numbers with the 979 prefix.
+	Isbn10 string `json:"isbn_10,omitempty"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type CollectionRequest struct {
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	Description    *string    `json:"description"`
+	Cover          *string    `json:"cover"`
+	PublishedFrom  *time.Time `json:"published_from"`
+	PublishedUntil *time.Time `json:"published_until"`
+	BookIds        []string   `json:"book_ids"`
+}
+
+type ListedBookResponse struct {
+	BookId    string    `json:"book_id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Year      int       `json:"year"`
+	Rating    float64   `json:"rating"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BestsellerResponse struct {
+	BookId string  `json:"book_id"`
+	Title  string  `json:"title"`
+	Author string  `json:"author"`
+	Year   int     `json:"year"`
+	Rating float64 `json:"rating"`
+	Sold   int     `json:"sold"`
+}
+
+type CollectionResponse struct {
+	Id             string               `json:"id"`
+	Slug           string               `json:"slug"`
+	Title          string               `json:"title"`
+	Description    string               `json:"description,omitempty"`
+	Cover          string               `json:"cover,omitempty"`
+	PublishedFrom  *time.Time           `json:"published_from,omitempty"`
+	PublishedUntil *time.Time           `json:"published_until,omitempty"`
+	Books          []ListedBookResponse `json:"books,omitempty"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
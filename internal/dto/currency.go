@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type ExchangeRateRequest struct {
+	Rate string `json:"rate"`
+}
+
+type ExchangeRateResponse struct {
+	Currency  string    `json:"currency"`
+	Rate      float64   `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExchangeRatesResponse struct {
+	Base  string                 `json:"base"`
+	Rates []ExchangeRateResponse `json:"rates"`
+}
+
+type CurrencyPriceRequest struct {
+	Cost float64 `json:"cost"`
+}
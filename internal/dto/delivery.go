@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+type DeliveryMethodRequest struct {
+	Code           string         `json:"code"`
+	Kind           string         `json:"kind"`
+	Name           string         `json:"name"`
+	BaseCost       pricing.Money  `json:"base_cost" swaggertype:"number"`
+	CostPerKg      pricing.Money  `json:"cost_per_kg" swaggertype:"number"`
+	FreeFrom       *pricing.Money `json:"free_from" swaggertype:"number"`
+	MaxWeightGrams *int           `json:"max_weight_grams"`
+	Active         bool           `json:"active"`
+}
+
+type DeliveryMethodResponse struct {
+	Id             string         `json:"id"`
+	Code           string         `json:"code"`
+	Kind           string         `json:"kind"`
+	Name           string         `json:"name"`
+	BaseCost       pricing.Money  `json:"base_cost" swaggertype:"number"`
+	CostPerKg      pricing.Money  `json:"cost_per_kg" swaggertype:"number"`
+	FreeFrom       *pricing.Money `json:"free_from,omitempty" swaggertype:"number"`
+	MaxWeightGrams int            `json:"max_weight_grams,omitempty"`
+	Active         bool           `json:"active"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+type DeliveryItemRequest struct {
+	BookId   string `json:"book_id"`
+	Quantity int    `json:"quantity"`
+}
+
+type DeliveryQuoteRequest struct {
+	Items []DeliveryItemRequest `json:"items"`
+}
+
+type DeliveryOptionResponse struct {
+	MethodId  string        `json:"method_id"`
+	Code      string        `json:"code"`
+	Kind      string        `json:"kind"`
+	Name      string        `json:"name"`
+	Cost      pricing.Money `json:"cost" swaggertype:"number"`
+	Available bool          `json:"available"`
+}
+
+type DeliveryQuoteResponse struct {
+	Currency    string                   `json:"currency"`
+	Total       pricing.Money            `json:"total" swaggertype:"number"`
+	WeightGrams int                      `json:"weight_grams"`
+	Options     []DeliveryOptionResponse `json:"options"`
+}
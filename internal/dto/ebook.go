@@ -0,0 +1,62 @@
+package dto
+
+import "time"
+
+type EbookFileResponse struct {
+	BookId string `json:"book_id"`
+	// Format is epub or pdf.
+	Format    string    `json:"format"`
+	Size      int64     `json:"size"`
+	Sha256    string    `json:"sha256"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type EbookPurchaseRequest struct {
+	UserId  string `json:"user_id"`
+	BookId  string `json:"book_id"`
+	OrderId string `json:"order_id"`
+	// DownloadLimit overrides the store's default number of downloads.
+	DownloadLimit *int `json:"download_limit"`
+}
+
+type EbookPurchaseResponse struct {
+	Id            string    `json:"id"`
+	UserId        string    `json:"user_id"`
+	BookId        string    `json:"book_id"`
+	OrderId       string    `json:"order_id"`
+	DownloadLimit int       `json:"download_limit"`
+	Downloads     int       `json:"downloads"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LibraryItemResponse is an e-book the user owns.
+type LibraryItemResponse struct {
+	PurchaseId string `json:"purchase_id"`
+	BookId     string `json:"book_id"`
+	Title      string `json:"title"`
+	Author     string `json:"author"`
+	OrderId    string `json:"order_id"`
+	// Formats are the file formats a download link can be made for.
+	Formats       []string  `json:"formats"`
+	DownloadsLeft int       `json:"downloads_left"`
+	PurchasedAt   time.Time `json:"purchased_at"`
+}
+
+type DownloadLinkResponse struct {
+	Url       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// WatermarkMatchResponse is a download whose watermark was found in a file.
+type WatermarkMatchResponse struct {
+	DownloadId   string    `json:"download_id"`
+	PurchaseId   string    `json:"purchase_id"`
+	OrderId      string    `json:"order_id"`
+	BookId       string    `json:"book_id"`
+	Title        string    `json:"title"`
+	UserId       string    `json:"user_id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Ip           string    `json:"ip,omitempty"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
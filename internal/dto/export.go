@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+// ExportBookResponse is one line of a JSON Lines catalogue export.
+type ExportBookResponse struct {
+	Id          string               `json:"id"`
+	Title       string               `json:"title"`
+	Isbn13      string               `json:"isbn_13,omitempty"`
+	Isbn10      string               `json:"isbn_10,omitempty"`
+	Author      string               `json:"author"`
+	Authors     []BookAuthorResponse `json:"authors"`
+	Publisher   string               `json:"publisher"`
+	PublisherId string               `json:"publisher_id,omitempty"`
+	WorkId      string               `json:"work_id"`
+	Year        int                  `json:"year"`
+	Cost        float64              `json:"cost"`
+	Discount    int                  `json:"discount,omitempty"`
+	Currency    string               `json:"currency"`
+	// Price is Cost less the book's own Discount; promotions are not
+	// included.
+	Price       pricing.Money `json:"price" swaggertype:"number"`
+	Format      string        `json:"format,omitempty"`
+	Language    string        `json:"language,omitempty"`
+	Pages       int           `json:"pages,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Amount      int           `json:"amount"`
+	TaxCategory string        `json:"tax_category"`
+	ReleaseDate string        `json:"release_date,omitempty"`
+	Preorder    bool          `json:"preorder"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	// Deleted books appear only in exports of changes since a timestamp.
+	Deleted bool `json:"deleted,omitempty"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+type GiftCardIssueRequest struct {
+	Amount   pricing.Money `json:"amount" swaggertype:"number"`
+	Currency string        `json:"currency"`
+	// ExpiresAt defaults to the configured validity from now.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type GiftCardPurchaseRequest struct {
+	// PaymentId is a paid payment of the buyer for a gift card order; what
+	// it took less refunds becomes the card's balance.
+	PaymentId string `json:"payment_id"`
+}
+
+type GiftCardRedeemRequest struct {
+	// Amount defaults to the whole balance. Either way no more than the
+	// order still owes is taken.
+	Amount *pricing.Money `json:"amount" swaggertype:"number"`
+	// OrderId is a pending order of the caller in the card's currency.
+	OrderId string `json:"order_id"`
+}
+
+type GiftCardTransactionResponse struct {
+	Id           string        `json:"id"`
+	Kind         string        `json:"kind"`
+	Amount       pricing.Money `json:"amount" swaggertype:"number"`
+	BalanceAfter pricing.Money `json:"balance_after" swaggertype:"number"`
+	OrderId      string        `json:"order_id,omitempty"`
+	ReturnId     string        `json:"return_id,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type GiftCardResponse struct {
+	Id             string                        `json:"id"`
+	Code           string                        `json:"code"`
+	Kind           string                        `json:"kind"`
+	InitialBalance pricing.Money                 `json:"initial_balance" swaggertype:"number"`
+	Balance        pricing.Money                 `json:"balance" swaggertype:"number"`
+	Currency       string                        `json:"currency"`
+	ExpiresAt      *time.Time                    `json:"expires_at,omitempty"`
+	Transactions   []GiftCardTransactionResponse `json:"transactions,omitempty"`
+	CreatedAt      time.Time                     `json:"created_at"`
+}
+
+type GiftCardRedeemResponse struct {
+	Redeemed    pricing.Money               `json:"redeemed" swaggertype:"number"`
+	Balance     pricing.Money               `json:"balance" swaggertype:"number"`
+	Currency    string                      `json:"currency"`
+	Transaction GiftCardTransactionResponse `json:"transaction"`
+}
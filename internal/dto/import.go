@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type ImportJobResponse struct {
+	Id       string `json:"id"`
+	FileName string `json:"file_name"`
+	Format   string `json:"format"`
+	DryRun   bool   `json:"dry_run"`
+	// Status is queued, running, done or failed.
+	Status        string `json:"status"`
+	TotalRows     int    `json:"total_rows"`
+	ProcessedRows int    `json:"processed_rows"`
+	// CreatedRows and UpdatedRows of a dry run count the books the import
+	// would create and update.
+	CreatedRows int `json:"created_rows"`
+	UpdatedRows int `json:"updated_rows"`
+	FailedRows  int `json:"failed_rows"`
+	// Progress is the share of processed rows, from 0 to 100.
+	Progress float64 `json:"progress"`
+	// Error explains why a failed job stopped; row errors are in ErrorFile.
+	Error      string     `json:"error,omitempty"`
+	ErrorFile  string     `json:"error_file,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+type OrderItemRequest struct {
+	BookId   string `json:"book_id"`
+	Quantity int    `json:"quantity"`
+}
+
+type OrderRequest struct {
+	Items    []OrderItemRequest `json:"items"`
+	Code     string             `json:"code"`
+	Currency string             `json:"currency"`
+	// DeliveryMethodId is required unless every book is an e-book.
+	DeliveryMethodId string `json:"delivery_method_id"`
+	// AddressId is required unless the delivery method is a pickup.
+	AddressId string `json:"address_id"`
+}
+
+type OrderItemResponse struct {
+	BookId      *string       `json:"book_id,omitempty"`
+	Title       string        `json:"title"`
+	Quantity    int           `json:"quantity"`
+	UnitPrice   pricing.Money `json:"unit_price" swaggertype:"number"`
+	Discount    pricing.Money `json:"discount" swaggertype:"number"`
+	TaxCategory string        `json:"tax_category"`
+	TaxRate     *float64      `json:"tax_rate,omitempty"`
+	Tax         pricing.Money `json:"tax" swaggertype:"number"`
+	Total       pricing.Money `json:"total" swaggertype:"number"`
+}
+
+type OrderDeliveryResponse struct {
+	MethodId *string       `json:"method_id,omitempty"`
+	Code     string        `json:"code"`
+	Name     string        `json:"name"`
+	Cost     pricing.Money `json:"cost" swaggertype:"number"`
+}
+
+type OrderAddressResponse struct {
+	AddressId  *string `json:"address_id,omitempty"`
+	Recipient  string  `json:"recipient"`
+	Phone      string  `json:"phone"`
+	Country    string  `json:"country"`
+	City       string  `json:"city"`
+	PostalCode string  `json:"postal_code"`
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2,omitempty"`
+}
+
+// OrderQuoteResponse is an order priced but not placed.
+type OrderQuoteResponse struct {
+	Currency     string                 `json:"currency"`
+	ExchangeRate float64                `json:"exchange_rate"`
+	PromoCode    *string                `json:"promo_code,omitempty"`
+	Items        []OrderItemResponse    `json:"items"`
+	Delivery     *OrderDeliveryResponse `json:"delivery,omitempty"`
+	Address      *OrderAddressResponse  `json:"address,omitempty"`
+	Subtotal     pricing.Money          `json:"subtotal" swaggertype:"number"`
+	Discount     pricing.Money          `json:"discount" swaggertype:"number"`
+	DeliveryCost pricing.Money          `json:"delivery_cost" swaggertype:"number"`
+	Tax          pricing.Money          `json:"tax" swaggertype:"number"`
+	Total        pricing.Money          `json:"total" swaggertype:"number"`
+}
+
+type GiftCardOrderRequest struct {
+	Amount pricing.Money `json:"amount" swaggertype:"number"`
+	// Currency defaults to the user's preference.
+	Currency string `json:"currency"`
+}
+
+type OrderResponse struct {
+	Id     string  `json:"id"`
+	UserId *string `json:"user_id,omitempty"`
+	Kind   string  `json:"kind"`
+	Status string  `json:"status"`
+	OrderQuoteResponse
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+type PaymentRequest struct {
+	// OrderId is a pending order of the caller. The amount and currency are
+	// taken from it.
+	OrderId string `json:"order_id"`
+}
+
+type FakeConfirmRequest struct {
+	Succeed bool `json:"succeed"`
+}
+
+type PaymentResponse struct {
+	Id           string        `json:"id"`
+	OrderId      string        `json:"order_id"`
+	Provider     string        `json:"provider"`
+	ProviderId   string        `json:"provider_id"`
+	ClientSecret string        `json:"client_secret,omitempty"`
+	Amount       pricing.Money `json:"amount" swaggertype:"number"`
+	Refunded     pricing.Money `json:"refunded_amount" swaggertype:"number"`
+	Currency     string        `json:"currency"`
+	Status       string        `json:"status"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
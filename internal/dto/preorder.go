@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+type PreorderRequest struct {
+	BookId   string `json:"book_id"`
+	Quantity int    `json:"quantity"`
+	Code     string `json:"code"`
+	Currency string `json:"currency"`
+	// DeliveryMethodId is required unless the book is an e-book.
+	DeliveryMethodId string `json:"delivery_method_id"`
+	// AddressId is required unless the delivery method is a pickup.
+	AddressId string `json:"address_id"`
+}
+
+type PreorderResponse struct {
+	Id          string     `json:"id"`
+	BookId      string     `json:"book_id"`
+	UserId      string     `json:"user_id"`
+	Quantity    int        `json:"quantity"`
+	Status      string     `json:"status"`
+	OrderId     *string    `json:"order_id,omitempty"`
+	AllocatedAt *time.Time `json:"allocated_at,omitempty"`
+	ShippedAt   *time.Time `json:"shipped_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type StockReceiptRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+type StockReceiptResponse struct {
+	Id       string `json:"id"`
+	BookId   string `json:"book_id"`
+	Quantity int    `json:"quantity"`
+	// Allocated is how many queued pre-orders the receipt served.
+	Allocated int `json:"allocated"`
+	// Held is how much of Amount is held for pre-orders still queued and
+	// cannot be ordered.
+	Held int `json:"held"`
+	// Amount is the stock after allocation.
+	Amount    int       `json:"amount"`
+	Preorder  bool      `json:"preorder"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+type ScheduledPriceRequest struct {
+	Cost        *float64  `json:"cost"`
+	Discount    *int      `json:"discount"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+type PricePointResponse struct {
+	Cost        float64   `json:"cost"`
+	Discount    int       `json:"discount,omitempty"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+type ScheduledPriceResponse struct {
+	Id          string    `json:"id"`
+	Cost        *float64  `json:"cost,omitempty"`
+	Discount    *int      `json:"discount,omitempty"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+type PriceTimelineResponse struct {
+	BookId    string                   `json:"book_id"`
+	History   []PricePointResponse     `json:"history"`
+	Scheduled []ScheduledPriceResponse `json:"scheduled"`
+}
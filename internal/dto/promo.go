@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+type CampaignTargetRequest struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"` // a genre name, or the ID of an author, publisher or book
+}
+
+type CampaignRequest struct {
+	Name          string                  `json:"name"`
+	Description   *string                 `json:"description"`
+	DiscountType  string                  `json:"discount_type"`
+	DiscountValue float64                 `json:"discount_value"`
+	Priority      int                     `json:"priority"`
+	Stackable     bool                    `json:"stackable"`
+	StartsAt      time.Time               `json:"starts_at"`
+	EndsAt        time.Time               `json:"ends_at"`
+	Targets       []CampaignTargetRequest `json:"targets"`
+}
+
+type CampaignTargetResponse struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type CampaignResponse struct {
+	Id            string                   `json:"id"`
+	Name          string                   `json:"name"`
+	Description   string                   `json:"description,omitempty"`
+	DiscountType  string                   `json:"discount_type"`
+	DiscountValue float64                  `json:"discount_value"`
+	Priority      int                      `json:"priority"`
+	Stackable     bool                     `json:"stackable"`
+	StartsAt      time.Time                `json:"starts_at"`
+	EndsAt        time.Time                `json:"ends_at"`
+	Targets       []CampaignTargetResponse `json:"targets"`
+}
+
+type PromoCodeRequest struct {
+	Code           string    `json:"code"`
+	Description    *string   `json:"description"`
+	DiscountType   string    `json:"discount_type"`
+	DiscountValue  float64   `json:"discount_value"`
+	MinOrderAmount float64   `json:"min_order_amount"`
+	MaxUses        *int      `json:"max_uses"`
+	MaxUsesPerUser *int      `json:"max_uses_per_user"`
+	Combinable     bool      `json:"combinable"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+}
+
+type PromoCodeResponse struct {
+	Id             string    `json:"id"`
+	Code           string    `json:"code"`
+	Description    string    `json:"description,omitempty"`
+	DiscountType   string    `json:"discount_type"`
+	DiscountValue  float64   `json:"discount_value"`
+	MinOrderAmount float64   `json:"min_order_amount"`
+	MaxUses        *int      `json:"max_uses,omitempty"`
+	MaxUsesPerUser *int      `json:"max_uses_per_user,omitempty"`
+	UsedCount      int       `json:"used_count"`
+	Combinable     bool      `json:"combinable"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+}
+
+type QuoteItemRequest struct {
+	BookId   string `json:"book_id"`
+	Quantity int    `json:"quantity"`
+}
+
+type QuoteRequest struct {
+	Items []QuoteItemRequest `json:"items"`
+	Code  string             `json:"code"`
+}
+
+type QuoteLineResponse struct {
+	BookId         string        `json:"book_id"`
+	Quantity       int           `json:"quantity"`
+	UnitPrice      pricing.Money `json:"unit_price" swaggertype:"number"`
+	UnitFinalPrice pricing.Money `json:"unit_final_price" swaggertype:"number"`
+	Total          pricing.Money `json:"total" swaggertype:"number"`
+	Campaigns      []string      `json:"campaigns,omitempty"`
+}
+
+type QuoteResponse struct {
+	Lines            []QuoteLineResponse `json:"lines"`
+	Subtotal         pricing.Money       `json:"subtotal" swaggertype:"number"`
+	CampaignDiscount pricing.Money       `json:"campaign_discount" swaggertype:"number"`
+	CodeDiscount     pricing.Money       `json:"code_discount" swaggertype:"number"`
+	Total            pricing.Money       `json:"total" swaggertype:"number"`
+	Code             string              `json:"code,omitempty"`
+}
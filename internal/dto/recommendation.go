@@ -0,0 +1,9 @@
+package dto
+
+type RecommendedBookResponse struct {
+	BookId string  `json:"book_id"`
+	Title  string  `json:"title"`
+	Author string  `json:"author"`
+	Rating float64 `json:"rating"`
+	Score  float64 `json:"score"`
+}
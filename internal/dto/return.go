@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+type ReturnItemRequest struct {
+	BookId   string `json:"book_id"`
+	Quantity int    `json:"quantity"`
+	Reason   string `json:"reason"`
+}
+
+type ReturnRequest struct {
+	PaymentId string              `json:"payment_id"`
+	Items     []ReturnItemRequest `json:"items"`
+	Comment   *string             `json:"comment"`
+}
+
+type ReturnDecisionRequest struct {
+	// RefundAmount overrides the refund computed from the returned items.
+	RefundAmount *pricing.Money `json:"refund_amount" swaggertype:"number"`
+	// StoreCredit refunds to the customer's store credit instead of the
+	// original payment.
+	StoreCredit bool    `json:"store_credit"`
+	Comment     *string `json:"comment"`
+}
+
+type ReturnItemResponse struct {
+	BookId    string        `json:"book_id"`
+	Quantity  int           `json:"quantity"`
+	Reason    string        `json:"reason"`
+	UnitPrice pricing.Money `json:"unit_price" swaggertype:"number"`
+	// Points are the loyalty points taken back on approval.
+	Points int `json:"points"`
+}
+
+type ReturnStatusChangeResponse struct {
+	Status    string    `json:"status"`
+	ActorId   string    `json:"actor_id,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReturnResponse struct {
+	Id           string                       `json:"id"`
+	UserId       string                       `json:"user_id"`
+	PaymentId    string                       `json:"payment_id"`
+	Status       string                       `json:"status"`
+	RefundAmount pricing.Money                `json:"refund_amount" swaggertype:"number"`
+	RefundMethod string                       `json:"refund_method"`
+	Currency     string                       `json:"currency"`
+	Comment      string                       `json:"comment,omitempty"`
+	Items        []ReturnItemResponse         `json:"items"`
+	History      []ReturnStatusChangeResponse `json:"history,omitempty"`
+	CreatedAt    time.Time                    `json:"created_at"`
+	UpdatedAt    time.Time                    `json:"updated_at"`
+}
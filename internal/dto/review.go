@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type ReviewRequest struct {
+	Rating int     `json:"rating"`
+	Text   *string `json:"text"`
+}
+
+type ReviewVisibilityRequest struct {
+	Hidden bool `json:"hidden"`
+}
+
+type ReviewResponse struct {
+	Id               string    `json:"id"`
+	BookId           string    `json:"book_id"`
+	UserId           string    `json:"user_id"`
+	Rating           int       `json:"rating"`
+	Text             string    `json:"text,omitempty"`
+	VerifiedPurchase bool      `json:"verified_purchase"`
+	Hidden           bool      `json:"hidden,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+type SeriesRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+type SeriesResponse struct {
+	Id          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Volumes     []VolumeResponse `json:"volumes,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+type VolumeResponse struct {
+	WorkId string `json:"work_id"`
+	Title  string `json:"title"`
+	Volume int    `json:"volume"`
+	// BookIds are the editions of the volume, oldest first.
+	BookIds []string `json:"book_ids"`
+}
+
+type VolumeOrderRequest struct {
+	WorkIds []string `json:"work_ids"`
+}
+
+type WorkRequest struct {
+	Title string `json:"title"`
+	// SeriesId and Volume place the work in a series; both or neither.
+	SeriesId *string `json:"series_id"`
+	Volume   *int    `json:"volume"`
+}
+
+type WorkResponse struct {
+	Id         string            `json:"id"`
+	Title      string            `json:"title"`
+	SeriesId   string            `json:"series_id,omitempty"`
+	SeriesName string            `json:"series_name,omitempty"`
+	Volume     int               `json:"volume,omitempty"`
+	Editions   []EditionResponse `json:"editions"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// EditionResponse is one book of a work. The price fields are filled where
+// editions are shown next to a priced book.
+type EditionResponse struct {
+	Id         string        `json:"id"`
+	Title      string        `json:"title"`
+	Format     string        `json:"format,omitempty"`
+	Language   string        `json:"language,omitempty"`
+	Pages      int           `json:"pages,omitempty"`
+	Isbn13     string        `json:"isbn_13,omitempty"`
+	Publisher  string        `json:"publisher"`
+	Year       int           `json:"year"`
+	Amount     int           `json:"amount"`
+	Cost       float64       `json:"cost"`
+	Currency   string        `json:"currency,omitempty"`
+	FinalPrice pricing.Money `json:"final_price,omitempty" swaggertype:"number"`
+}
+
+// BookSeriesResponse places a book in its series, with the neighbouring
+// volumes. BookId of a neighbour is its edition closest to this book: same
+// language, then same format.
+type BookSeriesResponse struct {
+	Id       string                  `json:"id"`
+	Name     string                  `json:"name"`
+	Volume   int                     `json:"volume"`
+	Previous *AdjacentVolumeResponse `json:"previous,omitempty"`
+	Next     *AdjacentVolumeResponse `json:"next,omitempty"`
+}
+
+type AdjacentVolumeResponse struct {
+	WorkId string `json:"work_id"`
+	Title  string `json:"title"`
+	Volume int    `json:"volume"`
+	BookId string `json:"book_id,omitempty"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type ShelfRequest struct {
+	Name   string `json:"name"`
+	Public bool   `json:"public"`
+}
+
+type ShelfBookRequest struct {
+	BookId string `json:"book_id"`
+}
+
+type ShelfOrderRequest struct {
+	BookIds []string `json:"book_ids"`
+}
+
+type ShelfBookResponse struct {
+	BookId    string    `json:"book_id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Available bool      `json:"available"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+type ShelfResponse struct {
+	Id        string              `json:"id"`
+	Name      string              `json:"name"`
+	IsDefault bool                `json:"is_default"`
+	Public    bool                `json:"public"`
+	Slug      string              `json:"slug,omitempty"`
+	Books     []ShelfBookResponse `json:"books,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
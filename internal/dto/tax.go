@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+type TaxResponse struct {
+	Region    string        `json:"region"`
+	Category  string        `json:"category"`
+	Rate      float64       `json:"rate"`
+	Inclusive bool          `json:"inclusive"`
+	Net       pricing.Money `json:"net" swaggertype:"number"`
+	Amount    pricing.Money `json:"amount" swaggertype:"number"`
+	Gross     pricing.Money `json:"gross" swaggertype:"number"`
+}
+
+type TaxRuleResponse struct {
+	Id            string     `json:"id"`
+	Region        string     `json:"region"`
+	Category      string     `json:"category"`
+	Rate          float64    `json:"rate"`
+	Inclusive     bool       `json:"inclusive"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+}
+
+type TaxReloadResponse struct {
+	Loaded int `json:"loaded"`
+}
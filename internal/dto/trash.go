@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type DeletedBookResponse struct {
+	Id        string    `json:"id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Publisher string    `json:"publisher"`
+	Year      int       `json:"year"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type DeletedUserResponse struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Surname   string    `json:"surname"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	Role      string    `json:"role"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
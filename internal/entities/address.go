@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+type Address struct {
+	Id         string
+	UserId     string
+	Label      *string
+	Recipient  string
+	Phone      string
+	Country    string
+	City       string
+	PostalCode string
+	Line1      string
+	Line2      *string
+	IsDefault  bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
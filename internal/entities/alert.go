@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+type BookAlert struct {
+	Id               string
+	UserId           string
+	BookId           string
+	Kind             string
+	Threshold        *float64
+	UnsubscribeToken string
+	Armed            bool
+	ArmedAt          time.Time
+	CreatedAt        time.Time
+}
+
+type AlertNotification struct {
+	Id        string
+	AlertId   *string
+	UserId    string
+	BookId    string
+	Kind      string
+	Message   string
+	DedupKey  string
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
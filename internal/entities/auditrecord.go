@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditRecord struct {
+	Id         string
+	ActorId    *string
+	ActorRole  string
+	Action     string
+	EntityType string
+	EntityId   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	Ip         string
+	RequestId  string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,44 @@
+package entities
+
+import "time"
+
+type Author struct {
+	Id        string
+	Name      string
+	Biography *string
+	PhotoData []byte
+	PhotoMime string
+	Aliases   []AuthorAlias `gorm:"foreignKey:AuthorId"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type AuthorAlias struct {
+	AuthorId string
+	Alias    string
+}
+
+// BookAuthor credits an author with a role on a book.
+type BookAuthor struct {
+	BookId   string
+	AuthorId string
+	Role     string
+	Position int
+	Author   *Author `gorm:"foreignKey:AuthorId"`
+}
+
+type Publisher struct {
+	Id          string
+	Name        string
+	Description *string
+	LogoData    []byte
+	LogoMime    string
+	Aliases     []PublisherAlias `gorm:"foreignKey:PublisherId"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type PublisherAlias struct {
+	PublisherId string
+	Alias       string
+}
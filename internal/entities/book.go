@@ -6,18 +6,38 @@ import (
 	"gorm.io/gorm"
 )
 
+// FormatEbook is the format of books sold as downloads. E-books have no
+// stock: Amount is not checked for them.
+const FormatEbook = "ebook"
+
 type Book struct {
 	Id          string
 	Title       string
+	Isbn        *string
 	Author      string
 	Year        int
 	Cost        float64
 	Discount    *int
 	Publisher   string
+	PublisherId *string
+	Authors     []BookAuthor `gorm:"foreignKey:BookId"`
+	WorkId      string
+	Format      *string
+	Language    *string
+	Pages       *int
 	Description *string
 	Amount      int
+	WeightGrams *int
+	WidthMm     *int
+	HeightMm    *int
+	DepthMm     *int
+	TaxCategory string
+	ReleaseDate *time.Time
+	Preorder    *bool
 	ImageData   []byte
 	ImageMime   string
+	Rating      float64
+	ReviewCount int
 	CreatedAt   time.Time
 	DeletedAt   gorm.DeletedAt
 }
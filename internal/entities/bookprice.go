@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BookPrice struct {
+	Id          string
+	BookId      string
+	Cost        float64
+	Discount    *int
+	EffectiveAt time.Time
+	CreatedAt   time.Time
+}
+
+type ScheduledPriceChange struct {
+	Id          string
+	BookId      string
+	Cost        *float64
+	Discount    *int
+	EffectiveAt time.Time
+	AppliedAt   *time.Time
+	CreatedBy   *string
+	CreatedAt   time.Time
+	DeletedAt   gorm.DeletedAt
+}
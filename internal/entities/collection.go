@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+type Collection struct {
+	Id             string
+	Slug           string
+	Title          string
+	Description    *string
+	CoverData      []byte
+	CoverMime      string
+	PublishedFrom  *time.Time
+	PublishedUntil *time.Time
+	Items          []CollectionItem
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type CollectionItem struct {
+	CollectionId string
+	BookId       string
+	Position     int
+}
@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+type ExchangeRate struct {
+	Currency  string `gorm:"primaryKey"`
+	Rate      float64
+	UpdatedAt time.Time
+}
+
+type BookCurrencyPrice struct {
+	BookId    string `gorm:"primaryKey"`
+	Currency  string `gorm:"primaryKey"`
+	Cost      float64
+	UpdatedAt time.Time
+}
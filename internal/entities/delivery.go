@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+type DeliveryMethod struct {
+	Id             string
+	Code           string
+	Kind           string
+	Name           string
+	BaseCost       float64
+	CostPerKg      float64
+	FreeFrom       *float64
+	MaxWeightGrams *int
+	Active         bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
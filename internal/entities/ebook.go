@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// EbookFile is the master file of an e-book in one file format. The file
+// itself is kept in storage under StorageKey.
+type EbookFile struct {
+	BookId     string
+	FileFormat string
+	StorageKey string
+	Size       int64
+	Sha256     string
+	UploadedBy *string
+	CreatedAt  time.Time
+}
+
+// EbookPurchase entitles a user to download an e-book bought in an order,
+// up to DownloadLimit times.
+type EbookPurchase struct {
+	Id            string
+	UserId        string
+	BookId        string
+	OrderId       string
+	DownloadLimit int
+	Downloads     int
+	Book          *Book       `gorm:"foreignKey:BookId"`
+	User          *User       `gorm:"foreignKey:UserId"`
+	Files         []EbookFile `gorm:"foreignKey:BookId;references:BookId"`
+	CreatedAt     time.Time
+}
+
+// EbookDownload is one copy of an e-book handed to its owner. Its Id is
+// stamped into the copy.
+type EbookDownload struct {
+	Id         string
+	PurchaseId string
+	FileFormat string
+	Ip         string
+	Purchase   *EbookPurchase `gorm:"foreignKey:PurchaseId"`
+	CreatedAt  time.Time
+}
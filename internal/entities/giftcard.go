@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+type GiftCard struct {
+	Id             string
+	Code           string
+	Kind           string
+	OwnerId        *string
+	PurchasedBy    *string
+	IssuedBy       *string
+	PaymentId      *string
+	InitialBalance float64
+	Balance        float64
+	Currency       string
+	ExpiresAt      *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type GiftCardTransaction struct {
+	Id           string
+	GiftCardId   string
+	Kind         string
+	Amount       float64
+	BalanceAfter float64
+	OrderId      *string
+	ReturnId     *string
+	ActorId      *string
+	CreatedAt    time.Time
+}
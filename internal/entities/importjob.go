@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ImportJob loads books from an uploaded spreadsheet in the background.
+type ImportJob struct {
+	Id            string
+	UserId        *string
+	UserRole      string
+	FileName      string
+	Format        string
+	Data          []byte
+	Mapping       json.RawMessage
+	DryRun        bool
+	Status        string
+	TotalRows     int
+	ProcessedRows int
+	CreatedRows   int
+	UpdatedRows   int
+	FailedRows    int
+	Error         *string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	StartedAt     *time.Time
+	FinishedAt    *time.Time
+}
+
+// ImportError is a problem with one field of a row, or with the whole row
+// when Field is "row".
+type ImportError struct {
+	Id      int64
+	JobId   string
+	Row     int
+	Field   string
+	Value   string
+	Message string
+}
@@ -0,0 +1,10 @@
+package entities
+
+import "time"
+
+type Invoice struct {
+	OrderId  string
+	Number   int64
+	UserId   *string
+	IssuedAt time.Time
+}
@@ -0,0 +1,50 @@
+package entities
+
+import "time"
+
+type Order struct {
+	Id           string
+	UserId       *string
+	Kind         string
+	Status       string
+	Currency     string
+	ExchangeRate float64
+	PromoCode    *string
+	Subtotal     float64
+	Discount     float64
+	Tax          float64
+	Total        float64
+	Items        []OrderItem `gorm:"foreignKey:OrderId"`
+	// The delivery method and address are copied when the order is placed.
+	// Orders of e-books only have neither, pickups have no address.
+	DeliveryMethodId *string
+	DeliveryCode     *string
+	DeliveryName     *string
+	DeliveryCost     float64
+	AddressId        *string
+	Recipient        *string
+	Phone            *string
+	Country          *string
+	City             *string
+	PostalCode       *string
+	Line1            *string
+	Line2            *string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type OrderItem struct {
+	Id          string
+	OrderId     string
+	BookId      *string
+	Title       string
+	Quantity    int
+	UnitPrice   float64
+	Discount    float64
+	TaxCategory string
+	TaxRate     *float64
+	Tax         float64
+	Total       float64
+	// Points are the loyalty points the line earned when the order was paid.
+	Points int
+}
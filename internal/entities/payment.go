@@ -0,0 +1,29 @@
+package entities
+
+import "time"
+
+type Payment struct {
+	Id         string
+	OrderId    string
+	UserId     *string
+	Provider   string
+	ProviderId string
+	Amount     float64
+	Currency   string
+	// RefundedAmount is how much of Amount has been refunded so far.
+	RefundedAmount float64
+	// RefundPending is how much has been asked of the provider but not yet
+	// confirmed as refunded.
+	RefundPending float64
+	Status        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type PaymentEvent struct {
+	Provider   string
+	EventId    string
+	PaymentId  *string
+	Type       string
+	ReceivedAt time.Time
+}
@@ -0,0 +1,29 @@
+package entities
+
+import "time"
+
+type Preorder struct {
+	Id          string
+	Seq         int64 `gorm:"->"`
+	BookId      string
+	UserId      string
+	Quantity    int
+	Status      string
+	OrderId     *string
+	AllocatedAt *time.Time
+	ShippedAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type StockReceipt struct {
+	Id        string
+	BookId    string
+	Quantity  int
+	Allocated int
+	// Held is how many copies in stock were held for the pre-orders still
+	// queued after the receipt.
+	Held      int
+	ActorId   *string
+	CreatedAt time.Time
+}
@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Campaign struct {
+	Id            string
+	Name          string
+	Description   *string
+	DiscountType  string
+	DiscountValue float64
+	Priority      int
+	Stackable     bool
+	StartsAt      time.Time
+	EndsAt        time.Time
+	Targets       []CampaignTarget
+	CreatedAt     time.Time
+	DeletedAt     gorm.DeletedAt
+}
+
+type CampaignTarget struct {
+	Id         string
+	CampaignId string
+	Kind       string
+	Value      string
+}
+
+type PromoCode struct {
+	Id             string
+	Code           string
+	Description    *string
+	DiscountType   string
+	DiscountValue  float64
+	MinOrderAmount float64
+	MaxUses        *int
+	MaxUsesPerUser *int
+	UsedCount      int
+	Combinable     bool
+	StartsAt       time.Time
+	EndsAt         time.Time
+	CreatedAt      time.Time
+	DeletedAt      gorm.DeletedAt
+}
+
+type PromoCodeRedemption struct {
+	Id          string
+	PromoCodeId string
+	UserId      string
+	OrderId     *string
+	CreatedAt   time.Time
+}
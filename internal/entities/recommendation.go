@@ -0,0 +1,13 @@
+package entities
+
+type BookRelation struct {
+	BookId    string
+	RelatedId string
+	Score     float64
+}
+
+type UserRecommendation struct {
+	UserId string
+	BookId string
+	Score  float64
+}
@@ -0,0 +1,44 @@
+package entities
+
+import "time"
+
+type ReturnRequest struct {
+	Id           string
+	UserId       string
+	PaymentId    string
+	Status       string
+	RefundAmount float64
+	RefundMethod string
+	Currency     string
+	Comment      *string
+	Items        []ReturnItem `gorm:"foreignKey:ReturnId"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type ReturnItem struct {
+	ReturnId  string
+	BookId    string
+	Quantity  int
+	Reason    string
+	UnitPrice float64
+	// Points are the loyalty points taken back when the return was approved.
+	Points int
+}
+
+type ReturnStatusChange struct {
+	Id        string
+	ReturnId  string
+	Status    string
+	ActorId   *string
+	Comment   *string
+	CreatedAt time.Time
+}
+
+type RestockEntry struct {
+	Id        string
+	BookId    string
+	Quantity  int
+	ReturnId  *string
+	CreatedAt time.Time
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+type Review struct {
+	Id               string
+	BookId           string
+	UserId           string
+	Rating           int
+	Text             *string
+	VerifiedPurchase bool
+	Hidden           bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
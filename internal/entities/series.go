@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+type Series struct {
+	Id          string
+	Name        string
+	Description *string
+	Works       []Work `gorm:"foreignKey:SeriesId"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Work is the text a book is an edition of. Editions are the books linked to
+// it; a work in a series has a volume number.
+type Work struct {
+	Id        string
+	Title     string
+	SeriesId  *string
+	Volume    *int
+	Series    *Series `gorm:"foreignKey:SeriesId"`
+	Editions  []Book  `gorm:"foreignKey:WorkId"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+type Shelf struct {
+	Id        string
+	UserId    string
+	Name      string
+	IsDefault bool
+	Public    bool
+	Slug      *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ShelfItem struct {
+	ShelfId  string
+	BookId   string
+	Position int
+	AddedAt  time.Time
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// TaxRule is the rate of a tax category in a region. EffectiveTo is
+// exclusive; nil means the rule has no end date yet.
+type TaxRule struct {
+	Id            string
+	Region        string
+	Category      string
+	Rate          float64
+	Inclusive     bool
+	EffectiveFrom time.Time
+	EffectiveTo   *time.Time
+}
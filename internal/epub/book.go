@@ -0,0 +1,104 @@
+// Package epub reads EPUB files and stamps them with per-buyer watermarks.
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+const (
+	mimetypePath  = "mimetype"
+	mimetype      = "application/epub+zip"
+	containerPath = "META-INF/container.xml"
+	packageType   = "application/oebps-package+xml"
+)
+
+// maxPackageSize bounds the container and package documents read into
+// memory; real ones are a few hundred kilobytes at most.
+const maxPackageSize = 8 << 20
+
+type container struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// Book is an EPUB opened for copying. Only the container and the package
+// document are read up front; content documents stay in the archive.
+type Book struct {
+	zip         *zip.Reader
+	packagePath string
+	pkg         []byte
+}
+
+// Open reads the structure of the EPUB in r.
+func Open(r io.ReaderAt, size int64) (*Book, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	data, err := readFile(archive, containerPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var c container
+	if err = xml.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalid
+	}
+
+	packagePath := ""
+	for _, rootfile := range c.Rootfiles {
+		if rootfile.MediaType == packageType || packagePath == "" {
+			packagePath = rootfile.FullPath
+		}
+		if rootfile.MediaType == packageType {
+			break
+		}
+	}
+	if packagePath == "" {
+		return nil, ErrInvalid
+	}
+
+	pkg, err := readFile(archive, packagePath)
+	if err != nil {
+		return nil, err
+	}
+	if closingTags["metadata"].Find(pkg) == nil || closingTags["manifest"].Find(pkg) == nil || closingTags["spine"].Find(pkg) == nil {
+		return nil, ErrInvalid
+	}
+
+	return &Book{zip: archive, packagePath: packagePath, pkg: pkg}, nil
+}
+
+func readFile(archive *zip.Reader, name string) ([]byte, error) {
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, ErrInvalid
+		}
+		defer rc.Close()
+
+		var buf bytes.Buffer
+		if _, err = io.Copy(&buf, io.LimitReader(rc, maxPackageSize+1)); err != nil {
+			if errors.Is(err, zip.ErrChecksum) || errors.Is(err, zip.ErrFormat) {
+				return nil, ErrInvalid
+			}
+			return nil, err
+		}
+		if buf.Len() > maxPackageSize {
+			return nil, ErrInvalid
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, ErrInvalid
+}
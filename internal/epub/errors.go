@@ -0,0 +1,8 @@
+package epub
+
+import "errors"
+
+var (
+	ErrInvalid     = errors.New("file is not a valid EPUB")
+	ErrNoWatermark = errors.New("no watermark found")
+)
@@ -0,0 +1,217 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// metaName names the package metadata entry holding the mark's Id.
+	metaName = "storybook:watermark"
+	itemId   = "storybook-watermark"
+	pageName = "storybook-watermark.xhtml"
+)
+
+// maxScanSize bounds each document Identify reads.
+const maxScanSize = 16 << 20
+
+var markRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`name="` + regexp.QuoteMeta(metaName) + `"\s+content="([^"]+)"`),
+	regexp.MustCompile(`content="([^"]+)"\s+name="` + regexp.QuoteMeta(metaName) + `"`),
+}
+
+// closingTags find the end of the package elements the mark goes into,
+// whatever their namespace prefix.
+var closingTags = map[string]*regexp.Regexp{
+	"metadata": regexp.MustCompile(`</([A-Za-z_][\w.-]*:)?metadata\s*>`),
+	"manifest": regexp.MustCompile(`</([A-Za-z_][\w.-]*:)?manifest\s*>`),
+	"spine":    regexp.MustCompile(`</([A-Za-z_][\w.-]*:)?spine\s*>`),
+}
+
+// Mark is what a watermark says about the copy it is stamped on.
+type Mark struct {
+	// Id identifies the copy; Identify finds it again.
+	Id      string
+	Name    string
+	Email   string
+	OrderId string
+	Time    time.Time
+}
+
+// Watermark writes a copy of the book to w with the mark added as package
+// metadata and as a last page in reading order. Every other entry is copied
+// without being recompressed, so the copy is written as it is read and the
+// source is never changed.
+func (b *Book) Watermark(w io.Writer, mark Mark) error {
+	pagePath := path.Join(path.Dir(b.packagePath), pageName)
+
+	pkg, err := b.markPackage(mark)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	// The mimetype entry comes first and uncompressed so that the media
+	// type sits at a fixed offset.
+	entry, err := zw.CreateHeader(&zip.FileHeader{Name: mimetypePath, Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(entry, mimetype); err != nil {
+		return err
+	}
+
+	for _, f := range b.zip.File {
+		switch f.Name {
+		case mimetypePath, pagePath:
+			continue
+		case b.packagePath:
+			if err = writeEntry(zw, f.Name, f.Modified, pkg); err != nil {
+				return err
+			}
+		default:
+			if err = zw.Copy(f); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = writeEntry(zw, pagePath, mark.Time, page(mark)); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// markPackage adds the mark to the package document: a meta entry, and the
+// watermark page to the manifest and at the end of the spine. The document
+// is edited as text, so everything else in it stays byte for byte.
+func (b *Book) markPackage(mark Mark) ([]byte, error) {
+	pkg := b.pkg
+
+	var err error
+	pkg, err = insertBefore(pkg, "metadata", func(prefix string) string {
+		return `<` + prefix + `meta name="` + metaName + `" content="` + escape(mark.Id) + `"/>`
+	})
+	if err != nil {
+		return nil, err
+	}
+	pkg, err = insertBefore(pkg, "manifest", func(prefix string) string {
+		return `<` + prefix + `item id="` + itemId + `" href="` + pageName + `" media-type="application/xhtml+xml"/>`
+	})
+	if err != nil {
+		return nil, err
+	}
+	return insertBefore(pkg, "spine", func(prefix string) string {
+		return `<` + prefix + `itemref idref="` + itemId + `"/>`
+	})
+}
+
+// Identify returns the ids of the marks found in an EPUB, looking in every
+// package and content document, so a mark survives the removal of the
+// watermark page or of the metadata but not of both.
+func Identify(r io.ReaderAt, size int64) ([]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	seen := map[string]bool{}
+	var ids []string
+	for _, f := range archive.File {
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".opf", ".xhtml", ".html", ".htm":
+		default:
+			continue
+		}
+
+		data, err := scan(f)
+		if err != nil {
+			continue
+		}
+
+		for _, re := range markRegexes {
+			for _, match := range re.FindAllSubmatch(data, -1) {
+				id := string(match[1])
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, ErrNoWatermark
+	}
+	return ids, nil
+}
+
+func scan(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, maxScanSize))
+}
+
+func page(mark Mark) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<!DOCTYPE html>` + "\n")
+	buf.WriteString(`<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="ru" lang="ru">` + "\n")
+	buf.WriteString("<head>\n")
+	buf.WriteString("<title>Story Book</title>\n")
+	fmt.Fprintf(&buf, `<meta name="%s" content="%s"/>`+"\n", metaName, escape(mark.Id))
+	buf.WriteString("</head>\n<body>\n")
+	buf.WriteString("<p>Эта копия продана для личного использования. Распространение запрещено.</p>\n")
+	fmt.Fprintf(&buf, "<p>Покупатель: %s &lt;%s&gt;</p>\n", escape(mark.Name), escape(mark.Email))
+	fmt.Fprintf(&buf, "<p>Заказ: %s</p>\n", escape(mark.OrderId))
+	fmt.Fprintf(&buf, "<p>Скачано: %s</p>\n", mark.Time.UTC().Format("2006-01-02 15:04 UTC"))
+	fmt.Fprintf(&buf, "<p>Копия: %s</p>\n", escape(mark.Id))
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+func writeEntry(zw *zip.Writer, name string, modified time.Time, data []byte) error {
+	entry, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(data)
+	return err
+}
+
+// insertBefore inserts an element before the closing tag of a package
+// element, using the same namespace prefix.
+func insertBefore(pkg []byte, element string, build func(prefix string) string) ([]byte, error) {
+	match := closingTags[element].FindSubmatchIndex(pkg)
+	if match == nil {
+		return nil, ErrInvalid
+	}
+
+	prefix := ""
+	if match[2] >= 0 {
+		prefix = string(pkg[match[2]:match[3]])
+	}
+
+	out := make([]byte, 0, len(pkg)+200)
+	out = append(out, pkg[:match[0]]...)
+	out = append(out, build(prefix)...)
+	out = append(out, pkg[match[0]:]...)
+	return out, nil
+}
+
+func escape(s string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
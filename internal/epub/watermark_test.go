@@ -0,0 +1,163 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testContainer = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+const testChapter = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>1</title></head><body><p>Глава 1</p></body></html>`
+
+// testBook builds an EPUB with one chapter and the given package document.
+func testBook(t *testing.T, pkg string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	entry, err := zw.CreateHeader(&zip.FileHeader{Name: mimetypePath, Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(entry, mimetype)
+
+	for _, file := range []struct{ name, data string }{
+		{containerPath, testContainer},
+		{"OEBPS/content.opf", pkg},
+		{"OEBPS/chapter1.xhtml", testChapter},
+	} {
+		entry, err = zw.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(entry, file.data)
+	}
+
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWatermark(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  string
+	}{
+		{
+			name: "default namespace",
+			pkg: `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata><dc:title xmlns:dc="http://purl.org/dc/elements/1.1/">Книга</dc:title></metadata>
+  <manifest><item id="c1" href="chapter1.xhtml" media-type="application/xhtml+xml"/></manifest>
+  <spine><itemref idref="c1"/></spine>
+</package>`,
+		},
+		{
+			name: "prefixed",
+			pkg: `<?xml version="1.0"?>
+<opf:package xmlns:opf="http://www.idpf.org/2007/opf" version="2.0">
+  <opf:metadata><dc:title xmlns:dc="http://purl.org/dc/elements/1.1/">Книга</dc:title></opf:metadata>
+  <opf:manifest><opf:item id="c1" href="chapter1.xhtml" media-type="application/xhtml+xml"/></opf:manifest>
+  <opf:spine><opf:itemref idref="c1"/></opf:spine>
+</opf:package>`,
+		},
+	}
+
+	mark := Mark{
+		Id:      "5f0c7c4e-3b7a-4f53-9a55-0b5b8e0d2c11",
+		Name:    "Иван <Петров>",
+		Email:   "ivan@example.com",
+		OrderId: "order-1",
+		Time:    time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := testBook(t, tt.pkg)
+
+			book, err := Open(bytes.NewReader(source), int64(len(source)))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			var out bytes.Buffer
+			if err = book.Watermark(&out, mark); err != nil {
+				t.Fatalf("Watermark() error = %v", err)
+			}
+
+			archive, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+			if err != nil {
+				t.Fatalf("watermarked copy is not a zip: %v", err)
+			}
+
+			first := archive.File[0]
+			if first.Name != mimetypePath || first.Method != zip.Store {
+				t.Errorf("first entry = %q (method %d), want uncompressed %q", first.Name, first.Method, mimetypePath)
+			}
+			// The local header of the first entry is 30 bytes, followed by
+			// its name and its contents.
+			if magic := mimetypePath + mimetype; !bytes.HasPrefix(out.Bytes()[30:], []byte(magic)) {
+				t.Errorf("copy does not have %q at offset 30", magic)
+			}
+
+			files := map[string]string{}
+			for _, f := range archive.File {
+				data, err := scan(f)
+				if err != nil {
+					t.Fatalf("reading %s: %v", f.Name, err)
+				}
+				files[f.Name] = string(data)
+			}
+
+			if files["OEBPS/chapter1.xhtml"] != testChapter {
+				t.Errorf("chapter changed: %q", files["OEBPS/chapter1.xhtml"])
+			}
+
+			pkg := files["OEBPS/content.opf"]
+			for _, want := range []string{
+				`name="` + metaName + `" content="` + mark.Id + `"`,
+				`id="` + itemId + `" href="` + pageName + `"`,
+				`idref="` + itemId + `"`,
+			} {
+				if !strings.Contains(pkg, want) {
+					t.Errorf("package has no %s:\n%s", want, pkg)
+				}
+			}
+
+			page := files["OEBPS/"+pageName]
+			if !strings.Contains(page, "Иван &lt;Петров&gt;") || !strings.Contains(page, mark.OrderId) {
+				t.Errorf("watermark page does not name the buyer:\n%s", page)
+			}
+
+			if _, err = Open(bytes.NewReader(out.Bytes()), int64(out.Len())); err != nil {
+				t.Errorf("watermarked copy does not open: %v", err)
+			}
+
+			ids, err := Identify(bytes.NewReader(out.Bytes()), int64(out.Len()))
+			if err != nil || len(ids) != 1 || ids[0] != mark.Id {
+				t.Errorf("Identify() = %v, %v, want [%s]", ids, err, mark.Id)
+			}
+		})
+	}
+}
+
+func TestIdentifyUnmarked(t *testing.T) {
+	source := testBook(t, `<package><metadata></metadata><manifest></manifest><spine></spine></package>`)
+
+	if _, err := Identify(bytes.NewReader(source), int64(len(source))); !errors.Is(err, ErrNoWatermark) {
+		t.Errorf("Identify() error = %v, want %v", err, ErrNoWatermark)
+	}
+}
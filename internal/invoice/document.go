@@ -0,0 +1,87 @@
+package invoice
+
+import (
+	"story-book/internal/pricing"
+	"time"
+)
+
+// Supported document languages.
+const (
+	LanguageRu = "ru"
+	LanguageEn = "en"
+)
+
+// Store is the seller block printed in the header.
+type Store struct {
+	Name    string
+	Address string
+	TaxId   string
+}
+
+// Line is one row of the document. Amounts are totals for the row, not per
+// unit, except UnitPrice.
+type Line struct {
+	Title     string
+	Quantity  int
+	UnitPrice pricing.Money
+	Discount  pricing.Money
+	Tax       pricing.Money
+	Total     pricing.Money
+}
+
+// Document is everything a receipt shows. It is rendered as is: the caller
+// computes every amount.
+type Document struct {
+	Number   string
+	IssuedAt time.Time
+	Language string
+	Store    Store
+	Customer string
+	Currency string
+	Lines    []Line
+	Subtotal pricing.Money
+	Discount pricing.Money
+	Tax      pricing.Money
+	Total    pricing.Money
+}
+
+var labels = map[string]map[string]string{
+	LanguageRu: {
+		"title":    "Кассовый чек",
+		"number":   "Номер",
+		"date":     "Дата",
+		"seller":   "Продавец",
+		"taxId":    "ИНН",
+		"customer": "Покупатель",
+		"item":     "Наименование",
+		"quantity": "Кол-во",
+		"price":    "Цена",
+		"discount": "Скидка",
+		"tax":      "НДС",
+		"total":    "Сумма",
+		"subtotal": "Итого без скидок",
+		"due":      "Итого к оплате",
+	},
+	LanguageEn: {
+		"title":    "Receipt",
+		"number":   "Number",
+		"date":     "Date",
+		"seller":   "Seller",
+		"taxId":    "Tax ID",
+		"customer": "Customer",
+		"item":     "Item",
+		"quantity": "Qty",
+		"price":    "Price",
+		"discount": "Discount",
+		"tax":      "VAT",
+		"total":    "Total",
+		"subtotal": "Subtotal",
+		"due":      "Total due",
+	},
+}
+
+// IsLanguage reports whether documents can be rendered in language.
+func IsLanguage(language string) bool {
+	_, ok := labels[language]
+	return ok
+}
@@ -0,0 +1,8 @@
+package invoice
+
+import "errors"
+
+var (
+	ErrUnknownLanguage = errors.New("unknown document language")
+	ErrFontUnavailable = errors.New("document font unavailable")
+)
@@ -0,0 +1,177 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"story-book/internal/pricing"
+	"strconv"
+	"sync"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	fontFamily = "body"
+	margin     = 10.0
+	rowHeight  = 7.0
+)
+
+// columns are the widths of the line table in millimetres; they add up to
+// the printable width of an A4 page.
+var columns = []float64{80, 15, 25, 25, 20, 25}
+
+// Renderer turns documents into PDF files. The core PDF fonts have no
+// Cyrillic, so a TrueType font is embedded into every file.
+type Renderer struct {
+	regularFile string
+	boldFile    string
+
+	mu      sync.Mutex
+	regular []byte
+	bold    []byte
+}
+
+// NewRenderer renders documents with the regular and bold TrueType fonts
+// in the given files. The fonts are read on first use, so a missing font
+// only fails rendering, not the whole application.
+func NewRenderer(regularFile, boldFile string) *Renderer {
+	return &Renderer{regularFile: regularFile, boldFile: boldFile}
+}
+
+func (r *Renderer) Render(doc *Document) ([]byte, error) {
+	text, ok := labels[doc.Language]
+	if !ok {
+		return nil, ErrUnknownLanguage
+	}
+
+	regular, bold, err := r.fonts()
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", regular)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", bold)
+	pdf.SetTitle(text["title"]+" "+doc.Number, true)
+	pdf.AddPage()
+
+	pdf.SetFont(fontFamily, "B", 16)
+	pdf.CellFormat(0, 10, text["title"], "", 1, "L", false, 0, "")
+
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.CellFormat(0, 6, text["number"]+": "+doc.Number, "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, text["date"]+": "+doc.IssuedAt.Format("02.01.2006 15:04"), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.CellFormat(0, 6, text["seller"], "", 1, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.CellFormat(0, 6, doc.Store.Name, "", 1, "L", false, 0, "")
+	if doc.Store.Address != "" {
+		pdf.MultiCell(0, 6, doc.Store.Address, "", "L", false)
+	}
+	if doc.Store.TaxId != "" {
+		pdf.CellFormat(0, 6, text["taxId"]+": "+doc.Store.TaxId, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(2)
+
+	if doc.Customer != "" {
+		pdf.SetFont(fontFamily, "B", 10)
+		pdf.CellFormat(0, 6, text["customer"], "", 1, "L", false, 0, "")
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.CellFormat(0, 6, doc.Customer, "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+
+	pdf.SetFont(fontFamily, "B", 9)
+	pdf.SetFillColor(235, 235, 235)
+	headers := []string{text["item"], text["quantity"], text["price"], text["discount"], text["tax"], text["total"]}
+	for i, header := range headers {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(columns[i], rowHeight, header, "1", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont(fontFamily, "", 9)
+	for _, line := range doc.Lines {
+		pdf.CellFormat(columns[0], rowHeight, fit(pdf, line.Title, columns[0]-2), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(columns[1], rowHeight, strconv.Itoa(line.Quantity), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[2], rowHeight, line.UnitPrice.String(), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[3], rowHeight, line.Discount.String(), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[4], rowHeight, line.Tax.String(), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[5], rowHeight, line.Total.String(), "1", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	totals := []struct {
+		label  string
+		amount pricing.Money
+		bold   bool
+	}{
+		{text["subtotal"], doc.Subtotal, false},
+		{text["discount"], doc.Discount, false},
+		{text["tax"], doc.Tax, false},
+		{text["due"], doc.Total, true},
+	}
+
+	labelWidth := columns[0] + columns[1] + columns[2] + columns[3] + columns[4]
+	for _, total := range totals {
+		style := ""
+		if total.bold {
+			style = "B"
+		}
+		pdf.SetFont(fontFamily, style, 10)
+		pdf.CellFormat(labelWidth, rowHeight, total.label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[5], rowHeight, total.amount.String()+" "+doc.Currency, "", 1, "R", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fonts reads the font files the first time they are needed. A failed read
+// is tried again on the next document.
+func (r *Renderer) fonts() ([]byte, []byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.regular != nil && r.bold != nil {
+		return r.regular, r.bold, nil
+	}
+
+	regular, err := os.ReadFile(r.regularFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrFontUnavailable, err)
+	}
+
+	bold, err := os.ReadFile(r.boldFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrFontUnavailable, err)
+	}
+
+	r.regular, r.bold = regular, bold
+	return regular, bold, nil
+}
+
+// fit shortens s with an ellipsis until it is at most width wide.
+func fit(pdf *fpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,8 @@
+package isbn
+
+import "errors"
+
+var (
+	ErrInvalid  = errors.New("isbn must be a valid ISBN-10 or ISBN-13")
+	ErrNoIsbn10 = errors.New("isbn with prefix 979 has no ISBN-10 form")
+)
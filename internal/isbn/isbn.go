@@ -0,0 +1,103 @@
+// Package isbn validates and converts International Standard Book Numbers.
+package isbn
+
+import "strings"
+
+// ISBN-13 prefixes. Every ISBN-10 maps to the 978 range.
+const (
+	bookland    = "978"
+	booklandNew = "979"
+)
+
+var separators = strings.NewReplacer("-", "", " ", "")
+
+// Normalize checks the digits and checksum of an ISBN-10 or ISBN-13 and
+// returns it as ISBN-13 without separators, the form books are stored and
+// looked up by. Hyphens and spaces are ignored, and the ISBN-10 check
+// character may be a lower case x.
+func Normalize(s string) (string, error) {
+	digits := strings.ToUpper(separators.Replace(strings.TrimSpace(s)))
+
+	switch len(digits) {
+	case 10:
+		if !valid10(digits) {
+			return "", ErrInvalid
+		}
+		body := bookland + digits[:9]
+		return body + string(check13(body)), nil
+	case 13:
+		if !valid13(digits) || !(strings.HasPrefix(digits, bookland) || strings.HasPrefix(digits, booklandNew)) {
+			return "", ErrInvalid
+		}
+		return digits, nil
+	}
+
+	return "", ErrInvalid
+}
+
+// To10 converts an ISBN-10 or ISBN-13 to ISBN-10 without separators. Only
+// numbers with the 978 prefix have an ISBN-10 form.
+func To10(s string) (string, error) {
+	digits, err := Normalize(s)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(digits, bookland) {
+		return "", ErrNoIsbn10
+	}
+
+	body := digits[3:12]
+	return body + string(check10(body)), nil
+}
+
+func valid10(digits string) bool {
+	for i := 0; i < 9; i++ {
+		if !isDigit(digits[i]) {
+			return false
+		}
+	}
+	return check10(digits[:9]) == digits[9]
+}
+
+func valid13(digits string) bool {
+	for i := 0; i < 13; i++ {
+		if !isDigit(digits[i]) {
+			return false
+		}
+	}
+	return check13(digits[:12]) == digits[12]
+}
+
+// check10 computes the ISBN-10 check character of nine digits: weights 10
+// down to 2, modulo 11, with X standing for 10.
+func check10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// check13 computes the ISBN-13 check digit of twelve digits: alternating
+// weights 1 and 3, modulo 10.
+func check13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(body[i]-'0') * weight
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,95 @@
+package isbn
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{in: "0306406152", want: "9780306406157"},
+		{in: "0-306-40615-2", want: "9780306406157"},
+		{in: " 0 306 40615 2 ", want: "9780306406157"},
+		{in: "080442957X", want: "9780804429573"},
+		{in: "080442957x", want: "9780804429573"},
+		{in: "9780306406157", want: "9780306406157"},
+		{in: "978-0-306-40615-7", want: "9780306406157"},
+		{in: "9791090636071", want: "9791090636071"},
+		{in: "0306406153", err: ErrInvalid},
+		{in: "0804429570", err: ErrInvalid},
+		{in: "X306406152", err: ErrInvalid},
+		{in: "03064061X2", err: ErrInvalid},
+		{in: "9780306406158", err: ErrInvalid},
+		{in: "9770306406150", err: ErrInvalid},
+		{in: "978030640615X", err: ErrInvalid},
+		{in: "030640615", err: ErrInvalid},
+		{in: "97803064061570", err: ErrInvalid},
+		{in: "", err: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Normalize(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.in, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTo10(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{in: "9780306406157", want: "0306406152"},
+		{in: "978-0-8044-2957-3", want: "080442957X"},
+		{in: "0-306-40615-2", want: "0306406152"},
+		{in: "9791090636071", err: ErrNoIsbn10},
+		{in: "9780306406158", err: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := To10(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("To10(%q) error = %v, want %v", tt.in, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("To10(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDigits(t *testing.T) {
+	for body := 0; body < 1000; body++ {
+		ten := fmt.Sprintf("123456%03d", body)
+		ten += string(check10(ten))
+		if !valid10(ten) {
+			t.Fatalf("valid10(%q) = false for its own check character", ten)
+		}
+
+		thirteen, err := Normalize(ten)
+		if err != nil {
+			t.Fatalf("Normalize(%q) error = %v", ten, err)
+		}
+		if !valid13(thirteen) {
+			t.Fatalf("valid13(%q) = false for a converted ISBN-10", thirteen)
+		}
+
+		back, err := To10(thirteen)
+		if err != nil || back != ten {
+			t.Fatalf("To10(%q) = %q, %v, want %q", thirteen, back, err, ten)
+		}
+	}
+}
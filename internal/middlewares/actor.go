@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"story-book/internal/services/auditservice"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ActorMiddleware stores the client address and request ID in the request
+// context. AuthMiddleware later adds the user to the same actor.
+func ActorMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := auditservice.WithActor(c.Request().Context(), auditservice.Actor{
+				Ip:        c.RealIP(),
+				RequestId: c.Response().Header().Get(echo.HeaderXRequestID),
+			})
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
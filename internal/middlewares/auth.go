@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"net/http"
+	"story-book/internal/services/auditservice"
 	"story-book/package/services/jwtservice"
 	"strings"
 
@@ -29,6 +30,34 @@ func AuthMiddleware(jwtService jwtservice.JWTService) echo.MiddlewareFunc {
 			c.Set("id", claims["sub"])
 			c.Set("role", claims["role"])
 
+			actor := auditservice.ActorFromContext(c.Request().Context())
+			actor.Id, _ = claims["sub"].(string)
+			actor.Role, _ = claims["role"].(string)
+			c.SetRequest(c.Request().WithContext(auditservice.WithActor(c.Request().Context(), actor)))
+
+			return next(c)
+		}
+	}
+}
+
+// OptionalAuthMiddleware identifies the caller when a valid token is present
+// and lets anonymous requests through otherwise.
+func OptionalAuthMiddleware(jwtService jwtservice.JWTService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			parts := strings.Split(c.Request().Header.Get("Authorization"), " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				return next(c)
+			}
+
+			claims, err := jwtService.ParseJWT(parts[1])
+			if err != nil {
+				return next(c)
+			}
+
+			c.Set("id", claims["sub"])
+			c.Set("role", claims["role"])
+
 			return next(c)
 		}
 	}
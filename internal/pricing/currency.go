@@ -0,0 +1,97 @@
+package pricing
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const rateScale = 1000000
+
+// Rate is an exchange rate from the base currency, scaled by 10^6:
+// 1 base unit buys Rate/10^6 units of the target currency.
+type Rate int64
+
+// UnitRate is the rate of the base currency to itself.
+const UnitRate Rate = rateScale
+
+// RateFromFloat converts a value read from a numeric(18, 6) column.
+func RateFromFloat(rate float64) Rate {
+	r, err := ParseRate(strconv.FormatFloat(rate, 'f', -1, 64))
+	if err != nil {
+		return Rate(math.Round(rate * rateScale))
+	}
+	return r
+}
+
+// ParseRate reads a positive decimal string with up to six fractional digits.
+func ParseRate(s string) (Rate, error) {
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(s), ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if len(fraction) > 6 || !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidRate
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidRate
+	}
+
+	fraction += strings.Repeat("0", 6-len(fraction))
+	micro, _ := strconv.ParseInt(fraction, 10, 64)
+
+	r := Rate(units*rateScale + micro)
+	if r <= 0 {
+		return 0, ErrInvalidRate
+	}
+	return r, nil
+}
+
+func (r Rate) Float() float64 {
+	return float64(r) / rateScale
+}
+
+func (r Rate) String() string {
+	whole := strconv.FormatInt(int64(r/rateScale), 10)
+	fraction := strings.TrimRight(strconv.FormatInt(int64(r%rateScale)+rateScale, 10)[1:], "0")
+	if fraction == "" {
+		return whole
+	}
+	return whole + "." + fraction
+}
+
+func Convert(amount Money, rate Rate, rounding Rounding) Money {
+	return Money(divide(int64(amount)*int64(rate), rateScale, rounding))
+}
+
+// ConvertToBase converts amount back from a currency with rate to the base
+// currency.
+func ConvertToBase(amount Money, rate Rate, rounding Rounding) Money {
+	return Money(divide(int64(amount)*rateScale, int64(rate), rounding))
+}
+
+// ConvertBreakdown expresses a breakdown in another currency. Without an
+// override every amount is converted at rate. With an override the list price
+// is taken as is, and the final price keeps the same share of it as in the
+// base currency. The discount is always the difference, so that
+// Price - DiscountAmount == FinalPrice holds exactly.
+func ConvertBreakdown(b Breakdown, currency string, rate Rate, override *Money, rounding Rounding) Breakdown {
+	converted := Breakdown{Currency: currency, Campaigns: b.Campaigns}
+
+	switch {
+	case override != nil && b.Price > 0:
+		converted.Price = *override
+		converted.FinalPrice = Money(divide(int64(*override)*int64(b.FinalPrice), int64(b.Price), rounding))
+	case override != nil:
+		converted.Price = *override
+		converted.FinalPrice = *override
+	default:
+		converted.Price = Convert(b.Price, rate, rounding)
+		converted.FinalPrice = Convert(b.FinalPrice, rate, rounding)
+	}
+
+	converted.DiscountAmount = converted.Price - converted.FinalPrice
+	return converted
+}
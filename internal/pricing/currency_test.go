@@ -0,0 +1,171 @@
+package pricing
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rate
+		err  error
+	}{
+		{in: "1", want: 1000000},
+		{in: "0.012345", want: 12345},
+		{in: "95.5", want: 95500000},
+		{in: ".5", want: 500000},
+		{in: " 2.000001 ", want: 2000001},
+		{in: "0", err: ErrInvalidRate},
+		{in: "0.000000", err: ErrInvalidRate},
+		{in: "", err: ErrInvalidRate},
+		{in: "-0.5", err: ErrInvalidRate},
+		{in: "-1", err: ErrInvalidRate},
+		{in: "+1", err: ErrInvalidRate},
+		{in: "1.+5", err: ErrInvalidRate},
+		{in: "1.-5", err: ErrInvalidRate},
+		{in: "1.0000001", err: ErrInvalidRate},
+		{in: "abc", err: ErrInvalidRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRate(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseRate(%q) error = %v, want %v", tt.in, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRate(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateString(t *testing.T) {
+	tests := []struct {
+		in   Rate
+		want string
+	}{
+		{in: 1000000, want: "1"},
+		{in: 10000000, want: "10"},
+		{in: 12345, want: "0.012345"},
+		{in: 95500000, want: "95.5"},
+		{in: 1, want: "0.000001"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Rate(%d).String() = %q, want %q", tt.in, got, tt.want)
+		}
+		if back, err := ParseRate(tt.want); err != nil || back != tt.in {
+			t.Errorf("ParseRate(%q) = %d, %v, want %d", tt.want, back, err, tt.in)
+		}
+	}
+}
+
+func TestConvertToBase(t *testing.T) {
+	tests := []struct {
+		amount   Money
+		rate     Rate
+		rounding Rounding
+		want     Money
+	}{
+		{amount: 10000, rate: UnitRate, rounding: HalfUp, want: 10000},
+		{amount: 110, rate: 12345, rounding: HalfUp, want: 8910},
+		{amount: 5, rate: 3000000, rounding: HalfUp, want: 2},
+		{amount: 5, rate: 3000000, rounding: Down, want: 1},
+		{amount: 9550, rate: 95500000, rounding: HalfUp, want: 100},
+	}
+
+	for _, tt := range tests {
+		if got := ConvertToBase(tt.amount, tt.rate, tt.rounding); got != tt.want {
+			t.Errorf("ConvertToBase(%d, %d, %v) = %d, want %d", tt.amount, tt.rate, tt.rounding, got, tt.want)
+		}
+	}
+}
+
+func TestConvertBreakdown(t *testing.T) {
+	base := Breakdown{Currency: "RUB", Price: 10000, DiscountAmount: 2500, FinalPrice: 7500, Campaigns: []string{"c1"}}
+	override := func(m Money) *Money { return &m }
+
+	tests := []struct {
+		name     string
+		in       Breakdown
+		rate     Rate
+		override *Money
+		rounding Rounding
+		want     Breakdown
+	}{
+		{
+			name:     "rate half up",
+			in:       base,
+			rate:     12345,
+			rounding: HalfUp,
+			want:     Breakdown{Price: 123, DiscountAmount: 30, FinalPrice: 93},
+		},
+		{
+			name:     "rate down",
+			in:       base,
+			rate:     12345,
+			rounding: Down,
+			want:     Breakdown{Price: 123, DiscountAmount: 31, FinalPrice: 92},
+		},
+		{
+			name:     "identity rate",
+			in:       base,
+			rate:     1000000,
+			rounding: HalfUp,
+			want:     Breakdown{Price: 10000, DiscountAmount: 2500, FinalPrice: 7500},
+		},
+		{
+			name:     "override keeps discount share",
+			in:       base,
+			rate:     12345,
+			override: override(15000),
+			rounding: HalfUp,
+			want:     Breakdown{Price: 15000, DiscountAmount: 3750, FinalPrice: 11250},
+		},
+		{
+			name:     "override share half up",
+			in:       Breakdown{Price: 300, DiscountAmount: 100, FinalPrice: 200},
+			override: override(1000),
+			rounding: HalfUp,
+			want:     Breakdown{Price: 1000, DiscountAmount: 333, FinalPrice: 667},
+		},
+		{
+			name:     "override share down",
+			in:       Breakdown{Price: 300, DiscountAmount: 100, FinalPrice: 200},
+			override: override(1000),
+			rounding: Down,
+			want:     Breakdown{Price: 1000, DiscountAmount: 334, FinalPrice: 666},
+		},
+		{
+			name:     "override of free book",
+			in:       Breakdown{},
+			override: override(500),
+			rounding: HalfUp,
+			want:     Breakdown{Price: 500, DiscountAmount: 0, FinalPrice: 500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConvertBreakdown(tt.in, "EUR", tt.rate, tt.override, tt.rounding)
+			if got.Currency != "EUR" {
+				t.Errorf("Currency = %q, want EUR", got.Currency)
+			}
+			if !slices.Equal(got.Campaigns, tt.in.Campaigns) {
+				t.Errorf("Campaigns = %v, want %v", got.Campaigns, tt.in.Campaigns)
+			}
+			if got.Price != tt.want.Price || got.DiscountAmount != tt.want.DiscountAmount || got.FinalPrice != tt.want.FinalPrice {
+				t.Errorf("got %d - %d = %d, want %d - %d = %d",
+					got.Price, got.DiscountAmount, got.FinalPrice,
+					tt.want.Price, tt.want.DiscountAmount, tt.want.FinalPrice)
+			}
+			if got.Price-got.DiscountAmount != got.FinalPrice {
+				t.Errorf("Price - DiscountAmount != FinalPrice: %d - %d != %d", got.Price, got.DiscountAmount, got.FinalPrice)
+			}
+		})
+	}
+}
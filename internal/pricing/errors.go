@@ -0,0 +1,9 @@
+package pricing
+
+import "errors"
+
+var (
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrInvalidRounding = errors.New("invalid rounding mode")
+	ErrInvalidRate     = errors.New("invalid exchange rate")
+)
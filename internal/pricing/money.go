@@ -0,0 +1,184 @@
+package pricing
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const minorUnits = 100
+
+// Money is an amount in minor units (kopecks, cents). Prices are converted to
+// Money once and every further calculation stays in integers.
+type Money int64
+
+// FromFloat converts a value read from a numeric(10, 2) column. The float is
+// formatted with the shortest representation that round-trips, so 0.29 is
+// read as "0.29" rather than 0.28999999999999998.
+func FromFloat(amount float64) Money {
+	m, err := Parse(strconv.FormatFloat(amount, 'f', -1, 64))
+	if err != nil {
+		return Money(math.Round(amount * minorUnits))
+	}
+	return m
+}
+
+// Parse reads a decimal string such as "199.90". Digits beyond the minor unit
+// are rounded half up.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+
+	fraction += "000"
+	minor, _ := strconv.ParseInt(fraction[:2], 10, 64)
+	if fraction[2] >= '5' {
+		minor++
+	}
+
+	m := Money(units*minorUnits + minor)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+func (m Money) Float() float64 {
+	return float64(m) / minorUnits
+}
+
+func (m Money) Mul(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// Portion returns part/whole of m rounded towards zero, for example the
+// share of a line total for some of its units. Taking the portions of
+// successive parts as differences, Portion(a+b, n) - Portion(a, n), makes
+// them add up to m once all whole units are taken.
+func (m Money) Portion(part, whole int) Money {
+	return Money(int64(m) * int64(part) / int64(whole))
+}
+
+// Units returns the whole units of m, dropping the minor ones.
+func (m Money) Units() int64 {
+	return int64(m / minorUnits)
+}
+
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign = "-"
+		m = -m
+	}
+	return sign + strconv.FormatInt(int64(m/minorUnits), 10) + "." + twoDigits(int64(m%minorUnits))
+}
+
+// MarshalJSON writes Money as an exact decimal number, e.g. 199.9 as 199.90.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	parsed, err := Parse(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Allocate splits amount between parts in proportion to weights, for example
+// an order discount between its lines. Parts get whole minor units; the
+// units left over by rounding down go to the largest remainders, earlier
+// parts first on ties, so the parts always add up to amount. Without any
+// positive weight nothing is allocated.
+func Allocate(amount Money, weights []Money) []Money {
+	parts := make([]Money, len(weights))
+
+	var total int64
+	for _, weight := range weights {
+		if weight > 0 {
+			total += int64(weight)
+		}
+	}
+	if total == 0 || amount == 0 {
+		return parts
+	}
+
+	remainders := make([]int64, len(weights))
+	left := amount
+	for i, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+		share := int64(amount) * int64(weight)
+		parts[i] = Money(share / total)
+		remainders[i] = share % total
+		left -= parts[i]
+	}
+
+	step := Money(1)
+	if left < 0 {
+		step = -1
+	}
+	for ; left != 0; left -= step {
+		best := -1
+		for i, remainder := range remainders {
+			if weights[i] > 0 && (best < 0 || abs(remainder) > abs(remainders[best])) {
+				best = i
+			}
+		}
+		parts[best] += step
+		remainders[best] = 0
+	}
+
+	return parts
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func twoDigits(n int64) string {
+	if n < 10 {
+		return "0" + strconv.FormatInt(n, 10)
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// isDigits reports whether s holds only ASCII digits. strconv.ParseInt takes
+// a sign of its own, so amounts are checked before they are parsed.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+		err  error
+	}{
+		{in: "199.90", want: 19990},
+		{in: "199.9", want: 19990},
+		{in: "199", want: 19900},
+		{in: "0.29", want: 29},
+		{in: ".5", want: 50},
+		{in: "5.", want: 500},
+		{in: " 7.25 ", want: 725},
+		{in: "+3", want: 300},
+		{in: "-3.10", want: -310},
+		{in: "1.005", want: 101},
+		{in: "1.004", want: 100},
+		{in: "1.0049", want: 100},
+		{in: "0.995", want: 100},
+		{in: "-1.005", want: -101},
+		{in: "-0.001", want: 0},
+		{in: "", err: ErrInvalidAmount},
+		{in: ".", err: ErrInvalidAmount},
+		{in: "-", err: ErrInvalidAmount},
+		{in: "+-5", err: ErrInvalidAmount},
+		{in: "-+5", err: ErrInvalidAmount},
+		{in: "--5", err: ErrInvalidAmount},
+		{in: "5-", err: ErrInvalidAmount},
+		{in: "1.-5", err: ErrInvalidAmount},
+		{in: "1.2.3", err: ErrInvalidAmount},
+		{in: "1e3", err: ErrInvalidAmount},
+		{in: "abc", err: ErrInvalidAmount},
+		{in: "99999999999999999999", err: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.in, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want Money
+	}{
+		{in: 0, want: 0},
+		{in: 0.29, want: 29},
+		{in: 199.9, want: 19990},
+		{in: 0.1 + 0.2, want: 30},
+		{in: 1.005, want: 101},
+		{in: -2.5, want: -250},
+		{in: 1e-7, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := FromFloat(tt.in); got != tt.want {
+			t.Errorf("FromFloat(%v) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want string
+	}{
+		{in: 0, want: "0.00"},
+		{in: 5, want: "0.05"},
+		{in: -5, want: "-0.05"},
+		{in: 19990, want: "199.90"},
+		{in: -19901, want: "-199.01"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  Money
+		weights []Money
+		want    []Money
+	}{
+		{name: "proportional", amount: 300, weights: []Money{100, 200}, want: []Money{100, 200}},
+		{name: "remainder to largest", amount: 100, weights: []Money{100, 200}, want: []Money{33, 67}},
+		{name: "tie to first", amount: 100, weights: []Money{100, 100, 100}, want: []Money{34, 33, 33}},
+		{name: "skips zero weights", amount: 100, weights: []Money{0, 300, 0, 100}, want: []Money{0, 75, 0, 25}},
+		{name: "negative amount", amount: -100, weights: []Money{100, 200}, want: []Money{-33, -67}},
+		{name: "no weight", amount: 100, weights: []Money{0, 0}, want: []Money{0, 0}},
+		{name: "nothing to split", amount: 0, weights: []Money{1, 2}, want: []Money{0, 0}},
+		{name: "empty", amount: 100, weights: nil, want: []Money{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Allocate(tt.amount, tt.weights)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Allocate(%d, %v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Allocate(%d, %v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPortion(t *testing.T) {
+	tests := []struct {
+		amount      Money
+		part, whole int
+		want        Money
+	}{
+		{amount: 1000, part: 1, whole: 3, want: 333},
+		{amount: 1000, part: 2, whole: 3, want: 666},
+		{amount: 1000, part: 3, whole: 3, want: 1000},
+		{amount: 1000, part: 0, whole: 3, want: 0},
+		{amount: -1000, part: 1, whole: 3, want: -333},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.Portion(tt.part, tt.whole); got != tt.want {
+			t.Errorf("Money(%d).Portion(%d, %d) = %d, want %d", tt.amount, tt.part, tt.whole, got, tt.want)
+		}
+	}
+
+	// Successive portions taken as differences add up to the whole amount.
+	var sum Money
+	for taken := 0; taken < 7; taken += 2 {
+		next := min(taken+2, 7)
+		sum += Money(1000).Portion(next, 7) - Money(1000).Portion(taken, 7)
+	}
+	if sum != 1000 {
+		t.Errorf("portions add up to %d, want 1000", sum)
+	}
+}
+
+func TestUnits(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want int64
+	}{
+		{in: 0, want: 0},
+		{in: 99, want: 0},
+		{in: 100, want: 1},
+		{in: 12345, want: 123},
+	}
+
+	for _, tt := range tests {
+		if got := tt.in.Units(); got != tt.want {
+			t.Errorf("Money(%d).Units() = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package pricing
+
+import (
+	"sort"
+	"story-book/internal/entities"
+	"strings"
+	"time"
+)
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+const (
+	TargetGenre     = "genre"
+	TargetAuthor    = "author"
+	TargetPublisher = "publisher"
+	TargetBook      = "book"
+)
+
+// Breakdown is the price of a single unit of a book.
+type Breakdown struct {
+	Currency       string
+	Price          Money
+	DiscountAmount Money
+	FinalPrice     Money
+	Campaigns      []string
+	// Tax is filled in by the tax step of the pipeline, if any.
+	Tax *Tax
+}
+
+type Engine interface {
+	PriceBook(book *entities.Book, genres []string, campaigns []entities.Campaign, now time.Time) Breakdown
+	Discount(amount Money, discountType string, value float64) Money
+}
+
+type engine struct {
+	currency string
+	rounding Rounding
+}
+
+// NewEngine returns an engine that prices books in the base currency.
+func NewEngine(currency string, rounding Rounding) Engine {
+	return &engine{currency: currency, rounding: rounding}
+}
+
+// PriceBook applies the book's own Discount and the campaigns running at now.
+//
+// The book discount and all stackable campaigns are applied one after another,
+// highest priority first, each rounded to a whole minor unit. Every
+// non-stackable campaign is then tried on its own against the base price, and
+// the cheapest outcome wins. Ties keep the stackable result.
+func (e *engine) PriceBook(book *entities.Book, genres []string, campaigns []entities.Campaign, now time.Time) Breakdown {
+	base := FromFloat(book.Cost)
+
+	final := base
+	var applied []string
+
+	if book.Discount != nil && *book.Discount > 0 {
+		final -= e.Discount(final, DiscountPercent, float64(*book.Discount))
+	}
+
+	var exclusive []entities.Campaign
+	for _, campaign := range active(campaigns, now) {
+		if !matches(&campaign, book, genres) {
+			continue
+		}
+
+		if !campaign.Stackable {
+			exclusive = append(exclusive, campaign)
+			continue
+		}
+
+		final -= e.Discount(final, campaign.DiscountType, campaign.DiscountValue)
+		applied = append(applied, campaign.Id)
+	}
+
+	for _, campaign := range exclusive {
+		price := base - e.Discount(base, campaign.DiscountType, campaign.DiscountValue)
+		if price < final {
+			final = price
+			applied = []string{campaign.Id}
+		}
+	}
+
+	return Breakdown{
+		Currency:       e.currency,
+		Price:          base,
+		DiscountAmount: base - final,
+		FinalPrice:     final,
+		Campaigns:      applied,
+	}
+}
+
+// Discount returns how much to take off amount, never more than amount itself.
+func (e *engine) Discount(amount Money, discountType string, value float64) Money {
+	var off Money
+	switch discountType {
+	case DiscountPercent:
+		off = ApplyPercent(amount, PercentFromFloat(value), e.rounding)
+	case DiscountFixed:
+		off = FromFloat(value)
+	}
+
+	if off < 0 {
+		return 0
+	}
+	if off > amount {
+		return amount
+	}
+	return off
+}
+
+// active returns campaigns running at now, highest priority first. Ties are
+// broken by ID so that the result never depends on query order.
+func active(campaigns []entities.Campaign, now time.Time) []entities.Campaign {
+	result := make([]entities.Campaign, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		if !now.Before(campaign.StartsAt) && now.Before(campaign.EndsAt) {
+			result = append(result, campaign)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Priority != result[j].Priority {
+			return result[i].Priority > result[j].Priority
+		}
+		return result[i].Id < result[j].Id
+	})
+
+	return result
+}
+
+// matches reports whether a campaign covers a book. Author and publisher
+// targets name entities by ID: a book matches an author it credits as its
+// author, not as translator or illustrator.
+func matches(campaign *entities.Campaign, book *entities.Book, genres []string) bool {
+	for _, target := range campaign.Targets {
+		switch target.Kind {
+		case TargetBook:
+			if target.Value == book.Id {
+				return true
+			}
+		case TargetAuthor:
+			for _, credit := range book.Authors {
+				if credit.Role == "author" && credit.AuthorId == target.Value {
+					return true
+				}
+			}
+		case TargetPublisher:
+			if book.PublisherId != nil && *book.PublisherId == target.Value {
+				return true
+			}
+		case TargetGenre:
+			for _, genre := range genres {
+				if strings.EqualFold(target.Value, genre) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package pricing
+
+import (
+	"slices"
+	"story-book/internal/entities"
+	"testing"
+	"time"
+)
+
+func TestDiscount(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       Money
+		discountType string
+		value        float64
+		rounding     Rounding
+		want         Money
+	}{
+		{name: "percent", amount: 1000, discountType: DiscountPercent, value: 10, want: 100},
+		{name: "percent rounded half up", amount: 100, discountType: DiscountPercent, value: 33.335, want: 33},
+		{name: "percent half up", amount: 99, discountType: DiscountPercent, value: 15, want: 15},
+		{name: "percent down", amount: 99, discountType: DiscountPercent, value: 15, rounding: Down, want: 14},
+		{name: "percent over 100 clamped", amount: 1000, discountType: DiscountPercent, value: 150, want: 1000},
+		{name: "negative percent clamped", amount: 1000, discountType: DiscountPercent, value: -10, want: 0},
+		{name: "fixed", amount: 1000, discountType: DiscountFixed, value: 5.5, want: 550},
+		{name: "fixed equal to amount", amount: 1000, discountType: DiscountFixed, value: 10, want: 1000},
+		{name: "fixed over amount clamped", amount: 1000, discountType: DiscountFixed, value: 20, want: 1000},
+		{name: "negative fixed clamped", amount: 1000, discountType: DiscountFixed, value: -5, want: 0},
+		{name: "free item", amount: 0, discountType: DiscountFixed, value: 5, want: 0},
+		{name: "unknown type", amount: 1000, discountType: "bogus", value: 10, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine("RUB", tt.rounding)
+			if got := e.Discount(tt.amount, tt.discountType, tt.value); got != tt.want {
+				t.Errorf("Discount(%d, %s, %v) = %d, want %d", tt.amount, tt.discountType, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceBook(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	discount := 10
+	publisherId := "publisher-1"
+	book := &entities.Book{
+		Id:          "book-1",
+		Authors:     []entities.BookAuthor{{AuthorId: "author-1", Role: "author"}, {AuthorId: "author-2", Role: "translator"}},
+		PublisherId: &publisherId,
+		Cost:        1000,
+		Discount:    &discount,
+	}
+	genres := []string{"Fantasy"}
+
+	campaign := func(id string, stackable bool, priority int, discountType string, value float64, kind, target string) entities.Campaign {
+		return entities.Campaign{
+			Id:            id,
+			DiscountType:  discountType,
+			DiscountValue: value,
+			Priority:      priority,
+			Stackable:     stackable,
+			StartsAt:      now.Add(-time.Hour),
+			EndsAt:        now.Add(time.Hour),
+			Targets:       []entities.CampaignTarget{{Kind: kind, Value: target}},
+		}
+	}
+
+	// The book's own 10% takes 1000.00 to 900.00, then stackable campaigns
+	// apply highest priority first: 10% to 810.00, then 50.00 to 760.00.
+	stackPercent := campaign("stack-percent", true, 2, DiscountPercent, 10, TargetBook, "book-1")
+	stackFixed := campaign("stack-fixed", true, 1, DiscountFixed, 50, TargetGenre, "fantasy")
+
+	expired := campaign("expired", true, 5, DiscountPercent, 50, TargetBook, "book-1")
+	expired.EndsAt = now
+	upcoming := campaign("upcoming", true, 5, DiscountPercent, 50, TargetBook, "book-1")
+	upcoming.StartsAt = now.Add(time.Minute)
+	// The translator is credited, but not as the book's author.
+	translator := campaign("translator", true, 5, DiscountPercent, 50, TargetAuthor, "author-2")
+
+	tests := []struct {
+		name      string
+		campaigns []entities.Campaign
+		want      Money
+		applied   []string
+	}{
+		{name: "book discount only", want: 90000},
+		{name: "stacking by priority",
+			campaigns: []entities.Campaign{stackFixed, stackPercent},
+			want:      76000, applied: []string{"stack-percent", "stack-fixed"}},
+		{name: "inactive and unmatched ignored",
+			campaigns: []entities.Campaign{expired, upcoming, translator, stackPercent},
+			want:      81000, applied: []string{"stack-percent"}},
+		{name: "exclusive beats stack",
+			campaigns: []entities.Campaign{stackPercent, stackFixed,
+				campaign("exclusive", false, 0, DiscountPercent, 30, TargetAuthor, "author-1")},
+			want: 70000, applied: []string{"exclusive"}},
+		{name: "exclusive applies to base price only",
+			campaigns: []entities.Campaign{campaign("exclusive", false, 0, DiscountPercent, 15, TargetPublisher, "publisher-1")},
+			want:      85000, applied: []string{"exclusive"}},
+		{name: "stack beats exclusive",
+			campaigns: []entities.Campaign{stackPercent, stackFixed,
+				campaign("exclusive", false, 0, DiscountPercent, 20, TargetBook, "book-1")},
+			want: 76000, applied: []string{"stack-percent", "stack-fixed"}},
+		{name: "tie keeps stack",
+			campaigns: []entities.Campaign{stackPercent, stackFixed,
+				campaign("exclusive", false, 0, DiscountFixed, 240, TargetBook, "book-1")},
+			want: 76000, applied: []string{"stack-percent", "stack-fixed"}},
+		{name: "cheapest exclusive wins",
+			campaigns: []entities.Campaign{
+				campaign("exclusive-a", false, 0, DiscountPercent, 20, TargetBook, "book-1"),
+				campaign("exclusive-b", false, 0, DiscountPercent, 40, TargetBook, "book-1"),
+				campaign("exclusive-c", false, 0, DiscountPercent, 30, TargetBook, "book-1")},
+			want: 60000, applied: []string{"exclusive-b"}},
+		{name: "stack cannot go below zero",
+			campaigns: []entities.Campaign{stackPercent,
+				campaign("huge", true, 1, DiscountFixed, 5000, TargetBook, "book-1")},
+			want: 0, applied: []string{"stack-percent", "huge"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewEngine("RUB", HalfUp).PriceBook(book, genres, tt.campaigns, now)
+			if got.Currency != "RUB" || got.Price != 100000 {
+				t.Errorf("Currency, Price = %s, %d, want RUB, 100000", got.Currency, got.Price)
+			}
+			if got.FinalPrice != tt.want {
+				t.Errorf("FinalPrice = %d, want %d", got.FinalPrice, tt.want)
+			}
+			if got.Price-got.DiscountAmount != got.FinalPrice {
+				t.Errorf("Price - DiscountAmount != FinalPrice: %d - %d != %d", got.Price, got.DiscountAmount, got.FinalPrice)
+			}
+			if !slices.Equal(got.Campaigns, tt.applied) {
+				t.Errorf("Campaigns = %v, want %v", got.Campaigns, tt.applied)
+			}
+		})
+	}
+}
+
+func TestPriceBookRounding(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	book := &entities.Book{Id: "book-1", Cost: 0.99}
+	campaigns := []entities.Campaign{{
+		Id:            "c1",
+		DiscountType:  DiscountPercent,
+		DiscountValue: 15,
+		Stackable:     true,
+		StartsAt:      now.Add(-time.Hour),
+		EndsAt:        now.Add(time.Hour),
+		Targets:       []entities.CampaignTarget{{Kind: TargetBook, Value: "book-1"}},
+	}}
+
+	tests := []struct {
+		rounding Rounding
+		want     Money
+	}{
+		{rounding: HalfUp, want: 84},
+		{rounding: HalfEven, want: 84},
+		{rounding: Down, want: 85},
+	}
+
+	for _, tt := range tests {
+		got := NewEngine("RUB", tt.rounding).PriceBook(book, nil, campaigns, now)
+		if got.FinalPrice != tt.want {
+			t.Errorf("rounding %d: FinalPrice = %d, want %d", tt.rounding, got.FinalPrice, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package pricing
+
+import "math"
+
+type Rounding int
+
+const (
+	HalfUp Rounding = iota
+	HalfEven
+	Down
+)
+
+func ParseRounding(s string) (Rounding, error) {
+	switch s {
+	case "half_up":
+		return HalfUp, nil
+	case "half_even":
+		return HalfEven, nil
+	case "down":
+		return Down, nil
+	}
+	return 0, ErrInvalidRounding
+}
+
+// Percent is a percentage in basis points: 12.5% is 1250.
+type Percent int64
+
+const hundredPercent Percent = 10000
+
+func PercentFromFloat(value float64) Percent {
+	return Percent(math.Round(value * 100))
+}
+
+// ApplyPercent returns p of amount rounded to a whole minor unit.
+func ApplyPercent(amount Money, p Percent, rounding Rounding) Money {
+	return Money(divide(int64(amount)*int64(p), int64(hundredPercent), rounding))
+}
+
+// divide returns n / d for d > 0, rounded away from the exact quotient by
+// mode. Negative numerators are rounded symmetrically to positive ones.
+func divide(n, d int64, rounding Rounding) int64 {
+	if n < 0 {
+		return -divide(-n, d, rounding)
+	}
+
+	q, r := n/d, n%d
+	switch rounding {
+	case HalfUp:
+		if 2*r >= d {
+			q++
+		}
+	case HalfEven:
+		if 2*r > d || (2*r == d && q%2 == 1) {
+			q++
+		}
+	}
+	return q
+}
@@ -0,0 +1,115 @@
+package pricing
+
+import "testing"
+
+func TestDivide(t *testing.T) {
+	tests := []struct {
+		name     string
+		n, d     int64
+		rounding Rounding
+		want     int64
+	}{
+		{name: "half up exact", n: 10, d: 5, rounding: HalfUp, want: 2},
+		{name: "half up below half", n: 4, d: 3, rounding: HalfUp, want: 1},
+		{name: "half up above half", n: 5, d: 3, rounding: HalfUp, want: 2},
+		{name: "half up half", n: 5, d: 2, rounding: HalfUp, want: 3},
+		{name: "half up odd half", n: 7, d: 2, rounding: HalfUp, want: 4},
+		{name: "half up negative half", n: -5, d: 2, rounding: HalfUp, want: -3},
+		{name: "half up negative below half", n: -4, d: 3, rounding: HalfUp, want: -1},
+		{name: "half up zero", n: 0, d: 7, rounding: HalfUp, want: 0},
+
+		{name: "half even half to even below", n: 5, d: 2, rounding: HalfEven, want: 2},
+		{name: "half even half to even above", n: 7, d: 2, rounding: HalfEven, want: 4},
+		{name: "half even quarter half", n: 10, d: 4, rounding: HalfEven, want: 2},
+		{name: "half even odd half", n: 6, d: 4, rounding: HalfEven, want: 2},
+		{name: "half even below half", n: 9, d: 4, rounding: HalfEven, want: 2},
+		{name: "half even above half", n: 11, d: 4, rounding: HalfEven, want: 3},
+		{name: "half even negative half", n: -5, d: 2, rounding: HalfEven, want: -2},
+		{name: "half even negative odd half", n: -7, d: 2, rounding: HalfEven, want: -4},
+
+		{name: "down half", n: 5, d: 2, rounding: Down, want: 2},
+		{name: "down above half", n: 5, d: 3, rounding: Down, want: 1},
+		{name: "down below one", n: 9, d: 10, rounding: Down, want: 0},
+		{name: "down negative", n: -5, d: 3, rounding: Down, want: -1},
+		{name: "down negative half", n: -5, d: 2, rounding: Down, want: -2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := divide(tt.n, tt.d, tt.rounding); got != tt.want {
+				t.Errorf("divide(%d, %d) = %d, want %d", tt.n, tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPercent(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Money
+		percent  Percent
+		rounding Rounding
+		want     Money
+	}{
+		{name: "exact", amount: 1000, percent: 1250, rounding: HalfUp, want: 125},
+		{name: "zero percent", amount: 1000, percent: 0, rounding: HalfUp, want: 0},
+		{name: "hundred percent", amount: 12345, percent: 10000, rounding: Down, want: 12345},
+		{name: "fraction half up", amount: 999, percent: 1500, rounding: HalfUp, want: 150},
+		{name: "fraction half even", amount: 999, percent: 1500, rounding: HalfEven, want: 150},
+		{name: "fraction down", amount: 999, percent: 1500, rounding: Down, want: 149},
+		{name: "half half up", amount: 25, percent: 1000, rounding: HalfUp, want: 3},
+		{name: "half half even to even", amount: 25, percent: 1000, rounding: HalfEven, want: 2},
+		{name: "odd half half even", amount: 35, percent: 1000, rounding: HalfEven, want: 4},
+		{name: "half down", amount: 25, percent: 1000, rounding: Down, want: 2},
+		{name: "negative half up", amount: -25, percent: 1000, rounding: HalfUp, want: -3},
+		{name: "negative half even", amount: -25, percent: 1000, rounding: HalfEven, want: -2},
+		{name: "negative down", amount: -25, percent: 1000, rounding: Down, want: -2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyPercent(tt.amount, tt.percent, tt.rounding); got != tt.want {
+				t.Errorf("ApplyPercent(%d, %d) = %d, want %d", tt.amount, tt.percent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentFromFloat(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want Percent
+	}{
+		{in: 0, want: 0},
+		{in: 12.5, want: 1250},
+		{in: 33.33, want: 3333},
+		{in: 100, want: 10000},
+	}
+
+	for _, tt := range tests {
+		if got := PercentFromFloat(tt.in); got != tt.want {
+			t.Errorf("PercentFromFloat(%v) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rounding
+		err  error
+	}{
+		{in: "half_up", want: HalfUp},
+		{in: "half_even", want: HalfEven},
+		{in: "down", want: Down},
+		{in: "up", err: ErrInvalidRounding},
+		{in: "", err: ErrInvalidRounding},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRounding(tt.in)
+		if err != tt.err || got != tt.want {
+			t.Errorf("ParseRounding(%q) = %d, %v, want %d, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
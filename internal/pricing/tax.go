@@ -0,0 +1,48 @@
+package pricing
+
+// Tax categories a book can belong to. Each is taxed at its own rate.
+const (
+	TaxCategoryBook  = "book"
+	TaxCategoryEbook = "ebook"
+	TaxCategoryOther = "other"
+)
+
+var taxCategories = map[string]bool{
+	TaxCategoryBook:  true,
+	TaxCategoryEbook: true,
+	TaxCategoryOther: true,
+}
+
+func IsTaxCategory(category string) bool {
+	return taxCategories[category]
+}
+
+// Tax is the tax part of a price. Net + Amount == Gross always holds.
+type Tax struct {
+	Region    string
+	Category  string
+	Rate      Percent
+	Inclusive bool
+	Net       Money
+	Amount    Money
+	Gross     Money
+}
+
+// ApplyTax splits or extends price by rate. An inclusive price already
+// contains the tax, which is extracted from it; an exclusive one has the tax
+// added on top.
+func ApplyTax(price Money, rate Percent, inclusive bool, rounding Rounding) Tax {
+	tax := Tax{Rate: rate, Inclusive: inclusive}
+
+	if inclusive {
+		tax.Gross = price
+		tax.Amount = Money(divide(int64(price)*int64(rate), int64(hundredPercent+rate), rounding))
+		tax.Net = price - tax.Amount
+		return tax
+	}
+
+	tax.Net = price
+	tax.Amount = ApplyPercent(price, rate, rounding)
+	tax.Gross = price + tax.Amount
+	return tax
+}
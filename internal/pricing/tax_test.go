@@ -0,0 +1,51 @@
+package pricing
+
+import "testing"
+
+func TestApplyTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		price     Money
+		rate      Percent
+		inclusive bool
+		rounding  Rounding
+		want      Tax
+	}{
+		{name: "inclusive exact", price: 12000, rate: 2000, inclusive: true, rounding: HalfUp,
+			want: Tax{Net: 10000, Amount: 2000, Gross: 12000}},
+		{name: "inclusive half up", price: 10000, rate: 2000, inclusive: true, rounding: HalfUp,
+			want: Tax{Net: 8333, Amount: 1667, Gross: 10000}},
+		{name: "inclusive down", price: 10000, rate: 2000, inclusive: true, rounding: Down,
+			want: Tax{Net: 8334, Amount: 1666, Gross: 10000}},
+		{name: "inclusive small", price: 105, rate: 1000, inclusive: true, rounding: HalfUp,
+			want: Tax{Net: 95, Amount: 10, Gross: 105}},
+		{name: "inclusive zero rate", price: 500, rate: 0, inclusive: true, rounding: HalfUp,
+			want: Tax{Net: 500, Amount: 0, Gross: 500}},
+		{name: "exclusive exact", price: 10000, rate: 1000, rounding: HalfUp,
+			want: Tax{Net: 10000, Amount: 1000, Gross: 11000}},
+		{name: "exclusive half up", price: 999, rate: 1250, rounding: HalfUp,
+			want: Tax{Net: 999, Amount: 125, Gross: 1124}},
+		{name: "exclusive half even", price: 999, rate: 1250, rounding: HalfEven,
+			want: Tax{Net: 999, Amount: 125, Gross: 1124}},
+		{name: "exclusive down", price: 999, rate: 1250, rounding: Down,
+			want: Tax{Net: 999, Amount: 124, Gross: 1123}},
+		{name: "exclusive zero price", price: 0, rate: 2000, rounding: HalfUp,
+			want: Tax{Net: 0, Amount: 0, Gross: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyTax(tt.price, tt.rate, tt.inclusive, tt.rounding)
+			if got.Net != tt.want.Net || got.Amount != tt.want.Amount || got.Gross != tt.want.Gross {
+				t.Errorf("got %d + %d = %d, want %d + %d = %d",
+					got.Net, got.Amount, got.Gross, tt.want.Net, tt.want.Amount, tt.want.Gross)
+			}
+			if got.Net+got.Amount != got.Gross {
+				t.Errorf("Net + Amount != Gross: %d + %d != %d", got.Net, got.Amount, got.Gross)
+			}
+			if got.Rate != tt.rate || got.Inclusive != tt.inclusive {
+				t.Errorf("Rate, Inclusive = %d, %v, want %d, %v", got.Rate, got.Inclusive, tt.rate, tt.inclusive)
+			}
+		})
+	}
+}
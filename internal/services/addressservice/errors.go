@@ -0,0 +1,15 @@
+package addressservice
+
+import "errors"
+
+var (
+	ErrAddressNotFound  = errors.New("address not found")
+	ErrTooManyAddresses = errors.New("too many addresses")
+	ErrInvalidLabel     = errors.New("label must be at most 50 characters")
+	ErrInvalidRecipient = errors.New("recipient must be between 1 and 100 characters")
+	ErrInvalidPhone     = errors.New("phone must contain 10 to 15 digits")
+	ErrInvalidCountry   = errors.New("country must be a two-letter ISO 3166 code")
+	ErrInvalidCity      = errors.New("city must be between 1 and 100 characters")
+	ErrInvalidPostal    = errors.New("postal code must be 3 to 10 letters, digits, spaces or hyphens")
+	ErrInvalidLine      = errors.New("address lines must be at most 200 characters and the first one is required")
+)
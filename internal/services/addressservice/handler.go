@@ -0,0 +1,253 @@
+package addressservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AddressService interface {
+	CreateAddress(ctx context.Context, address *entities.Address) (*entities.Address, error)
+	ReadAddresses(ctx context.Context, userId string) ([]entities.Address, error)
+	ReadAddress(ctx context.Context, userId, id string) (*entities.Address, error)
+	UpdateAddress(ctx context.Context, address *entities.Address) (*entities.Address, error)
+	DeleteAddress(ctx context.Context, userId, id string) error
+	SetDefault(ctx context.Context, userId, id string) (*entities.Address, error)
+}
+
+type AddressHandler struct {
+	service AddressService
+}
+
+func NewAddressHandler(service AddressService) *AddressHandler {
+	return &AddressHandler{service: service}
+}
+
+// CreateAddress
+// @Summary Добавить адрес доставки
+// @Description Первый адрес пользователя становится адресом по умолчанию
+// @Tags addresses
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.AddressRequest true "Адрес"
+// @Success 201 {object} dto.AddressResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /addresses [post]
+func (h *AddressHandler) CreateAddress(c echo.Context) error {
+	var request dto.AddressRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	address := fromAddressRequest(&request)
+	address.UserId = c.Get("id").(string)
+	address.IsDefault = request.IsDefault
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	address, err := h.service.CreateAddress(ctx, address)
+	if err != nil {
+		if errors.Is(err, ErrTooManyAddresses) {
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return addressError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toAddressResponse(address))
+}
+
+// ReadAddresses
+// @Summary Получить адресную книгу
+// @Tags addresses
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.AddressResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /addresses [get]
+func (h *AddressHandler) ReadAddresses(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	addresses, err := h.service.ReadAddresses(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.AddressResponse, 0, len(addresses))
+	for i := range addresses {
+		response = append(response, toAddressResponse(&addresses[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReadAddress
+// @Summary Получить адрес
+// @Tags addresses
+// @Security BearerAuth
+// @Param id path string true "ID адреса"
+// @Produce json
+// @Success 200 {object} dto.AddressResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /addresses/{id} [get]
+func (h *AddressHandler) ReadAddress(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	address, err := h.service.ReadAddress(ctx, userId, c.Param("id"))
+	if err != nil {
+		return addressError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAddressResponse(address))
+}
+
+// UpdateAddress
+// @Summary Изменить адрес
+// @Description Признак адреса по умолчанию меняется через PUT /addresses/{id}/default
+// @Tags addresses
+// @Security BearerAuth
+// @Param id path string true "ID адреса"
+// @Accept json
+// @Produce json
+// @Param request body dto.AddressRequest true "Адрес"
+// @Success 200 {object} dto.AddressResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /addresses/{id} [put]
+func (h *AddressHandler) UpdateAddress(c echo.Context) error {
+	var request dto.AddressRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	address := fromAddressRequest(&request)
+	address.Id = c.Param("id")
+	address.UserId = c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	address, err := h.service.UpdateAddress(ctx, address)
+	if err != nil {
+		return addressError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAddressResponse(address))
+}
+
+// DeleteAddress
+// @Summary Удалить адрес
+// @Tags addresses
+// @Security BearerAuth
+// @Param id path string true "ID адреса"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /addresses/{id} [delete]
+func (h *AddressHandler) DeleteAddress(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteAddress(ctx, userId, c.Param("id")); err != nil {
+		return addressError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetDefault
+// @Summary Сделать адрес адресом по умолчанию
+// @Tags addresses
+// @Security BearerAuth
+// @Param id path string true "ID адреса"
+// @Produce json
+// @Success 200 {object} dto.AddressResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /addresses/{id}/default [put]
+func (h *AddressHandler) SetDefault(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	address, err := h.service.SetDefault(ctx, userId, c.Param("id"))
+	if err != nil {
+		return addressError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAddressResponse(address))
+}
+
+func addressError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrAddressNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidLabel), errors.Is(err, ErrInvalidRecipient), errors.Is(err, ErrInvalidPhone),
+		errors.Is(err, ErrInvalidCountry), errors.Is(err, ErrInvalidCity), errors.Is(err, ErrInvalidPostal),
+		errors.Is(err, ErrInvalidLine):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func fromAddressRequest(request *dto.AddressRequest) *entities.Address {
+	return &entities.Address{
+		Label:      request.Label,
+		Recipient:  request.Recipient,
+		Phone:      request.Phone,
+		Country:    request.Country,
+		City:       request.City,
+		PostalCode: request.PostalCode,
+		Line1:      request.Line1,
+		Line2:      request.Line2,
+	}
+}
+
+func toAddressResponse(address *entities.Address) dto.AddressResponse {
+	response := dto.AddressResponse{
+		Id:         address.Id,
+		Recipient:  address.Recipient,
+		Phone:      address.Phone,
+		Country:    address.Country,
+		City:       address.City,
+		PostalCode: address.PostalCode,
+		Line1:      address.Line1,
+		IsDefault:  address.IsDefault,
+		CreatedAt:  address.CreatedAt,
+		UpdatedAt:  address.UpdatedAt,
+	}
+
+	if address.Label != nil {
+		response.Label = *address.Label
+	}
+	if address.Line2 != nil {
+		response.Line2 = *address.Line2
+	}
+
+	return response
+}
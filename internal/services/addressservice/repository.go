@@ -0,0 +1,142 @@
+package addressservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type addressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressRepository{db: db}
+}
+
+func (r *addressRepository) CountByUser(ctx context.Context, userId string) (int64, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Address{}).
+		Where("user_id = ?", userId).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *addressRepository) Create(ctx context.Context, address *entities.Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefault(tx, address.UserId); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(address).Error
+	})
+}
+
+// ReadByUser returns the user's addresses, the default one first.
+func (r *addressRepository) ReadByUser(ctx context.Context, userId string) ([]entities.Address, error) {
+	var addresses []entities.Address
+	if err := r.db.
+		WithContext(ctx).
+		Where("user_id = ?", userId).
+		Order("is_default DESC, created_at").
+		Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (r *addressRepository) ReadById(ctx context.Context, userId, id string) (*entities.Address, error) {
+	var address entities.Address
+	if err := r.db.
+		WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userId).
+		First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) Update(ctx context.Context, address *entities.Address) error {
+	res := r.db.
+		WithContext(ctx).
+		Model(&entities.Address{}).
+		Where("id = ? AND user_id = ?", address.Id, address.UserId).
+		Select("label", "recipient", "phone", "country", "city", "postal_code", "line1", "line2", "updated_at").
+		Updates(address)
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrAddressNotFound
+	}
+
+	return nil
+}
+
+func (r *addressRepository) Delete(ctx context.Context, userId, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var address entities.Address
+		res := tx.
+			Where("id = ? AND user_id = ?", id, userId).
+			Delete(&address)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrAddressNotFound
+		}
+
+		return tx.Exec(`
+			UPDATE addresses SET is_default = true, updated_at = @now
+			WHERE id = (SELECT id FROM addresses WHERE user_id = @user ORDER BY created_at LIMIT 1)
+				AND NOT EXISTS (SELECT 1 FROM addresses WHERE user_id = @user AND is_default)`,
+			map[string]any{"user": userId, "now": time.Now()},
+		).Error
+	})
+}
+
+func (r *addressRepository) SetDefault(ctx context.Context, userId, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := clearDefault(tx, userId); err != nil {
+			return err
+		}
+
+		res := tx.
+			Model(&entities.Address{}).
+			Where("id = ? AND user_id = ?", id, userId).
+			Updates(map[string]any{"is_default": true, "updated_at": time.Now()})
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrAddressNotFound
+		}
+
+		return nil
+	})
+}
+
+func clearDefault(tx *gorm.DB, userId string) error {
+	return tx.
+		Model(&entities.Address{}).
+		Where("user_id = ? AND is_default", userId).
+		Update("is_default", false).Error
+}
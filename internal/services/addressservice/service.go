@@ -0,0 +1,204 @@
+package addressservice
+
+import (
+	"context"
+	"regexp"
+	"story-book/internal/entities"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxAddresses    = 20
+	maxLabelLength  = 50
+	maxNameLength   = 100
+	maxLineLength   = 200
+	minPhoneDigits  = 10
+	maxPhoneDigits  = 15
+	phoneCharacters = "+-() "
+)
+
+var (
+	countryRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+	postalRegex  = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,8}[A-Z0-9]$`)
+)
+
+type AddressRepository interface {
+	CountByUser(ctx context.Context, userId string) (int64, error)
+	Create(ctx context.Context, address *entities.Address) error
+	ReadByUser(ctx context.Context, userId string) ([]entities.Address, error)
+	ReadById(ctx context.Context, userId, id string) (*entities.Address, error)
+	Update(ctx context.Context, address *entities.Address) error
+	Delete(ctx context.Context, userId, id string) error
+	SetDefault(ctx context.Context, userId, id string) error
+}
+
+type addressService struct {
+	repo AddressRepository
+}
+
+func NewAddressService(repo AddressRepository) AddressService {
+	return &addressService{repo: repo}
+}
+
+// CreateAddress adds an address to the user's address book. The first
+// address always becomes the default one.
+func (s *addressService) CreateAddress(ctx context.Context, address *entities.Address) (*entities.Address, error) {
+	if err := normalize(address); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByUser(ctx, address.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAddresses {
+		return nil, ErrTooManyAddresses
+	}
+
+	now := time.Now()
+
+	address.Id = uuid.NewString()
+	address.IsDefault = address.IsDefault || count == 0
+	address.CreatedAt = now
+	address.UpdatedAt = now
+
+	if err = s.repo.Create(ctx, address); err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+func (s *addressService) ReadAddresses(ctx context.Context, userId string) ([]entities.Address, error) {
+	return s.repo.ReadByUser(ctx, userId)
+}
+
+func (s *addressService) ReadAddress(ctx context.Context, userId, id string) (*entities.Address, error) {
+	return s.repo.ReadById(ctx, userId, id)
+}
+
+// UpdateAddress replaces the fields of one of the user's addresses. Whether
+// it is the default is changed through SetDefault only.
+func (s *addressService) UpdateAddress(ctx context.Context, address *entities.Address) (*entities.Address, error) {
+	if err := normalize(address); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.ReadById(ctx, address.UserId, address.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	address.IsDefault = current.IsDefault
+	address.CreatedAt = current.CreatedAt
+	address.UpdatedAt = time.Now()
+
+	if err = s.repo.Update(ctx, address); err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// DeleteAddress removes an address. When it was the default, the oldest
+// remaining address takes its place.
+func (s *addressService) DeleteAddress(ctx context.Context, userId, id string) error {
+	return s.repo.Delete(ctx, userId, id)
+}
+
+func (s *addressService) SetDefault(ctx context.Context, userId, id string) (*entities.Address, error) {
+	if err := s.repo.SetDefault(ctx, userId, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadById(ctx, userId, id)
+}
+
+// normalize trims the address and checks every field.
+func normalize(address *entities.Address) error {
+	address.Label = trimOptional(address.Label)
+	if address.Label != nil && utf8.RuneCountInString(*address.Label) > maxLabelLength {
+		return ErrInvalidLabel
+	}
+
+	address.Recipient = strings.TrimSpace(address.Recipient)
+	if address.Recipient == "" || utf8.RuneCountInString(address.Recipient) > maxNameLength {
+		return ErrInvalidRecipient
+	}
+
+	phone, err := normalizePhone(address.Phone)
+	if err != nil {
+		return err
+	}
+	address.Phone = phone
+
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+	if !countryRegex.MatchString(address.Country) {
+		return ErrInvalidCountry
+	}
+
+	address.City = strings.TrimSpace(address.City)
+	if address.City == "" || utf8.RuneCountInString(address.City) > maxNameLength {
+		return ErrInvalidCity
+	}
+
+	address.PostalCode = strings.ToUpper(strings.TrimSpace(address.PostalCode))
+	if !postalRegex.MatchString(address.PostalCode) {
+		return ErrInvalidPostal
+	}
+
+	address.Line1 = strings.TrimSpace(address.Line1)
+	if address.Line1 == "" || utf8.RuneCountInString(address.Line1) > maxLineLength {
+		return ErrInvalidLine
+	}
+
+	address.Line2 = trimOptional(address.Line2)
+	if address.Line2 != nil && utf8.RuneCountInString(*address.Line2) > maxLineLength {
+		return ErrInvalidLine
+	}
+
+	return nil
+}
+
+// normalizePhone keeps a leading plus and the digits, dropping the usual
+// separators.
+func normalizePhone(phone string) (string, error) {
+	phone = strings.TrimSpace(phone)
+
+	var b strings.Builder
+	digits := 0
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+			digits++
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case strings.ContainsRune(phoneCharacters, r):
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+
+	if digits < minPhoneDigits || digits > maxPhoneDigits {
+		return "", ErrInvalidPhone
+	}
+
+	return b.String(), nil
+}
+
+func trimOptional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,12 @@
+package alertservice
+
+import "errors"
+
+var (
+	ErrAlertNotFound    = errors.New("alert not found")
+	ErrBookNotFound     = errors.New("book not found")
+	ErrAlertExists      = errors.New("you are already subscribed to this alert")
+	ErrInvalidKind      = errors.New("alert kind must be restock or price_drop")
+	ErrInvalidThreshold = errors.New("price drop alerts need a positive threshold")
+	ErrNotStocked       = errors.New("e-books are never out of stock")
+)
@@ -0,0 +1,169 @@
+package alertservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AlertService interface {
+	Subscribe(ctx context.Context, alert *entities.BookAlert) (*entities.BookAlert, error)
+	ReadAlerts(ctx context.Context, userId string) ([]entities.BookAlert, error)
+	Unsubscribe(ctx context.Context, userId, id string) error
+	UnsubscribeByToken(ctx context.Context, token string) error
+	CheckBooks(ctx context.Context, books []entities.Book) (int, error)
+	CheckAll(ctx context.Context) (int, error)
+	Dispatch(ctx context.Context) (int, error)
+}
+
+type AlertHandler struct {
+	service AlertService
+}
+
+func NewAlertHandler(service AlertService) *AlertHandler {
+	return &AlertHandler{service: service}
+}
+
+// Subscribe
+// @Summary Подписаться на поступление или снижение цены книги
+// @Tags alerts
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Accept json
+// @Produce json
+// @Param request body dto.AlertRequest true "Тип подписки (restock, price_drop) и порог цены"
+// @Success 201 {object} dto.AlertResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/alerts [post]
+func (h *AlertHandler) Subscribe(c echo.Context) error {
+	var request dto.AlertRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	alert, err := h.service.Subscribe(ctx, &entities.BookAlert{
+		UserId:    userId,
+		BookId:    c.Param("id"),
+		Kind:      request.Kind,
+		Threshold: request.Threshold,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrAlertExists):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidKind), errors.Is(err, ErrInvalidThreshold), errors.Is(err, ErrNotStocked):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toAlertResponse(alert))
+}
+
+// ReadAlerts
+// @Summary Получить свои подписки
+// @Tags alerts
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.AlertResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /alerts [get]
+func (h *AlertHandler) ReadAlerts(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	alerts, err := h.service.ReadAlerts(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.AlertResponse, 0, len(alerts))
+	for i := range alerts {
+		response = append(response, toAlertResponse(&alerts[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Unsubscribe
+// @Summary Отменить подписку
+// @Tags alerts
+// @Security BearerAuth
+// @Param id path string true "ID подписки"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /alerts/{id} [delete]
+func (h *AlertHandler) Unsubscribe(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.Unsubscribe(ctx, userId, c.Param("id")); err != nil {
+		if errors.Is(err, ErrAlertNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UnsubscribeByToken
+// @Summary Отменить подписку по ссылке из уведомления
+// @Tags alerts
+// @Param token path string true "Токен отписки"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /alerts/unsubscribe/{token} [get]
+func (h *AlertHandler) UnsubscribeByToken(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.UnsubscribeByToken(ctx, c.Param("token")); err != nil {
+		if errors.Is(err, ErrAlertNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toAlertResponse(alert *entities.BookAlert) dto.AlertResponse {
+	response := dto.AlertResponse{
+		Id:        alert.Id,
+		BookId:    alert.BookId,
+		Kind:      alert.Kind,
+		Armed:     alert.Armed,
+		CreatedAt: alert.CreatedAt,
+	}
+
+	if alert.Threshold != nil {
+		response.Threshold = *alert.Threshold
+	}
+
+	return response
+}
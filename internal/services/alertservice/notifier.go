@@ -0,0 +1,26 @@
+package alertservice
+
+import (
+	"context"
+	"log"
+	"story-book/internal/entities"
+)
+
+// Notifier delivers a queued notification to the user. Delivery is at least
+// once: a notification whose Notify fails stays queued and is retried.
+type Notifier interface {
+	Notify(ctx context.Context, notification *entities.AlertNotification) error
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier returns a Notifier that writes notifications to the log.
+// It stands in until a mail or push channel is configured.
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+func (logNotifier) Notify(_ context.Context, notification *entities.AlertNotification) error {
+	log.Printf("notify user %s: %s", notification.UserId, notification.Message)
+	return nil
+}
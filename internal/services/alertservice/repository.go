@@ -0,0 +1,180 @@
+package alertservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uniqueViolationCode = "23505"
+
+type alertRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertRepository(db *gorm.DB) AlertRepository {
+	return &alertRepository{db: db}
+}
+
+func (r *alertRepository) ReadBook(ctx context.Context, bookId string) (*entities.Book, error) {
+	var book entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Where("id = ?", bookId).
+		First(&book).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	return &book, nil
+}
+
+// ReadAlertedBooks returns every book in the catalogue that has at least one
+// subscription.
+func (r *alertRepository) ReadAlertedBooks(ctx context.Context) ([]entities.Book, error) {
+	var books []entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Where("id IN (?)", r.db.Model(&entities.BookAlert{}).Select("book_id")).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (r *alertRepository) Create(ctx context.Context, alert *entities.BookAlert) error {
+	if err := r.db.WithContext(ctx).Create(alert).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlertExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *alertRepository) ReadByUser(ctx context.Context, userId string) ([]entities.BookAlert, error) {
+	var alerts []entities.BookAlert
+	if err := r.db.
+		WithContext(ctx).
+		Where("user_id = ?", userId).
+		Order("created_at DESC").
+		Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *alertRepository) ReadByBooks(ctx context.Context, bookIds []string) ([]entities.BookAlert, error) {
+	var alerts []entities.BookAlert
+	if err := r.db.
+		WithContext(ctx).
+		Where("book_id IN ?", bookIds).
+		Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *alertRepository) Delete(ctx context.Context, userId, id string) error {
+	return r.delete(ctx, "id = ? AND user_id = ?", id, userId)
+}
+
+func (r *alertRepository) DeleteByToken(ctx context.Context, token string) error {
+	return r.delete(ctx, "unsubscribe_token = ?", token)
+}
+
+// Fire queues the notification and disarms the alert in one transaction. The
+// unique dedup key makes a repeated fire for the same arming a no-op.
+func (r *alertRepository) Fire(ctx context.Context, notification *entities.AlertNotification) (bool, error) {
+	queued := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.
+			Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "dedup_key"}}, DoNothing: true}).
+			Create(notification)
+		if res.Error != nil {
+			return res.Error
+		}
+		queued = res.RowsAffected > 0
+
+		return tx.
+			Model(&entities.BookAlert{}).
+			Where("id = ?", *notification.AlertId).
+			Update("armed", false).Error
+	})
+	return queued, err
+}
+
+func (r *alertRepository) Rearm(ctx context.Context, ids []string, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.db.
+		WithContext(ctx).
+		Model(&entities.BookAlert{}).
+		Where("id IN ? AND armed = false", ids).
+		Updates(map[string]any{"armed": true, "armed_at": now}).Error
+}
+
+// SendPending hands up to limit queued notifications to send, oldest first,
+// and marks the ones it accepted as sent. Rows are locked with SKIP LOCKED so
+// that several instances never deliver the same notification.
+func (r *alertRepository) SendPending(ctx context.Context, limit int, send func(*entities.AlertNotification) error) (int, error) {
+	sent := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pending []entities.AlertNotification
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL").
+			Order("created_at").
+			Limit(limit).
+			Find(&pending).Error; err != nil {
+			return err
+		}
+
+		for i := range pending {
+			if err := send(&pending[i]); err != nil {
+				continue
+			}
+
+			if err := tx.
+				Model(&entities.AlertNotification{}).
+				Where("id = ?", pending[i].Id).
+				Update("sent_at", time.Now()).Error; err != nil {
+				return err
+			}
+			sent++
+		}
+
+		return nil
+	})
+	return sent, err
+}
+
+func (r *alertRepository) delete(ctx context.Context, query string, args ...any) error {
+	res := r.db.
+		WithContext(ctx).
+		Where(query, args...).
+		Delete(&entities.BookAlert{})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrAlertNotFound
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,215 @@
+package alertservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	KindRestock   = "restock"
+	KindPriceDrop = "price_drop"
+)
+
+const dispatchBatch = 100
+
+type AlertRepository interface {
+	ReadBook(ctx context.Context, bookId string) (*entities.Book, error)
+	ReadAlertedBooks(ctx context.Context) ([]entities.Book, error)
+	Create(ctx context.Context, alert *entities.BookAlert) error
+	ReadByUser(ctx context.Context, userId string) ([]entities.BookAlert, error)
+	ReadByBooks(ctx context.Context, bookIds []string) ([]entities.BookAlert, error)
+	Delete(ctx context.Context, userId, id string) error
+	DeleteByToken(ctx context.Context, token string) error
+	Fire(ctx context.Context, notification *entities.AlertNotification) (bool, error)
+	Rearm(ctx context.Context, ids []string, now time.Time) error
+	SendPending(ctx context.Context, limit int, send func(*entities.AlertNotification) error) (int, error)
+}
+
+// Pricer prices books in the base currency.
+type Pricer interface {
+	PriceBooks(ctx context.Context, books []entities.Book) (map[string]pricing.Breakdown, error)
+}
+
+type alertService struct {
+	repo      AlertRepository
+	pricer    Pricer
+	notifier  Notifier
+	publicUrl string
+}
+
+func NewAlertService(repo AlertRepository, pricer Pricer, notifier Notifier, publicUrl string) AlertService {
+	return &alertService{repo: repo, pricer: pricer, notifier: notifier, publicUrl: strings.TrimRight(publicUrl, "/")}
+}
+
+// Subscribe creates an alert. It starts armed, so a book that is already in
+// stock or already below the threshold notifies on the next check.
+func (s *alertService) Subscribe(ctx context.Context, alert *entities.BookAlert) (*entities.BookAlert, error) {
+	switch alert.Kind {
+	case KindRestock:
+		alert.Threshold = nil
+	case KindPriceDrop:
+		if alert.Threshold == nil || *alert.Threshold <= 0 {
+			return nil, ErrInvalidThreshold
+		}
+	default:
+		return nil, ErrInvalidKind
+	}
+
+	book, err := s.repo.ReadBook(ctx, alert.BookId)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Kind == KindRestock && book.Format != nil && *book.Format == entities.FormatEbook {
+		return nil, ErrNotStocked
+	}
+
+	now := time.Now()
+
+	alert.Id = uuid.NewString()
+	alert.UnsubscribeToken = newToken()
+	alert.Armed = true
+	alert.ArmedAt = now
+	alert.CreatedAt = now
+
+	if err = s.repo.Create(ctx, alert); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+func (s *alertService) ReadAlerts(ctx context.Context, userId string) ([]entities.BookAlert, error) {
+	return s.repo.ReadByUser(ctx, userId)
+}
+
+func (s *alertService) Unsubscribe(ctx context.Context, userId, id string) error {
+	return s.repo.Delete(ctx, userId, id)
+}
+
+func (s *alertService) UnsubscribeByToken(ctx context.Context, token string) error {
+	return s.repo.DeleteByToken(ctx, token)
+}
+
+// CheckBooks evaluates the alerts of the given books against their current
+// stock and effective price. An armed alert whose condition holds queues one
+// notification and disarms; a disarmed alert whose condition no longer holds
+// is re-armed, so every new restock or price drop notifies exactly once.
+func (s *alertService) CheckBooks(ctx context.Context, books []entities.Book) (int, error) {
+	if len(books) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(books))
+	byId := make(map[string]*entities.Book, len(books))
+	for i := range books {
+		ids = append(ids, books[i].Id)
+		byId[books[i].Id] = &books[i]
+	}
+
+	alerts, err := s.repo.ReadByBooks(ctx, ids)
+	if err != nil || len(alerts) == 0 {
+		return 0, err
+	}
+
+	prices, err := s.pricer.PriceBooks(ctx, books)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	var rearm []string
+	for _, alert := range alerts {
+		book, ok := byId[alert.BookId]
+		if !ok {
+			continue
+		}
+
+		met := s.met(&alert, book, prices[book.Id])
+
+		switch {
+		case met && alert.Armed:
+			ok, err := s.repo.Fire(ctx, s.notification(&alert, book, prices[book.Id]))
+			if err != nil {
+				return queued, err
+			}
+			if ok {
+				queued++
+			}
+		case !met && !alert.Armed:
+			rearm = append(rearm, alert.Id)
+		}
+	}
+
+	if err = s.repo.Rearm(ctx, rearm, time.Now()); err != nil {
+		return queued, err
+	}
+
+	return queued, nil
+}
+
+func (s *alertService) CheckAll(ctx context.Context) (int, error) {
+	books, err := s.repo.ReadAlertedBooks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.CheckBooks(ctx, books)
+}
+
+func (s *alertService) Dispatch(ctx context.Context) (int, error) {
+	return s.repo.SendPending(ctx, dispatchBatch, func(notification *entities.AlertNotification) error {
+		err := s.notifier.Notify(ctx, notification)
+		if err != nil {
+			log.Printf("failed to send alert notification %s: %v", notification.Id, err)
+		}
+		return err
+	})
+}
+
+func (s *alertService) met(alert *entities.BookAlert, book *entities.Book, price pricing.Breakdown) bool {
+	switch alert.Kind {
+	case KindRestock:
+		return book.Amount > 0
+	case KindPriceDrop:
+		return alert.Threshold != nil && price.FinalPrice <= pricing.FromFloat(*alert.Threshold)
+	}
+	return false
+}
+
+func (s *alertService) notification(alert *entities.BookAlert, book *entities.Book, price pricing.Breakdown) *entities.AlertNotification {
+	var message string
+	switch alert.Kind {
+	case KindRestock:
+		message = fmt.Sprintf("%q by %s is back in stock.", book.Title, book.Author)
+	case KindPriceDrop:
+		message = fmt.Sprintf("%q by %s now costs %s.", book.Title, book.Author, price.FinalPrice)
+	}
+
+	message += " Unsubscribe: " + s.publicUrl + "/alerts/unsubscribe/" + alert.UnsubscribeToken
+
+	return &entities.AlertNotification{
+		Id:        uuid.NewString(),
+		AlertId:   &alert.Id,
+		UserId:    alert.UserId,
+		BookId:    alert.BookId,
+		Kind:      alert.Kind,
+		Message:   message,
+		DedupKey:  fmt.Sprintf("%s:%d", alert.Id, alert.ArmedAt.UnixNano()),
+		CreatedAt: time.Now(),
+	}
+}
+
+func newToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,47 @@
+package alertservice
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunWorker re-checks every subscribed book, which catches price drops from
+// scheduled changes and campaigns, and then delivers queued notifications.
+// It blocks until ctx is done.
+func RunWorker(ctx context.Context, service AlertService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run(ctx, service)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func run(ctx context.Context, service AlertService) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	queued, err := service.CheckAll(ctx)
+	if err != nil {
+		log.Printf("alert check failed: %v", err)
+	} else if queued > 0 {
+		log.Printf("queued %d alert notifications", queued)
+	}
+
+	sent, err := service.Dispatch(ctx)
+	if err != nil {
+		log.Printf("alert dispatch failed: %v", err)
+		return
+	}
+
+	if sent > 0 {
+		log.Printf("sent %d alert notifications", sent)
+	}
+}
@@ -0,0 +1,26 @@
+package auditservice
+
+import "context"
+
+type actorKey struct{}
+
+// RoleSystem is the role of changes made by background workers rather than
+// by a user.
+const RoleSystem = "system"
+
+// Actor describes who performed a request and where it came from.
+type Actor struct {
+	Id        string
+	Role      string
+	Ip        string
+	RequestId string
+}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
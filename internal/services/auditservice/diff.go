@@ -0,0 +1,90 @@
+package auditservice
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+const redacted = "[redacted]"
+
+// sensitiveFields are never written to the log; a change is still recorded,
+// but only as a redaction marker.
+var sensitiveFields = map[string]struct{}{
+	"Password":  {},
+	"Salt":      {},
+	"Answer":    {},
+	"ImageData": {},
+}
+
+// diff returns the fields of before and after that differ. A nil side is
+// recorded as null, so creates keep only "after" and deletes only "before".
+func diff(before, after any) (json.RawMessage, json.RawMessage, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changedBefore := make(map[string]any)
+	changedAfter := make(map[string]any)
+
+	if beforeFields == nil || afterFields == nil {
+		for key, value := range beforeFields {
+			changedBefore[key] = redact(key, value)
+		}
+		for key, value := range afterFields {
+			changedAfter[key] = redact(key, value)
+		}
+	} else {
+		for key, value := range afterFields {
+			if reflect.DeepEqual(beforeFields[key], value) {
+				continue
+			}
+			changedBefore[key] = redact(key, beforeFields[key])
+			changedAfter[key] = redact(key, value)
+		}
+	}
+
+	return marshal(beforeFields, changedBefore), marshal(afterFields, changedAfter), nil
+}
+
+func toFields(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err = json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func redact(key string, value any) any {
+	if _, ok := sensitiveFields[key]; ok && value != nil && value != "" {
+		return redacted
+	}
+	return value
+}
+
+func marshal(fields, changed map[string]any) json.RawMessage {
+	if fields == nil {
+		return nil
+	}
+
+	raw, _ := json.Marshal(changed)
+	return raw
+}
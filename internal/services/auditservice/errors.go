@@ -0,0 +1,9 @@
+package auditservice
+
+import "errors"
+
+var (
+	ErrInvalidPage  = errors.New("invalid page")
+	ErrInvalidLimit = errors.New("invalid limit")
+	ErrInvalidTime  = errors.New("invalid time, expected RFC3339")
+)
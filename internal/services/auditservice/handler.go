@@ -0,0 +1,135 @@
+package auditservice
+
+import (
+	"context"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuditService interface {
+	Record(ctx context.Context, action, entityType, entityId string, before, after any) error
+	NewRecord(ctx context.Context, action, entityType, entityId string, before, after any) (*entities.AuditRecord, error)
+	ReadRecords(ctx context.Context, filter Filter, page, limit int) ([]entities.AuditRecord, error)
+}
+
+type AuditHandler struct {
+	service AuditService
+}
+
+func NewAuditHandler(service AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// ReadRecords
+// @Summary Получить журнал аудита
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param actor_id query string false "ID пользователя, выполнившего действие"
+// @Param action query string false "Действие (create, update, delete, restore, hard_delete)"
+// @Param entity_type query string false "Тип сущности (book, user)"
+// @Param entity_id query string false "ID сущности"
+// @Param from query string false "Начало периода (RFC3339)"
+// @Param to query string false "Конец периода (RFC3339)"
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Success 200 {array} dto.AuditRecordResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/audit [get]
+func (h *AuditHandler) ReadRecords(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidPage.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	filter := Filter{
+		ActorId:    c.QueryParam("actor_id"),
+		Action:     c.QueryParam("action"),
+		EntityType: c.QueryParam("entity_type"),
+		EntityId:   c.QueryParam("entity_id"),
+	}
+
+	if filter.From, err = parseTime(c.QueryParam("from")); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	if filter.To, err = parseTime(c.QueryParam("to")); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	response, err := h.service.ReadRecords(ctx, filter, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	records := make([]dto.AuditRecordResponse, 0, len(response))
+	for _, record := range response {
+		records = append(records, dto.AuditRecordResponse{
+			Id:         record.Id,
+			ActorId:    validate(record.ActorId),
+			ActorRole:  record.ActorRole,
+			Action:     record.Action,
+			EntityType: record.EntityType,
+			EntityId:   record.EntityId,
+			Before:     record.Before,
+			After:      record.After,
+			Ip:         record.Ip,
+			RequestId:  record.RequestId,
+			CreatedAt:  record.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, records)
+}
+
+func parseTime(str string) (*time.Time, error) {
+	if str == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return nil, ErrInvalidTime
+	}
+
+	return &t, nil
+}
+
+func validate[T any](t *T) T {
+	if t != nil {
+		return *t
+	}
+
+	var zero T
+	return zero
+}
@@ -0,0 +1,89 @@
+package auditservice
+
+import (
+	"context"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionDelete     = "delete"
+	ActionRestore    = "restore"
+	ActionHardDelete = "hard_delete"
+)
+
+const (
+	EntityBook = "book"
+	EntityUser = "user"
+)
+
+type Filter struct {
+	ActorId    string
+	Action     string
+	EntityType string
+	EntityId   string
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, record *entities.AuditRecord) error
+	ReadAll(ctx context.Context, filter Filter, offset, limit int) ([]entities.AuditRecord, error)
+}
+
+type auditService struct {
+	repo AuditRepository
+}
+
+func NewAuditService(repo AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// Record appends a change made by the actor stored in ctx.
+func (s *auditService) Record(ctx context.Context, action, entityType, entityId string, before, after any) error {
+	record, err := s.NewRecord(ctx, action, entityType, entityId, before, after)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, record)
+}
+
+// NewRecord builds the entry of a change made by the actor stored in ctx
+// without saving it, for callers that write it in their own transaction.
+// Only fields that differ between before and after are kept; pass nil for
+// the missing side of a create or delete.
+func (s *auditService) NewRecord(ctx context.Context, action, entityType, entityId string, before, after any) (*entities.AuditRecord, error) {
+	changedBefore, changedAfter, err := diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := ActorFromContext(ctx)
+
+	record := &entities.AuditRecord{
+		Id:         uuid.NewString(),
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   entityId,
+		Before:     changedBefore,
+		After:      changedAfter,
+		Ip:         actor.Ip,
+		RequestId:  actor.RequestId,
+	}
+
+	if actor.Id != "" {
+		record.ActorId = &actor.Id
+	}
+
+	return record, nil
+}
+
+func (s *auditService) ReadRecords(ctx context.Context, filter Filter, page, limit int) ([]entities.AuditRecord, error) {
+	return s.repo.ReadAll(ctx, filter, (page-1)*limit, limit)
+}
@@ -0,0 +1,17 @@
+package authorservice
+
+import "errors"
+
+var (
+	ErrAuthorNotFound   = errors.New("author not found")
+	ErrInvalidName      = errors.New("name must be between 1 and 200 characters")
+	ErrInvalidAlias     = errors.New("aliases must be distinct, at most 20, each between 1 and 200 characters")
+	ErrBiographyTooLong = errors.New("biography is too long")
+	ErrInvalidPhoto     = errors.New("invalid photo")
+	ErrNameTaken        = errors.New("name or alias is already used by another author")
+	ErrAuthorInUse      = errors.New("author is credited on books; merge it into another author instead")
+	ErrInvalidMerge     = errors.New("an author cannot be merged into itself")
+	ErrAccessDenied     = errors.New("access denied")
+	ErrInvalidPage      = errors.New("invalid page")
+	ErrInvalidLimit     = errors.New("invalid limit")
+)
@@ -0,0 +1,333 @@
+package authorservice
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuthorService interface {
+	CreateAuthor(ctx context.Context, author *entities.Author) (*entities.Author, error)
+	ReadAuthors(ctx context.Context, query string, page, limit int) ([]entities.Author, error)
+	ReadAuthor(ctx context.Context, id string) (*entities.Author, error)
+	UpdateAuthor(ctx context.Context, author *entities.Author) (*entities.Author, error)
+	DeleteAuthor(ctx context.Context, id string) error
+	MergeAuthors(ctx context.Context, targetId, sourceId string) (*entities.Author, error)
+}
+
+type AuthorHandler struct {
+	service AuthorService
+}
+
+func NewAuthorHandler(service AuthorService) *AuthorHandler {
+	return &AuthorHandler{service: service}
+}
+
+// CreateAuthor
+// @Summary Создать автора
+// @Tags authors
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.AuthorRequest true "Имя, биография, фото и другие написания имени"
+// @Success 201 {object} dto.AuthorResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /authors [post]
+func (h *AuthorHandler) CreateAuthor(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.AuthorRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	author, err := fromAuthorRequest(&request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	author, err = h.service.CreateAuthor(ctx, author)
+	if err != nil {
+		return authorError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toAuthorResponse(author))
+}
+
+// ReadAuthors
+// @Summary Получить авторов
+// @Tags authors
+// @Param q query string false "Поиск по имени и другим написаниям имени"
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Produce json
+// @Success 200 {array} dto.AuthorResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /authors [get]
+func (h *AuthorHandler) ReadAuthors(c echo.Context) error {
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidPage.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	authors, err := h.service.ReadAuthors(ctx, c.QueryParam("q"), page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.AuthorResponse, 0, len(authors))
+	for i := range authors {
+		response = append(response, toAuthorResponse(&authors[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReadAuthor
+// @Summary Получить автора
+// @Description Книги автора — GET /books?author_id={id}
+// @Tags authors
+// @Param id path string true "ID автора"
+// @Produce json
+// @Success 200 {object} dto.AuthorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /authors/{id} [get]
+func (h *AuthorHandler) ReadAuthor(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	author, err := h.service.ReadAuthor(ctx, c.Param("id"))
+	if err != nil {
+		return authorError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAuthorResponse(author))
+}
+
+// UpdateAuthor
+// @Summary Изменить автора
+// @Description Другие написания имени заменяются целиком; фото меняется, только если передано новое
+// @Tags authors
+// @Security BearerAuth
+// @Param id path string true "ID автора"
+// @Accept json
+// @Produce json
+// @Param request body dto.AuthorRequest true "Имя, биография, фото и другие написания имени"
+// @Success 200 {object} dto.AuthorResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /authors/{id} [put]
+func (h *AuthorHandler) UpdateAuthor(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.AuthorRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	author, err := fromAuthorRequest(&request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	author.Id = c.Param("id")
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	author, err = h.service.UpdateAuthor(ctx, author)
+	if err != nil {
+		return authorError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAuthorResponse(author))
+}
+
+// DeleteAuthor
+// @Summary Удалить автора
+// @Description Автора, указанного в книгах, удалить нельзя — его можно объединить с другим
+// @Tags authors
+// @Security BearerAuth
+// @Param id path string true "ID автора"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /authors/{id} [delete]
+func (h *AuthorHandler) DeleteAuthor(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteAuthor(ctx, c.Param("id")); err != nil {
+		return authorError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MergeAuthors
+// @Summary Объединить автора-дубликат с автором
+// @Description Книги дубликата переходят к автору, имя дубликата сохраняется как другое написание имени, дубликат удаляется
+// @Tags authors
+// @Security BearerAuth
+// @Param id path string true "ID автора, который остаётся"
+// @Accept json
+// @Produce json
+// @Param request body dto.MergeRequest true "ID дубликата"
+// @Success 200 {object} dto.AuthorResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /authors/{id}/merge [post]
+func (h *AuthorHandler) MergeAuthors(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.MergeRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	author, err := h.service.MergeAuthors(ctx, c.Param("id"), request.SourceId)
+	if err != nil {
+		return authorError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAuthorResponse(author))
+}
+
+func authorError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrAuthorNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrNameTaken), errors.Is(err, ErrAuthorInUse):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidName), errors.Is(err, ErrInvalidAlias), errors.Is(err, ErrBiographyTooLong),
+		errors.Is(err, ErrInvalidMerge):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func fromAuthorRequest(request *dto.AuthorRequest) (*entities.Author, error) {
+	author := &entities.Author{
+		Name:      request.Name,
+		Biography: request.Biography,
+	}
+
+	for _, alias := range request.Aliases {
+		author.Aliases = append(author.Aliases, entities.AuthorAlias{Alias: alias})
+	}
+
+	if request.Photo != nil && *request.Photo != "" {
+		data, mime, err := fromDataUrl(*request.Photo)
+		if err != nil {
+			return nil, ErrInvalidPhoto
+		}
+		author.PhotoData = data
+		author.PhotoMime = mime
+	}
+
+	return author, nil
+}
+
+func toAuthorResponse(author *entities.Author) dto.AuthorResponse {
+	response := dto.AuthorResponse{
+		Id:        author.Id,
+		Name:      author.Name,
+		Photo:     toDataUrl(author.PhotoData, author.PhotoMime),
+		Aliases:   make([]string, 0, len(author.Aliases)),
+		CreatedAt: author.CreatedAt,
+		UpdatedAt: author.UpdatedAt,
+	}
+
+	if author.Biography != nil {
+		response.Biography = *author.Biography
+	}
+	for _, alias := range author.Aliases {
+		response.Aliases = append(response.Aliases, alias.Alias)
+	}
+
+	return response
+}
+
+func toDataUrl(data []byte, mime string) string {
+	if data == nil {
+		return ""
+	}
+	if mime == "" {
+		mime = "image/png"
+	}
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// fromDataUrl accepts a data URL or bare base64, like book images.
+func fromDataUrl(str string) ([]byte, string, error) {
+	meta, data, found := strings.Cut(str, ",")
+	if !found || !strings.HasPrefix(meta, "data:") {
+		b, err := base64.StdEncoding.DecodeString(str)
+		return b, "", err
+	}
+
+	mime, _, _ := strings.Cut(strings.TrimPrefix(meta, "data:"), ";")
+
+	b, err := base64.StdEncoding.DecodeString(data)
+	return b, mime, err
+}
@@ -0,0 +1,255 @@
+package authorservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uniqueViolationCode = "23505"
+
+// refreshBooks rewrites the display names in books.author from the credits
+// of every book an author is credited on. Raw SQL so books in the trash are
+// kept in step too.
+const refreshBooks = `
+UPDATE books
+SET author = COALESCE(
+        (SELECT string_agg(a.name, ', ' ORDER BY ba.position)
+         FROM book_authors ba
+                  JOIN authors a ON a.id = ba.author_id
+         WHERE ba.book_id = books.id AND ba.role = 'author'),
+        (SELECT string_agg(a.name, ', ' ORDER BY ba.position)
+         FROM book_authors ba
+                  JOIN authors a ON a.id = ba.author_id
+         WHERE ba.book_id = books.id),
+        books.author)
+WHERE id IN (SELECT book_id FROM book_authors WHERE author_id = ?)`
+
+type authorRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthorRepository(db *gorm.DB) AuthorRepository {
+	return &authorRepository{db: db}
+}
+
+func (r *authorRepository) Create(ctx context.Context, author *entities.Author) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(author).Error; err != nil {
+			return err
+		}
+
+		return saveAliases(tx, author)
+	})
+	if isUniqueViolation(err) {
+		return ErrNameTaken
+	}
+	return err
+}
+
+// ReadAll lists authors by name. A query matches names and aliases.
+func (r *authorRepository) ReadAll(ctx context.Context, query string, offset, limit int) ([]entities.Author, error) {
+	db := r.db.
+		WithContext(ctx).
+		Preload("Aliases")
+
+	if query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(query)) + "%"
+		db = db.Where(
+			"LOWER(name) LIKE ? OR id IN (SELECT author_id FROM author_aliases WHERE LOWER(alias) LIKE ?)",
+			pattern, pattern,
+		)
+	}
+
+	var authors []entities.Author
+	if err := db.
+		Order("name, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&authors).Error; err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+func (r *authorRepository) ReadById(ctx context.Context, id string) (*entities.Author, error) {
+	return readAuthor(r.db.WithContext(ctx), id)
+}
+
+// Update replaces an author's name, biography and aliases, and the photo
+// when a new one is given. Books crediting the author show the new name.
+func (r *authorRepository) Update(ctx context.Context, author *entities.Author) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{
+			"name":       author.Name,
+			"biography":  author.Biography,
+			"updated_at": author.UpdatedAt,
+		}
+		if author.PhotoData != nil {
+			updates["photo_data"] = author.PhotoData
+			updates["photo_mime"] = author.PhotoMime
+		}
+
+		res := tx.
+			Model(&entities.Author{}).
+			Where("id = ?", author.Id).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrAuthorNotFound
+		}
+
+		if err := tx.
+			Where("author_id = ?", author.Id).
+			Delete(&entities.AuthorAlias{}).Error; err != nil {
+			return err
+		}
+
+		if err := saveAliases(tx, author); err != nil {
+			return err
+		}
+
+		return tx.Exec(refreshBooks, author.Id).Error
+	})
+	if isUniqueViolation(err) {
+		return ErrNameTaken
+	}
+	return err
+}
+
+// Delete removes an author no book credits.
+func (r *authorRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var credits int64
+		if err := tx.
+			Model(&entities.BookAuthor{}).
+			Where("author_id = ?", id).
+			Count(&credits).Error; err != nil {
+			return err
+		}
+		if credits > 0 {
+			return ErrAuthorInUse
+		}
+
+		res := tx.
+			Where("id = ?", id).
+			Delete(&entities.Author{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrAuthorNotFound
+		}
+		return nil
+	})
+}
+
+// Merge folds a duplicate author into the target: the duplicate's credits
+// move to the target, its name and aliases become the target's aliases and
+// the duplicate is deleted.
+func (r *authorRepository) Merge(ctx context.Context, targetId, sourceId string) (*entities.Author, error) {
+	var target *entities.Author
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		target, err = readAuthor(tx.Clauses(clause.Locking{Strength: "UPDATE"}), targetId)
+		if err != nil {
+			return err
+		}
+
+		source, err := readAuthor(tx.Clauses(clause.Locking{Strength: "UPDATE"}), sourceId)
+		if err != nil {
+			return err
+		}
+
+		// A book crediting both keeps the target's credit in that role.
+		if err = tx.Exec(`
+			UPDATE book_authors SET author_id = ?
+			WHERE author_id = ?
+			  AND NOT EXISTS (SELECT 1 FROM book_authors t
+			                  WHERE t.book_id = book_authors.book_id AND t.author_id = ? AND t.role = book_authors.role)`,
+			targetId, sourceId, targetId).Error; err != nil {
+			return err
+		}
+
+		names := []string{source.Name}
+		for _, alias := range source.Aliases {
+			names = append(names, alias.Alias)
+		}
+
+		// The source goes first: its aliases are unique across authors.
+		if err = tx.
+			Where("author_id = ?", sourceId).
+			Delete(&entities.BookAuthor{}).Error; err != nil {
+			return err
+		}
+		if err = tx.
+			Where("id = ?", sourceId).
+			Delete(&entities.Author{}).Error; err != nil {
+			return err
+		}
+
+		known := map[string]bool{strings.ToLower(target.Name): true}
+		for _, alias := range target.Aliases {
+			known[strings.ToLower(alias.Alias)] = true
+		}
+		for _, name := range names {
+			if known[strings.ToLower(name)] {
+				continue
+			}
+			known[strings.ToLower(name)] = true
+
+			alias := entities.AuthorAlias{AuthorId: targetId, Alias: name}
+			if err = tx.Create(&alias).Error; err != nil {
+				return err
+			}
+			target.Aliases = append(target.Aliases, alias)
+		}
+
+		return tx.Exec(refreshBooks, targetId).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func saveAliases(tx *gorm.DB, author *entities.Author) error {
+	if len(author.Aliases) == 0 {
+		return nil
+	}
+
+	for i := range author.Aliases {
+		author.Aliases[i].AuthorId = author.Id
+	}
+	return tx.Create(&author.Aliases).Error
+}
+
+func readAuthor(db *gorm.DB, id string) (*entities.Author, error) {
+	var author entities.Author
+	if err := db.
+		Preload("Aliases").
+		Where("id = ?", id).
+		First(&author).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAuthorNotFound
+		}
+		return nil, err
+	}
+	return &author, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
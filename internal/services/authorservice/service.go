@@ -0,0 +1,127 @@
+package authorservice
+
+import (
+	"context"
+	"story-book/internal/entities"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxNameLength      = 200
+	maxBiographyLength = 10000
+	maxAliases         = 20
+)
+
+type AuthorRepository interface {
+	Create(ctx context.Context, author *entities.Author) error
+	ReadAll(ctx context.Context, query string, offset, limit int) ([]entities.Author, error)
+	ReadById(ctx context.Context, id string) (*entities.Author, error)
+	Update(ctx context.Context, author *entities.Author) error
+	Delete(ctx context.Context, id string) error
+	Merge(ctx context.Context, targetId, sourceId string) (*entities.Author, error)
+}
+
+type authorService struct {
+	repo AuthorRepository
+}
+
+func NewAuthorService(repo AuthorRepository) AuthorService {
+	return &authorService{repo: repo}
+}
+
+func (s *authorService) CreateAuthor(ctx context.Context, author *entities.Author) (*entities.Author, error) {
+	if err := validateAuthor(author); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	author.Id = uuid.NewString()
+	author.CreatedAt = now
+	author.UpdatedAt = now
+
+	if err := s.repo.Create(ctx, author); err != nil {
+		return nil, err
+	}
+
+	return author, nil
+}
+
+func (s *authorService) ReadAuthors(ctx context.Context, query string, page, limit int) ([]entities.Author, error) {
+	return s.repo.ReadAll(ctx, strings.TrimSpace(query), (page-1)*limit, limit)
+}
+
+func (s *authorService) ReadAuthor(ctx context.Context, id string) (*entities.Author, error) {
+	return s.repo.ReadById(ctx, id)
+}
+
+func (s *authorService) UpdateAuthor(ctx context.Context, author *entities.Author) (*entities.Author, error) {
+	if err := validateAuthor(author); err != nil {
+		return nil, err
+	}
+
+	author.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, author); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadById(ctx, author.Id)
+}
+
+func (s *authorService) DeleteAuthor(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// MergeAuthors folds a duplicate into the target author. The duplicate's
+// name is kept as an alias, so books saved under it later still find the
+// target.
+func (s *authorService) MergeAuthors(ctx context.Context, targetId, sourceId string) (*entities.Author, error) {
+	if targetId == sourceId {
+		return nil, ErrInvalidMerge
+	}
+
+	return s.repo.Merge(ctx, targetId, sourceId)
+}
+
+func validateAuthor(author *entities.Author) error {
+	author.Name = normalizeName(author.Name)
+	if author.Name == "" || utf8.RuneCountInString(author.Name) > maxNameLength {
+		return ErrInvalidName
+	}
+
+	if author.Biography != nil {
+		biography := strings.TrimSpace(*author.Biography)
+		if utf8.RuneCountInString(biography) > maxBiographyLength {
+			return ErrBiographyTooLong
+		}
+		author.Biography = &biography
+		if biography == "" {
+			author.Biography = nil
+		}
+	}
+
+	if len(author.Aliases) > maxAliases {
+		return ErrInvalidAlias
+	}
+
+	seen := map[string]bool{strings.ToLower(author.Name): true}
+	for i := range author.Aliases {
+		alias := normalizeName(author.Aliases[i].Alias)
+		if alias == "" || utf8.RuneCountInString(alias) > maxNameLength || seen[strings.ToLower(alias)] {
+			return ErrInvalidAlias
+		}
+		seen[strings.ToLower(alias)] = true
+		author.Aliases[i].Alias = alias
+	}
+
+	return nil
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -3,5 +3,15 @@ package bookservice
 import "errors"
 
 var (
-	ErrBookNotFound = errors.New("book not found")
+	ErrBookNotFound      = errors.New("book not found")
+	ErrInvalidSort       = errors.New("invalid sort")
+	ErrInvalidSize       = errors.New("weight and dimensions must be positive")
+	ErrInvalidTax        = errors.New("tax_category must be one of book, ebook, other")
+	ErrInvalidRelease    = errors.New("release_date must be a date in YYYY-MM-DD format")
+	ErrInvalidCredits    = errors.New("authors must be distinct, at most 20, with role author, translator or illustrator")
+	ErrAuthorNotFound    = errors.New("author not found")
+	ErrPublisherNotFound = errors.New("publisher not found")
+	ErrDuplicateIsbn     = errors.New("a book with this isbn already exists")
+	ErrWorkNotFound      = errors.New("work not found")
+	ErrInvalidEdition    = errors.New("format must be one of hardcover, paperback, ebook, audiobook, language a two-letter code and pages positive")
 )
@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"story-book/internal/dto"
 	"story-book/internal/entities"
+	"story-book/internal/isbn"
+	"story-book/internal/pricing"
+	"story-book/internal/services/currencyservice"
 	"strconv"
 	"strings"
 	"time"
@@ -14,20 +17,30 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const releaseDateLayout = "2006-01-02"
+
 type BookService interface {
 	CreateBook(ctx context.Context, book *entities.Book) (*entities.Book, error)
-	ReadBooks(ctx context.Context, page, limit int) ([]entities.Book, error)
+	ValidateBook(book *entities.Book) error
+	ReadBooks(ctx context.Context, sort, authorId, publisherId string, page, limit int) ([]entities.Book, error)
 	ReedBookById(ctx context.Context, id string) (*entities.Book, error)
+	ReadBookByIsbn(ctx context.Context, isbn string) (*entities.Book, error)
+	ReadEditions(ctx context.Context, book *entities.Book) (*Editions, error)
 	UpdateBook(ctx context.Context, book *entities.Book) (*entities.Book, error)
 	DeleteBook(ctx context.Context, id string) error
 }
 
+type Pricer interface {
+	PriceBooks(ctx context.Context, books []entities.Book, currency, userId string) (map[string]pricing.Breakdown, error)
+}
+
 type BookHandler struct {
 	service BookService
+	pricer  Pricer
 }
 
-func NewBookHandler(service BookService) *BookHandler {
-	return &BookHandler{service: service}
+func NewBookHandler(service BookService, pricer Pricer) *BookHandler {
+	return &BookHandler{service: service, pricer: pricer}
 }
 
 // CreateBook
@@ -41,6 +54,8 @@ func NewBookHandler(service BookService) *BookHandler {
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /books [post]
 func (h *BookHandler) CreateBook(c echo.Context) error {
@@ -65,13 +80,34 @@ func (h *BookHandler) CreateBook(c echo.Context) error {
 	}
 
 	book := &entities.Book{
-		Title:     request.Title,
-		Author:    request.Author,
-		Year:      request.Year,
-		Cost:      request.Cost,
-		Publisher: request.Publisher,
-		ImageData: image,
-		ImageMime: mime,
+		Title:       request.Title,
+		Isbn:        request.Isbn,
+		Author:      request.Author,
+		Year:        request.Year,
+		Cost:        request.Cost,
+		Publisher:   request.Publisher,
+		WeightGrams: request.WeightGrams,
+		WidthMm:     request.WidthMm,
+		HeightMm:    request.HeightMm,
+		DepthMm:     request.DepthMm,
+		TaxCategory: request.TaxCategory,
+		Preorder:    request.Preorder,
+		PublisherId: request.PublisherId,
+		Authors:     fromCreditRequests(request.Authors),
+		WorkId:      validate(request.WorkId),
+		Format:      request.Format,
+		Language:    request.Language,
+		Pages:       request.Pages,
+		ImageData:   image,
+		ImageMime:   mime,
+	}
+
+	if request.ReleaseDate != nil {
+		releaseDate, err := time.Parse(releaseDateLayout, *request.ReleaseDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidRelease.Error()})
+		}
+		book.ReleaseDate = &releaseDate
 	}
 
 	if request.Discount != nil {
@@ -82,32 +118,31 @@ func (h *BookHandler) CreateBook(c echo.Context) error {
 		book.Description = request.Description
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	currency := c.QueryParam("currency")
+	userId, _ := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
 	book, err := h.service.CreateBook(ctx, book)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return bookError(c, err)
+	}
+
+	prices, err := h.pricer.PriceBooks(ctx, []entities.Book{*book}, currency, userId)
+	if err != nil {
+		return priceError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, dto.BookResponse{
-		Id:          book.Id,
-		Title:       book.Title,
-		Author:      book.Author,
-		Year:        book.Year,
-		Cost:        book.Cost,
-		Discount:    validate(book.Discount),
-		Publisher:   book.Publisher,
-		Description: validate(book.Description),
-		Amount:      book.Amount,
-		Image:       fromBytesToString(book.ImageData, book.ImageMime),
-	})
+	return c.JSON(http.StatusOK, toBookResponse(book, prices[book.Id]))
 }
 
 // ReadBook
 // @Summary Получить книгу по ID
+// @Description Вместе с книгой возвращаются другие издания того же произведения и соседние тома серии
 // @Tags books
 // @Param id path string true "ID книги"
+// @Param currency query string false "Валюта цен (по умолчанию валюта пользователя или базовая)"
 // @Produce json
 // @Success 200 {object} dto.BookResponse
 // @Failure 400 {object} dto.ErrorResponse
@@ -120,29 +155,82 @@ func (h *BookHandler) ReadBook(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
 	}
 
+	currency := c.QueryParam("currency")
+	userId, _ := c.Get("id").(string)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	book, err := h.service.ReedBookById(ctx, id)
 	if err != nil {
-		if errors.Is(err, ErrBookNotFound) {
-			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "book not found"})
-		}
-		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return bookError(c, err)
+	}
+
+	editions, err := h.service.ReadEditions(ctx, book)
+	if err != nil {
+		return bookError(c, err)
+	}
+
+	prices, err := h.pricer.PriceBooks(ctx, append([]entities.Book{*book}, editions.Siblings...), currency, userId)
+	if err != nil {
+		return priceError(c, err)
+	}
+
+	response := toBookResponse(book, prices[book.Id])
+	response.Editions = toEditionResponses(editions.Siblings, prices)
+	response.Series = toBookSeriesResponse(editions)
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReadBookByIsbn
+// @Summary Получить книгу по ISBN
+// @Tags books
+// @Param isbn path string true "ISBN-10 или ISBN-13, с дефисами или без"
+// @Param currency query string false "Валюта цен (по умолчанию валюта пользователя или базовая)"
+// @Produce json
+// @Success 200 {object} dto.BookResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/isbn/{isbn} [get]
+func (h *BookHandler) ReadBookByIsbn(c echo.Context) error {
+	currency := c.QueryParam("currency")
+	userId, _ := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	book, err := h.service.ReadBookByIsbn(ctx, c.Param("isbn"))
+	if err != nil {
+		return bookError(c, err)
+	}
+
+	prices, err := h.pricer.PriceBooks(ctx, []entities.Book{*book}, currency, userId)
+	if err != nil {
+		return priceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBookResponse(book, prices[book.Id]))
+}
+
+// ConvertIsbn
+// @Summary Проверить ISBN и получить обе его формы
+// @Tags books
+// @Param isbn path string true "ISBN-10 или ISBN-13, с дефисами или без"
+// @Produce json
+// @Success 200 {object} dto.IsbnResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /isbn/{isbn} [get]
+func (h *BookHandler) ConvertIsbn(c echo.Context) error {
+	isbn13, err := isbn.Normalize(c.Param("isbn"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, dto.BookResponse{
-		Id:          book.Id,
-		Title:       book.Title,
-		Author:      book.Author,
-		Year:        book.Year,
-		Cost:        book.Cost,
-		Discount:    validate(book.Discount),
-		Publisher:   book.Publisher,
-		Description: validate(book.Description),
-		Amount:      book.Amount,
-		Image:       fromBytesToString(book.ImageData, book.ImageMime),
-	})
+	isbn10, _ := isbn.To10(isbn13)
+
+	return c.JSON(http.StatusOK, dto.IsbnResponse{Isbn13: isbn13, Isbn10: isbn10})
 }
 
 // ReadBooks
@@ -151,6 +239,10 @@ func (h *BookHandler) ReadBook(c echo.Context) error {
 // @Produce json
 // @Param page query int false "Номер страницы (по умолчанию 1)"
 // @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Param sort query string false "Сортировка: newest, rating, reviews, title (по умолчанию newest)"
+// @Param author_id query string false "ID автора, переводчика или иллюстратора"
+// @Param publisher_id query string false "ID издательства"
+// @Param currency query string false "Валюта цен (по умолчанию валюта пользователя или базовая)"
 // @Success 200 {object} dto.BookResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
@@ -178,28 +270,28 @@ func (h *BookHandler) ReadBooks(c echo.Context) error {
 		}
 	}
 
+	currency := c.QueryParam("currency")
+	userId, _ := c.Get("id").(string)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	response, err := h.service.ReadBooks(ctx, page, limit)
+	response, err := h.service.ReadBooks(ctx, c.QueryParam("sort"), c.QueryParam("author_id"), c.QueryParam("publisher_id"), page, limit)
 	if err != nil {
+		if errors.Is(err, ErrInvalidSort) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 	}
 
-	books := make([]dto.BookResponse, len(response))
-	for _, book := range response {
-		books = append(books, dto.BookResponse{
-			Id:          book.Id,
-			Title:       book.Title,
-			Author:      book.Author,
-			Year:        book.Year,
-			Cost:        book.Cost,
-			Discount:    validate(book.Discount),
-			Publisher:   book.Publisher,
-			Description: validate(book.Description),
-			Amount:      book.Amount,
-			Image:       fromBytesToString(book.ImageData, book.ImageMime),
-		})
+	prices, err := h.pricer.PriceBooks(ctx, response, currency, userId)
+	if err != nil {
+		return priceError(c, err)
+	}
+
+	books := make([]dto.BookResponse, 0, len(response))
+	for i := range response {
+		books = append(books, toBookResponse(&response[i], prices[response[i].Id]))
 	}
 
 	return c.JSON(http.StatusOK, books)
@@ -218,6 +310,7 @@ func (h *BookHandler) ReadBooks(c echo.Context) error {
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 403 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /books/{id} [put]
 func (h *BookHandler) UpdateBook(c echo.Context) error {
@@ -236,7 +329,10 @@ func (h *BookHandler) UpdateBook(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 500000*time.Second)
+	currency := c.QueryParam("currency")
+	userId, _ := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
 	var image []byte
@@ -250,15 +346,36 @@ func (h *BookHandler) UpdateBook(c echo.Context) error {
 	}
 
 	book := &entities.Book{
-		Id:        id,
-		Title:     request.Title,
-		Author:    request.Author,
-		Year:      request.Year,
-		Cost:      request.Cost,
-		Publisher: request.Publisher,
-		Amount:    request.Amount,
-		ImageData: image,
-		ImageMime: mime,
+		Id:          id,
+		Title:       request.Title,
+		Isbn:        request.Isbn,
+		Author:      request.Author,
+		Year:        request.Year,
+		Cost:        request.Cost,
+		Publisher:   request.Publisher,
+		Amount:      request.Amount,
+		WeightGrams: request.WeightGrams,
+		WidthMm:     request.WidthMm,
+		HeightMm:    request.HeightMm,
+		DepthMm:     request.DepthMm,
+		TaxCategory: request.TaxCategory,
+		Preorder:    request.Preorder,
+		PublisherId: request.PublisherId,
+		Authors:     fromCreditRequests(request.Authors),
+		WorkId:      validate(request.WorkId),
+		Format:      request.Format,
+		Language:    request.Language,
+		Pages:       request.Pages,
+		ImageData:   image,
+		ImageMime:   mime,
+	}
+
+	if request.ReleaseDate != nil {
+		releaseDate, err := time.Parse(releaseDateLayout, *request.ReleaseDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidRelease.Error()})
+		}
+		book.ReleaseDate = &releaseDate
 	}
 
 	if request.Discount != nil {
@@ -272,24 +389,15 @@ func (h *BookHandler) UpdateBook(c echo.Context) error {
 	book, err := h.service.UpdateBook(ctx, book)
 
 	if err != nil {
-		if errors.Is(err, ErrBookNotFound) {
-			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "book not found"})
-		}
-		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return bookError(c, err)
+	}
+
+	prices, err := h.pricer.PriceBooks(ctx, []entities.Book{*book}, currency, userId)
+	if err != nil {
+		return priceError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, dto.BookResponse{
-		Id:          book.Id,
-		Title:       book.Title,
-		Author:      book.Author,
-		Year:        book.Year,
-		Cost:        book.Cost,
-		Discount:    validate(book.Discount),
-		Publisher:   book.Publisher,
-		Description: validate(book.Description),
-		Amount:      book.Amount,
-		Image:       fromBytesToString(book.ImageData, book.ImageMime),
-	})
+	return c.JSON(http.StatusOK, toBookResponse(book, prices[book.Id]))
 }
 
 // DeleteBook
@@ -314,7 +422,7 @@ func (h *BookHandler) DeleteBook(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
 	err := h.service.DeleteBook(ctx, id)
@@ -328,6 +436,160 @@ func (h *BookHandler) DeleteBook(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func toBookResponse(book *entities.Book, price pricing.Breakdown) dto.BookResponse {
+	isbn13 := validate(book.Isbn)
+	isbn10, _ := isbn.To10(isbn13)
+
+	return dto.BookResponse{
+		Id:             book.Id,
+		Title:          book.Title,
+		Isbn13:         isbn13,
+		Isbn10:         isbn10,
+		Author:         book.Author,
+		Year:           book.Year,
+		Cost:           book.Cost,
+		Discount:       validate(book.Discount),
+		Currency:       price.Currency,
+		Price:          price.Price,
+		DiscountAmount: price.DiscountAmount,
+		FinalPrice:     price.FinalPrice,
+		Publisher:      book.Publisher,
+		PublisherId:    validate(book.PublisherId),
+		Authors:        toCreditResponses(book.Authors),
+		Description:    validate(book.Description),
+		Amount:         book.Amount,
+		WeightGrams:    validate(book.WeightGrams),
+		WidthMm:        validate(book.WidthMm),
+		HeightMm:       validate(book.HeightMm),
+		DepthMm:        validate(book.DepthMm),
+		TaxCategory:    book.TaxCategory,
+		Tax:            toTaxResponse(price.Tax),
+		WorkId:         book.WorkId,
+		Format:         validate(book.Format),
+		Language:       validate(book.Language),
+		Pages:          validate(book.Pages),
+		ReleaseDate:    formatDate(book.ReleaseDate),
+		Preorder:       book.Preorder != nil && *book.Preorder,
+		Rating:         book.Rating,
+		ReviewCount:    book.ReviewCount,
+		Image:          fromBytesToString(book.ImageData, book.ImageMime),
+	}
+}
+
+func bookError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "book not found"})
+	case errors.Is(err, ErrAuthorNotFound), errors.Is(err, ErrPublisherNotFound), errors.Is(err, ErrWorkNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrDuplicateIsbn):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidSize), errors.Is(err, ErrInvalidTax), errors.Is(err, ErrInvalidCredits),
+		errors.Is(err, isbn.ErrInvalid), errors.Is(err, ErrInvalidEdition):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func toEditionResponses(books []entities.Book, prices map[string]pricing.Breakdown) []dto.EditionResponse {
+	response := make([]dto.EditionResponse, 0, len(books))
+	for _, book := range books {
+		response = append(response, dto.EditionResponse{
+			Id:         book.Id,
+			Title:      book.Title,
+			Format:     validate(book.Format),
+			Language:   validate(book.Language),
+			Pages:      validate(book.Pages),
+			Isbn13:     validate(book.Isbn),
+			Publisher:  book.Publisher,
+			Year:       book.Year,
+			Amount:     book.Amount,
+			Cost:       book.Cost,
+			Currency:   prices[book.Id].Currency,
+			FinalPrice: prices[book.Id].FinalPrice,
+		})
+	}
+	return response
+}
+
+func toBookSeriesResponse(editions *Editions) *dto.BookSeriesResponse {
+	work := editions.Work
+	if work.Series == nil || work.Volume == nil {
+		return nil
+	}
+
+	return &dto.BookSeriesResponse{
+		Id:       work.Series.Id,
+		Name:     work.Series.Name,
+		Volume:   *work.Volume,
+		Previous: toAdjacentVolumeResponse(editions.Previous),
+		Next:     toAdjacentVolumeResponse(editions.Next),
+	}
+}
+
+func toAdjacentVolumeResponse(volume *Volume) *dto.AdjacentVolumeResponse {
+	if volume == nil {
+		return nil
+	}
+
+	return &dto.AdjacentVolumeResponse{
+		WorkId: volume.Work.Id,
+		Title:  volume.Work.Title,
+		Volume: validate(volume.Work.Volume),
+		BookId: volume.BookId,
+	}
+}
+
+func fromCreditRequests(requests []dto.BookAuthorRequest) []entities.BookAuthor {
+	credits := make([]entities.BookAuthor, 0, len(requests))
+	for _, request := range requests {
+		credits = append(credits, entities.BookAuthor{AuthorId: request.AuthorId, Role: request.Role})
+	}
+	return credits
+}
+
+func toCreditResponses(credits []entities.BookAuthor) []dto.BookAuthorResponse {
+	response := make([]dto.BookAuthorResponse, 0, len(credits))
+	for _, credit := range credits {
+		name := ""
+		if credit.Author != nil {
+			name = credit.Author.Name
+		}
+		response = append(response, dto.BookAuthorResponse{Id: credit.AuthorId, Name: name, Role: credit.Role})
+	}
+	return response
+}
+
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(releaseDateLayout)
+}
+
+func toTaxResponse(tax *pricing.Tax) *dto.TaxResponse {
+	if tax == nil {
+		return nil
+	}
+
+	return &dto.TaxResponse{
+		Region:    tax.Region,
+		Category:  tax.Category,
+		Rate:      float64(tax.Rate) / 100,
+		Inclusive: tax.Inclusive,
+		Net:       tax.Net,
+		Amount:    tax.Amount,
+		Gross:     tax.Gross,
+	}
+}
+
+func priceError(c echo.Context, err error) error {
+	if errors.Is(err, currencyservice.ErrUnknownCurrency) || errors.Is(err, currencyservice.ErrInvalidCurrency) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
 func validate[T any](t *T) T {
 	if t != nil {
 		return *t
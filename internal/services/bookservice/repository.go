@@ -4,10 +4,17 @@ import (
 	"context"
 	"errors"
 	"story-book/internal/entities"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const uniqueViolationCode = "23505"
+
 type bookRepository struct {
 	db *gorm.DB
 }
@@ -16,14 +23,44 @@ func NewBookRepository(db *gorm.DB) BookRepository {
 	return &bookRepository{db: db}
 }
 
-func (r *bookRepository) Create(ctx context.Context, book *entities.Book) error {
-	return r.db.WithContext(ctx).Create(book).Error
+func (r *bookRepository) Create(ctx context.Context, book *entities.Book, audit Audit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveWork(tx, book); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Create(book).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrDuplicateIsbn
+			}
+			return err
+		}
+
+		if err := saveCredits(tx, book); err != nil {
+			return err
+		}
+
+		if err := recordPrice(tx, book.Id, book.Cost, book.Discount); err != nil {
+			return err
+		}
+
+		return writeAudit(tx, audit, book)
+	})
 }
 
-func (r *bookRepository) ReadAll(ctx context.Context, offset, limit int) ([]entities.Book, error) {
+func (r *bookRepository) ReadAll(ctx context.Context, order, authorId, publisherId string, offset, limit int) ([]entities.Book, error) {
+	query := withCredits(r.db.WithContext(ctx))
+
+	if authorId != "" {
+		query = query.Where("id IN (SELECT book_id FROM book_authors WHERE author_id = ?)", authorId)
+	}
+	if publisherId != "" {
+		query = query.Where("publisher_id = ?", publisherId)
+	}
+
 	var books []entities.Book
-	if err := r.db.
-		WithContext(ctx).
+	if err := query.
+		Order(order).
 		Limit(limit).
 		Offset(offset).
 		Find(&books).Error; err != nil {
@@ -37,8 +74,7 @@ func (r *bookRepository) ReadAll(ctx context.Context, offset, limit int) ([]enti
 
 func (r *bookRepository) ReadById(ctx context.Context, id string) (*entities.Book, error) {
 	var book entities.Book
-	if err := r.db.
-		WithContext(ctx).
+	if err := withCredits(r.db.WithContext(ctx)).
 		Where("id = ?", id).
 		First(&book).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -49,40 +85,409 @@ func (r *bookRepository) ReadById(ctx context.Context, id string) (*entities.Boo
 	return &book, nil
 }
 
-func (r *bookRepository) Update(ctx context.Context, book *entities.Book) (*entities.Book, error) {
-	var updatedBook entities.Book
-	res := r.db.
+func (r *bookRepository) ReadByIsbn(ctx context.Context, isbn string) (*entities.Book, error) {
+	var book entities.Book
+	if err := withCredits(r.db.WithContext(ctx)).
+		Where("isbn = ?", isbn).
+		First(&book).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *bookRepository) Update(ctx context.Context, book *entities.Book, audit Audit) (*entities.Book, error) {
+	var updatedBook, after entities.Book
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var before entities.Book
+		if err := tx.
+			Select("cost", "discount", "work_id").
+			Where("id = ?", book.Id).
+			First(&before).Error; err != nil {
+			return err
+		}
+
+		if book.WorkId != "" {
+			if err := checkWork(tx, book.WorkId); err != nil {
+				return err
+			}
+		}
+
+		res := tx.
+			Model(&entities.Book{}).
+			Omit(clause.Associations).
+			Where("id = ?", book.Id).
+			Updates(book).
+			Scan(&updatedBook)
+
+		if res.Error != nil {
+			if isUniqueViolation(res.Error) {
+				return ErrDuplicateIsbn
+			}
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrBookNotFound
+		}
+
+		if err := saveCredits(tx, book); err != nil {
+			return err
+		}
+
+		if book.WorkId != "" && book.WorkId != before.WorkId {
+			if err := dropEmptyWork(tx, before.WorkId); err != nil {
+				return err
+			}
+		}
+
+		if before.Cost != updatedBook.Cost || !equalDiscount(before.Discount, updatedBook.Discount) {
+			if err := recordPrice(tx, book.Id, updatedBook.Cost, updatedBook.Discount); err != nil {
+				return err
+			}
+		}
+
+		if err := withCredits(tx).
+			Where("id = ?", book.Id).
+			First(&after).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(tx, audit, &after)
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+func (r *bookRepository) Delete(ctx context.Context, id string, audit Audit) error {
+	// Genres share the book's deleted_at so that a restore from the trash
+	// brings back exactly the rows removed together with the book.
+	deletedAt := time.Now()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.
+			Model(&entities.Book{}).
+			Where("id = ?", id).
+			Update("deleted_at", deletedAt)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrBookNotFound
+		}
+
+		if err := tx.
+			Model(&entities.GenreOfBook{}).
+			Where("book_id = ?", id).
+			Update("deleted_at", deletedAt).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(tx, audit, nil)
+	})
+}
+
+// ReadWork reads a work with its series.
+func (r *bookRepository) ReadWork(ctx context.Context, id string) (*entities.Work, error) {
+	var work entities.Work
+	if err := r.db.
 		WithContext(ctx).
+		Preload("Series").
+		Where("id = ?", id).
+		First(&work).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkNotFound
+		}
+		return nil, err
+	}
+	return &work, nil
+}
+
+// ReadEditions lists the books of a work, oldest first, without their images.
+func (r *bookRepository) ReadEditions(ctx context.Context, workId string) ([]entities.Book, error) {
+	var books []entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Omit("image_data").
+		Where("work_id = ?", workId).
+		Order("created_at, id").
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// ReadVolume reads the work before or after a volume of a series, or nil at
+// either end. Volumes may have gaps, so it is the closest number that counts.
+func (r *bookRepository) ReadVolume(ctx context.Context, seriesId string, volume int, next bool) (*entities.Work, error) {
+	query := r.db.WithContext(ctx).Where("series_id = ?", seriesId)
+	if next {
+		query = query.Where("volume > ?", volume).Order("volume")
+	} else {
+		query = query.Where("volume < ?", volume).Order("volume DESC")
+	}
+
+	var work entities.Work
+	if err := query.First(&work).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &work, nil
+}
+
+// withCredits loads the authors credited on the books, in credit order.
+func withCredits(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Authors", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		Preload("Authors.Author", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		})
+}
+
+// saveCredits links a book to its authors and publisher and refreshes the
+// display names kept in books.author and books.publisher. A book saved with
+// the free-text names only is matched to authors and publishers by name or
+// alias, and an entity is created for a name not seen before. Credits the
+// request does not mention are left as they are.
+func saveCredits(tx *gorm.DB, book *entities.Book) error {
+	if len(book.Authors) == 0 && strings.TrimSpace(book.Author) != "" {
+		authorId, err := findAuthor(tx, normalizeName(book.Author))
+		if err != nil {
+			return err
+		}
+		book.Authors = []entities.BookAuthor{{AuthorId: authorId, Role: RoleAuthor}}
+	}
+
+	if len(book.Authors) > 0 {
+		if err := saveAuthors(tx, book); err != nil {
+			return err
+		}
+	}
+
+	if book.PublisherId == nil && strings.TrimSpace(book.Publisher) != "" {
+		publisherId, err := findPublisher(tx, normalizeName(book.Publisher))
+		if err != nil {
+			return err
+		}
+		book.PublisherId = &publisherId
+	}
+
+	if book.PublisherId == nil {
+		return nil
+	}
+
+	var publisher entities.Publisher
+	if err := tx.
+		Select("id", "name").
+		Where("id = ?", *book.PublisherId).
+		First(&publisher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPublisherNotFound
+		}
+		return err
+	}
+	book.Publisher = publisher.Name
+
+	return tx.
 		Model(&entities.Book{}).
 		Where("id = ?", book.Id).
-		Updates(book).
-		Scan(&updatedBook)
+		Updates(map[string]any{"publisher": book.Publisher, "publisher_id": book.PublisherId}).Error
+}
 
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return nil, ErrBookNotFound
+// saveAuthors replaces the book's author credits. books.author becomes the
+// names credited as authors, or of everyone credited if nobody is.
+func saveAuthors(tx *gorm.DB, book *entities.Book) error {
+	ids := make([]string, 0, len(book.Authors))
+	seen := make(map[string]bool, len(book.Authors))
+	for _, credit := range book.Authors {
+		if !seen[credit.AuthorId] {
+			seen[credit.AuthorId] = true
+			ids = append(ids, credit.AuthorId)
 		}
+	}
 
-		return nil, res.Error
+	var authors []entities.Author
+	if err := tx.
+		Select("id", "name").
+		Where("id IN ?", ids).
+		Find(&authors).Error; err != nil {
+		return err
+	}
+	if len(authors) != len(ids) {
+		return ErrAuthorNotFound
 	}
 
-	if res.RowsAffected == 0 {
-		return nil, ErrBookNotFound
+	names := make(map[string]string, len(authors))
+	for _, author := range authors {
+		names[author.Id] = author.Name
 	}
 
-	return &updatedBook, nil
-}
+	if err := tx.
+		Where("book_id = ?", book.Id).
+		Delete(&entities.BookAuthor{}).Error; err != nil {
+		return err
+	}
 
-func (r *bookRepository) Delete(ctx context.Context, id string) error {
-	res := r.db.WithContext(ctx).Delete(&entities.Book{Id: id})
+	var credited, everyone []string
+	for i := range book.Authors {
+		book.Authors[i].BookId = book.Id
+		book.Authors[i].Position = i
 
-	if res.Error != nil {
-		return res.Error
+		name := names[book.Authors[i].AuthorId]
+		everyone = append(everyone, name)
+		if book.Authors[i].Role == RoleAuthor {
+			credited = append(credited, name)
+		}
+	}
+	if len(credited) == 0 {
+		credited = everyone
 	}
 
-	if res.RowsAffected == 0 {
-		return ErrBookNotFound
+	if err := tx.Omit("Author").Create(&book.Authors).Error; err != nil {
+		return err
 	}
 
+	book.Author = strings.Join(credited, ", ")
+
+	return tx.
+		Model(&entities.Book{}).
+		Where("id = ?", book.Id).
+		Update("author", book.Author).Error
+}
+
+// saveWork makes a new book an edition of the work it names, or of a new
+// work with the book's title.
+func saveWork(tx *gorm.DB, book *entities.Book) error {
+	if book.WorkId != "" {
+		return checkWork(tx, book.WorkId)
+	}
+
+	now := time.Now()
+	work := entities.Work{Id: uuid.NewString(), Title: book.Title, CreatedAt: now, UpdatedAt: now}
+	if err := tx.Omit(clause.Associations).Create(&work).Error; err != nil {
+		return err
+	}
+
+	book.WorkId = work.Id
 	return nil
 }
+
+func checkWork(tx *gorm.DB, workId string) error {
+	var works int64
+	if err := tx.
+		Model(&entities.Work{}).
+		Where("id = ?", workId).
+		Count(&works).Error; err != nil {
+		return err
+	}
+	if works == 0 {
+		return ErrWorkNotFound
+	}
+	return nil
+}
+
+// dropEmptyWork deletes the work a book has moved away from when nothing is
+// left of it: no editions, trashed ones included, and no place in a series.
+// Works created implicitly for single books do not pile up that way.
+func dropEmptyWork(tx *gorm.DB, workId string) error {
+	return tx.Exec(`
+		DELETE FROM works
+		WHERE id = ?
+		  AND series_id IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM books WHERE work_id = works.id)`, workId).Error
+}
+
+func findAuthor(tx *gorm.DB, name string) (string, error) {
+	var author entities.Author
+	err := tx.
+		Select("id").
+		Where("LOWER(name) = LOWER(?) OR id IN (SELECT author_id FROM author_aliases WHERE LOWER(alias) = LOWER(?))", name, name).
+		First(&author).Error
+	if err == nil {
+		return author.Id, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	now := time.Now()
+	author = entities.Author{Id: uuid.NewString(), Name: name, CreatedAt: now, UpdatedAt: now}
+	if err = tx.Omit(clause.Associations).Create(&author).Error; err != nil {
+		return "", err
+	}
+	return author.Id, nil
+}
+
+func findPublisher(tx *gorm.DB, name string) (string, error) {
+	var publisher entities.Publisher
+	err := tx.
+		Select("id").
+		Where("LOWER(name) = LOWER(?) OR id IN (SELECT publisher_id FROM publisher_aliases WHERE LOWER(alias) = LOWER(?))", name, name).
+		First(&publisher).Error
+	if err == nil {
+		return publisher.Id, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	now := time.Now()
+	publisher = entities.Publisher{Id: uuid.NewString(), Name: name, CreatedAt: now, UpdatedAt: now}
+	if err = tx.Omit(clause.Associations).Create(&publisher).Error; err != nil {
+		return "", err
+	}
+	return publisher.Id, nil
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// writeAudit appends the audit entry of the change made in tx.
+func writeAudit(tx *gorm.DB, audit Audit, after *entities.Book) error {
+	record, err := audit(after)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(record).Error
+}
+
+// recordPrice appends the current cost and discount of a book to its price history.
+func recordPrice(tx *gorm.DB, bookId string, cost float64, discount *int) error {
+	return tx.Create(&entities.BookPrice{
+		Id:          uuid.NewString(),
+		BookId:      bookId,
+		Cost:        cost,
+		Discount:    discount,
+		EffectiveAt: time.Now(),
+	}).Error
+}
+
+func equalDiscount(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -2,32 +2,119 @@ package bookservice
 
 import (
 	"context"
-	"fmt"
+	"log"
 	"story-book/internal/entities"
+	"story-book/internal/isbn"
+	"story-book/internal/pricing"
+	"story-book/internal/services/auditservice"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
+// Roles an author can be credited with on a book.
+const (
+	RoleAuthor      = "author"
+	RoleTranslator  = "translator"
+	RoleIllustrator = "illustrator"
+)
+
+const maxCredits = 20
+
+// Formats an edition can be issued in.
+var formats = map[string]bool{
+	"hardcover": true,
+	"paperback": true,
+	"ebook":     true,
+	"audiobook": true,
+}
+
+var roles = map[string]bool{
+	RoleAuthor:      true,
+	RoleTranslator:  true,
+	RoleIllustrator: true,
+}
+
 type BookRepository interface {
-	Create(ctx context.Context, book *entities.Book) error
-	ReadAll(ctx context.Context, offset, limit int) ([]entities.Book, error)
+	Create(ctx context.Context, book *entities.Book, audit Audit) error
+	ReadAll(ctx context.Context, order, authorId, publisherId string, offset, limit int) ([]entities.Book, error)
 	ReadById(ctx context.Context, id string) (*entities.Book, error)
-	Update(ctx context.Context, book *entities.Book) (*entities.Book, error)
-	Delete(ctx context.Context, id string) error
+	ReadByIsbn(ctx context.Context, isbn string) (*entities.Book, error)
+	Update(ctx context.Context, book *entities.Book, audit Audit) (*entities.Book, error)
+	Delete(ctx context.Context, id string, audit Audit) error
+	ReadWork(ctx context.Context, id string) (*entities.Work, error)
+	ReadEditions(ctx context.Context, workId string) ([]entities.Book, error)
+	ReadVolume(ctx context.Context, seriesId string, volume int, next bool) (*entities.Work, error)
+}
+
+// Editions places a book among the other editions of its work and, for a
+// work in a series, between the neighbouring volumes.
+type Editions struct {
+	Work     *entities.Work
+	Siblings []entities.Book
+	Previous *Volume
+	Next     *Volume
+}
+
+// Volume is a neighbouring work in a series with the edition to link to.
+type Volume struct {
+	Work   entities.Work
+	BookId string
+}
+
+// sortOrders maps the sort keys accepted by GET /books to ORDER BY clauses.
+var sortOrders = map[string]string{
+	"":        "created_at DESC",
+	"rating":  "rating DESC, review_count DESC",
+	"reviews": "review_count DESC, rating DESC",
+	"newest":  "created_at DESC",
+	"title":   "title",
+}
+
+type Auditor interface {
+	NewRecord(ctx context.Context, action, entityType, entityId string, before, after any) (*entities.AuditRecord, error)
+}
+
+// Audit builds the audit entry of a change from the book as the change left
+// it, nil once deleted. The repository writes the entry in the transaction
+// that makes the change.
+type Audit func(after *entities.Book) (*entities.AuditRecord, error)
+
+// Watcher is told about updated books so that stock and price alerts can
+// fire without waiting for the next periodic check.
+type Watcher interface {
+	CheckBooks(ctx context.Context, books []entities.Book) (int, error)
+}
+
+// Preorders is told when a book's release is postponed so that customers
+// waiting for it hear about the delay.
+type Preorders interface {
+	NotifyDelay(ctx context.Context, book *entities.Book) (int, error)
 }
 
 type bookService struct {
-	repo BookRepository
+	repo      BookRepository
+	audit     Auditor
+	watcher   Watcher
+	preorders Preorders
 }
 
-func NewBookService(repo BookRepository) BookService {
-	return &bookService{repo: repo}
+func NewBookService(repo BookRepository, audit Auditor, watcher Watcher, preorders Preorders) BookService {
+	return &bookService{repo: repo, audit: audit, watcher: watcher, preorders: preorders}
 }
 
 func (s *bookService) CreateBook(ctx context.Context, book *entities.Book) (*entities.Book, error) {
+	if err := s.ValidateBook(book); err != nil {
+		return nil, err
+	}
+
+	if book.TaxCategory == "" {
+		book.TaxCategory = pricing.TaxCategoryBook
+	}
+
 	book.Id = uuid.NewString()
 
-	err := s.repo.Create(ctx, book)
+	err := s.repo.Create(ctx, book, s.record(ctx, auditservice.ActionCreate, book.Id, nil))
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +122,34 @@ func (s *bookService) CreateBook(ctx context.Context, book *entities.Book) (*ent
 	return book, nil
 }
 
+// ValidateBook runs the checks of CreateBook and UpdateBook that need no
+// database, normalising the ISBN and language on the way. An empty tax
+// category passes: a new book gets the default one and an update keeps the
+// current one.
+func (s *bookService) ValidateBook(book *entities.Book) error {
+	if err := validateSize(book); err != nil {
+		return err
+	}
+
+	if err := normalizeIsbn(book); err != nil {
+		return err
+	}
+
+	if err := validateEdition(book); err != nil {
+		return err
+	}
+
+	if err := validateCredits(book); err != nil {
+		return err
+	}
+
+	if book.TaxCategory != "" && !pricing.IsTaxCategory(book.TaxCategory) {
+		return ErrInvalidTax
+	}
+
+	return nil
+}
+
 func (s *bookService) ReedBookById(ctx context.Context, id string) (*entities.Book, error) {
 	book, err := s.repo.ReadById(ctx, id)
 	if err != nil {
@@ -44,9 +159,91 @@ func (s *bookService) ReedBookById(ctx context.Context, id string) (*entities.Bo
 	return book, nil
 }
 
-func (s *bookService) ReadBooks(ctx context.Context, page, limit int) ([]entities.Book, error) {
-	fmt.Println(uuid.NewString())
-	books, err := s.repo.ReadAll(ctx, (page-1)*limit, limit)
+// ReadEditions finds the sibling editions of a book and, when its work is
+// part of a series, the previous and next volumes. The edition linked for a
+// volume is the one closest to the book: same language, then same format.
+func (s *bookService) ReadEditions(ctx context.Context, book *entities.Book) (*Editions, error) {
+	work, err := s.repo.ReadWork(ctx, book.WorkId)
+	if err != nil {
+		return nil, err
+	}
+
+	editions, err := s.repo.ReadEditions(ctx, work.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Editions{Work: work}
+	for _, edition := range editions {
+		if edition.Id != book.Id {
+			result.Siblings = append(result.Siblings, edition)
+		}
+	}
+
+	if work.SeriesId == nil || work.Volume == nil {
+		return result, nil
+	}
+
+	if result.Previous, err = s.readVolume(ctx, book, *work.SeriesId, *work.Volume, false); err != nil {
+		return nil, err
+	}
+	if result.Next, err = s.readVolume(ctx, book, *work.SeriesId, *work.Volume, true); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *bookService) readVolume(ctx context.Context, book *entities.Book, seriesId string, volume int, next bool) (*Volume, error) {
+	work, err := s.repo.ReadVolume(ctx, seriesId, volume, next)
+	if err != nil || work == nil {
+		return nil, err
+	}
+
+	editions, err := s.repo.ReadEditions(ctx, work.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Volume{Work: *work}
+	best := -1
+	for _, edition := range editions {
+		score := 0
+		if sameValue(edition.Language, book.Language) {
+			score += 2
+		}
+		if sameValue(edition.Format, book.Format) {
+			score++
+		}
+		if score > best {
+			best = score
+			result.BookId = edition.Id
+		}
+	}
+
+	return result, nil
+}
+
+// ReadBookByIsbn finds a book by its ISBN-10 or ISBN-13, as scanned from a
+// barcode or typed with hyphens.
+func (s *bookService) ReadBookByIsbn(ctx context.Context, number string) (*entities.Book, error) {
+	isbn13, err := isbn.Normalize(number)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadByIsbn(ctx, isbn13)
+}
+
+// ReadBooks lists books, optionally only those credited to an author or
+// issued by a publisher.
+func (s *bookService) ReadBooks(ctx context.Context, sort, authorId, publisherId string, page, limit int) ([]entities.Book, error) {
+	order, ok := sortOrders[sort]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	books, err := s.repo.ReadAll(ctx, order+", id", authorId, publisherId, (page-1)*limit, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -55,21 +252,122 @@ func (s *bookService) ReadBooks(ctx context.Context, page, limit int) ([]entitie
 }
 
 func (s *bookService) UpdateBook(ctx context.Context, book *entities.Book) (*entities.Book, error) {
-	var err error
+	if err := s.ValidateBook(book); err != nil {
+		return nil, err
+	}
+
+	before, err := s.repo.ReadById(ctx, book.Id)
+	if err != nil {
+		return nil, err
+	}
 
-	updatedBook, err := s.repo.Update(ctx, book)
+	updatedBook, err := s.repo.Update(ctx, book, s.record(ctx, auditservice.ActionUpdate, book.Id, before))
 	if err != nil {
 		return nil, err
 	}
 
+	if _, err = s.watcher.CheckBooks(ctx, []entities.Book{*updatedBook}); err != nil {
+		log.Printf("failed to check alerts of book %s: %v", book.Id, err)
+	}
+
+	if before.ReleaseDate != nil && updatedBook.ReleaseDate != nil && updatedBook.ReleaseDate.After(*before.ReleaseDate) {
+		if _, err = s.preorders.NotifyDelay(ctx, updatedBook); err != nil {
+			log.Printf("failed to notify pre-orders of book %s: %v", book.Id, err)
+		}
+	}
+
 	return updatedBook, nil
 }
 
 func (s *bookService) DeleteBook(ctx context.Context, id string) error {
-	err := s.repo.Delete(ctx, id)
+	before, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id, s.record(ctx, auditservice.ActionDelete, id, before))
+}
+
+func (s *bookService) record(ctx context.Context, action, id string, before *entities.Book) Audit {
+	return func(after *entities.Book) (*entities.AuditRecord, error) {
+		return s.audit.NewRecord(ctx, action, auditservice.EntityBook, id, before, after)
+	}
+}
+
+// validateSize checks the shipping weight and dimensions. They are optional,
+// but when given must be positive.
+func validateSize(book *entities.Book) error {
+	for _, value := range []*int{book.WeightGrams, book.WidthMm, book.HeightMm, book.DepthMm} {
+		if value != nil && *value <= 0 {
+			return ErrInvalidSize
+		}
+	}
+	return nil
+}
+
+// normalizeIsbn stores the ISBN as ISBN-13 so that both forms of a number
+// hit the same unique index.
+func normalizeIsbn(book *entities.Book) error {
+	if book.Isbn == nil {
+		return nil
+	}
+
+	isbn13, err := isbn.Normalize(*book.Isbn)
 	if err != nil {
 		return err
 	}
+	book.Isbn = &isbn13
+
+	return nil
+}
+
+// validateEdition checks the edition attributes and lower-cases the language.
+func validateEdition(book *entities.Book) error {
+	if book.Format != nil && !formats[*book.Format] {
+		return ErrInvalidEdition
+	}
+
+	if book.Language != nil {
+		language := strings.ToLower(strings.TrimSpace(*book.Language))
+		if len(language) != 2 || strings.Trim(language, "abcdefghijklmnopqrstuvwxyz") != "" {
+			return ErrInvalidEdition
+		}
+		book.Language = &language
+	}
+
+	if book.Pages != nil && *book.Pages <= 0 {
+		return ErrInvalidEdition
+	}
+
+	return nil
+}
+
+func sameValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// validateCredits defaults the role of each credit to author and rejects
+// unknown roles and repeated credits.
+func validateCredits(book *entities.Book) error {
+	if len(book.Authors) > maxCredits {
+		return ErrInvalidCredits
+	}
+
+	seen := make(map[string]bool, len(book.Authors))
+	for i := range book.Authors {
+		if book.Authors[i].Role == "" {
+			book.Authors[i].Role = RoleAuthor
+		}
+
+		key := book.Authors[i].AuthorId + "/" + book.Authors[i].Role
+		if book.Authors[i].AuthorId == "" || !roles[book.Authors[i].Role] || seen[key] {
+			return ErrInvalidCredits
+		}
+		seen[key] = true
+	}
 
 	return nil
 }
@@ -0,0 +1,16 @@
+package collectionservice
+
+import "errors"
+
+var (
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrBookNotFound       = errors.New("book not found")
+	ErrSlugTaken          = errors.New("collection with the same slug already exists")
+	ErrInvalidSlug        = errors.New("slug must contain only lowercase letters, digits and hyphens")
+	ErrInvalidTitle       = errors.New("title must be between 1 and 200 characters")
+	ErrInvalidWindow      = errors.New("published_until must be after published_from")
+	ErrDuplicateBook      = errors.New("a book can appear in a collection only once")
+	ErrInvalidDays        = errors.New("invalid days")
+	ErrInvalidPeriod      = errors.New("days must be 7, 30 or 365")
+	ErrInvalidLimit       = errors.New("invalid limit")
+)
@@ -0,0 +1,457 @@
+package collectionservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// cacheMaxAge is how long clients and proxies may reuse a public list.
+const cacheMaxAge = 60
+
+const (
+	maxDays  = 365
+	maxLimit = 100
+)
+
+type CollectionService interface {
+	CreateCollection(ctx context.Context, collection *entities.Collection, bookIds []string) (*entities.Collection, error)
+	ReadCollections(ctx context.Context, published bool) ([]entities.Collection, error)
+	ReadCollection(ctx context.Context, slug string, published bool) (*entities.Collection, []entities.Book, error)
+	UpdateCollection(ctx context.Context, slug string, collection *entities.Collection, bookIds []string) (*entities.Collection, error)
+	DeleteCollection(ctx context.Context, slug string) error
+	ReadNewArrivals(ctx context.Context, days, limit int) ([]entities.Book, error)
+	ReadBestsellers(ctx context.Context, days, limit int) ([]Bestseller, error)
+}
+
+type CollectionHandler struct {
+	service CollectionService
+}
+
+func NewCollectionHandler(service CollectionService) *CollectionHandler {
+	return &CollectionHandler{service: service}
+}
+
+// CreateCollection
+// @Summary Создать подборку
+// @Tags collections
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CollectionRequest true "Данные подборки"
+// @Success 201 {object} dto.CollectionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /collections [post]
+func (h *CollectionHandler) CreateCollection(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	var request dto.CollectionRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	collection, err := toCollection(&request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid cover"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	collection, err = h.service.CreateCollection(ctx, collection, request.BookIds)
+	if err != nil {
+		return collectionError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toCollectionResponse(collection, nil))
+}
+
+// ReadCollections
+// @Summary Получить подборки
+// @Description Клиентам и гостям видны только опубликованные подборки
+// @Tags collections
+// @Produce json
+// @Success 200 {array} dto.CollectionResponse
+// @Success 304
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /collections [get]
+func (h *CollectionHandler) ReadCollections(c echo.Context) error {
+	staff := isStaff(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collections, err := h.service.ReadCollections(ctx, !staff)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.CollectionResponse, 0, len(collections))
+	for i := range collections {
+		response = append(response, toCollectionResponse(&collections[i], nil))
+	}
+
+	return cached(c, !staff, response)
+}
+
+// ReadCollection
+// @Summary Получить подборку
+// @Tags collections
+// @Param slug path string true "Адрес подборки"
+// @Produce json
+// @Success 200 {object} dto.CollectionResponse
+// @Success 304
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /collections/{slug} [get]
+func (h *CollectionHandler) ReadCollection(c echo.Context) error {
+	staff := isStaff(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection, books, err := h.service.ReadCollection(ctx, c.Param("slug"), !staff)
+	if err != nil {
+		if errors.Is(err, ErrCollectionNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return cached(c, !staff, toCollectionResponse(collection, books))
+}
+
+// UpdateCollection
+// @Summary Изменить подборку
+// @Tags collections
+// @Security BearerAuth
+// @Param slug path string true "Адрес подборки"
+// @Accept json
+// @Produce json
+// @Param request body dto.CollectionRequest true "Данные подборки"
+// @Success 200 {object} dto.CollectionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /collections/{slug} [put]
+func (h *CollectionHandler) UpdateCollection(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	var request dto.CollectionRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	collection, err := toCollection(&request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid cover"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	collection, err = h.service.UpdateCollection(ctx, c.Param("slug"), collection, request.BookIds)
+	if err != nil {
+		return collectionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toCollectionResponse(collection, nil))
+}
+
+// DeleteCollection
+// @Summary Удалить подборку
+// @Tags collections
+// @Security BearerAuth
+// @Param slug path string true "Адрес подборки"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /collections/{slug} [delete]
+func (h *CollectionHandler) DeleteCollection(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteCollection(ctx, c.Param("slug")); err != nil {
+		if errors.Is(err, ErrCollectionNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReadNewArrivals
+// @Summary Получить новинки
+// @Tags collections
+// @Param days query int false "За сколько последних дней (по умолчанию 30, не больше 365)"
+// @Param limit query int false "Количество книг (по умолчанию 20, не больше 100)"
+// @Produce json
+// @Success 200 {array} dto.ListedBookResponse
+// @Success 304
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /lists/new-arrivals [get]
+func (h *CollectionHandler) ReadNewArrivals(c echo.Context) error {
+	days := 30
+	limit := 20
+
+	var err error
+
+	if daysStr := c.QueryParam("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 1 || days > maxDays {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidDays.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	books, err := h.service.ReadNewArrivals(ctx, days, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return cached(c, true, toListedBookResponses(books))
+}
+
+// ReadBestsellers
+// @Summary Получить бестселлеры
+// @Description Книги, больше всего проданные в оплаченных заказах за период
+// @Tags collections
+// @Param days query int false "Период в днях: 7, 30 или 365 (по умолчанию 30)"
+// @Param limit query int false "Количество книг (по умолчанию 20, не больше 100)"
+// @Produce json
+// @Success 200 {array} dto.BestsellerResponse
+// @Success 304
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /lists/bestsellers [get]
+func (h *CollectionHandler) ReadBestsellers(c echo.Context) error {
+	days := 30
+	limit := 20
+
+	var err error
+
+	if daysStr := c.QueryParam("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidPeriod.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bestsellers, err := h.service.ReadBestsellers(ctx, days, limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPeriod) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.BestsellerResponse, 0, len(bestsellers))
+	for _, bestseller := range bestsellers {
+		response = append(response, dto.BestsellerResponse{
+			BookId: bestseller.BookId,
+			Title:  bestseller.Title,
+			Author: bestseller.Author,
+			Year:   bestseller.Year,
+			Rating: bestseller.Rating,
+			Sold:   bestseller.Sold,
+		})
+	}
+
+	return cached(c, true, response)
+}
+
+// cached writes body as JSON with an ETag, and answers 304 when the client
+// already has it. Public responses may also be stored by shared caches.
+func cached(c echo.Context, public bool, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	if public {
+		header.Set("Cache-Control", "public, max-age="+strconv.Itoa(cacheMaxAge))
+	} else {
+		header.Set("Cache-Control", "private, no-cache")
+	}
+
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSONBlob(http.StatusOK, data)
+}
+
+func collectionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrCollectionNotFound), errors.Is(err, ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrSlugTaken):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidSlug),
+		errors.Is(err, ErrInvalidTitle),
+		errors.Is(err, ErrInvalidWindow),
+		errors.Is(err, ErrDuplicateBook):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func isStaff(c echo.Context) bool {
+	role, _ := c.Get("role").(string)
+	return role != "" && role != "client"
+}
+
+func toCollection(request *dto.CollectionRequest) (*entities.Collection, error) {
+	collection := &entities.Collection{
+		Slug:           request.Slug,
+		Title:          request.Title,
+		Description:    request.Description,
+		PublishedFrom:  request.PublishedFrom,
+		PublishedUntil: request.PublishedUntil,
+	}
+
+	if request.Cover != nil {
+		cover, mime, err := fromStringToBytes(*request.Cover)
+		if err != nil {
+			return nil, err
+		}
+		collection.CoverData = cover
+		collection.CoverMime = mime
+	}
+
+	return collection, nil
+}
+
+func toCollectionResponse(collection *entities.Collection, books []entities.Book) dto.CollectionResponse {
+	response := dto.CollectionResponse{
+		Id:             collection.Id,
+		Slug:           collection.Slug,
+		Title:          collection.Title,
+		PublishedFrom:  collection.PublishedFrom,
+		PublishedUntil: collection.PublishedUntil,
+		Cover:          fromBytesToString(collection.CoverData, collection.CoverMime),
+		UpdatedAt:      collection.UpdatedAt,
+	}
+
+	if collection.Description != nil {
+		response.Description = *collection.Description
+	}
+
+	if books != nil {
+		response.Books = toListedBookResponses(books)
+	}
+
+	return response
+}
+
+func toListedBookResponses(books []entities.Book) []dto.ListedBookResponse {
+	response := make([]dto.ListedBookResponse, 0, len(books))
+	for _, book := range books {
+		response = append(response, dto.ListedBookResponse{
+			BookId:    book.Id,
+			Title:     book.Title,
+			Author:    book.Author,
+			Year:      book.Year,
+			Rating:    book.Rating,
+			CreatedAt: book.CreatedAt,
+		})
+	}
+	return response
+}
+
+func fromBytesToString(b []byte, mime string) string {
+	if b == nil {
+		return ""
+	}
+	if mime == "" {
+		mime = "image/png"
+	}
+	return "data:" + mime + ";base64," +
+		base64.StdEncoding.EncodeToString(b)
+}
+
+func fromStringToBytes(str string) ([]byte, string, error) {
+	if str == "" {
+		return nil, "", nil
+	}
+
+	const prefix = "data:"
+	if !strings.HasPrefix(str, prefix) {
+		b, err := base64.StdEncoding.DecodeString(str)
+		return b, "", err
+	}
+
+	parts := strings.SplitN(str, ",", 2)
+	if len(parts) != 2 {
+		return nil, "", errors.New("invalid data url")
+	}
+
+	meta := parts[0]
+	data := parts[1]
+
+	mime := ""
+	if strings.Contains(meta, ";") {
+		mime = strings.TrimPrefix(strings.Split(meta, ";")[0], "data:")
+	}
+
+	b, err := base64.StdEncoding.DecodeString(data)
+	return b, mime, err
+}
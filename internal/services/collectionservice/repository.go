@@ -0,0 +1,195 @@
+package collectionservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const uniqueViolationCode = "23505"
+
+type collectionRepository struct {
+	db *gorm.DB
+}
+
+func NewCollectionRepository(db *gorm.DB) CollectionRepository {
+	return &collectionRepository{db: db}
+}
+
+func (r *collectionRepository) CountBooks(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Book{}).
+		Where("id IN ?", ids).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *collectionRepository) Create(ctx context.Context, collection *entities.Collection) error {
+	if err := r.db.WithContext(ctx).Create(collection).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrSlugTaken
+		}
+		return err
+	}
+	return nil
+}
+
+// ReadAll lists collections without their items. With published set, only
+// those visible at now are returned.
+func (r *collectionRepository) ReadAll(ctx context.Context, published bool, now time.Time) ([]entities.Collection, error) {
+	query := r.db.WithContext(ctx)
+	if published {
+		query = whereVisible(query, now)
+	}
+
+	var collections []entities.Collection
+	if err := query.
+		Omit("cover_data").
+		Order("published_from DESC NULLS LAST, created_at DESC").
+		Find(&collections).Error; err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+func (r *collectionRepository) ReadBySlug(ctx context.Context, slug string, published bool, now time.Time) (*entities.Collection, error) {
+	query := r.db.WithContext(ctx).Where("slug = ?", slug)
+	if published {
+		query = whereVisible(query, now)
+	}
+
+	var collection entities.Collection
+	if err := query.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&collection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (r *collectionRepository) Update(ctx context.Context, collection *entities.Collection) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.
+			Model(&entities.Collection{}).
+			Where("id = ?", collection.Id).
+			Select("slug", "title", "description", "cover_data", "cover_mime", "published_from", "published_until", "updated_at").
+			Updates(collection)
+
+		if res.Error != nil {
+			if isUniqueViolation(res.Error) {
+				return ErrSlugTaken
+			}
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrCollectionNotFound
+		}
+
+		if err := tx.
+			Where("collection_id = ?", collection.Id).
+			Delete(&entities.CollectionItem{}).Error; err != nil {
+			return err
+		}
+
+		if len(collection.Items) == 0 {
+			return nil
+		}
+
+		return tx.Create(&collection.Items).Error
+	})
+}
+
+func (r *collectionRepository) Delete(ctx context.Context, id string) error {
+	res := r.db.
+		WithContext(ctx).
+		Delete(&entities.Collection{Id: id})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+// ReadBooks returns the books with the given IDs that are still in the
+// catalogue, in no particular order.
+func (r *collectionRepository) ReadBooks(ctx context.Context, ids []string) ([]entities.Book, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var books []entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Omit("image_data").
+		Where("id IN ?", ids).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (r *collectionRepository) ReadNewArrivals(ctx context.Context, since time.Time, limit int) ([]entities.Book, error) {
+	var books []entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Omit("image_data").
+		Where("created_at >= ?", since).
+		Order("created_at DESC, id").
+		Limit(limit).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// ReadBestsellers sums the copies of each book in paid orders placed since
+// the given moment. Ties go to the better rated book.
+func (r *collectionRepository) ReadBestsellers(ctx context.Context, since time.Time, limit int) ([]Bestseller, error) {
+	var bestsellers []Bestseller
+	if err := r.db.
+		WithContext(ctx).
+		Table("order_items AS oi").
+		Joins("JOIN orders o ON o.id = oi.order_id").
+		Joins("JOIN books ON books.id = oi.book_id AND books.deleted_at IS NULL").
+		Where("o.status = ? AND o.created_at >= ?", "paid", since).
+		Group("books.id").
+		Select("books.id AS book_id, books.title, books.author, books.year, books.rating, SUM(oi.quantity) AS sold").
+		Order("sold DESC, books.rating DESC, books.id").
+		Limit(limit).
+		Scan(&bestsellers).Error; err != nil {
+		return nil, err
+	}
+	return bestsellers, nil
+}
+
+func whereVisible(query *gorm.DB, now time.Time) *gorm.DB {
+	return query.
+		Where("published_from IS NOT NULL AND published_from <= ?", now).
+		Where("published_until IS NULL OR published_until > ?", now)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,205 @@
+package collectionservice
+
+import (
+	"context"
+	"regexp"
+	"story-book/internal/entities"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+const maxTitleLength = 200
+
+// bestsellerPeriods are the windows, in days, bestseller lists are kept for.
+var bestsellerPeriods = map[int]bool{7: true, 30: true, 365: true}
+
+// Bestseller is a book with the number of copies sold in paid orders.
+type Bestseller struct {
+	BookId string
+	Title  string
+	Author string
+	Year   int
+	Rating float64
+	Sold   int
+}
+
+type CollectionRepository interface {
+	CountBooks(ctx context.Context, ids []string) (int64, error)
+	Create(ctx context.Context, collection *entities.Collection) error
+	ReadAll(ctx context.Context, published bool, now time.Time) ([]entities.Collection, error)
+	ReadBySlug(ctx context.Context, slug string, published bool, now time.Time) (*entities.Collection, error)
+	Update(ctx context.Context, collection *entities.Collection) error
+	Delete(ctx context.Context, id string) error
+	ReadBooks(ctx context.Context, ids []string) ([]entities.Book, error)
+	ReadNewArrivals(ctx context.Context, since time.Time, limit int) ([]entities.Book, error)
+	ReadBestsellers(ctx context.Context, since time.Time, limit int) ([]Bestseller, error)
+}
+
+type collectionService struct {
+	repo CollectionRepository
+}
+
+func NewCollectionService(repo CollectionRepository) CollectionService {
+	return &collectionService{repo: repo}
+}
+
+func (s *collectionService) CreateCollection(ctx context.Context, collection *entities.Collection, bookIds []string) (*entities.Collection, error) {
+	if err := s.prepare(ctx, collection, bookIds); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	collection.CreatedAt = now
+	collection.UpdatedAt = now
+
+	if err := s.repo.Create(ctx, collection); err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// ReadCollections lists collections. Only staff pass published=false to see
+// drafts and expired ones.
+func (s *collectionService) ReadCollections(ctx context.Context, published bool) ([]entities.Collection, error) {
+	return s.repo.ReadAll(ctx, published, time.Now())
+}
+
+// ReadCollection returns the collection with its books in order. Books that
+// have since been deleted are skipped.
+func (s *collectionService) ReadCollection(ctx context.Context, slug string, published bool) (*entities.Collection, []entities.Book, error) {
+	collection, err := s.repo.ReadBySlug(ctx, slug, published, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]string, 0, len(collection.Items))
+	for _, item := range collection.Items {
+		ids = append(ids, item.BookId)
+	}
+
+	found, err := s.repo.ReadBooks(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byId := make(map[string]entities.Book, len(found))
+	for _, book := range found {
+		byId[book.Id] = book
+	}
+
+	books := make([]entities.Book, 0, len(found))
+	for _, id := range ids {
+		if book, ok := byId[id]; ok {
+			books = append(books, book)
+		}
+	}
+
+	return collection, books, nil
+}
+
+// UpdateCollection replaces every field of the collection, including its
+// books. A nil cover keeps the current one.
+func (s *collectionService) UpdateCollection(ctx context.Context, slug string, collection *entities.Collection, bookIds []string) (*entities.Collection, error) {
+	current, err := s.repo.ReadBySlug(ctx, slug, false, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	collection.Id = current.Id
+	collection.CreatedAt = current.CreatedAt
+	collection.UpdatedAt = time.Now()
+
+	if collection.CoverData == nil {
+		collection.CoverData = current.CoverData
+		collection.CoverMime = current.CoverMime
+	}
+
+	if err = s.prepare(ctx, collection, bookIds); err != nil {
+		return nil, err
+	}
+
+	if err = s.repo.Update(ctx, collection); err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+func (s *collectionService) DeleteCollection(ctx context.Context, slug string) error {
+	collection, err := s.repo.ReadBySlug(ctx, slug, false, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, collection.Id)
+}
+
+// ReadNewArrivals returns books added in the last days days, newest first.
+func (s *collectionService) ReadNewArrivals(ctx context.Context, days, limit int) ([]entities.Book, error) {
+	return s.repo.ReadNewArrivals(ctx, time.Now().AddDate(0, 0, -days), limit)
+}
+
+// ReadBestsellers returns the books sold most in paid orders placed in the
+// last days days. Only the 7, 30 and 365 day lists are offered, so shared
+// caches hold a handful of variants.
+func (s *collectionService) ReadBestsellers(ctx context.Context, days, limit int) ([]Bestseller, error) {
+	if !bestsellerPeriods[days] {
+		return nil, ErrInvalidPeriod
+	}
+
+	return s.repo.ReadBestsellers(ctx, time.Now().AddDate(0, 0, -days), limit)
+}
+
+func (s *collectionService) prepare(ctx context.Context, collection *entities.Collection, bookIds []string) error {
+	collection.Slug = strings.ToLower(strings.TrimSpace(collection.Slug))
+	if !slugRegex.MatchString(collection.Slug) || len(collection.Slug) > 100 {
+		return ErrInvalidSlug
+	}
+
+	collection.Title = strings.TrimSpace(collection.Title)
+	if collection.Title == "" || utf8.RuneCountInString(collection.Title) > maxTitleLength {
+		return ErrInvalidTitle
+	}
+
+	if collection.PublishedFrom != nil && collection.PublishedUntil != nil &&
+		!collection.PublishedUntil.After(*collection.PublishedFrom) {
+		return ErrInvalidWindow
+	}
+
+	seen := make(map[string]bool, len(bookIds))
+	for _, id := range bookIds {
+		if seen[id] {
+			return ErrDuplicateBook
+		}
+		seen[id] = true
+	}
+
+	count, err := s.repo.CountBooks(ctx, bookIds)
+	if err != nil {
+		return err
+	}
+	if count != int64(len(bookIds)) {
+		return ErrBookNotFound
+	}
+
+	if collection.Id == "" {
+		collection.Id = uuid.NewString()
+	}
+
+	collection.Items = make([]entities.CollectionItem, 0, len(bookIds))
+	for i, id := range bookIds {
+		collection.Items = append(collection.Items, entities.CollectionItem{
+			CollectionId: collection.Id,
+			BookId:       id,
+			Position:     i + 1,
+		})
+	}
+
+	return nil
+}
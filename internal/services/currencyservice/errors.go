@@ -0,0 +1,13 @@
+package currencyservice
+
+import "errors"
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrInvalidCurrency = errors.New("currency must be a three-letter ISO 4217 code")
+	ErrBaseCurrency    = errors.New("the base currency has no exchange rate")
+	ErrInvalidCost     = errors.New("cost must be positive")
+	ErrBookNotFound    = errors.New("book not found")
+	ErrPriceNotFound   = errors.New("currency price not found")
+	ErrRatesFile       = errors.New("invalid exchange rates file")
+)
@@ -0,0 +1,221 @@
+package currencyservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CurrencyService interface {
+	Base() string
+	ReadRates(ctx context.Context) ([]entities.ExchangeRate, error)
+	SetRate(ctx context.Context, currency, rate string) (*entities.ExchangeRate, error)
+	DeleteRate(ctx context.Context, currency string) error
+	LoadRates(ctx context.Context, path string) (int, error)
+	SetBookPrice(ctx context.Context, bookId, currency string, cost float64) (*entities.BookCurrencyPrice, error)
+	DeleteBookPrice(ctx context.Context, bookId, currency string) error
+	PriceBooks(ctx context.Context, books []entities.Book, currency, userId string) (map[string]pricing.Breakdown, error)
+	Resolve(ctx context.Context, currency, userId string) (string, pricing.Rate, error)
+	Overrides(ctx context.Context, currency string, bookIds []string) (map[string]pricing.Money, error)
+}
+
+type CurrencyHandler struct {
+	service CurrencyService
+}
+
+func NewCurrencyHandler(service CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{service: service}
+}
+
+// ReadRates
+// @Summary Получить курсы валют
+// @Tags currencies
+// @Produce json
+// @Success 200 {object} dto.ExchangeRatesResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /currencies [get]
+func (h *CurrencyHandler) ReadRates(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rates, err := h.service.ReadRates(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := dto.ExchangeRatesResponse{
+		Base:  h.service.Base(),
+		Rates: make([]dto.ExchangeRateResponse, 0, len(rates)),
+	}
+	for i := range rates {
+		response.Rates = append(response.Rates, toExchangeRateResponse(&rates[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// SetRate
+// @Summary Установить курс валюты
+// @Tags currencies
+// @Security BearerAuth
+// @Param currency path string true "Код валюты (ISO 4217)"
+// @Accept json
+// @Produce json
+// @Param request body dto.ExchangeRateRequest true "Курс: сколько единиц валюты стоит одна единица базовой"
+// @Success 200 {object} dto.ExchangeRateResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/rates/{currency} [put]
+func (h *CurrencyHandler) SetRate(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	var request dto.ExchangeRateRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	rate, err := h.service.SetRate(ctx, c.Param("currency"), request.Rate)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCurrency), errors.Is(err, ErrBaseCurrency), errors.Is(err, pricing.ErrInvalidRate):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toExchangeRateResponse(rate))
+}
+
+// DeleteRate
+// @Summary Удалить курс валюты
+// @Tags currencies
+// @Security BearerAuth
+// @Param currency path string true "Код валюты (ISO 4217)"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/rates/{currency} [delete]
+func (h *CurrencyHandler) DeleteRate(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteRate(ctx, c.Param("currency")); err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownCurrency):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidCurrency), errors.Is(err, ErrBaseCurrency):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetBookPrice
+// @Summary Установить цену книги в валюте
+// @Tags currencies
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Param currency path string true "Код валюты (ISO 4217)"
+// @Accept json
+// @Param request body dto.CurrencyPriceRequest true "Цена в валюте"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/currency-prices/{currency} [put]
+func (h *CurrencyHandler) SetBookPrice(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	var request dto.CurrencyPriceRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	_, err := h.service.SetBookPrice(ctx, c.Param("id"), c.Param("currency"), request.Cost)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidCurrency), errors.Is(err, ErrBaseCurrency), errors.Is(err, ErrInvalidCost):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteBookPrice
+// @Summary Удалить цену книги в валюте
+// @Tags currencies
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Param currency path string true "Код валюты (ISO 4217)"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/currency-prices/{currency} [delete]
+func (h *CurrencyHandler) DeleteBookPrice(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteBookPrice(ctx, c.Param("id"), c.Param("currency")); err != nil {
+		switch {
+		case errors.Is(err, ErrPriceNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidCurrency), errors.Is(err, ErrBaseCurrency):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toExchangeRateResponse(rate *entities.ExchangeRate) dto.ExchangeRateResponse {
+	return dto.ExchangeRateResponse{
+		Currency:  rate.Currency,
+		Rate:      rate.Rate,
+		UpdatedAt: rate.UpdatedAt,
+	}
+}
@@ -0,0 +1,167 @@
+package currencyservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const foreignKeyViolationCode = "23503"
+
+type currencyRepository struct {
+	db *gorm.DB
+}
+
+func NewCurrencyRepository(db *gorm.DB) CurrencyRepository {
+	return &currencyRepository{db: db}
+}
+
+func (r *currencyRepository) ReadRates(ctx context.Context) ([]entities.ExchangeRate, error) {
+	var rates []entities.ExchangeRate
+	if err := r.db.
+		WithContext(ctx).
+		Order("currency").
+		Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+func (r *currencyRepository) ReadRate(ctx context.Context, currency string) (*entities.ExchangeRate, error) {
+	var rate entities.ExchangeRate
+	if err := r.db.
+		WithContext(ctx).
+		Where("currency = ?", currency).
+		First(&rate).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUnknownCurrency
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *currencyRepository) UpsertRates(ctx context.Context, rates []entities.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for i := range rates {
+		rates[i].UpdatedAt = now
+	}
+
+	return r.db.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+		}).
+		Create(&rates).Error
+}
+
+func (r *currencyRepository) DeleteRate(ctx context.Context, currency string) error {
+	res := r.db.
+		WithContext(ctx).
+		Where("currency = ?", currency).
+		Delete(&entities.ExchangeRate{})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrUnknownCurrency
+	}
+
+	return nil
+}
+
+func (r *currencyRepository) ReadOverrides(ctx context.Context, currency string, bookIds []string) ([]entities.BookCurrencyPrice, error) {
+	var prices []entities.BookCurrencyPrice
+	if err := r.db.
+		WithContext(ctx).
+		Where("currency = ? AND book_id IN ?", currency, bookIds).
+		Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (r *currencyRepository) BookExists(ctx context.Context, id string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Book{}).
+		Where("id = ?", id).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// UpsertOverride pins a book's price in a currency. A book removed since it
+// was checked is reported as not found.
+func (r *currencyRepository) UpsertOverride(ctx context.Context, price *entities.BookCurrencyPrice) error {
+	price.UpdatedAt = time.Now()
+
+	if err := r.db.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "book_id"}, {Name: "currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"cost", "updated_at"}),
+		}).
+		Create(price).Error; err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrBookNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *currencyRepository) DeleteOverride(ctx context.Context, bookId, currency string) error {
+	res := r.db.
+		WithContext(ctx).
+		Where("book_id = ? AND currency = ?", bookId, currency).
+		Delete(&entities.BookCurrencyPrice{})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrPriceNotFound
+	}
+
+	return nil
+}
+
+func (r *currencyRepository) ReadUserCurrency(ctx context.Context, userId string) (string, error) {
+	var user entities.User
+	if err := r.db.
+		WithContext(ctx).
+		Select("currency").
+		Where("id = ?", userId).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if user.Currency == nil {
+		return "", nil
+	}
+	return *user.Currency, nil
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode
+}
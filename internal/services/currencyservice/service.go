@@ -0,0 +1,280 @@
+package currencyservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"regexp"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type CurrencyRepository interface {
+	ReadRates(ctx context.Context) ([]entities.ExchangeRate, error)
+	ReadRate(ctx context.Context, currency string) (*entities.ExchangeRate, error)
+	UpsertRates(ctx context.Context, rates []entities.ExchangeRate) error
+	DeleteRate(ctx context.Context, currency string) error
+	ReadOverrides(ctx context.Context, currency string, bookIds []string) ([]entities.BookCurrencyPrice, error)
+	BookExists(ctx context.Context, id string) (bool, error)
+	UpsertOverride(ctx context.Context, price *entities.BookCurrencyPrice) error
+	DeleteOverride(ctx context.Context, bookId, currency string) error
+	ReadUserCurrency(ctx context.Context, userId string) (string, error)
+}
+
+// BasePricer prices books in the base currency.
+type BasePricer interface {
+	PriceBooks(ctx context.Context, books []entities.Book) (map[string]pricing.Breakdown, error)
+}
+
+type currencyService struct {
+	repo     CurrencyRepository
+	pricer   BasePricer
+	base     string
+	rounding pricing.Rounding
+}
+
+func NewCurrencyService(repo CurrencyRepository, pricer BasePricer, base string, rounding pricing.Rounding) CurrencyService {
+	return &currencyService{repo: repo, pricer: pricer, base: base, rounding: rounding}
+}
+
+func (s *currencyService) Base() string {
+	return s.base
+}
+
+func (s *currencyService) ReadRates(ctx context.Context) ([]entities.ExchangeRate, error) {
+	return s.repo.ReadRates(ctx)
+}
+
+func (s *currencyService) SetRate(ctx context.Context, currency, rate string) (*entities.ExchangeRate, error) {
+	currency, err := s.foreign(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := pricing.ParseRate(rate)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := []entities.ExchangeRate{{Currency: currency, Rate: parsed.Float()}}
+	if err = s.repo.UpsertRates(ctx, rates); err != nil {
+		return nil, err
+	}
+
+	return &rates[0], nil
+}
+
+func (s *currencyService) DeleteRate(ctx context.Context, currency string) error {
+	currency, err := s.foreign(currency)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteRate(ctx, currency)
+}
+
+// LoadRates upserts the rates from a JSON file of the form
+// {"base": "RUB", "rates": {"USD": "0.011"}}. The file must be quoted in the
+// configured base currency.
+func (s *currencyService) LoadRates(ctx context.Context, path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var file struct {
+		Base  string            `json:"base"`
+		Rates map[string]string `json:"rates"`
+	}
+	if err = json.Unmarshal(data, &file); err != nil {
+		return 0, ErrRatesFile
+	}
+
+	if normalize(file.Base) != s.base {
+		return 0, ErrRatesFile
+	}
+
+	rates := make([]entities.ExchangeRate, 0, len(file.Rates))
+	for currency, value := range file.Rates {
+		currency, err = s.foreign(currency)
+		if err != nil {
+			return 0, err
+		}
+
+		rate, err := pricing.ParseRate(value)
+		if err != nil {
+			return 0, err
+		}
+
+		rates = append(rates, entities.ExchangeRate{Currency: currency, Rate: rate.Float()})
+	}
+
+	if err = s.repo.UpsertRates(ctx, rates); err != nil {
+		return 0, err
+	}
+
+	return len(rates), nil
+}
+
+func (s *currencyService) SetBookPrice(ctx context.Context, bookId, currency string, cost float64) (*entities.BookCurrencyPrice, error) {
+	currency, err := s.foreign(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if cost <= 0 {
+		return nil, ErrInvalidCost
+	}
+
+	if _, err = uuid.Parse(bookId); err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	exists, err := s.repo.BookExists(ctx, bookId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrBookNotFound
+	}
+
+	price := &entities.BookCurrencyPrice{
+		BookId:   bookId,
+		Currency: currency,
+		Cost:     pricing.FromFloat(cost).Float(),
+	}
+
+	if err = s.repo.UpsertOverride(ctx, price); err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
+
+func (s *currencyService) DeleteBookPrice(ctx context.Context, bookId, currency string) error {
+	currency, err := s.foreign(currency)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteOverride(ctx, bookId, currency)
+}
+
+// PriceBooks prices books in the requested currency. An empty currency falls
+// back to the user's preference and then to the base currency.
+func (s *currencyService) PriceBooks(ctx context.Context, books []entities.Book, currency, userId string) (map[string]pricing.Breakdown, error) {
+	currency, rate, err := s.Resolve(ctx, currency, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := s.pricer.PriceBooks(ctx, books)
+	if err != nil {
+		return nil, err
+	}
+
+	if currency == s.base {
+		return prices, nil
+	}
+
+	ids := make([]string, 0, len(books))
+	for _, book := range books {
+		ids = append(ids, book.Id)
+	}
+
+	overrides, err := s.Overrides(ctx, currency, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, price := range prices {
+		var override *pricing.Money
+		if cost, ok := overrides[id]; ok {
+			override = &cost
+		}
+		prices[id] = pricing.ConvertBreakdown(price, currency, rate, override, s.rounding)
+	}
+
+	return prices, nil
+}
+
+// Resolve picks the currency to price in and returns it with its rate. A
+// requested currency must have a rate. Without one the user's preference is
+// used, unless its rate has since been removed; the base currency is the
+// last resort.
+func (s *currencyService) Resolve(ctx context.Context, currency, userId string) (string, pricing.Rate, error) {
+	currency = normalize(currency)
+	if currency != "" || userId == "" {
+		return s.rate(ctx, currency)
+	}
+
+	preferred, err := s.repo.ReadUserCurrency(ctx, userId)
+	if err != nil {
+		return "", 0, err
+	}
+
+	currency, rate, err := s.rate(ctx, normalize(preferred))
+	if errors.Is(err, ErrUnknownCurrency) || errors.Is(err, ErrInvalidCurrency) {
+		return s.base, pricing.UnitRate, nil
+	}
+	return currency, rate, err
+}
+
+// Overrides returns the prices staff pinned for books in currency, keyed by
+// book ID.
+func (s *currencyService) Overrides(ctx context.Context, currency string, bookIds []string) (map[string]pricing.Money, error) {
+	overrides := make(map[string]pricing.Money)
+	if len(bookIds) == 0 || currency == s.base {
+		return overrides, nil
+	}
+
+	rows, err := s.repo.ReadOverrides(ctx, currency, bookIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		overrides[row.BookId] = pricing.FromFloat(row.Cost)
+	}
+
+	return overrides, nil
+}
+
+func (s *currencyService) rate(ctx context.Context, currency string) (string, pricing.Rate, error) {
+	if currency == "" || currency == s.base {
+		return s.base, pricing.UnitRate, nil
+	}
+
+	if !currencyRegex.MatchString(currency) {
+		return "", 0, ErrInvalidCurrency
+	}
+
+	rate, err := s.repo.ReadRate(ctx, currency)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return currency, pricing.RateFromFloat(rate.Rate), nil
+}
+
+// foreign normalizes currency and rejects the base currency, which is
+// always quoted at 1 and never stored.
+func (s *currencyService) foreign(currency string) (string, error) {
+	currency = normalize(currency)
+	if !currencyRegex.MatchString(currency) {
+		return "", ErrInvalidCurrency
+	}
+	if currency == s.base {
+		return "", ErrBaseCurrency
+	}
+	return currency, nil
+}
+
+func normalize(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
@@ -0,0 +1,19 @@
+package deliveryservice
+
+import "errors"
+
+var (
+	ErrMethodNotFound  = errors.New("delivery method not found")
+	ErrBookNotFound    = errors.New("book not found")
+	ErrCodeTaken       = errors.New("delivery method with the same code already exists")
+	ErrInvalidCode     = errors.New("code must contain only lowercase letters, digits and underscores")
+	ErrInvalidKind     = errors.New("kind must be one of courier, pickup, post")
+	ErrInvalidName     = errors.New("name must be between 1 and 100 characters")
+	ErrInvalidCost     = errors.New("costs must not be negative and free_from must be positive")
+	ErrInvalidWeight   = errors.New("max_weight_grams must be positive")
+	ErrNoItems         = errors.New("quote must list at least one book")
+	ErrTooManyItems    = errors.New("too many books in one quote")
+	ErrDuplicateItem   = errors.New("each book may be listed only once")
+	ErrInvalidQuantity = errors.New("quantity must be between 1 and 100")
+	ErrAccessDenied    = errors.New("access denied")
+)
@@ -0,0 +1,279 @@
+package deliveryservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DeliveryService interface {
+	CreateMethod(ctx context.Context, method *entities.DeliveryMethod) (*entities.DeliveryMethod, error)
+	ReadMethods(ctx context.Context, withInactive bool) ([]entities.DeliveryMethod, error)
+	UpdateMethod(ctx context.Context, method *entities.DeliveryMethod) (*entities.DeliveryMethod, error)
+	DeleteMethod(ctx context.Context, id string) error
+	QuoteDelivery(ctx context.Context, items []Item) (*Quote, error)
+}
+
+type DeliveryHandler struct {
+	service DeliveryService
+}
+
+func NewDeliveryHandler(service DeliveryService) *DeliveryHandler {
+	return &DeliveryHandler{service: service}
+}
+
+// CreateMethod
+// @Summary Создать способ доставки
+// @Tags delivery
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.DeliveryMethodRequest true "Способ доставки и правила расчёта стоимости"
+// @Success 201 {object} dto.DeliveryMethodResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /delivery/methods [post]
+func (h *DeliveryHandler) CreateMethod(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.DeliveryMethodRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	method, err := h.service.CreateMethod(ctx, fromMethodRequest(&request))
+	if err != nil {
+		return methodError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toMethodResponse(method))
+}
+
+// ReadMethods
+// @Summary Получить способы доставки
+// @Description Неактивные способы видны только сотрудникам
+// @Tags delivery
+// @Produce json
+// @Success 200 {array} dto.DeliveryMethodResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /delivery/methods [get]
+func (h *DeliveryHandler) ReadMethods(c echo.Context) error {
+	role, _ := c.Get("role").(string)
+	withInactive := role != "" && role != "client"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	methods, err := h.service.ReadMethods(ctx, withInactive)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.DeliveryMethodResponse, 0, len(methods))
+	for i := range methods {
+		response = append(response, toMethodResponse(&methods[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// UpdateMethod
+// @Summary Изменить способ доставки
+// @Tags delivery
+// @Security BearerAuth
+// @Param id path string true "ID способа доставки"
+// @Accept json
+// @Produce json
+// @Param request body dto.DeliveryMethodRequest true "Способ доставки и правила расчёта стоимости"
+// @Success 200 {object} dto.DeliveryMethodResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /delivery/methods/{id} [put]
+func (h *DeliveryHandler) UpdateMethod(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.DeliveryMethodRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	method := fromMethodRequest(&request)
+	method.Id = c.Param("id")
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	method, err := h.service.UpdateMethod(ctx, method)
+	if err != nil {
+		return methodError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toMethodResponse(method))
+}
+
+// DeleteMethod
+// @Summary Удалить способ доставки
+// @Tags delivery
+// @Security BearerAuth
+// @Param id path string true "ID способа доставки"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /delivery/methods/{id} [delete]
+func (h *DeliveryHandler) DeleteMethod(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteMethod(ctx, c.Param("id")); err != nil {
+		if errors.Is(err, ErrMethodNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// QuoteDelivery
+// @Summary Рассчитать стоимость доставки
+// @Description Стоимость считается по весу книг и сумме заказа для каждого активного способа доставки
+// @Tags delivery
+// @Accept json
+// @Produce json
+// @Param request body dto.DeliveryQuoteRequest true "Книги и количество"
+// @Success 200 {object} dto.DeliveryQuoteResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /delivery/quote [post]
+func (h *DeliveryHandler) QuoteDelivery(c echo.Context) error {
+	var request dto.DeliveryQuoteRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	items := make([]Item, 0, len(request.Items))
+	for _, item := range request.Items {
+		items = append(items, Item{BookId: item.BookId, Quantity: item.Quantity})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	quote, err := h.service.QuoteDelivery(ctx, items)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrNoItems), errors.Is(err, ErrTooManyItems), errors.Is(err, ErrDuplicateItem),
+			errors.Is(err, ErrInvalidQuantity):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := dto.DeliveryQuoteResponse{
+		Currency:    quote.Currency,
+		Total:       quote.Total,
+		WeightGrams: quote.WeightGrams,
+		Options:     make([]dto.DeliveryOptionResponse, 0, len(quote.Options)),
+	}
+
+	for _, option := range quote.Options {
+		response.Options = append(response.Options, dto.DeliveryOptionResponse{
+			MethodId:  option.Method.Id,
+			Code:      option.Method.Code,
+			Kind:      option.Method.Kind,
+			Name:      option.Method.Name,
+			Cost:      option.Cost,
+			Available: option.Available,
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func methodError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrMethodNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrCodeTaken):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidCode), errors.Is(err, ErrInvalidKind), errors.Is(err, ErrInvalidName),
+		errors.Is(err, ErrInvalidCost), errors.Is(err, ErrInvalidWeight):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func fromMethodRequest(request *dto.DeliveryMethodRequest) *entities.DeliveryMethod {
+	method := &entities.DeliveryMethod{
+		Code:           request.Code,
+		Kind:           request.Kind,
+		Name:           request.Name,
+		BaseCost:       request.BaseCost.Float(),
+		CostPerKg:      request.CostPerKg.Float(),
+		MaxWeightGrams: request.MaxWeightGrams,
+		Active:         request.Active,
+	}
+
+	if request.FreeFrom != nil {
+		freeFrom := request.FreeFrom.Float()
+		method.FreeFrom = &freeFrom
+	}
+
+	return method
+}
+
+func toMethodResponse(method *entities.DeliveryMethod) dto.DeliveryMethodResponse {
+	response := dto.DeliveryMethodResponse{
+		Id:        method.Id,
+		Code:      method.Code,
+		Kind:      method.Kind,
+		Name:      method.Name,
+		BaseCost:  pricing.FromFloat(method.BaseCost),
+		CostPerKg: pricing.FromFloat(method.CostPerKg),
+		Active:    method.Active,
+		CreatedAt: method.CreatedAt,
+		UpdatedAt: method.UpdatedAt,
+	}
+
+	if method.FreeFrom != nil {
+		freeFrom := pricing.FromFloat(*method.FreeFrom)
+		response.FreeFrom = &freeFrom
+	}
+	if method.MaxWeightGrams != nil {
+		response.MaxWeightGrams = *method.MaxWeightGrams
+	}
+
+	return response
+}
@@ -0,0 +1,115 @@
+package deliveryservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const uniqueViolationCode = "23505"
+
+type deliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewDeliveryRepository(db *gorm.DB) DeliveryRepository {
+	return &deliveryRepository{db: db}
+}
+
+func (r *deliveryRepository) Create(ctx context.Context, method *entities.DeliveryMethod) error {
+	if err := r.db.WithContext(ctx).Create(method).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrCodeTaken
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *deliveryRepository) ReadAll(ctx context.Context, activeOnly bool) ([]entities.DeliveryMethod, error) {
+	query := r.db.WithContext(ctx)
+
+	if activeOnly {
+		query = query.Where("active = true")
+	}
+
+	var methods []entities.DeliveryMethod
+	if err := query.
+		Order("base_cost, name").
+		Find(&methods).Error; err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+func (r *deliveryRepository) ReadById(ctx context.Context, id string) (*entities.DeliveryMethod, error) {
+	var method entities.DeliveryMethod
+	if err := r.db.
+		WithContext(ctx).
+		Where("id = ?", id).
+		First(&method).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMethodNotFound
+		}
+		return nil, err
+	}
+	return &method, nil
+}
+
+func (r *deliveryRepository) Update(ctx context.Context, method *entities.DeliveryMethod) error {
+	res := r.db.
+		WithContext(ctx).
+		Model(&entities.DeliveryMethod{}).
+		Where("id = ?", method.Id).
+		Select("code", "kind", "name", "base_cost", "cost_per_kg", "free_from", "max_weight_grams", "active", "updated_at").
+		Updates(method)
+
+	if res.Error != nil {
+		if isUniqueViolation(res.Error) {
+			return ErrCodeTaken
+		}
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrMethodNotFound
+	}
+
+	return nil
+}
+
+func (r *deliveryRepository) Delete(ctx context.Context, id string) error {
+	res := r.db.
+		WithContext(ctx).
+		Delete(&entities.DeliveryMethod{Id: id})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrMethodNotFound
+	}
+
+	return nil
+}
+
+func (r *deliveryRepository) ReadBooks(ctx context.Context, ids []string) ([]entities.Book, error) {
+	var books []entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Omit("image_data").
+		Where("id IN ?", ids).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,257 @@
+package deliveryservice
+
+import (
+	"context"
+	"regexp"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxNameLength = 100
+	maxItems      = 50
+	maxQuantity   = 100
+
+	// defaultWeightGrams stands in for books whose weight is not filled in
+	// yet, roughly a hardcover of 300 pages.
+	defaultWeightGrams = 500
+	gramsPerKg         = 1000
+)
+
+var codeRegex = regexp.MustCompile(`^[a-z0-9_]{1,30}$`)
+
+// Delivery method kinds. Pickups need no address.
+const (
+	KindCourier = "courier"
+	KindPickup  = "pickup"
+	KindPost    = "post"
+)
+
+var kinds = map[string]bool{
+	KindCourier: true,
+	KindPickup:  true,
+	KindPost:    true,
+}
+
+type DeliveryRepository interface {
+	Create(ctx context.Context, method *entities.DeliveryMethod) error
+	ReadAll(ctx context.Context, activeOnly bool) ([]entities.DeliveryMethod, error)
+	ReadById(ctx context.Context, id string) (*entities.DeliveryMethod, error)
+	Update(ctx context.Context, method *entities.DeliveryMethod) error
+	Delete(ctx context.Context, id string) error
+	ReadBooks(ctx context.Context, ids []string) ([]entities.Book, error)
+}
+
+// Pricer prices books in the base currency, promotions included.
+type Pricer interface {
+	PriceBooks(ctx context.Context, books []entities.Book) (map[string]pricing.Breakdown, error)
+}
+
+// Item is a book and how many copies of it are shipped.
+type Item struct {
+	BookId   string
+	Quantity int
+}
+
+// Option is what one delivery method would cost for a parcel.
+type Option struct {
+	Method    entities.DeliveryMethod
+	Cost      pricing.Money
+	Available bool
+}
+
+// Quote prices every active delivery method for a set of books.
+type Quote struct {
+	Currency    string
+	Total       pricing.Money
+	WeightGrams int
+	Options     []Option
+}
+
+type deliveryService struct {
+	repo     DeliveryRepository
+	pricer   Pricer
+	currency string
+}
+
+func NewDeliveryService(repo DeliveryRepository, pricer Pricer, currency string) DeliveryService {
+	return &deliveryService{repo: repo, pricer: pricer, currency: currency}
+}
+
+func (s *deliveryService) CreateMethod(ctx context.Context, method *entities.DeliveryMethod) (*entities.DeliveryMethod, error) {
+	if err := validateMethod(method); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	method.Id = uuid.NewString()
+	method.CreatedAt = now
+	method.UpdatedAt = now
+
+	if err := s.repo.Create(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
+
+// ReadMethods lists delivery methods. Inactive ones are shown to staff only.
+func (s *deliveryService) ReadMethods(ctx context.Context, withInactive bool) ([]entities.DeliveryMethod, error) {
+	return s.repo.ReadAll(ctx, !withInactive)
+}
+
+func (s *deliveryService) UpdateMethod(ctx context.Context, method *entities.DeliveryMethod) (*entities.DeliveryMethod, error) {
+	if err := validateMethod(method); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.ReadById(ctx, method.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	method.CreatedAt = current.CreatedAt
+	method.UpdatedAt = time.Now()
+
+	if err = s.repo.Update(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
+
+func (s *deliveryService) DeleteMethod(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// QuoteDelivery weighs and prices the books and returns the cost of each
+// active delivery method. Methods the parcel is too heavy for are listed as
+// unavailable.
+func (s *deliveryService) QuoteDelivery(ctx context.Context, items []Item) (*Quote, error) {
+	if err := validateItems(items); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.BookId)
+	}
+
+	books, err := s.repo.ReadBooks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(books) != len(items) {
+		return nil, ErrBookNotFound
+	}
+
+	prices, err := s.pricer.PriceBooks(ctx, books)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]entities.Book, len(books))
+	for _, book := range books {
+		byId[book.Id] = book
+	}
+
+	quote := &Quote{Currency: s.currency}
+	for _, item := range items {
+		book := byId[item.BookId]
+
+		weight := defaultWeightGrams
+		if book.WeightGrams != nil {
+			weight = *book.WeightGrams
+		}
+
+		quote.WeightGrams += weight * item.Quantity
+		quote.Total += prices[book.Id].FinalPrice.Mul(item.Quantity)
+	}
+
+	methods, err := s.repo.ReadAll(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Options = make([]Option, 0, len(methods))
+	for _, method := range methods {
+		cost, available := price(&method, quote.WeightGrams, quote.Total)
+		quote.Options = append(quote.Options, Option{Method: method, Cost: cost, Available: available})
+	}
+
+	return quote, nil
+}
+
+// price applies a method's cost rules: a base cost plus a rate for every
+// started kilogram, waived once the order total reaches free_from.
+func price(method *entities.DeliveryMethod, weightGrams int, total pricing.Money) (pricing.Money, bool) {
+	if method.MaxWeightGrams != nil && weightGrams > *method.MaxWeightGrams {
+		return 0, false
+	}
+
+	if method.FreeFrom != nil && total >= pricing.FromFloat(*method.FreeFrom) {
+		return 0, true
+	}
+
+	kilograms := (weightGrams + gramsPerKg - 1) / gramsPerKg
+
+	return pricing.FromFloat(method.BaseCost) + pricing.FromFloat(method.CostPerKg).Mul(kilograms), true
+}
+
+func validateMethod(method *entities.DeliveryMethod) error {
+	method.Code = strings.TrimSpace(method.Code)
+	if !codeRegex.MatchString(method.Code) {
+		return ErrInvalidCode
+	}
+
+	if !kinds[method.Kind] {
+		return ErrInvalidKind
+	}
+
+	method.Name = strings.TrimSpace(method.Name)
+	if method.Name == "" || utf8.RuneCountInString(method.Name) > maxNameLength {
+		return ErrInvalidName
+	}
+
+	if method.BaseCost < 0 || method.CostPerKg < 0 || (method.FreeFrom != nil && *method.FreeFrom <= 0) {
+		return ErrInvalidCost
+	}
+
+	if method.MaxWeightGrams != nil && *method.MaxWeightGrams <= 0 {
+		return ErrInvalidWeight
+	}
+
+	return nil
+}
+
+func validateItems(items []Item) error {
+	if len(items) == 0 {
+		return ErrNoItems
+	}
+	if len(items) > maxItems {
+		return ErrTooManyItems
+	}
+
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item.BookId == "" {
+			return ErrBookNotFound
+		}
+		if seen[item.BookId] {
+			return ErrDuplicateItem
+		}
+		seen[item.BookId] = true
+
+		if item.Quantity < 1 || item.Quantity > maxQuantity {
+			return ErrInvalidQuantity
+		}
+	}
+
+	return nil
+}
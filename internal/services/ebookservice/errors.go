@@ -0,0 +1,27 @@
+package ebookservice
+
+import "errors"
+
+var (
+	ErrBookNotFound      = errors.New("book not found")
+	ErrNotEbook          = errors.New("book is not an e-book")
+	ErrInvalidFileFormat = errors.New("file format must be epub or pdf")
+	ErrFileMismatch      = errors.New("file is not a valid file of the given format")
+	ErrFileRequired      = errors.New("file is required")
+	ErrFileTooLarge      = errors.New("file is too large")
+	ErrFileNotFound      = errors.New("e-book file not found")
+	ErrPurchaseNotFound  = errors.New("purchase not found")
+	ErrPurchaseExists    = errors.New("the user already owns this e-book from this order")
+	ErrInvalidPurchase   = errors.New("user_id, book_id and order_id must be UUIDs")
+	ErrOrderNotPaid      = errors.New("the order has no paid payment of this user")
+	ErrBookNotInOrder    = errors.New("the book is not in the order")
+	ErrInvalidLimit      = errors.New("download_limit must be positive")
+	ErrInvalidLink       = errors.New("download link is invalid")
+	ErrLinkExpired       = errors.New("download link has expired")
+	ErrDownloadLimit     = errors.New("download limit reached")
+	ErrDamagedFile       = errors.New("e-book file is damaged")
+	ErrNotEpub           = errors.New("file is not a valid EPUB")
+	ErrNoWatermark       = errors.New("no watermark found in the file")
+	ErrDownloadNotFound  = errors.New("the watermark matches no download of this store")
+	ErrAccessDenied      = errors.New("access denied")
+)
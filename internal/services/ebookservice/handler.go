@@ -0,0 +1,438 @@
+package ebookservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxFileSize = 200 << 20
+
+type EbookService interface {
+	UploadFile(ctx context.Context, actorId, bookId, format string, r io.Reader) (*entities.EbookFile, error)
+	ReadFiles(ctx context.Context, bookId string) ([]entities.EbookFile, error)
+	DeleteFile(ctx context.Context, bookId, format string) error
+	GrantPurchase(ctx context.Context, purchase *entities.EbookPurchase) (*entities.EbookPurchase, error)
+	ReadLibrary(ctx context.Context, userId string) ([]entities.EbookPurchase, error)
+	CreateLink(ctx context.Context, userId, purchaseId, format string) (string, time.Time, error)
+	Download(ctx context.Context, purchaseId, format string, expires int64, signature, ip string) (*Download, error)
+	IdentifyCopy(ctx context.Context, data []byte) ([]entities.EbookDownload, error)
+}
+
+type EbookHandler struct {
+	service EbookService
+}
+
+func NewEbookHandler(service EbookService) *EbookHandler {
+	return &EbookHandler{service: service}
+}
+
+// UploadFile
+// @Summary Загрузить файл электронной книги
+// @Description Книга должна иметь формат ebook. Файл заменяет ранее загруженный в том же формате
+// @Tags ebooks
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "ID книги"
+// @Param format path string true "Формат файла: epub или pdf"
+// @Param file formData file true "Файл, до 200 МБ"
+// @Success 200 {object} dto.EbookFileResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/ebook-files/{format} [put]
+func (h *EbookHandler) UploadFile(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrFileRequired.Error()})
+	}
+	if header.Size > maxFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: ErrFileTooLarge.Error()})
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), time.Minute)
+	defer cancel()
+
+	ebookFile, err := h.service.UploadFile(ctx, c.Get("id").(string), c.Param("id"), c.Param("format"), file)
+	if err != nil {
+		return ebookError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toEbookFileResponse(ebookFile))
+}
+
+// ReadFiles
+// @Summary Получить файлы электронной книги
+// @Tags ebooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID книги"
+// @Success 200 {array} dto.EbookFileResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/ebook-files [get]
+func (h *EbookHandler) ReadFiles(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	files, err := h.service.ReadFiles(ctx, c.Param("id"))
+	if err != nil {
+		return ebookError(c, err)
+	}
+
+	response := make([]dto.EbookFileResponse, 0, len(files))
+	for _, file := range files {
+		response = append(response, toEbookFileResponse(&file))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// DeleteFile
+// @Summary Удалить файл электронной книги
+// @Description Купившие книгу больше не смогут скачать её в этом формате
+// @Tags ebooks
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Param format path string true "Формат файла: epub или pdf"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/ebook-files/{format} [delete]
+func (h *EbookHandler) DeleteFile(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteFile(ctx, c.Param("id"), c.Param("format")); err != nil {
+		return ebookError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GrantPurchase
+// @Summary Выдать пользователю купленную электронную книгу
+// @Description Оплаченные заказы выдают свои электронные книги сами; выдача вручную — для остальных случаев. У заказа должен быть оплаченный платёж этого пользователя, а книга — быть в заказе. Без download_limit действует лимит скачиваний магазина
+// @Tags ebooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.EbookPurchaseRequest true "Покупка"
+// @Success 201 {object} dto.EbookPurchaseResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /ebooks/purchases [post]
+func (h *EbookHandler) GrantPurchase(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.EbookPurchaseRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	purchase := &entities.EbookPurchase{
+		UserId:  request.UserId,
+		BookId:  request.BookId,
+		OrderId: request.OrderId,
+	}
+	if request.DownloadLimit != nil {
+		if *request.DownloadLimit < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+		purchase.DownloadLimit = *request.DownloadLimit
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	purchase, err := h.service.GrantPurchase(ctx, purchase)
+	if err != nil {
+		return ebookError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.EbookPurchaseResponse{
+		Id:            purchase.Id,
+		UserId:        purchase.UserId,
+		BookId:        purchase.BookId,
+		OrderId:       purchase.OrderId,
+		DownloadLimit: purchase.DownloadLimit,
+		Downloads:     purchase.Downloads,
+		CreatedAt:     purchase.CreatedAt,
+	})
+}
+
+// ReadLibrary
+// @Summary Получить мои электронные книги
+// @Tags ebooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.LibraryItemResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /library [get]
+func (h *EbookHandler) ReadLibrary(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	purchases, err := h.service.ReadLibrary(ctx, c.Get("id").(string))
+	if err != nil {
+		return ebookError(c, err)
+	}
+
+	response := make([]dto.LibraryItemResponse, 0, len(purchases))
+	for _, purchase := range purchases {
+		item := dto.LibraryItemResponse{
+			PurchaseId:    purchase.Id,
+			BookId:        purchase.BookId,
+			OrderId:       purchase.OrderId,
+			Formats:       make([]string, 0, len(purchase.Files)),
+			DownloadsLeft: purchase.DownloadLimit - purchase.Downloads,
+			PurchasedAt:   purchase.CreatedAt,
+		}
+		if purchase.Book != nil {
+			item.Title = purchase.Book.Title
+			item.Author = purchase.Book.Author
+		}
+		for _, file := range purchase.Files {
+			item.Formats = append(item.Formats, file.FileFormat)
+		}
+		response = append(response, item)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// CreateLink
+// @Summary Получить ссылку на скачивание электронной книги
+// @Description Ссылка подписана и действует ограниченное время; каждое скачивание по ней расходует лимит покупки
+// @Tags ebooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID покупки"
+// @Param format query string true "Формат файла: epub или pdf"
+// @Success 201 {object} dto.DownloadLinkResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /library/{id}/links [post]
+func (h *EbookHandler) CreateLink(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	link, expiresAt, err := h.service.CreateLink(ctx, c.Get("id").(string), c.Param("id"), c.QueryParam("format"))
+	if err != nil {
+		return ebookError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.DownloadLinkResponse{Url: link, ExpiresAt: expiresAt})
+}
+
+// Download
+// @Summary Скачать электронную книгу по подписанной ссылке
+// @Description Каждая копия EPUB помечается данными покупателя и номером скачивания; размер такой копии заранее не известен
+// @Tags ebooks
+// @Produce application/epub+zip
+// @Produce application/pdf
+// @Param id path string true "ID покупки"
+// @Param format path string true "Формат файла: epub или pdf"
+// @Param expires query int true "Срок действия ссылки (Unix time)"
+// @Param signature query string true "Подпись ссылки"
+// @Success 200 {file} file
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 410 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /ebooks/download/{id}/{format} [get]
+func (h *EbookHandler) Download(c echo.Context) error {
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrInvalidLink.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	download, err := h.service.Download(ctx, c.Param("id"), c.Param("format"), expires, c.QueryParam("signature"), c.RealIP())
+	if err != nil {
+		return ebookError(c, err)
+	}
+	defer download.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, download.ContentType)
+	header.Set(echo.HeaderContentDisposition, attachment(download.Name))
+	if download.Size >= 0 {
+		header.Set(echo.HeaderContentLength, strconv.FormatInt(download.Size, 10))
+	}
+	header.Set("Cache-Control", "private, no-store")
+	c.Response().WriteHeader(http.StatusOK)
+	return download.Write(c.Response())
+}
+
+// IdentifyCopy
+// @Summary Определить покупателя по копии EPUB
+// @Description Ищет в файле водяные знаки и возвращает скачивания, к которым они относятся. Только для администраторов
+// @Tags ebooks
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Файл EPUB, до 200 МБ"
+// @Success 200 {array} dto.WatermarkMatchResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/ebooks/identify [post]
+func (h *EbookHandler) IdentifyCopy(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrFileRequired.Error()})
+	}
+	if header.Size > maxFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: ErrFileTooLarge.Error()})
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	downloads, err := h.service.IdentifyCopy(ctx, data)
+	if err != nil {
+		return ebookError(c, err)
+	}
+
+	response := make([]dto.WatermarkMatchResponse, 0, len(downloads))
+	for i := range downloads {
+		response = append(response, toWatermarkMatchResponse(&downloads[i]))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// attachment names a download, with an ASCII fallback for clients that do
+// not read RFC 5987 names.
+func attachment(name string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r > 0x20 && r < 0x7f && r != '"' && r != '\\' {
+			return r
+		}
+		return '_'
+	}, name)
+	return `attachment; filename="` + fallback + `"; filename*=UTF-8''` + url.PathEscape(name)
+}
+
+func toEbookFileResponse(file *entities.EbookFile) dto.EbookFileResponse {
+	return dto.EbookFileResponse{
+		BookId:    file.BookId,
+		Format:    file.FileFormat,
+		Size:      file.Size,
+		Sha256:    file.Sha256,
+		CreatedAt: file.CreatedAt,
+	}
+}
+
+func toWatermarkMatchResponse(download *entities.EbookDownload) dto.WatermarkMatchResponse {
+	response := dto.WatermarkMatchResponse{
+		DownloadId:   download.Id,
+		PurchaseId:   download.PurchaseId,
+		Ip:           download.Ip,
+		DownloadedAt: download.CreatedAt,
+	}
+	if purchase := download.Purchase; purchase != nil {
+		response.OrderId = purchase.OrderId
+		response.BookId = purchase.BookId
+		response.UserId = purchase.UserId
+		if purchase.Book != nil {
+			response.Title = purchase.Book.Title
+		}
+		if purchase.User != nil {
+			response.Name = strings.TrimSpace(purchase.User.Name + " " + purchase.User.Surname)
+			response.Email = purchase.User.Email
+		}
+	}
+	return response
+}
+
+func ebookError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrBookNotFound), errors.Is(err, ErrFileNotFound), errors.Is(err, ErrPurchaseNotFound),
+		errors.Is(err, ErrNoWatermark), errors.Is(err, ErrDownloadNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidFileFormat), errors.Is(err, ErrFileMismatch), errors.Is(err, ErrInvalidPurchase),
+		errors.Is(err, ErrInvalidLimit), errors.Is(err, ErrNotEpub):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrNotEbook), errors.Is(err, ErrPurchaseExists), errors.Is(err, ErrOrderNotPaid),
+		errors.Is(err, ErrBookNotInOrder):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidLink), errors.Is(err, ErrDownloadLimit):
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrLinkExpired):
+		return c.JSON(http.StatusGone, dto.ErrorResponse{Error: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+}
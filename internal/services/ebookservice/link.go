@@ -0,0 +1,25 @@
+package ebookservice
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// signer signs download links with HMAC-SHA256, so that a link names a
+// purchase, a file format and an expiry that cannot be altered.
+type signer struct {
+	key []byte
+}
+
+func (s signer) sign(purchaseId, format string, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(purchaseId + "\n" + format + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s signer) verify(purchaseId, format string, expires int64, signature string) bool {
+	expected := s.sign(purchaseId, format, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
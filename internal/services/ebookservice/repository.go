@@ -0,0 +1,222 @@
+package ebookservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"story-book/internal/services/paymentservice"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uniqueViolationCode = "23505"
+
+type ebookRepository struct {
+	db *gorm.DB
+}
+
+func NewEbookRepository(db *gorm.DB) EbookRepository {
+	return &ebookRepository{db: db}
+}
+
+func (r *ebookRepository) ReadBook(ctx context.Context, id string) (*entities.Book, error) {
+	var book entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Select("id", "title", "author", "format").
+		Where("id = ?", id).
+		First(&book).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	return &book, nil
+}
+
+// SaveFile records a master file, replacing the one of the same book and
+// format.
+func (r *ebookRepository) SaveFile(ctx context.Context, file *entities.EbookFile) error {
+	return r.db.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "book_id"}, {Name: "file_format"}},
+			UpdateAll: true,
+		}).
+		Create(file).Error
+}
+
+func (r *ebookRepository) ReadFiles(ctx context.Context, bookId string) ([]entities.EbookFile, error) {
+	var files []entities.EbookFile
+	if err := r.db.
+		WithContext(ctx).
+		Where("book_id = ?", bookId).
+		Order("file_format").
+		Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *ebookRepository) ReadFile(ctx context.Context, bookId, format string) (*entities.EbookFile, error) {
+	var file entities.EbookFile
+	if err := r.db.
+		WithContext(ctx).
+		Where("book_id = ? AND file_format = ?", bookId, format).
+		First(&file).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *ebookRepository) DeleteFile(ctx context.Context, bookId, format string) error {
+	result := r.db.
+		WithContext(ctx).
+		Where("book_id = ? AND file_format = ?", bookId, format).
+		Delete(&entities.EbookFile{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFileNotFound
+	}
+	return nil
+}
+
+// OrderPaid tells whether the user has a paid payment for the order.
+func (r *ebookRepository) OrderPaid(ctx context.Context, orderId, userId string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Payment{}).
+		Where("order_id = ? AND user_id = ? AND status = ?", orderId, userId, paymentservice.StatusPaid).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// OrderHasBook tells whether the book is one of the order's lines.
+func (r *ebookRepository) OrderHasBook(ctx context.Context, orderId, bookId string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.OrderItem{}).
+		Where("order_id = ? AND book_id = ?", orderId, bookId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *ebookRepository) CreatePurchase(ctx context.Context, purchase *entities.EbookPurchase) error {
+	if err := r.db.WithContext(ctx).Omit("Book", "User", "Files").Create(purchase).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrPurchaseExists
+		}
+		return err
+	}
+	return nil
+}
+
+// ReadPurchase reads a purchase with its book, trashed or not, and the
+// book's files.
+func (r *ebookRepository) ReadPurchase(ctx context.Context, id string) (*entities.EbookPurchase, error) {
+	var purchase entities.EbookPurchase
+	if err := r.withBook(r.db.WithContext(ctx)).
+		Where("id = ?", id).
+		First(&purchase).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPurchaseNotFound
+		}
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+// ReadLibrary reads the purchases of a user, newest first.
+func (r *ebookRepository) ReadLibrary(ctx context.Context, userId string) ([]entities.EbookPurchase, error) {
+	var purchases []entities.EbookPurchase
+	if err := r.withBook(r.db.WithContext(ctx)).
+		Where("user_id = ?", userId).
+		Order("created_at DESC").
+		Find(&purchases).Error; err != nil {
+		return nil, err
+	}
+	return purchases, nil
+}
+
+// UseDownload counts a download against the purchase's limit and records
+// it, in one transaction.
+func (r *ebookRepository) UseDownload(ctx context.Context, download *entities.EbookDownload) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&entities.EbookPurchase{}).
+			Where("id = ? AND downloads < download_limit", download.PurchaseId).
+			UpdateColumn("downloads", gorm.Expr("downloads + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDownloadLimit
+		}
+
+		return tx.Omit("Purchase").Create(download).Error
+	})
+}
+
+// ReadUser reads the name and email of a user, deleted or not.
+func (r *ebookRepository) ReadUser(ctx context.Context, id string) (*entities.User, error) {
+	var user entities.User
+	if err := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Select("id", "name", "surname", "email").
+		Where("id = ?", id).
+		First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ReadDownloads reads downloads with their purchase, book and buyer.
+func (r *ebookRepository) ReadDownloads(ctx context.Context, ids []string) ([]entities.EbookDownload, error) {
+	var downloads []entities.EbookDownload
+	if err := r.db.
+		WithContext(ctx).
+		Preload("Purchase").
+		Preload("Purchase.Book", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id", "title", "author", "format")
+		}).
+		Preload("Purchase.User", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id", "name", "surname", "email")
+		}).
+		Where("id IN ?", ids).
+		Order("created_at").
+		Find(&downloads).Error; err != nil {
+		return nil, err
+	}
+	return downloads, nil
+}
+
+// withBook preloads the book without its cover, so owners keep access to
+// books moved to the trash, and its files.
+func (r *ebookRepository) withBook(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Book", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id", "title", "author", "format")
+		}).
+		Preload("Files", func(db *gorm.DB) *gorm.DB {
+			return db.Order("file_format")
+		})
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,426 @@
+package ebookservice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"story-book/internal/entities"
+	"story-book/internal/epub"
+	"story-book/internal/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FormatEpub = "epub"
+	FormatPdf  = "pdf"
+)
+
+var contentTypes = map[string]string{
+	FormatEpub: "application/epub+zip",
+	FormatPdf:  "application/pdf",
+}
+
+// An EPUB is a zip whose first entry is an uncompressed file named mimetype
+// holding the media type, so the media type sits at a fixed offset.
+var (
+	zipMagic  = []byte("PK\x03\x04")
+	epubMagic = []byte("mimetypeapplication/epub+zip")
+	pdfMagic  = []byte("%PDF-")
+)
+
+const epubMagicOffset = 30
+
+type EbookRepository interface {
+	ReadBook(ctx context.Context, id string) (*entities.Book, error)
+	SaveFile(ctx context.Context, file *entities.EbookFile) error
+	ReadFiles(ctx context.Context, bookId string) ([]entities.EbookFile, error)
+	ReadFile(ctx context.Context, bookId, format string) (*entities.EbookFile, error)
+	DeleteFile(ctx context.Context, bookId, format string) error
+	OrderPaid(ctx context.Context, orderId, userId string) (bool, error)
+	OrderHasBook(ctx context.Context, orderId, bookId string) (bool, error)
+	CreatePurchase(ctx context.Context, purchase *entities.EbookPurchase) error
+	ReadPurchase(ctx context.Context, id string) (*entities.EbookPurchase, error)
+	ReadLibrary(ctx context.Context, userId string) ([]entities.EbookPurchase, error)
+	UseDownload(ctx context.Context, download *entities.EbookDownload) error
+	ReadUser(ctx context.Context, id string) (*entities.User, error)
+	ReadDownloads(ctx context.Context, ids []string) ([]entities.EbookDownload, error)
+}
+
+// Download is a copy of an e-book being sent to its owner. EPUB copies are
+// watermarked as they are written, so their size is not known in advance
+// and Size is -1.
+type Download struct {
+	Name        string
+	ContentType string
+	Size        int64
+	file        storage.File
+	write       func(w io.Writer) error
+}
+
+// Write sends the copy to w.
+func (d *Download) Write(w io.Writer) error {
+	return d.write(w)
+}
+
+// Close closes the master file.
+func (d *Download) Close() error {
+	return d.file.Close()
+}
+
+type ebookService struct {
+	repo          EbookRepository
+	storage       storage.Storage
+	signer        signer
+	publicUrl     string
+	linkTTL       time.Duration
+	downloadLimit int
+}
+
+// NewEbookService keeps e-book files in store. Download links point at
+// publicUrl, are signed with signingKey and last linkTTL; a purchase allows
+// downloadLimit downloads unless staff set another limit.
+func NewEbookService(repo EbookRepository, store storage.Storage, signingKey, publicUrl string, linkTTL time.Duration, downloadLimit int) EbookService {
+	return &ebookService{
+		repo:          repo,
+		storage:       store,
+		signer:        signer{key: []byte(signingKey)},
+		publicUrl:     strings.TrimRight(publicUrl, "/"),
+		linkTTL:       linkTTL,
+		downloadLimit: downloadLimit,
+	}
+}
+
+// UploadFile stores the master file of an e-book in one format, replacing
+// the previous one. The file must start like an EPUB or PDF.
+func (s *ebookService) UploadFile(ctx context.Context, actorId, bookId, format string, r io.Reader) (*entities.EbookFile, error) {
+	if _, ok := contentTypes[format]; !ok {
+		return nil, ErrInvalidFileFormat
+	}
+
+	if _, err := s.readEbook(ctx, bookId); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	head, err := br.Peek(epubMagicOffset + len(epubMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !matches(format, head) {
+		return nil, ErrFileMismatch
+	}
+
+	key := "ebooks/" + bookId + "." + format
+	hash := sha256.New()
+	size, err := s.storage.Put(ctx, key, io.TeeReader(br, hash))
+	if err != nil {
+		return nil, err
+	}
+
+	file := &entities.EbookFile{
+		BookId:     bookId,
+		FileFormat: format,
+		StorageKey: key,
+		Size:       size,
+		Sha256:     hex.EncodeToString(hash.Sum(nil)),
+		CreatedAt:  time.Now(),
+	}
+	if actorId != "" {
+		file.UploadedBy = &actorId
+	}
+
+	if err = s.repo.SaveFile(ctx, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (s *ebookService) ReadFiles(ctx context.Context, bookId string) ([]entities.EbookFile, error) {
+	if _, err := uuid.Parse(bookId); err != nil {
+		return nil, ErrBookNotFound
+	}
+	return s.repo.ReadFiles(ctx, bookId)
+}
+
+func (s *ebookService) DeleteFile(ctx context.Context, bookId, format string) error {
+	if _, err := uuid.Parse(bookId); err != nil {
+		return ErrFileNotFound
+	}
+
+	file, err := s.repo.ReadFile(ctx, bookId, format)
+	if err != nil {
+		return err
+	}
+	if err = s.repo.DeleteFile(ctx, bookId, format); err != nil {
+		return err
+	}
+	return s.storage.Delete(ctx, file.StorageKey)
+}
+
+// GrantPurchase gives a user an e-book bought in an order, for purchases
+// the payment did not grant by itself. The order must have a paid payment
+// of that user and the book among its lines.
+func (s *ebookService) GrantPurchase(ctx context.Context, purchase *entities.EbookPurchase) (*entities.EbookPurchase, error) {
+	for _, id := range []string{purchase.UserId, purchase.BookId, purchase.OrderId} {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, ErrInvalidPurchase
+		}
+	}
+
+	if purchase.DownloadLimit == 0 {
+		purchase.DownloadLimit = s.downloadLimit
+	}
+	if purchase.DownloadLimit < 1 {
+		return nil, ErrInvalidLimit
+	}
+
+	if _, err := s.readEbook(ctx, purchase.BookId); err != nil {
+		return nil, err
+	}
+
+	paid, err := s.repo.OrderPaid(ctx, purchase.OrderId, purchase.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !paid {
+		return nil, ErrOrderNotPaid
+	}
+
+	bought, err := s.repo.OrderHasBook(ctx, purchase.OrderId, purchase.BookId)
+	if err != nil {
+		return nil, err
+	}
+	if !bought {
+		return nil, ErrBookNotInOrder
+	}
+
+	purchase.Id = uuid.NewString()
+	purchase.Downloads = 0
+	purchase.CreatedAt = time.Now()
+
+	if err = s.repo.CreatePurchase(ctx, purchase); err != nil {
+		return nil, err
+	}
+	return purchase, nil
+}
+
+func (s *ebookService) ReadLibrary(ctx context.Context, userId string) ([]entities.EbookPurchase, error) {
+	return s.repo.ReadLibrary(ctx, userId)
+}
+
+// CreateLink returns a signed link to download a purchased e-book file. The
+// link works until it expires; every use counts against the purchase's
+// download limit.
+func (s *ebookService) CreateLink(ctx context.Context, userId, purchaseId, format string) (string, time.Time, error) {
+	if _, ok := contentTypes[format]; !ok {
+		return "", time.Time{}, ErrInvalidFileFormat
+	}
+
+	purchase, err := s.readPurchase(ctx, purchaseId)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if purchase.UserId != userId {
+		return "", time.Time{}, ErrPurchaseNotFound
+	}
+	if purchase.Downloads >= purchase.DownloadLimit {
+		return "", time.Time{}, ErrDownloadLimit
+	}
+	if !hasFormat(purchase.Files, format) {
+		return "", time.Time{}, ErrFileNotFound
+	}
+
+	expiresAt := time.Now().Add(s.linkTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.signer.sign(purchase.Id, format, expires))
+
+	link := s.publicUrl + "/ebooks/download/" + purchase.Id + "/" + format + "?" + query.Encode()
+	return link, expiresAt, nil
+}
+
+// Download checks a signed link and opens the file it names, counting and
+// recording the download. EPUB copies are stamped with the buyer's name,
+// email and order and with the id of the download; the master file is only
+// read. The caller closes the download.
+func (s *ebookService) Download(ctx context.Context, purchaseId, format string, expires int64, signature, ip string) (*Download, error) {
+	if !s.signer.verify(purchaseId, format, expires, signature) {
+		return nil, ErrInvalidLink
+	}
+	if time.Now().Unix() > expires {
+		return nil, ErrLinkExpired
+	}
+
+	purchase, err := s.readPurchase(ctx, purchaseId)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := s.repo.ReadFile(ctx, purchase.BookId, format)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.storage.Open(ctx, meta.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	download, err := s.prepare(ctx, purchase, format, file, ip)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return download, nil
+}
+
+func (s *ebookService) prepare(ctx context.Context, purchase *entities.EbookPurchase, format string, file storage.File, ip string) (*Download, error) {
+	title := "book"
+	if purchase.Book != nil {
+		title = purchase.Book.Title
+	}
+
+	record := &entities.EbookDownload{
+		Id:         uuid.NewString(),
+		PurchaseId: purchase.Id,
+		FileFormat: format,
+		Ip:         ip,
+		CreatedAt:  time.Now(),
+	}
+
+	download := &Download{
+		Name:        title + "." + format,
+		ContentType: contentTypes[format],
+		Size:        file.Size(),
+		file:        file,
+		write: func(w io.Writer) error {
+			_, err := io.Copy(w, file)
+			return err
+		},
+	}
+
+	if format == FormatEpub {
+		// Opened before the download is counted, so a broken master
+		// costs the buyer nothing.
+		book, err := epub.Open(file, file.Size())
+		if err != nil {
+			if errors.Is(err, epub.ErrInvalid) {
+				return nil, ErrDamagedFile
+			}
+			return nil, err
+		}
+
+		user, err := s.repo.ReadUser(ctx, purchase.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		mark := epub.Mark{
+			Id:      record.Id,
+			Name:    strings.TrimSpace(user.Name + " " + user.Surname),
+			Email:   user.Email,
+			OrderId: purchase.OrderId,
+			Time:    record.CreatedAt,
+		}
+		download.Size = -1
+		download.write = func(w io.Writer) error {
+			return book.Watermark(w, mark)
+		}
+	}
+
+	if err := s.repo.UseDownload(ctx, record); err != nil {
+		return nil, err
+	}
+	return download, nil
+}
+
+// IdentifyCopy finds the downloads whose watermarks are in an EPUB, and so
+// who bought the copy it was made from.
+func (s *ebookService) IdentifyCopy(ctx context.Context, data []byte) ([]entities.EbookDownload, error) {
+	ids, err := epub.Identify(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		switch {
+		case errors.Is(err, epub.ErrInvalid):
+			return nil, ErrNotEpub
+		case errors.Is(err, epub.ErrNoWatermark):
+			return nil, ErrNoWatermark
+		}
+		return nil, err
+	}
+
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, err = uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, ErrNoWatermark
+	}
+
+	downloads, err := s.repo.ReadDownloads(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+	if len(downloads) == 0 {
+		return nil, ErrDownloadNotFound
+	}
+	return downloads, nil
+}
+
+func (s *ebookService) readEbook(ctx context.Context, bookId string) (*entities.Book, error) {
+	if _, err := uuid.Parse(bookId); err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	book, err := s.repo.ReadBook(ctx, bookId)
+	if err != nil {
+		return nil, err
+	}
+	if book.Format == nil || *book.Format != entities.FormatEbook {
+		return nil, ErrNotEbook
+	}
+	return book, nil
+}
+
+func (s *ebookService) readPurchase(ctx context.Context, id string) (*entities.EbookPurchase, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrPurchaseNotFound
+	}
+	return s.repo.ReadPurchase(ctx, id)
+}
+
+func matches(format string, head []byte) bool {
+	switch format {
+	case FormatEpub:
+		return bytes.HasPrefix(head, zipMagic) &&
+			len(head) >= epubMagicOffset+len(epubMagic) &&
+			bytes.Equal(head[epubMagicOffset:epubMagicOffset+len(epubMagic)], epubMagic)
+	case FormatPdf:
+		return bytes.HasPrefix(head, pdfMagic)
+	}
+	return false
+}
+
+func hasFormat(files []entities.EbookFile, format string) bool {
+	for _, file := range files {
+		if file.FileFormat == format {
+			return true
+		}
+	}
+	return false
+}
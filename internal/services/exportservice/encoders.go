@@ -0,0 +1,174 @@
+package exportservice
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"story-book/internal/dto"
+	"story-book/internal/isbn"
+	"strconv"
+	"time"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	datetimeLayout = time.RFC3339
+)
+
+var csvHeader = []string{
+	"id", "isbn_13", "isbn_10", "title", "author", "publisher", "publisher_id", "work_id", "year",
+	"cost", "discount", "price", "currency", "format", "language", "pages", "amount", "tax_category",
+	"release_date", "preorder", "description", "updated_at", "deleted",
+}
+
+type csvEncoder struct {
+	w        *csv.Writer
+	currency string
+}
+
+// newCsvEncoder writes one book per row under a header of field names, the
+// same names POST /imports recognises.
+func newCsvEncoder(w io.Writer, currency string) encoder {
+	return &csvEncoder{w: csv.NewWriter(w), currency: currency}
+}
+
+func (e *csvEncoder) Begin() error {
+	return e.w.Write(csvHeader)
+}
+
+func (e *csvEncoder) Write(b *book) error {
+	isbn13, isbn10 := isbns(b.Isbn)
+	return e.w.Write([]string{
+		b.Id,
+		isbn13,
+		isbn10,
+		b.Title,
+		b.Author,
+		b.Publisher,
+		value(b.PublisherId),
+		b.WorkId,
+		strconv.Itoa(b.Year),
+		strconv.FormatFloat(b.Cost, 'f', 2, 64),
+		number(b.Discount),
+		b.price.String(),
+		e.currency,
+		value(b.Format),
+		value(b.Language),
+		number(b.Pages),
+		strconv.Itoa(b.Amount),
+		b.TaxCategory,
+		date(b.ReleaseDate),
+		strconv.FormatBool(b.Preorder != nil && *b.Preorder),
+		value(b.Description),
+		b.UpdatedAt.UTC().Format(datetimeLayout),
+		strconv.FormatBool(b.DeletedAt != nil),
+	})
+}
+
+func (e *csvEncoder) End() error {
+	return nil
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlEncoder struct {
+	w        *json.Encoder
+	currency string
+}
+
+// newJsonlEncoder writes one dto.ExportBookResponse per line.
+func newJsonlEncoder(w io.Writer, currency string) encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonlEncoder{w: enc, currency: currency}
+}
+
+func (e *jsonlEncoder) Begin() error {
+	return nil
+}
+
+func (e *jsonlEncoder) Write(b *book) error {
+	isbn13, isbn10 := isbns(b.Isbn)
+	credits := b.credits
+	if credits == nil {
+		credits = []dto.BookAuthorResponse{}
+	}
+	return e.w.Encode(dto.ExportBookResponse{
+		Id:          b.Id,
+		Title:       b.Title,
+		Isbn13:      isbn13,
+		Isbn10:      isbn10,
+		Author:      b.Author,
+		Authors:     credits,
+		Publisher:   b.Publisher,
+		PublisherId: value(b.PublisherId),
+		WorkId:      b.WorkId,
+		Year:        b.Year,
+		Cost:        b.Cost,
+		Discount:    zero(b.Discount),
+		Currency:    e.currency,
+		Price:       b.price,
+		Format:      value(b.Format),
+		Language:    value(b.Language),
+		Pages:       zero(b.Pages),
+		Description: value(b.Description),
+		Amount:      b.Amount,
+		TaxCategory: b.TaxCategory,
+		ReleaseDate: date(b.ReleaseDate),
+		Preorder:    b.Preorder != nil && *b.Preorder,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+		Deleted:     b.DeletedAt != nil,
+	})
+}
+
+func (e *jsonlEncoder) End() error {
+	return nil
+}
+
+func (e *jsonlEncoder) Flush() error {
+	return nil
+}
+
+// isbns returns the ISBN-13 and, for 978 numbers, the ISBN-10 of a book.
+func isbns(number *string) (string, string) {
+	if number == nil {
+		return "", ""
+	}
+	isbn10, err := isbn.To10(*number)
+	if err != nil {
+		return *number, ""
+	}
+	return *number, isbn10
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func number(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func zero(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+func date(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
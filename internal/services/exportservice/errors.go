@@ -0,0 +1,10 @@
+package exportservice
+
+import "errors"
+
+var (
+	ErrInvalidFormat = errors.New("format must be csv, jsonl or onix")
+	ErrInvalidSince  = errors.New("since must be an RFC 3339 timestamp")
+	ErrInvalidFilter = errors.New("author_id and publisher_id must be UUIDs")
+	ErrAccessDenied  = errors.New("access denied")
+)
@@ -0,0 +1,117 @@
+package exportservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"story-book/internal/dto"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderNextSince carries the since timestamp for the next incremental
+// export.
+const HeaderNextSince = "X-Next-Since"
+
+type ExportService interface {
+	Export(ctx context.Context, format string, filter Filter, w io.Writer) (int, error)
+}
+
+type ExportHandler struct {
+	service ExportService
+}
+
+func NewExportHandler(service ExportService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+// ExportBooks
+// @Summary Выгрузить каталог в CSV, JSON Lines или ONIX 3.0
+// @Description Книги выгружаются потоком в порядке изменения. С since выгружаются только книги, изменённые с этого момента, включая удалённые: в CSV и JSON Lines они помечены deleted, в ONIX — NotificationType 05. Значение since для следующей выгрузки — в заголовке X-Next-Since. В ONIX цена — без скидок акций, PriceType 02, если налог включён в цену
+// @Tags exports
+// @Security BearerAuth
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/xml
+// @Param format query string true "Формат: csv, jsonl или onix"
+// @Param since query string false "Только изменения с этого момента (RFC3339)"
+// @Param author_id query string false "ID автора"
+// @Param publisher_id query string false "ID издательства"
+// @Param book_format query string false "Формат издания: hardcover, paperback, ebook или audiobook"
+// @Param language query string false "Язык книги (ISO 639-1)"
+// @Param in_stock query bool false "Только книги в наличии"
+// @Success 200 {file} file
+// @Header 200 {string} X-Next-Since "since для следующей выгрузки"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /exports/books [get]
+func (h *ExportHandler) ExportBooks(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	format := c.QueryParam("format")
+	contentType, extension, err := FileType(format)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	filter := Filter{
+		AuthorId:    c.QueryParam("author_id"),
+		PublisherId: c.QueryParam("publisher_id"),
+		Format:      c.QueryParam("book_format"),
+		Language:    c.QueryParam("language"),
+	}
+
+	if sinceStr := c.QueryParam("since"); sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidSince.Error()})
+		}
+		filter.Since = &since
+	}
+
+	if inStockStr := c.QueryParam("in_stock"); inStockStr != "" {
+		if filter.InStock, err = strconv.ParseBool(inStockStr); err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid in_stock"})
+		}
+	}
+
+	start := time.Now()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, contentType)
+	header.Set(echo.HeaderContentDisposition, `attachment; filename="books-`+start.Format("20060102-150405")+`.`+extension+`"`)
+	header.Set(HeaderNextSince, NextSince(start).Format(time.RFC3339))
+
+	// No timeout: a full catalogue takes as long as the client takes to
+	// read it, and a client that goes away cancels the request.
+	if _, err = h.service.Export(c.Request().Context(), format, filter, c.Response()); err != nil {
+		// Once the first books are out the status cannot change; the
+		// client sees a truncated file.
+		if c.Response().Committed {
+			return err
+		}
+		header.Del(echo.HeaderContentType)
+		header.Del(echo.HeaderContentDisposition)
+		header.Del(HeaderNextSince)
+		return exportError(c, err)
+	}
+
+	return nil
+}
+
+func exportError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidFormat), errors.Is(err, ErrInvalidFilter):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+}
@@ -0,0 +1,320 @@
+package exportservice
+
+import (
+	"encoding/xml"
+	"io"
+	"story-book/internal/entities"
+	"strconv"
+	"time"
+)
+
+// ONIX for Books 3.0, reference tags. Codes are from the EDItEUR code lists;
+// the list number is given next to each.
+const (
+	onixNamespace = "http://ns.editeur.org/onix/3.0/reference"
+	onixRelease   = "3.0"
+	onixSentTime  = "20060102T150405Z"
+)
+
+// productForms maps book formats to list 150.
+var productForms = map[string]string{
+	"hardcover": "BB",
+	"paperback": "BC",
+	"ebook":     "ED",
+	"audiobook": "AJ",
+}
+
+// unknownForm is a book of unspecified binding.
+const unknownForm = "BA"
+
+// contributorRoles maps credit roles to list 17.
+var contributorRoles = map[string]string{
+	"author":      "A01",
+	"translator":  "B06",
+	"illustrator": "A12",
+}
+
+// languageCodes maps the ISO 639-1 codes of books to the ISO 639-2/B codes
+// of list 74. Books in other languages are sent without one.
+var languageCodes = map[string]string{
+	"ru": "rus",
+	"en": "eng",
+	"uk": "ukr",
+	"be": "bel",
+	"kk": "kaz",
+	"de": "ger",
+	"fr": "fre",
+	"es": "spa",
+	"it": "ita",
+	"pl": "pol",
+	"pt": "por",
+	"zh": "chi",
+	"ja": "jpn",
+}
+
+type onixHeader struct {
+	XMLName      xml.Name `xml:"Header"`
+	SenderName   string   `xml:"Sender>SenderName"`
+	SentDateTime string   `xml:"SentDateTime"`
+}
+
+type onixProduct struct {
+	XMLName            xml.Name                `xml:"Product"`
+	RecordReference    string                  `xml:"RecordReference"`
+	NotificationType   string                  `xml:"NotificationType"`
+	ProductIdentifiers []onixProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  *onixDescriptiveDetail  `xml:"DescriptiveDetail,omitempty"`
+	CollateralDetail   *onixCollateralDetail   `xml:"CollateralDetail,omitempty"`
+	PublishingDetail   *onixPublishingDetail   `xml:"PublishingDetail,omitempty"`
+	ProductSupply      *onixProductSupply      `xml:"ProductSupply,omitempty"`
+}
+
+type onixProductIdentifier struct {
+	ProductIdType string `xml:"ProductIDType"`
+	IdValue       string `xml:"IDValue"`
+}
+
+type onixDescriptiveDetail struct {
+	ProductComposition string            `xml:"ProductComposition"`
+	ProductForm        string            `xml:"ProductForm"`
+	TitleType          string            `xml:"TitleDetail>TitleType"`
+	TitleElement       onixTitleElement  `xml:"TitleDetail>TitleElement"`
+	Contributors       []onixContributor `xml:"Contributor"`
+	Language           *onixLanguage     `xml:"Language,omitempty"`
+	Extent             *onixExtent       `xml:"Extent,omitempty"`
+}
+
+type onixTitleElement struct {
+	TitleElementLevel string `xml:"TitleElementLevel"`
+	TitleText         string `xml:"TitleText"`
+}
+
+type onixContributor struct {
+	SequenceNumber  int    `xml:"SequenceNumber"`
+	ContributorRole string `xml:"ContributorRole"`
+	PersonName      string `xml:"PersonName"`
+}
+
+type onixLanguage struct {
+	LanguageRole string `xml:"LanguageRole"`
+	LanguageCode string `xml:"LanguageCode"`
+}
+
+type onixExtent struct {
+	ExtentType  string `xml:"ExtentType"`
+	ExtentValue int    `xml:"ExtentValue"`
+	ExtentUnit  string `xml:"ExtentUnit"`
+}
+
+type onixCollateralDetail struct {
+	TextType        string `xml:"TextContent>TextType"`
+	ContentAudience string `xml:"TextContent>ContentAudience"`
+	Text            string `xml:"TextContent>Text"`
+}
+
+type onixPublishingDetail struct {
+	PublishingRole   string              `xml:"Publisher>PublishingRole"`
+	PublisherName    string              `xml:"Publisher>PublisherName"`
+	PublishingStatus string              `xml:"PublishingStatus"`
+	PublishingDate   *onixPublishingDate `xml:"PublishingDate,omitempty"`
+}
+
+type onixPublishingDate struct {
+	PublishingDateRole string   `xml:"PublishingDateRole"`
+	Date               onixDate `xml:"Date"`
+}
+
+type onixDate struct {
+	DateFormat string `xml:"dateformat,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type onixProductSupply struct {
+	SupplyDetail onixSupplyDetail `xml:"SupplyDetail"`
+}
+
+type onixSupplyDetail struct {
+	SupplierRole        string     `xml:"Supplier>SupplierRole"`
+	SupplierName        string     `xml:"Supplier>SupplierName"`
+	ProductAvailability string     `xml:"ProductAvailability"`
+	Stock               *onixStock `xml:"Stock,omitempty"`
+	Price               onixPrice  `xml:"Price"`
+}
+
+type onixStock struct {
+	OnHand int `xml:"OnHand"`
+}
+
+type onixPrice struct {
+	PriceType    string `xml:"PriceType"`
+	PriceAmount  string `xml:"PriceAmount"`
+	CurrencyCode string `xml:"CurrencyCode"`
+}
+
+type onixEncoder struct {
+	w        io.Writer
+	enc      *xml.Encoder
+	currency string
+	sender   string
+	now      time.Time
+}
+
+// newOnixEncoder writes an ONIX 3.0 message with one Product per book.
+func newOnixEncoder(w io.Writer, currency, sender string) encoder {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &onixEncoder{w: w, enc: enc, currency: currency, sender: sender, now: time.Now()}
+}
+
+func (e *onixEncoder) Begin() error {
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+
+	start := xml.StartElement{
+		Name: xml.Name{Local: "ONIXMessage"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "release"}, Value: onixRelease},
+			{Name: xml.Name{Local: "xmlns"}, Value: onixNamespace},
+		},
+	}
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	return e.enc.Encode(onixHeader{
+		SenderName:   e.sender,
+		SentDateTime: e.now.UTC().Format(onixSentTime),
+	})
+}
+
+func (e *onixEncoder) Write(b *book) error {
+	product := onixProduct{
+		RecordReference:  b.Id,
+		NotificationType: "03", // list 1: confirmed
+		ProductIdentifiers: []onixProductIdentifier{
+			{ProductIdType: "01", IdValue: b.Id}, // list 5: proprietary
+		},
+	}
+	if b.Isbn != nil {
+		product.ProductIdentifiers = append(product.ProductIdentifiers, onixProductIdentifier{ProductIdType: "15", IdValue: *b.Isbn})
+	}
+
+	// A deletion names the product and nothing else.
+	if b.DeletedAt != nil {
+		product.NotificationType = "05"
+		return e.enc.Encode(product)
+	}
+
+	product.DescriptiveDetail = e.descriptive(b)
+	if b.Description != nil && *b.Description != "" {
+		// List 153: description; list 154: any audience.
+		product.CollateralDetail = &onixCollateralDetail{TextType: "03", ContentAudience: "00", Text: *b.Description}
+	}
+	product.PublishingDetail = e.publishing(b)
+	product.ProductSupply = e.supply(b)
+
+	return e.enc.Encode(product)
+}
+
+func (e *onixEncoder) descriptive(b *book) *onixDescriptiveDetail {
+	form, ok := productForms[value(b.Format)]
+	if !ok {
+		form = unknownForm
+	}
+
+	detail := &onixDescriptiveDetail{
+		ProductComposition: "00", // list 2: single item
+		ProductForm:        form,
+		TitleType:          "01", // list 15: distinctive title
+		TitleElement:       onixTitleElement{TitleElementLevel: "01", TitleText: b.Title},
+	}
+
+	for i, credit := range b.credits {
+		role, ok := contributorRoles[credit.Role]
+		if !ok {
+			continue
+		}
+		detail.Contributors = append(detail.Contributors, onixContributor{SequenceNumber: i + 1, ContributorRole: role, PersonName: credit.Name})
+	}
+	if len(detail.Contributors) == 0 && b.Author != "" {
+		detail.Contributors = []onixContributor{{SequenceNumber: 1, ContributorRole: "A01", PersonName: b.Author}}
+	}
+
+	if code, ok := languageCodes[value(b.Language)]; ok {
+		// List 22: language of text.
+		detail.Language = &onixLanguage{LanguageRole: "01", LanguageCode: code}
+	}
+	if b.Pages != nil && *b.Pages > 0 {
+		// List 23: main content page count; list 24: pages.
+		detail.Extent = &onixExtent{ExtentType: "00", ExtentValue: *b.Pages, ExtentUnit: "03"}
+	}
+	return detail
+}
+
+func (e *onixEncoder) publishing(b *book) *onixPublishingDetail {
+	// List 45: publisher; list 64: active or forthcoming.
+	detail := &onixPublishingDetail{PublishingRole: "01", PublisherName: b.Publisher, PublishingStatus: "04"}
+	if b.ReleaseDate != nil && b.ReleaseDate.After(e.now) {
+		detail.PublishingStatus = "02"
+	}
+
+	// List 163: publication date; list 55: YYYYMMDD or YYYY.
+	switch {
+	case b.ReleaseDate != nil:
+		detail.PublishingDate = &onixPublishingDate{PublishingDateRole: "01", Date: onixDate{DateFormat: "00", Value: b.ReleaseDate.Format("20060102")}}
+	case b.Year > 0:
+		detail.PublishingDate = &onixPublishingDate{PublishingDateRole: "01", Date: onixDate{DateFormat: "05", Value: strconv.Itoa(b.Year)}}
+	}
+	return detail
+}
+
+func (e *onixEncoder) supply(b *book) *onixProductSupply {
+	// List 65: in stock, not yet available or out of stock.
+	availability := "31"
+	switch {
+	case ebook(b):
+		availability = "21"
+	case b.Amount > 0:
+		availability = "21"
+	case b.Preorder != nil && *b.Preorder:
+		availability = "10"
+	}
+
+	// List 58: recommended retail price excluding or including tax.
+	priceType := "01"
+	if b.taxIncluded {
+		priceType = "02"
+	}
+
+	supply := &onixProductSupply{SupplyDetail: onixSupplyDetail{
+		SupplierRole:        "00", // list 93: unspecified
+		SupplierName:        e.sender,
+		ProductAvailability: availability,
+		Price:               onixPrice{PriceType: priceType, PriceAmount: b.price.String(), CurrencyCode: e.currency},
+	}}
+	// E-books have no stock to report.
+	if !ebook(b) {
+		supply.SupplyDetail.Stock = &onixStock{OnHand: b.Amount}
+	}
+	return supply
+}
+
+func ebook(b *book) bool {
+	return b.Format != nil && *b.Format == entities.FormatEbook
+}
+
+func (e *onixEncoder) End() error {
+	if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "ONIXMessage"}}); err != nil {
+		return err
+	}
+	if err := e.enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+func (e *onixEncoder) Flush() error {
+	return e.enc.Flush()
+}
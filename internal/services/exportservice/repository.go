@@ -0,0 +1,77 @@
+package exportservice
+
+import (
+	"context"
+	"story-book/internal/entities"
+
+	"gorm.io/gorm"
+)
+
+// columns are those of books without image_data, so that a full export holds
+// one row at a time and no cover images. Credits come as a JSON array in
+// position order.
+const columns = `books.id, books.isbn, books.title, books.author, books.year, books.cost, books.discount,
+	books.publisher, books.publisher_id, books.work_id, books.format, books.language, books.pages,
+	books.description, books.amount, books.tax_category, books.release_date, books.preorder,
+	books.created_at, books.updated_at, books.deleted_at,
+	(select json_agg(json_build_object('id', a.id, 'name', a.name, 'role', ba.role) order by ba.position, a.name)::text
+	 from book_authors ba join authors a on a.id = ba.author_id
+	 where ba.book_id = books.id) as credits`
+
+type exportRepository struct {
+	db *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+// Stream calls fn for every book matching the filter, oldest change first,
+// reading rows from the database as fn consumes them.
+func (r *exportRepository) Stream(ctx context.Context, filter Filter, fn func(row *Row) error) error {
+	query := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Table("books").
+		Select(columns)
+
+	if filter.Since != nil {
+		// Soft deletion is an update too, so books deleted since then are
+		// included and reported as deleted.
+		query = query.Where("books.updated_at >= ?", *filter.Since)
+	} else {
+		query = query.Where("books.deleted_at IS NULL")
+	}
+	if filter.AuthorId != "" {
+		query = query.Where("books.id IN (SELECT book_id FROM book_authors WHERE author_id = ?)", filter.AuthorId)
+	}
+	if filter.PublisherId != "" {
+		query = query.Where("books.publisher_id = ?", filter.PublisherId)
+	}
+	if filter.Format != "" {
+		query = query.Where("books.format = ?", filter.Format)
+	}
+	if filter.Language != "" {
+		query = query.Where("books.language = ?", filter.Language)
+	}
+	if filter.InStock {
+		query = query.Where("(books.amount > 0 OR books.format = ?)", entities.FormatEbook)
+	}
+
+	rows, err := query.Order("books.updated_at, books.id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row Row
+		if err = r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err = fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
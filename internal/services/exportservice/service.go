@@ -0,0 +1,244 @@
+package exportservice
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FormatCsv   = "csv"
+	FormatJsonl = "jsonl"
+	FormatOnix  = "onix"
+)
+
+// flushEvery is how many books are written between flushes to the client.
+const flushEvery = 100
+
+// overlap is taken off the next since timestamp so that books changed by
+// transactions still running when an export starts are not missed. Feeds
+// may see such books twice.
+const overlap = time.Minute
+
+type fileType struct {
+	contentType string
+	extension   string
+}
+
+var fileTypes = map[string]fileType{
+	FormatCsv:   {contentType: "text/csv; charset=utf-8", extension: "csv"},
+	FormatJsonl: {contentType: "application/x-ndjson", extension: "jsonl"},
+	FormatOnix:  {contentType: "application/xml; charset=utf-8", extension: "xml"},
+}
+
+// Filter narrows an export. With Since set only books changed at or after it
+// are exported, deleted ones included.
+type Filter struct {
+	AuthorId    string
+	PublisherId string
+	Format      string
+	Language    string
+	InStock     bool
+	Since       *time.Time
+}
+
+// Row is a book as read for export, without its cover image.
+type Row struct {
+	Id          string
+	Isbn        *string
+	Title       string
+	Author      string
+	Year        int
+	Cost        float64
+	Discount    *int
+	Publisher   string
+	PublisherId *string
+	WorkId      string
+	Format      *string
+	Language    *string
+	Pages       *int
+	Description *string
+	Amount      int
+	TaxCategory string
+	ReleaseDate *time.Time
+	Preorder    *bool
+	Credits     *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   *time.Time
+}
+
+type ExportRepository interface {
+	Stream(ctx context.Context, filter Filter, fn func(row *Row) error) error
+}
+
+// Taxes tells ONIX feeds whether prices include tax.
+type Taxes interface {
+	ReadRules(ctx context.Context, region string) ([]entities.TaxRule, error)
+}
+
+// encoder writes books in one file format.
+type encoder interface {
+	Begin() error
+	Write(book *book) error
+	End() error
+	Flush() error
+}
+
+// book is a row ready for encoding.
+type book struct {
+	*Row
+	credits []dto.BookAuthorResponse
+	price   pricing.Money
+	// taxIncluded tells whether price includes the tax of the book's
+	// category.
+	taxIncluded bool
+}
+
+type exportService struct {
+	repo     ExportRepository
+	taxes    Taxes
+	engine   pricing.Engine
+	region   string
+	currency string
+	sender   string
+}
+
+// NewExportService exports the catalogue priced in currency. ONIX feeds name
+// sender as the store and state prices by the tax rules of region.
+func NewExportService(repo ExportRepository, taxes Taxes, engine pricing.Engine, region, currency, sender string) ExportService {
+	return &exportService{repo: repo, taxes: taxes, engine: engine, region: region, currency: currency, sender: sender}
+}
+
+// FileType returns the content type and file extension of an export format.
+func FileType(format string) (string, string, error) {
+	t, ok := fileTypes[format]
+	if !ok {
+		return "", "", ErrInvalidFormat
+	}
+	return t.contentType, t.extension, nil
+}
+
+// NextSince returns the since timestamp for the export following one that
+// started at start.
+func NextSince(start time.Time) time.Time {
+	return start.Add(-overlap).Truncate(time.Second)
+}
+
+// Export writes the books matching the filter to w, flushing it every few
+// books if it can be flushed. It returns the number of books written.
+func (s *exportService) Export(ctx context.Context, format string, filter Filter, w io.Writer) (int, error) {
+	if _, _, err := FileType(format); err != nil {
+		return 0, err
+	}
+	if err := checkFilter(filter); err != nil {
+		return 0, err
+	}
+
+	inclusive, err := s.inclusive(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	buf := bufio.NewWriter(w)
+	var enc encoder
+	switch format {
+	case FormatCsv:
+		enc = newCsvEncoder(buf, s.currency)
+	case FormatJsonl:
+		enc = newJsonlEncoder(buf, s.currency)
+	case FormatOnix:
+		enc = newOnixEncoder(buf, s.currency, s.sender)
+	}
+
+	flush := func() error {
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+		if err := buf.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	if err = enc.Begin(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = s.repo.Stream(ctx, filter, func(row *Row) error {
+		b := &book{Row: row, price: s.price(row), taxIncluded: inclusive[row.TaxCategory]}
+		if row.Credits != nil {
+			if err := json.Unmarshal([]byte(*row.Credits), &b.credits); err != nil {
+				return err
+			}
+		}
+
+		if err := enc.Write(b); err != nil {
+			return err
+		}
+		count++
+		if count%flushEvery == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err = enc.End(); err != nil {
+		return count, err
+	}
+	return count, flush()
+}
+
+func checkFilter(filter Filter) error {
+	for _, id := range []string{filter.AuthorId, filter.PublisherId} {
+		if id == "" {
+			continue
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			return ErrInvalidFilter
+		}
+	}
+	return nil
+}
+
+// price is the cost less the book's own discount. Promotions are left out:
+// feeds are read long after they are written.
+func (s *exportService) price(row *Row) pricing.Money {
+	price := pricing.FromFloat(row.Cost)
+	if row.Discount != nil && *row.Discount > 0 {
+		price -= s.engine.Discount(price, pricing.DiscountPercent, float64(*row.Discount))
+	}
+	return price
+}
+
+// inclusive tells by tax category whether the rule in force at now includes
+// tax in catalogue prices.
+func (s *exportService) inclusive(ctx context.Context, now time.Time) (map[string]bool, error) {
+	rules, err := s.taxes.ReadRules(ctx, s.region)
+	if err != nil {
+		return nil, err
+	}
+
+	inclusive := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if now.Before(rule.EffectiveFrom) || (rule.EffectiveTo != nil && !now.Before(*rule.EffectiveTo)) {
+			continue
+		}
+		inclusive[rule.Category] = rule.Inclusive
+	}
+	return inclusive, nil
+}
@@ -0,0 +1,23 @@
+package giftcardservice
+
+import "errors"
+
+var (
+	ErrCardNotFound        = errors.New("gift card not found")
+	ErrCardExpired         = errors.New("gift card has expired")
+	ErrInsufficientBalance = errors.New("gift card balance is insufficient")
+	ErrCurrencyMismatch    = errors.New("currency does not match the gift card")
+	ErrInvalidAmount       = errors.New("amount must be positive")
+	ErrInvalidCurrency     = errors.New("currency must be a three-letter ISO 4217 code")
+	ErrInvalidExpiry       = errors.New("expiry must be in the future")
+	ErrInvalidOrder        = errors.New("order_id is required")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrOrderNotPending     = errors.New("order is not awaiting payment")
+	ErrOrderCovered        = errors.New("order is already covered")
+	ErrGiftCardOrder       = errors.New("gift cards cannot pay for gift card orders")
+	ErrNotGiftCardOrder    = errors.New("payment is not for a gift card order")
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrPaymentNotPaid      = errors.New("only paid payments can buy a gift card")
+	ErrPaymentUsed         = errors.New("payment has already bought a gift card")
+	ErrAccessDenied        = errors.New("access denied")
+)
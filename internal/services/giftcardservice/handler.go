@@ -0,0 +1,255 @@
+package giftcardservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type GiftCardService interface {
+	IssueCard(ctx context.Context, actorId string, amount pricing.Money, currency string, expiresAt *time.Time) (*entities.GiftCard, error)
+	PurchaseCard(ctx context.Context, userId, paymentId string) (*entities.GiftCard, error)
+	ReadCard(ctx context.Context, userId, role, code string) (*entities.GiftCard, []entities.GiftCardTransaction, error)
+	Redeem(ctx context.Context, userId, code string, amount *pricing.Money, orderId string) (*entities.GiftCard, *entities.GiftCardTransaction, error)
+	ReadStoreCredit(ctx context.Context, userId string) ([]entities.GiftCard, error)
+	CreditReturn(ctx context.Context, userId, returnId string, amount pricing.Money, currency string) error
+}
+
+type GiftCardHandler struct {
+	service GiftCardService
+}
+
+func NewGiftCardHandler(service GiftCardService) *GiftCardHandler {
+	return &GiftCardHandler{service: service}
+}
+
+// IssueCard
+// @Summary Выпустить подарочную карту
+// @Tags gift-cards
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.GiftCardIssueRequest true "Номинал, валюта и срок действия"
+// @Success 201 {object} dto.GiftCardResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /gift-cards [post]
+func (h *GiftCardHandler) IssueCard(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.GiftCardIssueRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	card, err := h.service.IssueCard(ctx, c.Get("id").(string), request.Amount, request.Currency, request.ExpiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidAmount), errors.Is(err, ErrInvalidCurrency), errors.Is(err, ErrInvalidExpiry):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toCardResponse(card, nil))
+}
+
+// PurchaseCard
+// @Summary Купить подарочную карту
+// @Description Карта выпускается по оплаченному заказу подарочной карты (POST /orders/gift-card). Номинал — оплаченная сумма за вычетом возвратов, валюта — валюта платежа
+// @Tags gift-cards
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.GiftCardPurchaseRequest true "Платёж"
+// @Success 201 {object} dto.GiftCardResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /gift-cards/purchase [post]
+func (h *GiftCardHandler) PurchaseCard(c echo.Context) error {
+	var request dto.GiftCardPurchaseRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	card, err := h.service.PurchaseCard(ctx, c.Get("id").(string), request.PaymentId)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPaymentNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrPaymentNotPaid), errors.Is(err, ErrPaymentUsed), errors.Is(err, ErrNotGiftCardOrder):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toCardResponse(card, nil))
+}
+
+// ReadCard
+// @Summary Получить подарочную карту по коду
+// @Description История операций видна сотрудникам, покупателю и владельцу карты
+// @Tags gift-cards
+// @Security BearerAuth
+// @Param code path string true "Код карты"
+// @Produce json
+// @Success 200 {object} dto.GiftCardResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /gift-cards/{code} [get]
+func (h *GiftCardHandler) ReadCard(c echo.Context) error {
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	card, transactions, err := h.service.ReadCard(ctx, userId, role, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, ErrCardNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toCardResponse(card, transactions))
+}
+
+// Redeem
+// @Summary Списать средства с подарочной карты
+// @Description Списывается в счёт неоплаченного заказа покупателя в валюте заказа, не больше остатка к оплате. Без суммы списывается весь остаток карты. При отмене заказа средства возвращаются на карту
+// @Tags gift-cards
+// @Security BearerAuth
+// @Param code path string true "Код карты"
+// @Accept json
+// @Produce json
+// @Param request body dto.GiftCardRedeemRequest true "Сумма и заказ"
+// @Success 200 {object} dto.GiftCardRedeemResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /gift-cards/{code}/redeem [post]
+func (h *GiftCardHandler) Redeem(c echo.Context) error {
+	var request dto.GiftCardRedeemRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	card, transaction, err := h.service.Redeem(ctx, c.Get("id").(string), c.Param("code"), request.Amount, request.OrderId)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCardNotFound), errors.Is(err, ErrOrderNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrCardExpired),
+			errors.Is(err, ErrInsufficientBalance),
+			errors.Is(err, ErrCurrencyMismatch),
+			errors.Is(err, ErrOrderNotPending),
+			errors.Is(err, ErrOrderCovered),
+			errors.Is(err, ErrGiftCardOrder):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidAmount), errors.Is(err, ErrInvalidOrder):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, dto.GiftCardRedeemResponse{
+		Redeemed:    -pricing.FromFloat(transaction.Amount),
+		Balance:     pricing.FromFloat(card.Balance),
+		Currency:    card.Currency,
+		Transaction: toTransactionResponse(transaction),
+	})
+}
+
+// ReadStoreCredit
+// @Summary Получить средства на внутреннем счёте магазина
+// @Description Один счёт на каждую валюту; код счёта списывается через POST /gift-cards/{code}/redeem
+// @Tags gift-cards
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.GiftCardResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /store-credit [get]
+func (h *GiftCardHandler) ReadStoreCredit(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cards, err := h.service.ReadStoreCredit(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.GiftCardResponse, 0, len(cards))
+	for i := range cards {
+		response = append(response, toCardResponse(&cards[i], nil))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func toCardResponse(card *entities.GiftCard, transactions []entities.GiftCardTransaction) dto.GiftCardResponse {
+	response := dto.GiftCardResponse{
+		Id:             card.Id,
+		Code:           card.Code,
+		Kind:           card.Kind,
+		InitialBalance: pricing.FromFloat(card.InitialBalance),
+		Balance:        pricing.FromFloat(card.Balance),
+		Currency:       card.Currency,
+		ExpiresAt:      card.ExpiresAt,
+		CreatedAt:      card.CreatedAt,
+	}
+
+	for i := range transactions {
+		response.Transactions = append(response.Transactions, toTransactionResponse(&transactions[i]))
+	}
+
+	return response
+}
+
+func toTransactionResponse(transaction *entities.GiftCardTransaction) dto.GiftCardTransactionResponse {
+	response := dto.GiftCardTransactionResponse{
+		Id:           transaction.Id,
+		Kind:         transaction.Kind,
+		Amount:       pricing.FromFloat(transaction.Amount),
+		BalanceAfter: pricing.FromFloat(transaction.BalanceAfter),
+		CreatedAt:    transaction.CreatedAt,
+	}
+
+	if transaction.OrderId != nil {
+		response.OrderId = *transaction.OrderId
+	}
+	if transaction.ReturnId != nil {
+		response.ReturnId = *transaction.ReturnId
+	}
+
+	return response
+}
@@ -0,0 +1,266 @@
+package giftcardservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"story-book/internal/services/orderservice"
+	"story-book/internal/services/paymentservice"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uniqueViolationCode = "23505"
+
+type giftCardRepository struct {
+	db *gorm.DB
+}
+
+func NewGiftCardRepository(db *gorm.DB) GiftCardRepository {
+	return &giftCardRepository{db: db}
+}
+
+// Create stores a new card together with the transaction that funded it.
+func (r *giftCardRepository) Create(ctx context.Context, card *entities.GiftCard, transaction *entities.GiftCardTransaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(card).Error; err != nil {
+			if isUniqueViolation(err) && card.PaymentId != nil {
+				return ErrPaymentUsed
+			}
+			return err
+		}
+
+		return tx.Create(transaction).Error
+	})
+}
+
+func (r *giftCardRepository) ReadByCode(ctx context.Context, code string) (*entities.GiftCard, error) {
+	return readCard(r.db.WithContext(ctx), code)
+}
+
+func (r *giftCardRepository) ReadTransactions(ctx context.Context, cardId string) ([]entities.GiftCardTransaction, error) {
+	var transactions []entities.GiftCardTransaction
+	if err := r.db.
+		WithContext(ctx).
+		Where("gift_card_id = ?", cardId).
+		Order("created_at").
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func (r *giftCardRepository) ReadCredits(ctx context.Context, userId string) ([]entities.GiftCard, error) {
+	var cards []entities.GiftCard
+	if err := r.db.
+		WithContext(ctx).
+		Where("owner_id = ? AND kind = ?", userId, KindCredit).
+		Order("currency").
+		Find(&cards).Error; err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// HasPaymentCard reports whether the payment has already bought a card.
+func (r *giftCardRepository) HasPaymentCard(ctx context.Context, paymentId string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.GiftCard{}).
+		Where("payment_id = ?", paymentId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Redeem takes amount off a card towards the transaction's order, or the
+// whole balance when amount is nil, never more than the order still owes.
+// The order row and then the card row are locked for the length of the
+// transaction, so concurrent redemptions queue up and each sees the balance
+// and the amount owed the previous one left. Cancelling an order locks them
+// in the same order.
+func (r *giftCardRepository) Redeem(ctx context.Context, code string, amount *pricing.Money, transaction *entities.GiftCardTransaction) (*entities.GiftCard, error) {
+	var card *entities.GiftCard
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owed, order, err := owedOn(tx, *transaction.OrderId, *transaction.ActorId)
+		if err != nil {
+			return err
+		}
+
+		card, err = readCard(tx.Clauses(clause.Locking{Strength: "UPDATE"}), code)
+		if err != nil {
+			return err
+		}
+
+		// Store credit is spent by its owner only.
+		if card.Kind == KindCredit && (card.OwnerId == nil || *card.OwnerId != *transaction.ActorId) {
+			return ErrCardNotFound
+		}
+		if card.ExpiresAt != nil && !card.ExpiresAt.After(transaction.CreatedAt) {
+			return ErrCardExpired
+		}
+		if card.Currency != order.Currency {
+			return ErrCurrencyMismatch
+		}
+
+		balance := pricing.FromFloat(card.Balance)
+		spent := balance
+		if amount != nil {
+			spent = *amount
+		}
+		if balance == 0 || spent > balance {
+			return ErrInsufficientBalance
+		}
+		spent = min(spent, owed)
+
+		balance -= spent
+		card.Balance = balance.Float()
+		card.UpdatedAt = transaction.CreatedAt
+
+		if err = tx.
+			Model(&entities.GiftCard{}).
+			Where("id = ?", card.Id).
+			Updates(map[string]any{
+				"balance":    card.Balance,
+				"updated_at": card.UpdatedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		transaction.GiftCardId = card.Id
+		transaction.Amount = (-spent).Float()
+		transaction.BalanceAfter = card.Balance
+
+		return tx.Create(transaction).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// Credit adds to the owner's store credit in the card's currency, opening
+// the credit card from card if the owner has none yet. A return is credited
+// once: crediting it again is a no-op.
+func (r *giftCardRepository) Credit(ctx context.Context, card *entities.GiftCard, transaction *entities.GiftCardTransaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if transaction.ReturnId != nil {
+			var credited int64
+			if err := tx.
+				Model(&entities.GiftCardTransaction{}).
+				Where("return_id = ?", *transaction.ReturnId).
+				Count(&credited).Error; err != nil {
+				return err
+			}
+			if credited > 0 {
+				return nil
+			}
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(card).Error; err != nil {
+			return err
+		}
+
+		var current entities.GiftCard
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("owner_id = ? AND kind = ? AND currency = ?", *card.OwnerId, KindCredit, card.Currency).
+			First(&current).Error; err != nil {
+			return err
+		}
+
+		balance := pricing.FromFloat(current.Balance) + pricing.FromFloat(transaction.Amount)
+
+		if err := tx.
+			Model(&entities.GiftCard{}).
+			Where("id = ?", current.Id).
+			Updates(map[string]any{
+				"balance":    balance.Float(),
+				"updated_at": transaction.CreatedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		transaction.GiftCardId = current.Id
+		transaction.BalanceAfter = balance.Float()
+
+		return tx.Create(transaction).Error
+	})
+}
+
+// owedOn locks a pending order of the user and returns what it still owes
+// after earlier redemptions. Orders with an authorized or paid payment owe
+// nothing more, and gift card orders cannot be paid with cards at all.
+func owedOn(tx *gorm.DB, orderId, userId string) (pricing.Money, *entities.Order, error) {
+	var order entities.Order
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", orderId).
+		First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil, ErrOrderNotFound
+		}
+		return 0, nil, err
+	}
+
+	if order.UserId == nil || *order.UserId != userId {
+		return 0, nil, ErrOrderNotFound
+	}
+	if !orderservice.AwaitsPayment(order.Status) {
+		return 0, nil, ErrOrderNotPending
+	}
+	if order.Kind == orderservice.KindGiftCard {
+		return 0, nil, ErrGiftCardOrder
+	}
+
+	var settled int64
+	if err := tx.
+		Model(&entities.Payment{}).
+		Where("order_id = ? AND status IN ?", orderId, []string{paymentservice.StatusAuthorized, paymentservice.StatusPaid}).
+		Count(&settled).Error; err != nil {
+		return 0, nil, err
+	}
+	if settled > 0 {
+		return 0, nil, ErrOrderNotPending
+	}
+
+	var redeemed float64
+	if err := tx.
+		Table("gift_card_transactions AS t").
+		Joins("JOIN gift_cards AS c ON c.id = t.gift_card_id").
+		Where("t.order_id = ? AND t.kind IN ? AND c.currency = ?", orderId, []string{TransactionRedeem, TransactionReverse}, order.Currency).
+		Select("COALESCE(-SUM(t.amount), 0)").
+		Scan(&redeemed).Error; err != nil {
+		return 0, nil, err
+	}
+
+	owed := pricing.FromFloat(order.Total) - pricing.FromFloat(redeemed)
+	if owed <= 0 {
+		return 0, nil, ErrOrderCovered
+	}
+
+	return owed, &order, nil
+}
+
+func readCard(db *gorm.DB, code string) (*entities.GiftCard, error) {
+	var card entities.GiftCard
+	if err := db.
+		Where("code = ?", code).
+		First(&card).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCardNotFound
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
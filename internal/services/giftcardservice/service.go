@@ -0,0 +1,289 @@
+package giftcardservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"regexp"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"story-book/internal/services/orderservice"
+	"story-book/internal/services/paymentservice"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Card kinds. Gift cards are bought or issued and spent by whoever holds the
+// code; store credit belongs to one user and is where refunds can go.
+const (
+	KindGift   = "gift"
+	KindCredit = "credit"
+)
+
+// Transaction kinds of the card ledger.
+const (
+	TransactionIssue    = "issue"
+	TransactionPurchase = "purchase"
+	TransactionRedeem   = "redeem"
+	TransactionRefund   = "refund"
+	// TransactionReverse puts a redemption back when its order is cancelled.
+	TransactionReverse = "reverse"
+)
+
+var currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type GiftCardRepository interface {
+	Create(ctx context.Context, card *entities.GiftCard, transaction *entities.GiftCardTransaction) error
+	ReadByCode(ctx context.Context, code string) (*entities.GiftCard, error)
+	ReadTransactions(ctx context.Context, cardId string) ([]entities.GiftCardTransaction, error)
+	ReadCredits(ctx context.Context, userId string) ([]entities.GiftCard, error)
+	HasPaymentCard(ctx context.Context, paymentId string) (bool, error)
+	Redeem(ctx context.Context, code string, amount *pricing.Money, transaction *entities.GiftCardTransaction) (*entities.GiftCard, error)
+	Credit(ctx context.Context, card *entities.GiftCard, transaction *entities.GiftCardTransaction) error
+}
+
+// Payments is the part of the payment service gift card purchases rely on.
+type Payments interface {
+	ReadPayment(ctx context.Context, userId, role, id string) (*entities.Payment, error)
+}
+
+// Orders reads the gift card orders payments are made for.
+type Orders interface {
+	ReadOrder(ctx context.Context, userId, role, id string) (*entities.Order, error)
+}
+
+type giftCardService struct {
+	repo     GiftCardRepository
+	payments Payments
+	orders   Orders
+	validity time.Duration
+}
+
+// NewGiftCardService creates the service. Cards expire after validity unless
+// staff set another expiry when issuing them.
+func NewGiftCardService(repo GiftCardRepository, payments Payments, orders Orders, validity time.Duration) GiftCardService {
+	return &giftCardService{repo: repo, payments: payments, orders: orders, validity: validity}
+}
+
+// IssueCard creates a gift card on behalf of the store, e.g. as a goodwill
+// gesture or a prize.
+func (s *giftCardService) IssueCard(ctx context.Context, actorId string, amount pricing.Money, currency string, expiresAt *time.Time) (*entities.GiftCard, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !currencyRegex.MatchString(currency) {
+		return nil, ErrInvalidCurrency
+	}
+
+	now := time.Now()
+
+	if expiresAt == nil {
+		expiry := now.Add(s.validity)
+		expiresAt = &expiry
+	} else if !expiresAt.After(now) {
+		return nil, ErrInvalidExpiry
+	}
+
+	card := s.newCard(KindGift, amount, currency, expiresAt, now)
+	card.IssuedBy = &actorId
+
+	transaction := newTransaction(card, TransactionIssue, amount, &actorId, now)
+
+	if err := s.repo.Create(ctx, card, transaction); err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// PurchaseCard mints the gift card bought by one of the buyer's paid gift
+// card orders. The card is worth what the payment took less anything
+// refunded since, in the payment currency. Each payment buys one card.
+func (s *giftCardService) PurchaseCard(ctx context.Context, userId, paymentId string) (*entities.GiftCard, error) {
+	payment, err := s.payments.ReadPayment(ctx, userId, "client", paymentId)
+	if err != nil {
+		if errors.Is(err, paymentservice.ErrPaymentNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	if payment.Status != paymentservice.StatusPaid {
+		return nil, ErrPaymentNotPaid
+	}
+
+	order, err := s.orders.ReadOrder(ctx, userId, "client", payment.OrderId)
+	if err != nil {
+		if errors.Is(err, orderservice.ErrOrderNotFound) {
+			return nil, ErrNotGiftCardOrder
+		}
+		return nil, err
+	}
+
+	if order.Kind != orderservice.KindGiftCard {
+		return nil, ErrNotGiftCardOrder
+	}
+
+	minted, err := s.repo.HasPaymentCard(ctx, payment.Id)
+	if err != nil {
+		return nil, err
+	}
+	if minted {
+		return nil, ErrPaymentUsed
+	}
+
+	amount := pricing.FromFloat(payment.Amount) - pricing.FromFloat(payment.RefundedAmount)
+	if amount <= 0 {
+		return nil, ErrPaymentNotPaid
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.validity)
+
+	card := s.newCard(KindGift, amount, payment.Currency, &expiresAt, now)
+	card.PurchasedBy = &userId
+	card.PaymentId = &payment.Id
+
+	transaction := newTransaction(card, TransactionPurchase, amount, &userId, now)
+
+	if err = s.repo.Create(ctx, card, transaction); err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// ReadCard looks a card up by its code. The code is the secret, so anyone
+// holding it sees the balance; the ledger is shown to staff and to the
+// user who bought or owns the card.
+func (s *giftCardService) ReadCard(ctx context.Context, userId, role, code string) (*entities.GiftCard, []entities.GiftCardTransaction, error) {
+	card, err := s.repo.ReadByCode(ctx, normalizeCode(code))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if card.Kind == KindCredit && role == "client" && (card.OwnerId == nil || *card.OwnerId != userId) {
+		return nil, nil, ErrCardNotFound
+	}
+
+	if role == "client" && !isHolder(card, userId) {
+		return card, nil, nil
+	}
+
+	transactions, err := s.repo.ReadTransactions(ctx, card.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return card, transactions, nil
+}
+
+// Redeem spends a card towards one of the user's pending orders, in part
+// or, when amount is nil, in full. The card must be in the order currency,
+// and no more is taken than the order still owes.
+func (s *giftCardService) Redeem(ctx context.Context, userId, code string, amount *pricing.Money, orderId string) (*entities.GiftCard, *entities.GiftCardTransaction, error) {
+	if amount != nil && *amount <= 0 {
+		return nil, nil, ErrInvalidAmount
+	}
+
+	orderId = strings.TrimSpace(orderId)
+	if orderId == "" {
+		return nil, nil, ErrInvalidOrder
+	}
+	if _, err := uuid.Parse(orderId); err != nil {
+		return nil, nil, ErrOrderNotFound
+	}
+
+	transaction := &entities.GiftCardTransaction{
+		Id:        uuid.NewString(),
+		Kind:      TransactionRedeem,
+		OrderId:   &orderId,
+		ActorId:   &userId,
+		CreatedAt: time.Now(),
+	}
+
+	card, err := s.repo.Redeem(ctx, normalizeCode(code), amount, transaction)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return card, transaction, nil
+}
+
+// ReadStoreCredit returns the user's store credit, one card per currency.
+func (s *giftCardService) ReadStoreCredit(ctx context.Context, userId string) ([]entities.GiftCard, error) {
+	return s.repo.ReadCredits(ctx, userId)
+}
+
+// CreditReturn refunds a return to the user's store credit instead of the
+// original payment. Store credit does not expire.
+func (s *giftCardService) CreditReturn(ctx context.Context, userId, returnId string, amount pricing.Money, currency string) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	now := time.Now()
+
+	card := s.newCard(KindCredit, 0, currency, nil, now)
+	card.OwnerId = &userId
+
+	transaction := newTransaction(card, TransactionRefund, amount, nil, now)
+	transaction.ReturnId = &returnId
+
+	return s.repo.Credit(ctx, card, transaction)
+}
+
+func (s *giftCardService) newCard(kind string, balance pricing.Money, currency string, expiresAt *time.Time, now time.Time) *entities.GiftCard {
+	return &entities.GiftCard{
+		Id:             uuid.NewString(),
+		Code:           newCode(),
+		Kind:           kind,
+		InitialBalance: balance.Float(),
+		Balance:        balance.Float(),
+		Currency:       currency,
+		ExpiresAt:      expiresAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+func newTransaction(card *entities.GiftCard, kind string, amount pricing.Money, actorId *string, now time.Time) *entities.GiftCardTransaction {
+	return &entities.GiftCardTransaction{
+		Id:           uuid.NewString(),
+		GiftCardId:   card.Id,
+		Kind:         kind,
+		Amount:       amount.Float(),
+		BalanceAfter: card.Balance,
+		ActorId:      actorId,
+		CreatedAt:    now,
+	}
+}
+
+func isHolder(card *entities.GiftCard, userId string) bool {
+	return (card.OwnerId != nil && *card.OwnerId == userId) ||
+		(card.PurchasedBy != nil && *card.PurchasedBy == userId)
+}
+
+// newCode returns 16 random base32 characters in groups of four, e.g.
+// ABCD-EFGH-IJKL-MNOP: 80 bits, too many to guess.
+func newCode() string {
+	b := make([]byte, 10)
+	_, _ = rand.Read(b)
+	raw := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+	return raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+}
+
+// normalizeCode accepts codes typed in lower case, with spaces or without
+// dashes.
+func normalizeCode(code string) string {
+	raw := strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(code))
+	if len(raw) != 16 {
+		return raw
+	}
+	return raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+}
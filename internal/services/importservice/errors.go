@@ -0,0 +1,14 @@
+package importservice
+
+import "errors"
+
+var (
+	ErrJobNotFound    = errors.New("import job not found")
+	ErrFileRequired   = errors.New("file is required")
+	ErrFileTooLarge   = errors.New("file is too large")
+	ErrTooManyRows    = errors.New("file has too many rows")
+	ErrNoRows         = errors.New("file has no rows below the header")
+	ErrInvalidMapping = errors.New("invalid column mapping")
+	ErrAmbiguousMatch = errors.New("several books have this title and author; add the isbn to pick one")
+	ErrAccessDenied   = errors.New("access denied")
+)
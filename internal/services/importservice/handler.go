@@ -0,0 +1,239 @@
+package importservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/sheet"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxFileSize = 10 << 20
+
+type ImportService interface {
+	CreateJob(ctx context.Context, job *entities.ImportJob, mapping map[string]string) (*entities.ImportJob, error)
+	ReadJob(ctx context.Context, id string) (*entities.ImportJob, error)
+	ReadErrors(ctx context.Context, id string) ([]entities.ImportError, error)
+	ProcessNext(ctx context.Context) (bool, error)
+	ImportNow(ctx context.Context, job *entities.ImportJob, mapping map[string]string, onProgress func(job *entities.ImportJob)) (*entities.ImportJob, error)
+}
+
+type ImportHandler struct {
+	service ImportService
+}
+
+func NewImportHandler(service ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// CreateImport
+// @Summary Загрузить каталог из CSV или XLSX
+// @Description Файл обрабатывается в фоне, ход импорта — GET /imports/{id}. Книги сопоставляются по ISBN, затем по названию и автору: найденные обновляются, остальные создаются. Без сопоставления колонка используется, если её заголовок совпадает с полем: isbn, title, author, publisher, year, cost, discount, amount, description, tax_category, format, language, pages, release_date, work_id, weight_grams, width_mm, height_mm, depth_mm
+// @Tags imports
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Файл .csv или .xlsx, до 10 МБ"
+// @Param mapping formData string false "Сопоставление полей и заголовков колонок в JSON, например {\"title\": \"Название\", \"cost\": \"Цена\"}"
+// @Param dry_run formData bool false "Только проверить строки, ничего не меняя"
+// @Success 202 {object} dto.ImportJobResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /imports [post]
+func (h *ImportHandler) CreateImport(c echo.Context) error {
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrFileRequired.Error()})
+	}
+	if header.Size > maxFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: ErrFileTooLarge.Error()})
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxFileSize))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	mapping := map[string]string{}
+	if raw := c.FormValue("mapping"); raw != "" {
+		if err = json.Unmarshal([]byte(raw), &mapping); err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidMapping.Error()})
+		}
+	}
+
+	dryRun := false
+	if raw := c.FormValue("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid dry_run"})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	job, err := h.service.CreateJob(ctx, &entities.ImportJob{
+		UserId:   &userId,
+		UserRole: role,
+		FileName: header.Filename,
+		Data:     data,
+		DryRun:   dryRun,
+	}, mapping)
+	if err != nil {
+		return importError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, toImportJobResponse(job))
+}
+
+// ReadImport
+// @Summary Получить ход импорта
+// @Tags imports
+// @Security BearerAuth
+// @Param id path string true "ID импорта"
+// @Produce json
+// @Success 200 {object} dto.ImportJobResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /imports/{id} [get]
+func (h *ImportHandler) ReadImport(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	job, err := h.service.ReadJob(ctx, c.Param("id"))
+	if err != nil {
+		return importError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toImportJobResponse(job))
+}
+
+// ReadImportErrors
+// @Summary Скачать ошибки импорта в CSV
+// @Description Колонки: row — номер строки в файле (заголовок — строка 1), field — поле или row для всей строки, value, message
+// @Tags imports
+// @Security BearerAuth
+// @Param id path string true "ID импорта"
+// @Produce text/csv
+// @Success 200 {file} file
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /imports/{id}/errors.csv [get]
+func (h *ImportHandler) ReadImportErrors(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	rowErrors, err := h.service.ReadErrors(ctx, id)
+	if err != nil {
+		return importError(c, err)
+	}
+
+	data, err := WriteErrors(rowErrors)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="import-`+id+`-errors.csv"`)
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// WriteErrors renders a job's row errors as CSV.
+func WriteErrors(rowErrors []entities.ImportError) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write([]string{"row", "field", "value", "message"}); err != nil {
+		return nil, err
+	}
+	for _, rowError := range rowErrors {
+		if err := writer.Write([]string{strconv.Itoa(rowError.Row), rowError.Field, rowError.Value, rowError.Message}); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func importError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrFileTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrFileRequired), errors.Is(err, ErrTooManyRows), errors.Is(err, ErrNoRows),
+		errors.Is(err, ErrInvalidMapping), errors.Is(err, sheet.ErrUnsupportedFormat),
+		errors.Is(err, sheet.ErrInvalidFile), errors.Is(err, sheet.ErrEmpty):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func toImportJobResponse(job *entities.ImportJob) dto.ImportJobResponse {
+	response := dto.ImportJobResponse{
+		Id:            job.Id,
+		FileName:      job.FileName,
+		Format:        job.Format,
+		DryRun:        job.DryRun,
+		Status:        job.Status,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		CreatedRows:   job.CreatedRows,
+		UpdatedRows:   job.UpdatedRows,
+		FailedRows:    job.FailedRows,
+		CreatedAt:     job.CreatedAt,
+		StartedAt:     job.StartedAt,
+		FinishedAt:    job.FinishedAt,
+	}
+
+	if job.TotalRows > 0 {
+		response.Progress = float64(job.ProcessedRows*1000/job.TotalRows) / 10
+	}
+	if job.Error != nil {
+		response.Error = *job.Error
+	}
+	if job.FailedRows > 0 {
+		response.ErrorFile = "/imports/" + job.Id + "/errors.csv"
+	}
+
+	return response
+}
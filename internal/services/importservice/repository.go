@@ -0,0 +1,168 @@
+package importservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type importRepository struct {
+	db *gorm.DB
+}
+
+func NewImportRepository(db *gorm.DB) ImportRepository {
+	return &importRepository{db: db}
+}
+
+func (r *importRepository) Create(ctx context.Context, job *entities.ImportJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// ReadById reads a job without the uploaded file.
+func (r *importRepository) ReadById(ctx context.Context, id string) (*entities.ImportJob, error) {
+	var job entities.ImportJob
+	if err := r.db.
+		WithContext(ctx).
+		Omit("data").
+		Where("id = ?", id).
+		First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Claim takes the oldest queued job, or a running one whose worker stopped
+// reporting progress before staleBefore, and marks it running. It returns
+// nil when there is nothing to do.
+func (r *importRepository) Claim(ctx context.Context, staleBefore time.Time) (*entities.ImportJob, error) {
+	var job entities.ImportJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND updated_at < ?)", StatusQueued, StatusRunning, staleBefore).
+			Order("created_at").
+			First(&job).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		job.Status = StatusRunning
+		job.UpdatedAt = now
+		if job.StartedAt == nil {
+			job.StartedAt = &now
+		}
+
+		return tx.
+			Model(&entities.ImportJob{}).
+			Where("id = ?", job.Id).
+			Updates(map[string]any{"status": job.Status, "started_at": job.StartedAt, "updated_at": job.UpdatedAt}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// SaveProgress stores the counters of a running job together with the row
+// errors found since the last call, so a job resumed after a restart picks
+// up exactly where the report ends.
+func (r *importRepository) SaveProgress(ctx context.Context, job *entities.ImportJob, rowErrors []entities.ImportError) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(rowErrors) > 0 {
+			if err := tx.Create(&rowErrors).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.
+			Model(&entities.ImportJob{}).
+			Where("id = ?", job.Id).
+			Updates(progress(job)).Error
+	})
+}
+
+// Finish records the outcome of a job and drops the uploaded file.
+func (r *importRepository) Finish(ctx context.Context, job *entities.ImportJob) error {
+	updates := progress(job)
+	updates["status"] = job.Status
+	updates["error"] = job.Error
+	updates["finished_at"] = job.FinishedAt
+	updates["data"] = nil
+
+	return r.db.
+		WithContext(ctx).
+		Model(&entities.ImportJob{}).
+		Where("id = ?", job.Id).
+		Updates(updates).Error
+}
+
+func (r *importRepository) ReadErrors(ctx context.Context, jobId string) ([]entities.ImportError, error) {
+	var rowErrors []entities.ImportError
+	if err := r.db.
+		WithContext(ctx).
+		Where("job_id = ?", jobId).
+		Order("row, id").
+		Find(&rowErrors).Error; err != nil {
+		return nil, err
+	}
+	return rowErrors, nil
+}
+
+// FindByIsbn returns the id of the book with an ISBN, or "" if there is none.
+func (r *importRepository) FindByIsbn(ctx context.Context, isbn string) (string, error) {
+	var ids []string
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Book{}).
+		Where("isbn = ?", isbn).
+		Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		return "", err
+	}
+	if len(ids) == 0 {
+		return "", nil
+	}
+	return ids[0], nil
+}
+
+// FindByTitleAuthor returns up to two books with the title and author, so
+// that an ambiguous match can be told apart. With withoutIsbn only books
+// that have no ISBN yet are considered.
+func (r *importRepository) FindByTitleAuthor(ctx context.Context, title, author string, withoutIsbn bool) ([]string, error) {
+	query := r.db.
+		WithContext(ctx).
+		Model(&entities.Book{}).
+		Where("LOWER(title) = LOWER(?) AND LOWER(author) = LOWER(?)", title, author)
+	if withoutIsbn {
+		query = query.Where("isbn IS NULL")
+	}
+
+	var ids []string
+	if err := query.
+		Order("created_at, id").
+		Limit(2).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func progress(job *entities.ImportJob) map[string]any {
+	return map[string]any{
+		"processed_rows": job.ProcessedRows,
+		"created_rows":   job.CreatedRows,
+		"updated_rows":   job.UpdatedRows,
+		"failed_rows":    job.FailedRows,
+		"updated_at":     time.Now(),
+	}
+}
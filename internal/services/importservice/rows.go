@@ -0,0 +1,210 @@
+package importservice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"story-book/internal/entities"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields a column can be mapped to. Without a mapping a column is used when
+// its header is the field name.
+var fields = []string{
+	"isbn", "title", "author", "publisher", "year", "cost", "discount", "amount",
+	"description", "tax_category", "format", "language", "pages", "release_date",
+	"work_id", "weight_grams", "width_mm", "height_mm", "depth_mm",
+}
+
+// requiredFields must be filled for a row to create a book. A row that
+// updates a book may leave any of them empty.
+var requiredFields = []string{"title", "author", "cost"}
+
+const releaseDateLayout = "2006-01-02"
+
+// thousands strips the spaces suppliers group digits with.
+var thousands = strings.NewReplacer(" ", "", "\u00a0", "")
+
+// excelEpoch is day zero of the serial dates spreadsheet programs store.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// resolveColumns finds the column index of every mapped field. The mapping
+// names a header for a field; headers are compared case-insensitively.
+func resolveColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field] = true
+	}
+	for field := range mapping {
+		if !known[field] {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidMapping, field)
+		}
+	}
+
+	headers := make(map[string]int, len(header))
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, ok := headers[key]; !ok && key != "" {
+			headers[key] = i
+		}
+	}
+
+	columns := make(map[string]int, len(fields))
+	for _, field := range fields {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+
+		index, ok := headers[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("%w: column %q not found", ErrInvalidMapping, name)
+			}
+			continue
+		}
+		columns[field] = index
+	}
+
+	_, hasIsbn := columns["isbn"]
+	_, hasTitle := columns["title"]
+	_, hasAuthor := columns["author"]
+	if !hasIsbn && !(hasTitle && hasAuthor) {
+		return nil, fmt.Errorf("%w: books are matched by isbn or by title and author, map either", ErrInvalidMapping)
+	}
+
+	return columns, nil
+}
+
+// parseRow turns the cells of a row into a book. Empty cells leave fields
+// unset; present lists the fields that had a value.
+func parseRow(row []string, columns map[string]int, number int) (*entities.Book, map[string]bool, []entities.ImportError) {
+	book := &entities.Book{}
+	present := make(map[string]bool, len(columns))
+	var errs []entities.ImportError
+
+	for _, field := range fields {
+		index, ok := columns[field]
+		if !ok {
+			continue
+		}
+
+		value := ""
+		if index < len(row) {
+			value = strings.TrimSpace(row[index])
+		}
+		if value == "" {
+			continue
+		}
+		present[field] = true
+
+		if err := setField(book, field, value); err != nil {
+			errs = append(errs, entities.ImportError{Row: number, Field: field, Value: value, Message: err.Error()})
+		}
+	}
+
+	return book, present, errs
+}
+
+func setField(book *entities.Book, field, value string) error {
+	switch field {
+	case "isbn":
+		book.Isbn = &value
+	case "title":
+		book.Title = value
+	case "author":
+		book.Author = value
+	case "publisher":
+		book.Publisher = value
+	case "description":
+		book.Description = &value
+	case "tax_category":
+		book.TaxCategory = value
+	case "format":
+		format := strings.ToLower(value)
+		book.Format = &format
+	case "language":
+		book.Language = &value
+	case "work_id":
+		book.WorkId = value
+	case "cost":
+		cost, err := parseDecimal(value)
+		if err != nil || cost < 0 {
+			return errors.New("must be a non-negative number")
+		}
+		book.Cost = cost
+	case "release_date":
+		date, err := parseDate(value)
+		if err != nil {
+			return errors.New("must be a date in YYYY-MM-DD format")
+		}
+		book.ReleaseDate = &date
+	default:
+		number, err := parseInt(value)
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		setInt(book, field, number)
+	}
+
+	return nil
+}
+
+func setInt(book *entities.Book, field string, number int) {
+	switch field {
+	case "year":
+		book.Year = number
+	case "amount":
+		book.Amount = number
+	case "discount":
+		book.Discount = &number
+	case "pages":
+		book.Pages = &number
+	case "weight_grams":
+		book.WeightGrams = &number
+	case "width_mm":
+		book.WidthMm = &number
+	case "height_mm":
+		book.HeightMm = &number
+	case "depth_mm":
+		book.DepthMm = &number
+	}
+}
+
+// parseDecimal reads prices as suppliers write them: "1 234,50", "1,234.50"
+// or "1234.5".
+func parseDecimal(value string) (float64, error) {
+	value = thousands.Replace(value)
+	if strings.Contains(value, ",") {
+		if strings.Contains(value, ".") {
+			value = strings.ReplaceAll(value, ",", "")
+		} else {
+			value = strings.ReplaceAll(value, ",", ".")
+		}
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseInt also accepts whole numbers stored as decimals, such as "3.0".
+func parseInt(value string) (int, error) {
+	number, err := strconv.ParseFloat(thousands.Replace(value), 64)
+	if err != nil || number != math.Trunc(number) || math.Abs(number) > math.MaxInt32 {
+		return 0, strconv.ErrSyntax
+	}
+	return int(number), nil
+}
+
+// parseDate reads YYYY-MM-DD text or the day number an XLSX date cell holds.
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse(releaseDateLayout, value); err == nil {
+		return date, nil
+	}
+
+	days, err := parseInt(value)
+	if err != nil || days <= 0 {
+		return time.Time{}, strconv.ErrSyntax
+	}
+	return excelEpoch.AddDate(0, 0, days), nil
+}
@@ -0,0 +1,360 @@
+package importservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"story-book/internal/entities"
+	"story-book/internal/isbn"
+	"story-book/internal/services/auditservice"
+	"story-book/internal/sheet"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job statuses.
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+const (
+	maxRows = 20000
+	// progressEvery is how many rows are processed between progress saves.
+	progressEvery = 50
+	// staleAfter is how long a running job may go without saving progress
+	// before another worker takes it over.
+	staleAfter = 10 * time.Minute
+)
+
+type ImportRepository interface {
+	Create(ctx context.Context, job *entities.ImportJob) error
+	ReadById(ctx context.Context, id string) (*entities.ImportJob, error)
+	Claim(ctx context.Context, staleBefore time.Time) (*entities.ImportJob, error)
+	SaveProgress(ctx context.Context, job *entities.ImportJob, rowErrors []entities.ImportError) error
+	Finish(ctx context.Context, job *entities.ImportJob) error
+	ReadErrors(ctx context.Context, jobId string) ([]entities.ImportError, error)
+	FindByIsbn(ctx context.Context, isbn string) (string, error)
+	FindByTitleAuthor(ctx context.Context, title, author string, withoutIsbn bool) ([]string, error)
+}
+
+// Books creates and updates books with the checks of POST and PUT /books.
+type Books interface {
+	ValidateBook(book *entities.Book) error
+	CreateBook(ctx context.Context, book *entities.Book) (*entities.Book, error)
+	UpdateBook(ctx context.Context, book *entities.Book) (*entities.Book, error)
+}
+
+type importService struct {
+	repo  ImportRepository
+	books Books
+}
+
+func NewImportService(repo ImportRepository, books Books) ImportService {
+	return &importService{repo: repo, books: books}
+}
+
+// CreateJob checks the file and the column mapping and queues the import.
+// Problems with the file as a whole are reported here; problems with single
+// rows end up in the job's error report.
+func (s *importService) CreateJob(ctx context.Context, job *entities.ImportJob, mapping map[string]string) (*entities.ImportJob, error) {
+	if err := newJob(job, mapping); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ImportNow runs an import in the caller instead of the background worker,
+// for the command line. The job is created running so no worker takes it.
+func (s *importService) ImportNow(ctx context.Context, job *entities.ImportJob, mapping map[string]string, onProgress func(job *entities.ImportJob)) (*entities.ImportJob, error) {
+	if err := newJob(job, mapping); err != nil {
+		return nil, err
+	}
+
+	job.Status = StatusRunning
+	job.StartedAt = &job.CreatedAt
+
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if err := s.runJob(ctx, job, onProgress); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (s *importService) ReadJob(ctx context.Context, id string) (*entities.ImportJob, error) {
+	return s.repo.ReadById(ctx, id)
+}
+
+func (s *importService) ReadErrors(ctx context.Context, id string) ([]entities.ImportError, error) {
+	if _, err := s.repo.ReadById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadErrors(ctx, id)
+}
+
+// ProcessNext runs one waiting job to the end. It reports whether there was
+// a job, so a worker can drain the queue before sleeping.
+func (s *importService) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := s.repo.Claim(ctx, time.Now().Add(-staleAfter))
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	return true, s.runJob(ctx, job, nil)
+}
+
+// runJob imports the rows of a claimed job, resuming after the rows already
+// processed. Books are written on behalf of the user who uploaded the file.
+// onProgress, if given, is called after every saved batch.
+func (s *importService) runJob(ctx context.Context, job *entities.ImportJob, onProgress func(job *entities.ImportJob)) error {
+	rows, columns, err := s.prepare(job)
+	if err != nil {
+		return s.fail(ctx, job, err)
+	}
+
+	actor := auditservice.Actor{Role: job.UserRole, RequestId: "import-" + job.Id}
+	if job.UserId != nil {
+		actor.Id = *job.UserId
+	}
+	ctx = auditservice.WithActor(ctx, actor)
+
+	// created remembers the books a dry run would have created, so that a
+	// later row for the same book counts as an update, as it would for real.
+	created := make(map[string]bool)
+
+	var batch []entities.ImportError
+	for i := job.ProcessedRows + 1; i < len(rows); i++ {
+		action, rowErrors := s.importRow(ctx, job, rows[i], i+1, columns, created)
+		if ctx.Err() != nil {
+			// Shutting down: the job stays running and is resumed from the
+			// last saved batch.
+			return ctx.Err()
+		}
+
+		switch {
+		case len(rowErrors) > 0:
+			job.FailedRows++
+			batch = append(batch, rowErrors...)
+		case action == actionCreate:
+			job.CreatedRows++
+		case action == actionUpdate:
+			job.UpdatedRows++
+		}
+		job.ProcessedRows++
+
+		if job.ProcessedRows%progressEvery == 0 || i == len(rows)-1 {
+			if err = s.repo.SaveProgress(ctx, job, batch); err != nil {
+				return err
+			}
+			batch = nil
+
+			if onProgress != nil {
+				onProgress(job)
+			}
+		}
+	}
+
+	now := time.Now()
+	job.Status = StatusDone
+	job.FinishedAt = &now
+
+	return s.repo.Finish(ctx, job)
+}
+
+// newJob checks an uploaded file and fills in a queued job for it.
+func newJob(job *entities.ImportJob, mapping map[string]string) error {
+	if len(job.Data) == 0 {
+		return ErrFileRequired
+	}
+
+	format, err := sheet.Format(job.FileName)
+	if err != nil {
+		return err
+	}
+
+	rows, err := sheet.Read(format, job.Data)
+	if err != nil {
+		return err
+	}
+	if len(rows)-1 > maxRows {
+		return ErrTooManyRows
+	}
+	if len(rows) < 2 {
+		return ErrNoRows
+	}
+
+	if _, err = resolveColumns(rows[0], mapping); err != nil {
+		return err
+	}
+
+	job.Mapping, err = json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	job.Id = uuid.NewString()
+	job.Format = format
+	job.Status = StatusQueued
+	job.TotalRows = len(rows) - 1
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	return nil
+}
+
+func (s *importService) prepare(job *entities.ImportJob) ([][]string, map[string]int, error) {
+	var mapping map[string]string
+	if err := json.Unmarshal(job.Mapping, &mapping); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidMapping, err)
+	}
+
+	rows, err := sheet.Read(job.Format, job.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns, err := resolveColumns(rows[0], mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rows, columns, nil
+}
+
+func (s *importService) fail(ctx context.Context, job *entities.ImportJob, cause error) error {
+	now := time.Now()
+	message := cause.Error()
+
+	job.Status = StatusFailed
+	job.Error = &message
+	job.FinishedAt = &now
+
+	if err := s.repo.Finish(ctx, job); err != nil {
+		return err
+	}
+
+	log.Printf("import job %s failed: %v", job.Id, cause)
+	return nil
+}
+
+const (
+	actionCreate = "create"
+	actionUpdate = "update"
+)
+
+// importRow creates or updates the book of one row, or only checks it in a
+// dry run. A row is matched to a book by ISBN, then by title and author; a
+// title and author match is skipped when the row's ISBN names a different
+// edition.
+func (s *importService) importRow(ctx context.Context, job *entities.ImportJob, row []string, number int, columns map[string]int, created map[string]bool) (string, []entities.ImportError) {
+	book, present, rowErrors := parseRow(row, columns, number)
+	if len(present) == 0 {
+		return "", nil
+	}
+	if len(rowErrors) > 0 {
+		return "", rowErrors
+	}
+
+	if err := s.books.ValidateBook(book); err != nil {
+		return "", []entities.ImportError{rowError(number, err)}
+	}
+
+	id, key, err := s.match(ctx, book)
+	if err != nil {
+		return "", []entities.ImportError{rowError(number, err)}
+	}
+
+	if id == "" && !created[key] {
+		for _, field := range requiredFields {
+			if !present[field] {
+				rowErrors = append(rowErrors, entities.ImportError{
+					Row: number, Field: field, Message: "is required for a new book",
+				})
+			}
+		}
+		if len(rowErrors) > 0 {
+			return "", rowErrors
+		}
+	}
+
+	action := actionUpdate
+	if id == "" && !created[key] {
+		action = actionCreate
+	}
+
+	if job.DryRun {
+		created[key] = true
+		return action, nil
+	}
+
+	if id == "" {
+		_, err = s.books.CreateBook(ctx, book)
+	} else {
+		book.Id = id
+		_, err = s.books.UpdateBook(ctx, book)
+	}
+	if err != nil {
+		return "", []entities.ImportError{rowError(number, err)}
+	}
+
+	return action, nil
+}
+
+// match finds the book a row is about. key identifies the row's book within
+// the file, for rows that refer to a book the same import creates.
+func (s *importService) match(ctx context.Context, book *entities.Book) (string, string, error) {
+	key := strings.ToLower(book.Title) + "\x00" + strings.ToLower(book.Author)
+	if book.Isbn != nil {
+		key = *book.Isbn
+
+		id, err := s.repo.FindByIsbn(ctx, *book.Isbn)
+		if err != nil || id != "" {
+			return id, key, err
+		}
+	}
+
+	if book.Title == "" || book.Author == "" {
+		return "", key, nil
+	}
+
+	ids, err := s.repo.FindByTitleAuthor(ctx, book.Title, book.Author, book.Isbn != nil)
+	if err != nil {
+		return "", key, err
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", key, nil
+	case 1:
+		return ids[0], key, nil
+	}
+	return "", key, ErrAmbiguousMatch
+}
+
+// rowError reports an error of the whole row, or of the ISBN when that is
+// what the book checks rejected.
+func rowError(number int, err error) entities.ImportError {
+	field := "row"
+	if errors.Is(err, isbn.ErrInvalid) {
+		field = "isbn"
+	}
+	return entities.ImportError{Row: number, Field: field, Message: err.Error()}
+}
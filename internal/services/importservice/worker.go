@@ -0,0 +1,40 @@
+package importservice
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunWorker processes queued imports one at a time, draining the queue
+// before sleeping for interval. It blocks until ctx is done.
+func RunWorker(ctx context.Context, service ImportService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run(ctx, service)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func run(ctx context.Context, service ImportService) {
+	for ctx.Err() == nil {
+		found, err := service.ProcessNext(ctx)
+		if err != nil {
+			// A job cut short by shutdown is taken up again once it goes stale.
+			if ctx.Err() == nil {
+				log.Printf("import failed: %v", err)
+			}
+			return
+		}
+		if !found {
+			return
+		}
+	}
+}
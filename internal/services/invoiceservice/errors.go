@@ -0,0 +1,8 @@
+package invoiceservice
+
+import "errors"
+
+var (
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrInvalidLanguage = errors.New("language must be ru or en")
+)
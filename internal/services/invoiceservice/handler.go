@@ -0,0 +1,58 @@
+package invoiceservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type InvoiceService interface {
+	Invoice(ctx context.Context, userId, role, orderId, language string) ([]byte, string, error)
+}
+
+type InvoiceHandler struct {
+	service InvoiceService
+}
+
+func NewInvoiceHandler(service InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{service: service}
+}
+
+// ReadInvoice
+// @Summary Скачать чек по заказу в PDF
+// @Tags invoices
+// @Security BearerAuth
+// @Param id path string true "ID заказа"
+// @Param lang query string false "Язык документа: ru (по умолчанию) или en"
+// @Produce application/pdf
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /orders/{id}/invoice.pdf [get]
+func (h *InvoiceHandler) ReadInvoice(c echo.Context) error {
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	data, number, err := h.service.Invoice(ctx, userId, role, c.Param("id"), c.QueryParam("lang"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrOrderNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidLanguage):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+number+`.pdf"`)
+	return c.Blob(http.StatusOK, "application/pdf", data)
+}
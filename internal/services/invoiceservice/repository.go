@@ -0,0 +1,103 @@
+package invoiceservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"story-book/internal/services/paymentservice"
+	"story-book/internal/services/returnservice"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+// ReadPayments returns the settled payments of an order, oldest first.
+func (r *invoiceRepository) ReadPayments(ctx context.Context, orderId string) ([]entities.Payment, error) {
+	var payments []entities.Payment
+	if err := r.db.
+		WithContext(ctx).
+		Where("order_id = ? AND status IN ?", orderId, []string{paymentservice.StatusPaid, paymentservice.StatusRefunded}).
+		Order("created_at").
+		Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *invoiceRepository) ReadUser(ctx context.Context, id string) (*entities.User, error) {
+	var user entities.User
+	if err := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Select("id", "name", "surname", "email").
+		Where("id = ?", id).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Issue returns the invoice of an order, allocating the next number the
+// first time it is asked for.
+func (r *invoiceRepository) Issue(ctx context.Context, invoice *entities.Invoice) (*entities.Invoice, error) {
+	if err := r.db.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Omit("number").
+		Create(invoice).Error; err != nil {
+		return nil, err
+	}
+
+	var issued entities.Invoice
+	if err := r.db.
+		WithContext(ctx).
+		Where("order_id = ?", invoice.OrderId).
+		First(&issued).Error; err != nil {
+		return nil, err
+	}
+	return &issued, nil
+}
+
+func (r *invoiceRepository) ReadOrder(ctx context.Context, id string) (*entities.Order, error) {
+	var order entities.Order
+	if err := r.db.
+		WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("title")
+		}).
+		Where("id = ?", id).
+		First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ReadRefunds returns the refunded returns of an order with their books,
+// oldest first.
+func (r *invoiceRepository) ReadRefunds(ctx context.Context, orderId string) ([]entities.ReturnRequest, error) {
+	var requests []entities.ReturnRequest
+	if err := r.db.
+		WithContext(ctx).
+		Preload("Items").
+		Where("status = ?", returnservice.StatusRefunded).
+		Where("payment_id IN (?)", r.db.Model(&entities.Payment{}).Select("id").Where("order_id = ?", orderId)).
+		Order("created_at").
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
@@ -0,0 +1,317 @@
+package invoiceservice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"story-book/internal/entities"
+	"story-book/internal/invoice"
+	"story-book/internal/pricing"
+	"story-book/internal/services/orderservice"
+	"story-book/internal/services/returnservice"
+	"strings"
+	"time"
+)
+
+// titles are the lines of a receipt that do not come from a book.
+var titles = map[string]map[string]string{
+	invoice.LanguageRu: {
+		"giftCard": "Подарочный сертификат",
+		"delivery": "Доставка",
+		"refund":   "Возврат",
+	},
+	invoice.LanguageEn: {
+		"giftCard": "Gift card",
+		"delivery": "Delivery",
+		"refund":   "Refund",
+	},
+}
+
+type InvoiceRepository interface {
+	ReadPayments(ctx context.Context, orderId string) ([]entities.Payment, error)
+	ReadOrder(ctx context.Context, id string) (*entities.Order, error)
+	ReadRefunds(ctx context.Context, orderId string) ([]entities.ReturnRequest, error)
+	ReadUser(ctx context.Context, id string) (*entities.User, error)
+	Issue(ctx context.Context, invoice *entities.Invoice) (*entities.Invoice, error)
+}
+
+type Renderer interface {
+	Render(doc *invoice.Document) ([]byte, error)
+}
+
+// Taxer finds the VAT contained in amounts already paid.
+type Taxer interface {
+	TaxIncluded(ctx context.Context, region, category string, gross pricing.Money, at time.Time) (*pricing.Tax, error)
+}
+
+type Store interface {
+	Read(name string) ([]byte, error)
+	Write(name string, data []byte) error
+}
+
+type invoiceService struct {
+	repo     InvoiceRepository
+	renderer Renderer
+	store    Store
+	taxer    Taxer
+	seller   invoice.Store
+}
+
+func NewInvoiceService(repo InvoiceRepository, renderer Renderer, store Store, taxer Taxer, seller invoice.Store) InvoiceService {
+	return &invoiceService{repo: repo, renderer: renderer, store: store, taxer: taxer, seller: seller}
+}
+
+// Invoice returns the PDF receipt of an order and its number. The file is
+// rendered on first request and served from the store afterwards, until a
+// refund changes what the order came to. Clients may only download
+// receipts of their own orders.
+func (s *invoiceService) Invoice(ctx context.Context, userId, role, orderId, language string) ([]byte, string, error) {
+	if language == "" {
+		language = invoice.LanguageRu
+	}
+	if !invoice.IsLanguage(language) {
+		return nil, "", ErrInvalidLanguage
+	}
+
+	payments, err := s.repo.ReadPayments(ctx, orderId)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(payments) == 0 {
+		return nil, "", ErrOrderNotFound
+	}
+
+	order, err := s.repo.ReadOrder(ctx, orderId)
+	if err != nil {
+		return nil, "", err
+	}
+	if order == nil {
+		return nil, "", ErrOrderNotFound
+	}
+
+	if role == "client" && (order.UserId == nil || *order.UserId != userId) {
+		return nil, "", ErrOrderNotFound
+	}
+
+	refunds, err := s.repo.ReadRefunds(ctx, orderId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Refunds through the provider that no return accounts for, such as
+	// goodwill refunds made by staff.
+	var refunded, extra pricing.Money
+	for _, payment := range payments {
+		extra += pricing.FromFloat(payment.RefundedAmount)
+	}
+	for _, request := range refunds {
+		amount := pricing.FromFloat(request.RefundAmount)
+		refunded += amount
+		if request.RefundMethod == returnservice.RefundPayment {
+			extra -= amount
+		}
+	}
+	extra = max(extra, 0)
+	refunded += extra
+
+	issued, err := s.repo.Issue(ctx, &entities.Invoice{
+		OrderId:  orderId,
+		UserId:   order.UserId,
+		IssuedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	number := fmt.Sprintf("INV-%06d", issued.Number)
+	// The amount refunded is part of the file name, so a new refund renders
+	// a new file instead of serving the stale one.
+	name := number + "-" + language + ".pdf"
+	if refunded > 0 {
+		name = fmt.Sprintf("%s-%s-r%d.pdf", number, language, int64(refunded))
+	}
+
+	data, err := s.store.Read(name)
+	if err == nil {
+		return data, number, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, "", err
+	}
+
+	doc, err := s.document(ctx, issued, number, language, order, payments[0].CreatedAt, refunds, extra)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err = s.renderer.Render(doc)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = s.store.Write(name, data); err != nil {
+		return nil, "", err
+	}
+
+	return data, number, nil
+}
+
+// document builds the receipt from the order's lines, each taxed by its own
+// category at the rate in force where it was delivered when it was paid.
+// Refunds follow as negative lines: returned books by title, and whatever
+// was refunded beyond the returns as one line taxed in proportion to the
+// whole order.
+func (s *invoiceService) document(ctx context.Context, issued *entities.Invoice, number, language string, order *entities.Order, paidAt time.Time, refunds []entities.ReturnRequest, extra pricing.Money) (*invoice.Document, error) {
+	doc := &invoice.Document{
+		Number:   number,
+		IssuedAt: issued.IssuedAt,
+		Language: language,
+		Store:    s.seller,
+		Currency: order.Currency,
+	}
+
+	if issued.UserId != nil {
+		user, err := s.repo.ReadUser(ctx, *issued.UserId)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			doc.Customer = strings.TrimSpace(user.Name+" "+user.Surname) + " <" + user.Email + ">"
+		}
+	}
+
+	text := titles[invoice.LanguageRu]
+	if language == invoice.LanguageEn {
+		text = titles[invoice.LanguageEn]
+	}
+
+	add := func(line invoice.Line, subtotal pricing.Money) {
+		doc.Lines = append(doc.Lines, line)
+		doc.Subtotal += subtotal
+		doc.Discount += line.Discount
+		doc.Tax += line.Tax
+		doc.Total += line.Total
+	}
+
+	var region string
+	if order.Country != nil {
+		region = *order.Country
+	}
+
+	items := make(map[string]entities.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		if item.BookId != nil {
+			items[*item.BookId] = item
+		}
+
+		unit := pricing.FromFloat(item.UnitPrice)
+		total := pricing.FromFloat(item.Total)
+		vat, err := s.vat(ctx, region, item.TaxCategory, total, paidAt)
+		if err != nil {
+			return nil, err
+		}
+
+		add(invoice.Line{
+			Title:     item.Title,
+			Quantity:  item.Quantity,
+			UnitPrice: unit,
+			Discount:  pricing.FromFloat(item.Discount),
+			Tax:       vat,
+			Total:     total,
+		}, unit.Mul(item.Quantity))
+	}
+
+	// A gift card is a means of payment, not a supply, so it is not taxed.
+	if order.Kind == orderservice.KindGiftCard {
+		total := pricing.FromFloat(order.Total)
+		add(invoice.Line{Title: text["giftCard"], Quantity: 1, UnitPrice: total, Total: total}, total)
+	}
+
+	// Delivery is not taxed when the order is priced.
+	if order.DeliveryCost > 0 {
+		cost := pricing.FromFloat(order.DeliveryCost)
+		title := text["delivery"]
+		if order.DeliveryName != nil {
+			title += ": " + *order.DeliveryName
+		}
+		add(invoice.Line{Title: title, Quantity: 1, UnitPrice: cost, Total: cost}, cost)
+	}
+
+	for _, request := range refunds {
+		lines, err := s.refundLines(ctx, request, items, region, paidAt, text["refund"])
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			add(line, line.Total)
+		}
+	}
+
+	if extra > 0 {
+		var vat pricing.Money
+		if total := pricing.FromFloat(order.Total); total > 0 {
+			vat = pricing.FromFloat(order.Tax).Portion(int(extra), int(total))
+		}
+		add(invoice.Line{
+			Title:     text["refund"] + " " + order.Id,
+			Quantity:  1,
+			UnitPrice: -extra,
+			Tax:       -vat,
+			Total:     -extra,
+		}, -extra)
+	}
+
+	return doc, nil
+}
+
+// refundLines splits the amount refunded by a return over its books in
+// proportion to their price, the last book taking the rounding remainder.
+func (s *invoiceService) refundLines(ctx context.Context, request entities.ReturnRequest, items map[string]entities.OrderItem, region string, paidAt time.Time, title string) ([]invoice.Line, error) {
+	weights := make([]int, len(request.Items))
+	whole := 0
+	for i, item := range request.Items {
+		weights[i] = int(pricing.FromFloat(item.UnitPrice).Mul(item.Quantity))
+		whole += weights[i]
+	}
+	if whole == 0 {
+		return nil, nil
+	}
+
+	refund := pricing.FromFloat(request.RefundAmount)
+	lines := make([]invoice.Line, 0, len(request.Items))
+	var allocated pricing.Money
+	for i, item := range request.Items {
+		amount := refund.Portion(weights[i], whole)
+		if i == len(request.Items)-1 {
+			amount = refund - allocated
+		}
+		allocated += amount
+
+		line := items[item.BookId]
+		vat, err := s.vat(ctx, region, line.TaxCategory, amount, paidAt)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, invoice.Line{
+			Title:     title + ": " + line.Title,
+			Quantity:  item.Quantity,
+			UnitPrice: -pricing.FromFloat(item.UnitPrice),
+			Tax:       -vat,
+			Total:     -amount,
+		})
+	}
+
+	return lines, nil
+}
+
+// vat returns the tax contained in an amount paid for a category delivered
+// to region, zero if no rule was in force. Orders without an address are
+// taxed where the store is.
+func (s *invoiceService) vat(ctx context.Context, region, category string, amount pricing.Money, at time.Time) (pricing.Money, error) {
+	tax, err := s.taxer.TaxIncluded(ctx, region, category, amount, at)
+	if err != nil || tax == nil {
+		return 0, err
+	}
+	return tax.Amount, nil
+}
@@ -0,0 +1,44 @@
+package invoiceservice
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FileStore keeps generated documents on disk so they are rendered once and
+// downloaded many times.
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Read returns a stored file, or an error satisfying os.IsNotExist.
+func (s *FileStore) Read(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.dir, name))
+}
+
+// Write stores a file through a temporary one, so a concurrent Read never
+// sees it half written.
+func (s *FileStore) Write(name string, data []byte) error {
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
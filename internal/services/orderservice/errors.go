@@ -0,0 +1,24 @@
+package orderservice
+
+import "errors"
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrBookNotFound      = errors.New("book not found")
+	ErrEmptyOrder        = errors.New("order must contain at least one item")
+	ErrTooManyItems      = errors.New("too many items")
+	ErrInvalidQuantity   = errors.New("quantity must be between 1 and 100")
+	ErrDuplicateItem     = errors.New("each book may appear only once")
+	ErrOutOfStock        = errors.New("not enough copies in stock")
+	ErrInvalidTransition = errors.New("order is not in a state that allows this operation")
+	ErrPaymentInProgress = errors.New("order has a payment in progress")
+	ErrDeliveryRequired  = errors.New("delivery_method_id is required for printed books")
+	ErrMethodNotFound    = errors.New("delivery method not found")
+	ErrMethodUnavailable = errors.New("delivery method is not available for this parcel")
+	ErrAddressRequired   = errors.New("address_id is required for this delivery method")
+	ErrAddressNotFound   = errors.New("address not found")
+	ErrInvalidAmount     = errors.New("amount must be positive and at most 100000")
+	ErrInvalidStatus     = errors.New("invalid status")
+	ErrInvalidPage       = errors.New("invalid page")
+	ErrInvalidLimit      = errors.New("invalid limit")
+)
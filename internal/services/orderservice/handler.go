@@ -0,0 +1,358 @@
+package orderservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"story-book/internal/services/currencyservice"
+	"story-book/internal/services/deliveryservice"
+	"story-book/internal/services/promoservice"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OrderService interface {
+	QuoteOrder(ctx context.Context, userId string, checkout Checkout) (*entities.Order, error)
+	CreateOrder(ctx context.Context, userId string, checkout Checkout) (*entities.Order, error)
+	CreatePreorderOrder(ctx context.Context, userId string, checkout Checkout) (*entities.Order, error)
+	CreateGiftCardOrder(ctx context.Context, userId string, amount pricing.Money, currency string) (*entities.Order, error)
+	ReadOrders(ctx context.Context, userId, role, status string, page, limit int) ([]entities.Order, error)
+	ReadOrder(ctx context.Context, userId, role, id string) (*entities.Order, error)
+	CancelOrder(ctx context.Context, userId, role, id string) (*entities.Order, error)
+}
+
+type OrderHandler struct {
+	service OrderService
+}
+
+func NewOrderHandler(service OrderService) *OrderHandler {
+	return &OrderHandler{service: service}
+}
+
+// QuoteOrder
+// @Summary Рассчитать заказ
+// @Description Считает заказ так же, как при оформлении, вместе с доставкой, но не создаёт его и не расходует промокод
+// @Tags orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.OrderRequest true "Книги, промокод, валюта, способ доставки и адрес"
+// @Success 200 {object} dto.OrderQuoteResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /orders/quote [post]
+func (h *OrderHandler) QuoteOrder(c echo.Context) error {
+	var request dto.OrderRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	order, err := h.service.QuoteOrder(ctx, c.Get("id").(string), toCheckout(&request))
+	if err != nil {
+		return orderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toOrderQuoteResponse(order))
+}
+
+// CreateOrder
+// @Summary Оформить заказ
+// @Description Цены, скидки, доставка и налог рассчитываются на сервере; валюта, курс, способ доставки и адрес сохраняются в заказе. По умолчанию используется валюта из профиля. Для печатных книг нужен способ доставки, для всех способов, кроме самовывоза, — адрес. Печатные экземпляры списываются со склада при оформлении и возвращаются при отмене
+// @Tags orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.OrderRequest true "Книги, промокод, валюта, способ доставки и адрес"
+// @Success 201 {object} dto.OrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /orders [post]
+func (h *OrderHandler) CreateOrder(c echo.Context) error {
+	var request dto.OrderRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	order, err := h.service.CreateOrder(ctx, c.Get("id").(string), toCheckout(&request))
+	if err != nil {
+		return orderError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toOrderResponse(order))
+}
+
+// CreateGiftCardOrder
+// @Summary Оформить заказ подарочной карты
+// @Description Заказ оплачивается через POST /payments, после оплаты карта выпускается через POST /gift-cards/purchase. Номинал не облагается налогом
+// @Tags orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.GiftCardOrderRequest true "Номинал и валюта"
+// @Success 201 {object} dto.OrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /orders/gift-card [post]
+func (h *OrderHandler) CreateGiftCardOrder(c echo.Context) error {
+	var request dto.GiftCardOrderRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	order, err := h.service.CreateGiftCardOrder(ctx, c.Get("id").(string), request.Amount, request.Currency)
+	if err != nil {
+		return orderError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toOrderResponse(order))
+}
+
+// ReadOrders
+// @Summary Получить список заказов
+// @Description Клиент видит только свои заказы
+// @Tags orders
+// @Security BearerAuth
+// @Param status query string false "Статус: pending, paid, failed, cancelled"
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Produce json
+// @Success 200 {array} dto.OrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /orders [get]
+func (h *OrderHandler) ReadOrders(c echo.Context) error {
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidPage.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	orders, err := h.service.ReadOrders(ctx, userId, role, c.QueryParam("status"), page, limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.OrderResponse, 0, len(orders))
+	for i := range orders {
+		response = append(response, toOrderResponse(&orders[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReadOrder
+// @Summary Получить заказ
+// @Tags orders
+// @Security BearerAuth
+// @Param id path string true "ID заказа"
+// @Produce json
+// @Success 200 {object} dto.OrderResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /orders/{id} [get]
+func (h *OrderHandler) ReadOrder(c echo.Context) error {
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	order, err := h.service.ReadOrder(ctx, userId, role, c.Param("id"))
+	if err != nil {
+		return orderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toOrderResponse(order))
+}
+
+// CancelOrder
+// @Summary Отменить заказ
+// @Description Отменить можно только неоплаченный заказ, по которому нет незавершённого платежа
+// @Tags orders
+// @Security BearerAuth
+// @Param id path string true "ID заказа"
+// @Produce json
+// @Success 200 {object} dto.OrderResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c echo.Context) error {
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	order, err := h.service.CancelOrder(ctx, userId, role, c.Param("id"))
+	if err != nil {
+		return orderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toOrderResponse(order))
+}
+
+func orderError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrOrderNotFound),
+		errors.Is(err, ErrBookNotFound),
+		errors.Is(err, ErrMethodNotFound),
+		errors.Is(err, ErrAddressNotFound),
+		errors.Is(err, deliveryservice.ErrBookNotFound),
+		errors.Is(err, promoservice.ErrBookNotFound),
+		errors.Is(err, promoservice.ErrPromoCodeNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrEmptyOrder),
+		errors.Is(err, ErrTooManyItems),
+		errors.Is(err, ErrInvalidQuantity),
+		errors.Is(err, ErrDuplicateItem),
+		errors.Is(err, ErrDeliveryRequired),
+		errors.Is(err, ErrMethodUnavailable),
+		errors.Is(err, ErrAddressRequired),
+		errors.Is(err, ErrInvalidAmount),
+		errors.Is(err, currencyservice.ErrUnknownCurrency),
+		errors.Is(err, currencyservice.ErrInvalidCurrency),
+		errors.Is(err, promoservice.ErrPromoCodeInactive),
+		errors.Is(err, promoservice.ErrPromoCodeExhausted),
+		errors.Is(err, promoservice.ErrPromoCodeUserLimit),
+		errors.Is(err, promoservice.ErrMinOrderAmount):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidTransition),
+		errors.Is(err, ErrPaymentInProgress),
+		errors.Is(err, ErrOutOfStock):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func toCheckout(request *dto.OrderRequest) Checkout {
+	items := make([]Item, 0, len(request.Items))
+	for _, item := range request.Items {
+		items = append(items, Item{BookId: item.BookId, Quantity: item.Quantity})
+	}
+
+	return Checkout{
+		Items:            items,
+		Code:             request.Code,
+		Currency:         request.Currency,
+		DeliveryMethodId: request.DeliveryMethodId,
+		AddressId:        request.AddressId,
+	}
+}
+
+func toOrderResponse(order *entities.Order) dto.OrderResponse {
+	return dto.OrderResponse{
+		Id:                 order.Id,
+		UserId:             order.UserId,
+		Kind:               order.Kind,
+		Status:             order.Status,
+		OrderQuoteResponse: toOrderQuoteResponse(order),
+		CreatedAt:          order.CreatedAt,
+		UpdatedAt:          order.UpdatedAt,
+	}
+}
+
+func toOrderQuoteResponse(order *entities.Order) dto.OrderQuoteResponse {
+	items := make([]dto.OrderItemResponse, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, dto.OrderItemResponse{
+			BookId:      item.BookId,
+			Title:       item.Title,
+			Quantity:    item.Quantity,
+			UnitPrice:   pricing.FromFloat(item.UnitPrice),
+			Discount:    pricing.FromFloat(item.Discount),
+			TaxCategory: item.TaxCategory,
+			TaxRate:     item.TaxRate,
+			Tax:         pricing.FromFloat(item.Tax),
+			Total:       pricing.FromFloat(item.Total),
+		})
+	}
+
+	response := dto.OrderQuoteResponse{
+		Currency:     order.Currency,
+		ExchangeRate: order.ExchangeRate,
+		PromoCode:    order.PromoCode,
+		Items:        items,
+		Subtotal:     pricing.FromFloat(order.Subtotal),
+		Discount:     pricing.FromFloat(order.Discount),
+		DeliveryCost: pricing.FromFloat(order.DeliveryCost),
+		Tax:          pricing.FromFloat(order.Tax),
+		Total:        pricing.FromFloat(order.Total),
+	}
+
+	if order.DeliveryCode != nil {
+		response.Delivery = &dto.OrderDeliveryResponse{
+			MethodId: order.DeliveryMethodId,
+			Code:     *order.DeliveryCode,
+			Name:     deref(order.DeliveryName),
+			Cost:     pricing.FromFloat(order.DeliveryCost),
+		}
+	}
+
+	if order.Line1 != nil {
+		response.Address = &dto.OrderAddressResponse{
+			AddressId:  order.AddressId,
+			Recipient:  deref(order.Recipient),
+			Phone:      deref(order.Phone),
+			Country:    deref(order.Country),
+			City:       deref(order.City),
+			PostalCode: deref(order.PostalCode),
+			Line1:      *order.Line1,
+			Line2:      order.Line2,
+		}
+	}
+
+	return response
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
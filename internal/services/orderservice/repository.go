@@ -0,0 +1,387 @@
+package orderservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"story-book/internal/services/promoservice"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type orderRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderRepository(db *gorm.DB) OrderRepository {
+	return &orderRepository{db: db}
+}
+
+func (r *orderRepository) ReadBooks(ctx context.Context, ids []string) ([]entities.Book, error) {
+	var books []entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Select("id", "title", "format", "tax_category").
+		Where("id IN ?", ids).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// Create saves an order with its items, takes its printed copies out of
+// stock and consumes a use of its promo code in one transaction, so a failed
+// insert never uses up the code or the stock. The book and code rows are
+// locked so that concurrent checkouts cannot oversell either.
+func (r *orderRepository) Create(ctx context.Context, order *entities.Order) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if order.Kind == KindBooks {
+			if err := reserveStock(tx, order.Items); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+
+		if order.PromoCode == nil || order.UserId == nil {
+			return nil
+		}
+
+		return redeemCode(tx, *order.PromoCode, *order.UserId, order.Id, order.CreatedAt)
+	})
+}
+
+func (r *orderRepository) ReadAll(ctx context.Context, userId, status string, offset, limit int) ([]entities.Order, error) {
+	query := r.db.WithContext(ctx).Preload("Items", byTitle)
+
+	if userId != "" {
+		query = query.Where("user_id = ?", userId)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var orders []entities.Order
+	if err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) ReadById(ctx context.Context, id string) (*entities.Order, error) {
+	return readOrder(r.db.WithContext(ctx), id)
+}
+
+// ChangeStatus moves an order from one status to another. The order is locked
+// so that a concurrent payment cannot slip past the check. An order is not
+// cancelled while one of its payments may still be captured.
+func (r *orderRepository) ChangeStatus(ctx context.Context, id, from, to string, now time.Time) (*entities.Order, error) {
+	var order *entities.Order
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = readOrder(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != from {
+			return ErrInvalidTransition
+		}
+
+		if to == StatusCancelled {
+			var open int64
+			if err = tx.
+				Model(&entities.Payment{}).
+				Where("order_id = ? AND status IN ?", id, []string{"pending", "authorized"}).
+				Count(&open).Error; err != nil {
+				return err
+			}
+			if open > 0 {
+				return ErrPaymentInProgress
+			}
+		}
+
+		if err = tx.
+			Model(&entities.Order{}).
+			Where("id = ?", id).
+			Updates(map[string]any{"status": to, "updated_at": now}).Error; err != nil {
+			return err
+		}
+
+		if to == StatusCancelled {
+			release := releaseStock
+			if order.Kind == KindPreorder {
+				release = cancelPreorder
+			}
+			if err = release(tx, id, now); err != nil {
+				return err
+			}
+			if err = releaseCode(tx, id); err != nil {
+				return err
+			}
+			if err = reverseRedemptions(tx, id, now); err != nil {
+				return err
+			}
+		}
+
+		order.Status = to
+		order.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// reserveStock takes the copies of printed books on order out of stock.
+// Copies queued pre-orders are waiting for are held for them and cannot be
+// ordered. E-books have no stock. Books are locked in id order so that
+// concurrent checkouts of the same books cannot deadlock.
+func reserveStock(tx *gorm.DB, items []entities.OrderItem) error {
+	quantities := make(map[string]int, len(items))
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.BookId == nil {
+			continue
+		}
+		quantities[*item.BookId] += item.Quantity
+		ids = append(ids, *item.BookId)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var books []entities.Book
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "amount").
+		Where("id IN ? AND (format IS NULL OR format <> ?)", ids, entities.FormatEbook).
+		Order("id").
+		Find(&books).Error; err != nil {
+		return err
+	}
+
+	var queued []struct {
+		BookId   string
+		Quantity int
+	}
+	if err := tx.
+		Model(&entities.Preorder{}).
+		Select("book_id, SUM(quantity) AS quantity").
+		Where("book_id IN ? AND status = ?", ids, "queued").
+		Group("book_id").
+		Scan(&queued).Error; err != nil {
+		return err
+	}
+	held := make(map[string]int, len(queued))
+	for _, row := range queued {
+		held[row.BookId] = row.Quantity
+	}
+
+	for _, book := range books {
+		quantity := quantities[book.Id]
+		if book.Amount-held[book.Id] < quantity {
+			return ErrOutOfStock
+		}
+
+		if err := tx.
+			Model(&entities.Book{}).
+			Where("id = ?", book.Id).
+			Update("amount", gorm.Expr("amount - ?", quantity)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseStock puts the printed copies of a cancelled order back in stock.
+// Raw SQL so books moved to the trash get their copies back too.
+func releaseStock(tx *gorm.DB, orderId string, _ time.Time) error {
+	return tx.Exec(`
+		UPDATE books SET amount = books.amount + order_items.quantity
+		FROM order_items
+		WHERE order_items.order_id = ?
+			AND books.id = order_items.book_id
+			AND (books.format IS NULL OR books.format <> ?)`,
+		orderId, entities.FormatEbook).Error
+}
+
+// cancelPreorder cancels the pre-order of a cancelled pre-order order. The
+// copies already allocated to it go back in stock.
+func cancelPreorder(tx *gorm.DB, orderId string, now time.Time) error {
+	var preorders []entities.Preorder
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status IN ?", orderId, []string{"queued", "allocated"}).
+		Find(&preorders).Error; err != nil {
+		return err
+	}
+
+	for _, preorder := range preorders {
+		if preorder.Status == "allocated" {
+			if err := tx.Exec(
+				"UPDATE books SET amount = amount + ? WHERE id = ?",
+				preorder.Quantity, preorder.BookId,
+			).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.
+			Model(&entities.Preorder{}).
+			Where("id = ?", preorder.Id).
+			Updates(map[string]any{"status": "cancelled", "updated_at": now}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func redeemCode(tx *gorm.DB, code, userId, orderId string, now time.Time) error {
+	var promoCode entities.PromoCode
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).
+		First(&promoCode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return promoservice.ErrPromoCodeNotFound
+		}
+		return err
+	}
+
+	if promoCode.MaxUses != nil && promoCode.UsedCount >= *promoCode.MaxUses {
+		return promoservice.ErrPromoCodeExhausted
+	}
+
+	if promoCode.MaxUsesPerUser != nil {
+		var used int64
+		if err := tx.
+			Model(&entities.PromoCodeRedemption{}).
+			Where("promo_code_id = ? AND user_id = ?", promoCode.Id, userId).
+			Count(&used).Error; err != nil {
+			return err
+		}
+		if used >= int64(*promoCode.MaxUsesPerUser) {
+			return promoservice.ErrPromoCodeUserLimit
+		}
+	}
+
+	if err := tx.
+		Model(&entities.PromoCode{}).
+		Where("id = ?", promoCode.Id).
+		Update("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&entities.PromoCodeRedemption{
+		Id:          uuid.NewString(),
+		PromoCodeId: promoCode.Id,
+		UserId:      userId,
+		OrderId:     &orderId,
+		CreatedAt:   now,
+	}).Error
+}
+
+// releaseCode gives back the promo code uses of a cancelled order. The code
+// may have been deleted since, which does not stop its count going down.
+func releaseCode(tx *gorm.DB, orderId string) error {
+	var redemptions []entities.PromoCodeRedemption
+	if err := tx.
+		Where("order_id = ?", orderId).
+		Find(&redemptions).Error; err != nil {
+		return err
+	}
+
+	for _, redemption := range redemptions {
+		if err := tx.Delete(&redemption).Error; err != nil {
+			return err
+		}
+
+		if err := tx.
+			Unscoped().
+			Model(&entities.PromoCode{}).
+			Where("id = ? AND used_count > 0", redemption.PromoCodeId).
+			Update("used_count", gorm.Expr("used_count - 1")).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reverseRedemptions puts what gift cards and store credit paid towards a
+// cancelled order back on the cards, recording a reverse transaction for
+// each redemption.
+func reverseRedemptions(tx *gorm.DB, orderId string, now time.Time) error {
+	var redemptions []entities.GiftCardTransaction
+	if err := tx.
+		Where("order_id = ? AND kind = ?", orderId, "redeem").
+		Order("created_at").
+		Find(&redemptions).Error; err != nil {
+		return err
+	}
+
+	for _, redemption := range redemptions {
+		var card entities.GiftCard
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", redemption.GiftCardId).
+			First(&card).Error; err != nil {
+			return err
+		}
+
+		balance := pricing.FromFloat(card.Balance) - pricing.FromFloat(redemption.Amount)
+
+		if err := tx.
+			Model(&entities.GiftCard{}).
+			Where("id = ?", card.Id).
+			Updates(map[string]any{"balance": balance.Float(), "updated_at": now}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&entities.GiftCardTransaction{
+			Id:           uuid.NewString(),
+			GiftCardId:   card.Id,
+			Kind:         "reverse",
+			Amount:       -redemption.Amount,
+			BalanceAfter: balance.Float(),
+			OrderId:      &orderId,
+			CreatedAt:    now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readOrder(db *gorm.DB, id string) (*entities.Order, error) {
+	var order entities.Order
+	if err := db.
+		Preload("Items", byTitle).
+		Where("id = ?", id).
+		First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+func byTitle(db *gorm.DB) *gorm.DB {
+	return db.Order("title")
+}
@@ -0,0 +1,464 @@
+package orderservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"story-book/internal/services/addressservice"
+	"story-book/internal/services/deliveryservice"
+	"story-book/internal/services/promoservice"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Order statuses. An order is placed pending and becomes paid once its
+// payment succeeds. A failed payment makes it failed until the customer
+// opens a new one, which makes it pending again; only pending and failed
+// orders can be cancelled.
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+const (
+	maxItems    = 50
+	maxQuantity = 100
+	// maxGiftCardAmount caps a bought gift card in any currency.
+	maxGiftCardAmount = pricing.Money(10000000)
+)
+
+// Order kinds. A gift card order buys a gift card of its total and has no
+// lines; the card is minted once the order is paid. A pre-order order pays
+// for a pre-order: it takes no stock when placed, as the pre-order queue
+// allocates its copies.
+const (
+	KindBooks    = "books"
+	KindGiftCard = "gift_card"
+	KindPreorder = "preorder"
+)
+
+var statuses = map[string]bool{
+	StatusPending:   true,
+	StatusPaid:      true,
+	StatusFailed:    true,
+	StatusCancelled: true,
+}
+
+// AwaitsPayment reports whether an order in status can still be paid.
+func AwaitsPayment(status string) bool {
+	return status == StatusPending || status == StatusFailed
+}
+
+type OrderRepository interface {
+	ReadBooks(ctx context.Context, ids []string) ([]entities.Book, error)
+	Create(ctx context.Context, order *entities.Order) error
+	ReadAll(ctx context.Context, userId, status string, offset, limit int) ([]entities.Order, error)
+	ReadById(ctx context.Context, id string) (*entities.Order, error)
+	ChangeStatus(ctx context.Context, id, from, to string, now time.Time) (*entities.Order, error)
+}
+
+// Quoter prices items in the base currency with promotions and a promo code.
+type Quoter interface {
+	Quote(ctx context.Context, userId string, items []promoservice.QuoteItem, code string) (*promoservice.Quote, error)
+}
+
+// Converter picks the order currency and the prices pinned in it.
+type Converter interface {
+	Resolve(ctx context.Context, currency, userId string) (string, pricing.Rate, error)
+	Overrides(ctx context.Context, currency string, bookIds []string) (map[string]pricing.Money, error)
+}
+
+// Delivery prices the delivery methods for a parcel in the base currency.
+type Delivery interface {
+	QuoteDelivery(ctx context.Context, items []deliveryservice.Item) (*deliveryservice.Quote, error)
+}
+
+// Addresses reads the user's saved addresses.
+type Addresses interface {
+	ReadAddress(ctx context.Context, userId, id string) (*entities.Address, error)
+}
+
+// Taxer taxes a price by the rule of its category in force at a moment.
+type Taxer interface {
+	Tax(ctx context.Context, category string, price pricing.Money, at time.Time) (*pricing.Tax, error)
+}
+
+type Item struct {
+	BookId   string
+	Quantity int
+}
+
+// Checkout is what the customer submits to price or place an order.
+type Checkout struct {
+	Items            []Item
+	Code             string
+	Currency         string
+	DeliveryMethodId string
+	AddressId        string
+}
+
+type orderService struct {
+	repo      OrderRepository
+	quoter    Quoter
+	converter Converter
+	delivery  Delivery
+	addresses Addresses
+	taxer     Taxer
+	rounding  pricing.Rounding
+}
+
+func NewOrderService(repo OrderRepository, quoter Quoter, converter Converter, delivery Delivery, addresses Addresses, taxer Taxer, rounding pricing.Rounding) OrderService {
+	return &orderService{
+		repo:      repo,
+		quoter:    quoter,
+		converter: converter,
+		delivery:  delivery,
+		addresses: addresses,
+		taxer:     taxer,
+		rounding:  rounding,
+	}
+}
+
+// QuoteOrder prices a checkout exactly as CreateOrder would, delivery and
+// address included, without placing the order or using the promo code.
+func (s *orderService) QuoteOrder(ctx context.Context, userId string, checkout Checkout) (*entities.Order, error) {
+	return s.price(ctx, userId, checkout)
+}
+
+// CreateOrder prices the checkout and places a pending order. The currency
+// defaults to the user's preference; the rate used is stored with the order,
+// as are the delivery method and address. Printed copies are taken out of
+// stock and a promo code use is consumed together with saving the order;
+// cancelling the order gives both back.
+func (s *orderService) CreateOrder(ctx context.Context, userId string, checkout Checkout) (*entities.Order, error) {
+	order, err := s.price(ctx, userId, checkout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.repo.Create(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// CreatePreorderOrder prices a pre-order's book like CreateOrder and places
+// a pending pre-order order for it. No copies are taken out of stock.
+func (s *orderService) CreatePreorderOrder(ctx context.Context, userId string, checkout Checkout) (*entities.Order, error) {
+	order, err := s.price(ctx, userId, checkout)
+	if err != nil {
+		return nil, err
+	}
+	order.Kind = KindPreorder
+
+	if err = s.repo.Create(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// CreateGiftCardOrder places a pending order for a gift card of amount in
+// currency, which defaults like a book order's. Gift cards are not taxed
+// when sold: tax is due on what they are later spent on.
+func (s *orderService) CreateGiftCardOrder(ctx context.Context, userId string, amount pricing.Money, currency string) (*entities.Order, error) {
+	if amount <= 0 || amount > maxGiftCardAmount {
+		return nil, ErrInvalidAmount
+	}
+
+	currency, rate, err := s.converter.Resolve(ctx, currency, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	order := &entities.Order{
+		Id:           uuid.NewString(),
+		UserId:       &userId,
+		Kind:         KindGiftCard,
+		Status:       StatusPending,
+		Currency:     currency,
+		ExchangeRate: rate.Float(),
+		Subtotal:     amount.Float(),
+		Total:        amount.Float(),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err = s.repo.Create(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ReadOrders lists orders, newest first. Clients see only their own.
+func (s *orderService) ReadOrders(ctx context.Context, userId, role, status string, page, limit int) ([]entities.Order, error) {
+	if status != "" && !statuses[status] {
+		return nil, ErrInvalidStatus
+	}
+
+	if role != "client" {
+		userId = ""
+	}
+
+	return s.repo.ReadAll(ctx, userId, status, (page-1)*limit, limit)
+}
+
+func (s *orderService) ReadOrder(ctx context.Context, userId, role, id string) (*entities.Order, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	order, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == "client" && (order.UserId == nil || *order.UserId != userId) {
+		return nil, ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// CancelOrder cancels an order awaiting payment, gives back its promo code
+// use and puts gift card redemptions back on the cards. A pre-order order
+// cancels its pre-order too. Clients may cancel only their own.
+func (s *orderService) CancelOrder(ctx context.Context, userId, role, id string) (*entities.Order, error) {
+	order, err := s.ReadOrder(ctx, userId, role, id)
+	if err != nil {
+		return nil, err
+	}
+
+	from := StatusPending
+	if order.Status == StatusFailed {
+		from = StatusFailed
+	}
+
+	return s.repo.ChangeStatus(ctx, id, from, StatusCancelled, time.Now())
+}
+
+// price builds an order without saving it. Promotions and the promo code are
+// evaluated in the base currency, and the code discount is shared between
+// the lines it covers. Each line is then converted to the order currency,
+// keeping a pinned price if staff set one, and taxed by its category, so
+// the order totals are plain sums of the lines plus delivery.
+func (s *orderService) price(ctx context.Context, userId string, checkout Checkout) (*entities.Order, error) {
+	items := checkout.Items
+
+	if len(items) == 0 {
+		return nil, ErrEmptyOrder
+	}
+	if len(items) > maxItems {
+		return nil, ErrTooManyItems
+	}
+
+	seen := make(map[string]bool, len(items))
+	quoteItems := make([]promoservice.QuoteItem, 0, len(items))
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Quantity < 1 || item.Quantity > maxQuantity {
+			return nil, ErrInvalidQuantity
+		}
+		if _, err := uuid.Parse(item.BookId); err != nil {
+			return nil, ErrBookNotFound
+		}
+		if seen[item.BookId] {
+			return nil, ErrDuplicateItem
+		}
+		seen[item.BookId] = true
+
+		quoteItems = append(quoteItems, promoservice.QuoteItem{BookId: item.BookId, Quantity: item.Quantity})
+		ids = append(ids, item.BookId)
+	}
+
+	currency, rate, err := s.converter.Resolve(ctx, checkout.Currency, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.quoter.Quote(ctx, userId, quoteItems, checkout.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	books, err := s.repo.ReadBooks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]*entities.Book, len(books))
+	for i := range books {
+		byId[books[i].Id] = &books[i]
+	}
+
+	overrides, err := s.converter.Overrides(ctx, currency, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	order := &entities.Order{
+		Id:           uuid.NewString(),
+		UserId:       &userId,
+		Kind:         KindBooks,
+		Status:       StatusPending,
+		Currency:     currency,
+		ExchangeRate: rate.Float(),
+		Items:        make([]entities.OrderItem, 0, len(quote.Lines)),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if quote.Code != "" {
+		order.PromoCode = &quote.Code
+	}
+
+	var subtotal, discount, tax, total pricing.Money
+	var parcel []deliveryservice.Item
+	for _, line := range quote.Lines {
+		book, ok := byId[line.BookId]
+		if !ok {
+			return nil, ErrBookNotFound
+		}
+
+		if book.Format == nil || *book.Format != entities.FormatEbook {
+			parcel = append(parcel, deliveryservice.Item{BookId: book.Id, Quantity: line.Quantity})
+		}
+
+		var override *pricing.Money
+		if cost, ok := overrides[line.BookId]; ok {
+			override = &cost
+		}
+		unit := pricing.ConvertBreakdown(pricing.Breakdown{Price: line.UnitPrice, FinalPrice: line.UnitFinal}, currency, rate, override, s.rounding)
+
+		// The whole line is converted against the unit list price just
+		// found, so a pinned price carries its discounts along.
+		gross := unit.Price.Mul(line.Quantity)
+		converted := pricing.ConvertBreakdown(pricing.Breakdown{
+			Price:      line.UnitPrice.Mul(line.Quantity),
+			FinalPrice: line.UnitFinal.Mul(line.Quantity) - line.CodeDiscount,
+		}, currency, rate, &gross, s.rounding)
+
+		lineTax, err := s.taxer.Tax(ctx, book.TaxCategory, converted.FinalPrice, now)
+		if err != nil {
+			return nil, err
+		}
+
+		bookId := book.Id
+		item := entities.OrderItem{
+			Id:          uuid.NewString(),
+			OrderId:     order.Id,
+			BookId:      &bookId,
+			Title:       book.Title,
+			Quantity:    line.Quantity,
+			UnitPrice:   unit.Price.Float(),
+			Discount:    converted.DiscountAmount.Float(),
+			TaxCategory: book.TaxCategory,
+			Total:       converted.FinalPrice.Float(),
+		}
+
+		lineTotal := converted.FinalPrice
+		if lineTax != nil {
+			taxRate := float64(lineTax.Rate) / 100
+			item.TaxRate = &taxRate
+			item.Tax = lineTax.Amount.Float()
+			item.Total = lineTax.Gross.Float()
+			lineTotal = lineTax.Gross
+			tax += lineTax.Amount
+		}
+
+		order.Items = append(order.Items, item)
+		subtotal += gross
+		discount += converted.DiscountAmount
+		total += lineTotal
+	}
+
+	if len(parcel) > 0 {
+		cost, err := s.ship(ctx, userId, checkout, parcel, rate, order)
+		if err != nil {
+			return nil, err
+		}
+		total += cost
+	}
+
+	order.Subtotal = subtotal.Float()
+	order.Discount = discount.Float()
+	order.Tax = tax.Float()
+	order.Total = total.Float()
+
+	return order, nil
+}
+
+// ship copies the chosen delivery method and address into the order and
+// returns the delivery cost in the order currency. Delivery is priced for
+// the printed books only and is not taxed.
+func (s *orderService) ship(ctx context.Context, userId string, checkout Checkout, parcel []deliveryservice.Item, rate pricing.Rate, order *entities.Order) (pricing.Money, error) {
+	if checkout.DeliveryMethodId == "" {
+		return 0, ErrDeliveryRequired
+	}
+
+	quote, err := s.delivery.QuoteDelivery(ctx, parcel)
+	if err != nil {
+		return 0, err
+	}
+
+	var option *deliveryservice.Option
+	for i := range quote.Options {
+		if quote.Options[i].Method.Id == checkout.DeliveryMethodId {
+			option = &quote.Options[i]
+			break
+		}
+	}
+	if option == nil {
+		return 0, ErrMethodNotFound
+	}
+	if !option.Available {
+		return 0, ErrMethodUnavailable
+	}
+
+	cost := pricing.Convert(option.Cost, rate, s.rounding)
+
+	method := option.Method
+	order.DeliveryMethodId = &method.Id
+	order.DeliveryCode = &method.Code
+	order.DeliveryName = &method.Name
+	order.DeliveryCost = cost.Float()
+
+	if method.Kind == deliveryservice.KindPickup {
+		return cost, nil
+	}
+
+	if checkout.AddressId == "" {
+		return 0, ErrAddressRequired
+	}
+	if _, err = uuid.Parse(checkout.AddressId); err != nil {
+		return 0, ErrAddressNotFound
+	}
+
+	address, err := s.addresses.ReadAddress(ctx, userId, checkout.AddressId)
+	if err != nil {
+		if errors.Is(err, addressservice.ErrAddressNotFound) {
+			return 0, ErrAddressNotFound
+		}
+		return 0, err
+	}
+
+	order.AddressId = &address.Id
+	order.Recipient = &address.Recipient
+	order.Phone = &address.Phone
+	order.Country = &address.Country
+	order.City = &address.City
+	order.PostalCode = &address.PostalCode
+	order.Line1 = &address.Line1
+	order.Line2 = address.Line2
+
+	return cost, nil
+}
@@ -0,0 +1,19 @@
+package paymentservice
+
+import "errors"
+
+var (
+	ErrPaymentNotFound   = errors.New("payment not found")
+	ErrUnknownProvider   = errors.New("unknown payment provider")
+	ErrInvalidSignature  = errors.New("invalid webhook signature")
+	ErrInvalidPayload    = errors.New("invalid webhook payload")
+	ErrInvalidAmount     = errors.New("amount must be positive")
+	ErrInvalidOrder      = errors.New("order_id is required")
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrOrderNotPending   = errors.New("order is not awaiting payment")
+	ErrNothingToPay      = errors.New("order is already covered")
+	ErrInvalidTransition = errors.New("payment is not in a state that allows this operation")
+	ErrAccessDenied      = errors.New("access denied")
+	ErrIntentNotFound    = errors.New("payment intent not found")
+	ErrGiftCardBought    = errors.New("payment has bought a gift card and cannot be refunded")
+)
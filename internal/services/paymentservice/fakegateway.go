@@ -0,0 +1,176 @@
+package paymentservice
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"story-book/internal/pricing"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const FakeProviderName = "fake"
+
+type fakeIntent struct {
+	amount   pricing.Money
+	refunded pricing.Money
+	status   string
+}
+
+// FakeGateway is an in-process provider for development and QA. Intents are
+// kept in memory; Confirm stands in for the customer entering card details.
+// Every state change is delivered as a signed webhook to webhookUrl, so the
+// same verification path as a real provider is exercised.
+type FakeGateway struct {
+	mu         sync.Mutex
+	intents    map[string]*fakeIntent
+	secret     []byte
+	webhookUrl string
+	client     *http.Client
+}
+
+func NewFakeGateway(secret, webhookUrl string) *FakeGateway {
+	return &FakeGateway{
+		intents:    make(map[string]*fakeIntent),
+		secret:     []byte(secret),
+		webhookUrl: webhookUrl,
+		client:     &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return FakeProviderName
+}
+
+func (g *FakeGateway) CreateIntent(_ context.Context, amount pricing.Money, _, _ string) (*Intent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := "fake_" + uuid.NewString()
+	g.intents[id] = &fakeIntent{amount: amount}
+
+	return &Intent{ProviderId: id, ClientSecret: id + "_secret"}, nil
+}
+
+// Confirm authorizes the intent, or fails it when succeed is false.
+func (g *FakeGateway) Confirm(providerId string, succeed bool) error {
+	event := EventFailed
+	if succeed {
+		event = EventAuthorized
+	}
+	return g.move(providerId, "", event)
+}
+
+func (g *FakeGateway) Capture(_ context.Context, providerId string) error {
+	return g.move(providerId, EventAuthorized, EventSucceeded)
+}
+
+// Refund returns amount of a captured intent. The intent is marked refunded
+// once nothing is left of it; partial refunds are reported with their amount
+// and leave it captured.
+func (g *FakeGateway) Refund(_ context.Context, providerId string, amount pricing.Money) error {
+	g.mu.Lock()
+	intent, ok := g.intents[providerId]
+	if !ok {
+		g.mu.Unlock()
+		return ErrIntentNotFound
+	}
+	if intent.status != EventSucceeded {
+		g.mu.Unlock()
+		return ErrInvalidTransition
+	}
+	if amount <= 0 || amount > intent.amount-intent.refunded {
+		g.mu.Unlock()
+		return ErrInvalidAmount
+	}
+	intent.refunded += amount
+	if intent.refunded == intent.amount {
+		intent.status = EventRefunded
+	}
+	g.mu.Unlock()
+
+	go g.deliver(&Event{Id: "evt_" + uuid.NewString(), Type: EventRefunded, ProviderId: providerId, Amount: amount})
+
+	return nil
+}
+
+func (g *FakeGateway) Status(_ context.Context, providerId string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	intent, ok := g.intents[providerId]
+	if !ok {
+		return "", ErrIntentNotFound
+	}
+	return intent.status, nil
+}
+
+func (g *FakeGateway) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	if !hmac.Equal([]byte(signature), []byte(g.sign(payload))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil || event.Id == "" || event.ProviderId == "" {
+		return nil, ErrInvalidPayload
+	}
+
+	return &event, nil
+}
+
+func (g *FakeGateway) move(providerId, from, to string) error {
+	g.mu.Lock()
+	intent, ok := g.intents[providerId]
+	if !ok {
+		g.mu.Unlock()
+		return ErrIntentNotFound
+	}
+	if intent.status != from {
+		g.mu.Unlock()
+		return ErrInvalidTransition
+	}
+	intent.status = to
+	g.mu.Unlock()
+
+	go g.deliver(&Event{Id: "evt_" + uuid.NewString(), Type: to, ProviderId: providerId})
+
+	return nil
+}
+
+// deliver posts the event to the webhook endpoint. A lost delivery is only
+// logged; the reconciler catches up through Status.
+func (g *FakeGateway) deliver(event *Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("fake gateway: failed to encode event %s: %v", event.Id, err)
+		return
+	}
+
+	request, err := http.NewRequest(http.MethodPost, g.webhookUrl, bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("fake gateway: failed to build webhook request: %v", err)
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(SignatureHeader, g.sign(payload))
+
+	response, err := g.client.Do(request)
+	if err != nil {
+		log.Printf("fake gateway: failed to deliver event %s: %v", event.Id, err)
+		return
+	}
+	_ = response.Body.Close()
+}
+
+func (g *FakeGateway) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
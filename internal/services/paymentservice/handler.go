@@ -0,0 +1,269 @@
+package paymentservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxWebhookSize caps the webhook body read into memory.
+const maxWebhookSize = 64 << 10
+
+type PaymentService interface {
+	CreatePayment(ctx context.Context, userId, orderId string) (*entities.Payment, string, error)
+	ReadPayment(ctx context.Context, userId, role, id string) (*entities.Payment, error)
+	Capture(ctx context.Context, id string) (*entities.Payment, error)
+	Refund(ctx context.Context, id string, amount pricing.Money) (*entities.Payment, error)
+	HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error
+	Reconcile(ctx context.Context) (int, error)
+}
+
+type PaymentHandler struct {
+	service PaymentService
+	fake    *FakeGateway
+}
+
+// NewPaymentHandler builds the handler. fake is nil unless the fake gateway
+// is the configured provider.
+func NewPaymentHandler(service PaymentService, fake *FakeGateway) *PaymentHandler {
+	return &PaymentHandler{service: service, fake: fake}
+}
+
+// CreatePayment
+// @Summary Создать платёж по заказу
+// @Description Сумма и валюта берутся из заказа за вычетом оплаты подарочными картами
+// @Tags payments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.PaymentRequest true "Заказ"
+// @Success 201 {object} dto.PaymentResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments [post]
+func (h *PaymentHandler) CreatePayment(c echo.Context) error {
+	var request dto.PaymentRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	payment, secret, err := h.service.CreatePayment(ctx, userId, request.OrderId)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidOrder):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrOrderNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrOrderNotPending), errors.Is(err, ErrNothingToPay):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := toPaymentResponse(payment)
+	response.ClientSecret = secret
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// ReadPayment
+// @Summary Получить платёж
+// @Tags payments
+// @Security BearerAuth
+// @Param id path string true "ID платежа"
+// @Produce json
+// @Success 200 {object} dto.PaymentResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments/{id} [get]
+func (h *PaymentHandler) ReadPayment(c echo.Context) error {
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	payment, err := h.service.ReadPayment(ctx, userId, role, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, ErrPaymentNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toPaymentResponse(payment))
+}
+
+// Capture
+// @Summary Списать авторизованный платёж
+// @Description Результат приходит вебхуком, статус платежа меняется асинхронно
+// @Tags payments
+// @Security BearerAuth
+// @Param id path string true "ID платежа"
+// @Produce json
+// @Success 202 {object} dto.PaymentResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments/{id}/capture [post]
+func (h *PaymentHandler) Capture(c echo.Context) error {
+	return h.staffAction(c, h.service.Capture)
+}
+
+// Refund
+// @Summary Вернуть оплаченный платёж
+// @Description Результат приходит вебхуком, статус платежа меняется асинхронно
+// @Tags payments
+// @Security BearerAuth
+// @Param id path string true "ID платежа"
+// @Produce json
+// @Success 202 {object} dto.PaymentResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments/{id}/refund [post]
+func (h *PaymentHandler) Refund(c echo.Context) error {
+	return h.staffAction(c, func(ctx context.Context, id string) (*entities.Payment, error) {
+		return h.service.Refund(ctx, id, 0)
+	})
+}
+
+// Webhook
+// @Summary Принять вебхук платёжного провайдера
+// @Tags payments
+// @Param provider path string true "Имя провайдера"
+// @Param X-Signature header string true "Подпись тела запроса"
+// @Accept json
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /payments/webhooks/{provider} [post]
+func (h *PaymentHandler) Webhook(c echo.Context) error {
+	payload, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookSize))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err = h.service.HandleWebhook(ctx, c.Param("provider"), payload, c.Request().Header.Get(SignatureHeader))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownProvider):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidSignature):
+			return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidPayload):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// FakeConfirm
+// @Summary Подтвердить платёж в тестовом шлюзе
+// @Description Доступно только сотрудникам при PAYMENT_PROVIDER=fake; заменяет ввод карты покупателем
+// @Tags payments
+// @Security BearerAuth
+// @Param providerId path string true "ID платежа у провайдера"
+// @Accept json
+// @Param request body dto.FakeConfirmRequest true "Успешна ли оплата"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /payments/fake/{providerId}/confirm [post]
+func (h *PaymentHandler) FakeConfirm(c echo.Context) error {
+	// Confirming stands in for the customer's bank. Letting customers do it
+	// would let them mark their own payments paid.
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	if h.fake == nil {
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: ErrUnknownProvider.Error()})
+	}
+
+	var request dto.FakeConfirmRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	if err := h.fake.Confirm(c.Param("providerId"), request.Succeed); err != nil {
+		switch {
+		case errors.Is(err, ErrIntentNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidTransition):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *PaymentHandler) staffAction(c echo.Context, action func(ctx context.Context, id string) (*entities.Payment, error)) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	payment, err := action(ctx, c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPaymentNotFound), errors.Is(err, ErrIntentNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrGiftCardBought):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, toPaymentResponse(payment))
+}
+
+func toPaymentResponse(payment *entities.Payment) dto.PaymentResponse {
+	return dto.PaymentResponse{
+		Id:         payment.Id,
+		OrderId:    payment.OrderId,
+		Provider:   payment.Provider,
+		ProviderId: payment.ProviderId,
+		Amount:     pricing.FromFloat(payment.Amount),
+		Refunded:   pricing.FromFloat(payment.RefundedAmount),
+		Currency:   payment.Currency,
+		Status:     payment.Status,
+		CreatedAt:  payment.CreatedAt,
+		UpdatedAt:  payment.UpdatedAt,
+	}
+}
@@ -0,0 +1,92 @@
+package paymentservice
+
+import (
+	"context"
+	"fmt"
+	"story-book/internal/pricing"
+)
+
+// Payment statuses as stored locally.
+const (
+	StatusPending    = "pending"
+	StatusAuthorized = "authorized"
+	StatusPaid       = "paid"
+	StatusFailed     = "failed"
+	StatusRefunded   = "refunded"
+)
+
+// SignatureHeader carries the webhook signature.
+const SignatureHeader = "X-Signature"
+
+// Webhook event types every provider reports in.
+const (
+	EventAuthorized = "payment.authorized"
+	EventSucceeded  = "payment.succeeded"
+	EventFailed     = "payment.failed"
+	EventRefunded   = "payment.refunded"
+)
+
+// Intent is a payment created at the provider and waiting for the customer.
+type Intent struct {
+	ProviderId   string
+	ClientSecret string
+}
+
+// Event is a verified webhook notification. Amount is set on refunds; zero
+// means the whole payment.
+type Event struct {
+	Id         string        `json:"id"`
+	Type       string        `json:"type"`
+	ProviderId string        `json:"provider_id"`
+	Amount     pricing.Money `json:"amount,omitempty"`
+}
+
+// Provider is a payment gateway. Calls that change state at the gateway only
+// start the change: the outcome arrives later as a webhook, or is picked up by
+// the reconciler through Status.
+type Provider interface {
+	Name() string
+	CreateIntent(ctx context.Context, amount pricing.Money, currency, reference string) (*Intent, error)
+	Capture(ctx context.Context, providerId string) error
+	Refund(ctx context.Context, providerId string, amount pricing.Money) error
+	// Status reports the current state of the payment as an event type, or
+	// an empty string while it is still pending.
+	Status(ctx context.Context, providerId string) (string, error)
+	VerifyWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// ProviderFactory builds a gateway from the secret its webhooks are signed
+// with and the URL they must be sent to.
+type ProviderFactory func(secret, webhookUrl string) Provider
+
+// providers are the gateways PAYMENT_PROVIDER may name. A real gateway is
+// made available by adding its factory here.
+var providers = map[string]ProviderFactory{
+	FakeProviderName: func(secret, webhookUrl string) Provider {
+		return NewFakeGateway(secret, webhookUrl)
+	},
+}
+
+// NewProvider builds the named gateway. Its webhooks are expected at
+// /payments/webhooks/{name} under publicUrl.
+func NewProvider(name, secret, publicUrl string) (Provider, error) {
+	factory, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+
+	return factory(secret, publicUrl+"/payments/webhooks/"+name), nil
+}
+
+// transitions lists, for each event type, the local statuses it may move a
+// payment out of. Events that arrive late or twice simply do not match.
+var transitions = map[string]struct {
+	from []string
+	to   string
+}{
+	EventAuthorized: {from: []string{StatusPending}, to: StatusAuthorized},
+	EventSucceeded:  {from: []string{StatusPending, StatusAuthorized}, to: StatusPaid},
+	EventFailed:     {from: []string{StatusPending, StatusAuthorized}, to: StatusFailed},
+	// A partial refund keeps the payment paid; see transition.
+	EventRefunded: {from: []string{StatusPaid}, to: StatusRefunded},
+}
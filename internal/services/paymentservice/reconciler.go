@@ -0,0 +1,39 @@
+package paymentservice
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunReconciler settles payments whose webhooks never arrived. It blocks
+// until ctx is done.
+func RunReconciler(ctx context.Context, service PaymentService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reconcile(ctx, service)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func reconcile(ctx context.Context, service PaymentService) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	updated, err := service.Reconcile(ctx)
+	if err != nil {
+		log.Printf("payment reconciliation failed: %v", err)
+		return
+	}
+
+	if updated > 0 {
+		log.Printf("reconciled %d payments", updated)
+	}
+}
@@ -0,0 +1,424 @@
+package paymentservice
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"story-book/internal/services/orderservice"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type paymentRepository struct {
+	db            *gorm.DB
+	downloadLimit int
+}
+
+// NewPaymentRepository stores payments. E-books of paid orders are granted
+// with downloadLimit downloads each.
+func NewPaymentRepository(db *gorm.DB, downloadLimit int) PaymentRepository {
+	return &paymentRepository{db: db, downloadLimit: downloadLimit}
+}
+
+// Create saves a new payment. An order whose last payment failed is
+// pending again while the new one is in progress. The order is locked and
+// checked again so that it cannot be cancelled while the payment is opened.
+func (r *paymentRepository) Create(ctx context.Context, payment *entities.Payment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order entities.Order
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "status").
+			Where("id = ?", payment.OrderId).
+			Take(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+		if !orderservice.AwaitsPayment(order.Status) {
+			return ErrOrderNotPending
+		}
+
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+
+		return tx.
+			Model(&entities.Order{}).
+			Where("id = ? AND status = ?", payment.OrderId, orderservice.StatusFailed).
+			Updates(map[string]any{"status": orderservice.StatusPending, "updated_at": payment.CreatedAt}).Error
+	})
+}
+
+func (r *paymentRepository) ReadById(ctx context.Context, id string) (*entities.Payment, error) {
+	var payment entities.Payment
+	if err := r.db.
+		WithContext(ctx).
+		Where("id = ?", id).
+		First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ReadOpen returns pending and authorized payments not touched since before.
+func (r *paymentRepository) ReadOpen(ctx context.Context, before time.Time, limit int) ([]entities.Payment, error) {
+	var payments []entities.Payment
+	if err := r.db.
+		WithContext(ctx).
+		Where("status IN ? AND updated_at < ?", []string{StatusPending, StatusAuthorized}, before).
+		Order("updated_at").
+		Limit(limit).
+		Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// HasSettled reports whether the order has a payment that is authorized or
+// paid, so that it is not charged twice.
+func (r *paymentRepository) HasSettled(ctx context.Context, orderId string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Payment{}).
+		Where("order_id = ? AND status IN ?", orderId, []string{StatusAuthorized, StatusPaid}).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ReadRedeemed returns how much of the order has been paid with gift cards
+// and store credit in the order currency, less redemptions put back.
+func (r *paymentRepository) ReadRedeemed(ctx context.Context, orderId, currency string) (pricing.Money, error) {
+	var redeemed float64
+	if err := r.db.
+		WithContext(ctx).
+		Table("gift_card_transactions AS t").
+		Joins("JOIN gift_cards AS c ON c.id = t.gift_card_id").
+		Where("t.order_id = ? AND t.kind IN ? AND c.currency = ?", orderId, []string{"redeem", "reverse"}, currency).
+		Select("COALESCE(-SUM(t.amount), 0)").
+		Scan(&redeemed).Error; err != nil {
+		return 0, err
+	}
+	return pricing.FromFloat(redeemed), nil
+}
+
+// HasGiftCard reports whether the payment has bought a gift card.
+func (r *paymentRepository) HasGiftCard(ctx context.Context, paymentId string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.GiftCard{}).
+		Where("payment_id = ?", paymentId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ApplyEvent records a webhook event and moves its payment along. An event
+// seen before is ignored, which makes redelivered webhooks harmless. It
+// reports whether the payment status changed.
+func (r *paymentRepository) ApplyEvent(ctx context.Context, provider string, event *Event) (bool, error) {
+	changed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment entities.Payment
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("provider = ? AND provider_id = ?", provider, event.ProviderId).
+			First(&payment).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		record := &entities.PaymentEvent{
+			Provider:   provider,
+			EventId:    event.Id,
+			Type:       event.Type,
+			ReceivedAt: time.Now(),
+		}
+		if payment.Id != "" {
+			record.PaymentId = &payment.Id
+		}
+
+		res := tx.
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(record)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 || payment.Id == "" {
+			return nil
+		}
+
+		changed, err = r.transition(tx, &payment, event.Type, event.Amount)
+		return err
+	})
+	return changed, err
+}
+
+// Transition moves a payment as if eventType had been received, without
+// recording an event. The reconciler uses it with states read from the
+// provider.
+func (r *paymentRepository) Transition(ctx context.Context, id, eventType string) (bool, error) {
+	changed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment entities.Payment
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&payment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPaymentNotFound
+			}
+			return err
+		}
+
+		var err error
+		changed, err = r.transition(tx, &payment, eventType, 0)
+		return err
+	})
+	return changed, err
+}
+
+// RequestRefund sets amount of a paid payment aside as a pending refund and
+// returns it; zero sets aside everything the payment has left. The payment
+// is locked, so concurrent refunds cannot exceed what it has left.
+func (r *paymentRepository) RequestRefund(ctx context.Context, id string, amount pricing.Money) (pricing.Money, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment entities.Payment
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&payment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPaymentNotFound
+			}
+			return err
+		}
+
+		if payment.Status != StatusPaid {
+			return ErrInvalidTransition
+		}
+
+		pending := pricing.FromFloat(payment.RefundPending)
+		left := pricing.FromFloat(payment.Amount) - pricing.FromFloat(payment.RefundedAmount) - pending
+		if amount == 0 {
+			amount = left
+		}
+		if amount <= 0 || amount > left {
+			return ErrInvalidAmount
+		}
+
+		return tx.
+			Model(&entities.Payment{}).
+			Where("id = ?", id).
+			Updates(map[string]any{"refund_pending": (pending + amount).Float(), "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+// CancelRefund gives back a pending refund the provider refused.
+func (r *paymentRepository) CancelRefund(ctx context.Context, id string, amount pricing.Money) error {
+	return r.db.
+		WithContext(ctx).
+		Model(&entities.Payment{}).
+		Where("id = ?", id).
+		Update("refund_pending", gorm.Expr("GREATEST(refund_pending - ?, 0)", amount.Float())).Error
+}
+
+// Touch marks a payment as looked at now without changing it.
+func (r *paymentRepository) Touch(ctx context.Context, id string) error {
+	return r.db.
+		WithContext(ctx).
+		Model(&entities.Payment{}).
+		Where("id = ?", id).
+		Update("updated_at", time.Now()).Error
+}
+
+// transition applies an event to a locked payment. A refund adds amount to
+// what has been refunded, zero meaning the rest of the payment, and the
+// payment is only marked refunded once nothing is left. A payment that
+// succeeds marks its order paid and grants its e-books in the same
+// transaction; one that fails
+// marks it failed unless another payment of the order is still open.
+func (r *paymentRepository) transition(tx *gorm.DB, payment *entities.Payment, eventType string, amount pricing.Money) (bool, error) {
+	rule, ok := transitions[eventType]
+	if !ok || !slices.Contains(rule.from, payment.Status) {
+		return false, nil
+	}
+
+	now := time.Now()
+	updates := map[string]any{"status": rule.to, "updated_at": now}
+
+	if eventType == EventRefunded {
+		paid := pricing.FromFloat(payment.Amount)
+		refunded := pricing.FromFloat(payment.RefundedAmount)
+		if amount <= 0 || refunded+amount > paid {
+			amount = paid - refunded
+		}
+		refunded += amount
+		if refunded < paid {
+			updates["status"] = payment.Status
+		}
+		updates["refunded_amount"] = refunded.Float()
+		payment.RefundedAmount = refunded.Float()
+
+		// The confirmed amount is no longer pending; a full refund settles
+		// everything that was.
+		pending := pricing.FromFloat(payment.RefundPending) - amount
+		if pending < 0 || refunded == paid {
+			pending = 0
+		}
+		updates["refund_pending"] = pending.Float()
+		payment.RefundPending = pending.Float()
+	}
+
+	if err := tx.
+		Model(&entities.Payment{}).
+		Where("id = ?", payment.Id).
+		Updates(updates).Error; err != nil {
+		return false, err
+	}
+
+	payment.Status = updates["status"].(string)
+
+	if payment.Status == StatusPaid && eventType == EventSucceeded {
+		// Orders are not cancelled while a payment is open, so the order is
+		// still awaiting payment here.
+		res := tx.
+			Model(&entities.Order{}).
+			Where("id = ? AND status <> ?", payment.OrderId, orderservice.StatusPaid).
+			Updates(map[string]any{"status": orderservice.StatusPaid, "updated_at": now})
+		if res.Error != nil {
+			return false, res.Error
+		}
+
+		if res.RowsAffected > 0 {
+			if err := awardPoints(tx, payment.OrderId); err != nil {
+				return false, err
+			}
+			if err := grantEbooks(tx, payment.OrderId, r.downloadLimit, now); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if payment.Status == StatusFailed {
+		open := tx.
+			Model(&entities.Payment{}).
+			Select("1").
+			Where("order_id = ? AND id <> ? AND status IN ?", payment.OrderId, payment.Id, []string{StatusPending, StatusAuthorized, StatusPaid})
+		if err := tx.
+			Model(&entities.Order{}).
+			Where("id = ? AND status = ? AND NOT EXISTS (?)", payment.OrderId, orderservice.StatusPending, open).
+			Updates(map[string]any{"status": orderservice.StatusFailed, "updated_at": now}).Error; err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// awardPoints credits the buyer of a newly paid order with one loyalty point
+// per whole unit of the base currency paid for each line. Each line keeps
+// its points, so that returning its books can take them back.
+func awardPoints(tx *gorm.DB, orderId string) error {
+	var order entities.Order
+	if err := tx.
+		Preload("Items").
+		Where("id = ?", orderId).
+		First(&order).Error; err != nil {
+		return err
+	}
+
+	if order.UserId == nil {
+		return nil
+	}
+
+	rate := pricing.RateFromFloat(order.ExchangeRate)
+
+	total := 0
+	for _, item := range order.Items {
+		points := int(pricing.ConvertToBase(pricing.FromFloat(item.Total), rate, pricing.Down).Units())
+		if points == 0 {
+			continue
+		}
+
+		if err := tx.
+			Model(&entities.OrderItem{}).
+			Where("id = ?", item.Id).
+			Update("points", points).Error; err != nil {
+			return err
+		}
+		total += points
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	return tx.
+		Model(&entities.User{}).
+		Where("id = ?", *order.UserId).
+		Update("points", gorm.Expr("COALESCE(points, 0) + ?", total)).Error
+}
+
+// grantEbooks adds every e-book of a newly paid order to its buyer's
+// library. E-books already granted by staff are left as they are.
+func grantEbooks(tx *gorm.DB, orderId string, downloadLimit int, now time.Time) error {
+	var order entities.Order
+	if err := tx.
+		Select("id", "user_id").
+		Where("id = ?", orderId).
+		First(&order).Error; err != nil {
+		return err
+	}
+
+	if order.UserId == nil {
+		return nil
+	}
+
+	var bookIds []string
+	if err := tx.
+		Table("order_items AS i").
+		Joins("JOIN books AS b ON b.id = i.book_id").
+		Where("i.order_id = ? AND b.format = ?", orderId, entities.FormatEbook).
+		Distinct().
+		Pluck("i.book_id", &bookIds).Error; err != nil {
+		return err
+	}
+
+	for _, bookId := range bookIds {
+		if err := tx.
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Omit("Book", "User", "Files").
+			Create(&entities.EbookPurchase{
+				Id:            uuid.NewString(),
+				UserId:        *order.UserId,
+				BookId:        bookId,
+				OrderId:       orderId,
+				DownloadLimit: downloadLimit,
+				CreatedAt:     now,
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
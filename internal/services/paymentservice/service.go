@@ -0,0 +1,248 @@
+package paymentservice
+
+import (
+	"context"
+	"errors"
+	"log"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"story-book/internal/services/orderservice"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// reconcileAfter is how long a payment may wait for a webhook before the
+	// reconciler asks the provider directly.
+	reconcileAfter = time.Minute
+	reconcileBatch = 100
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *entities.Payment) error
+	ReadById(ctx context.Context, id string) (*entities.Payment, error)
+	ReadOpen(ctx context.Context, before time.Time, limit int) ([]entities.Payment, error)
+	HasSettled(ctx context.Context, orderId string) (bool, error)
+	ReadRedeemed(ctx context.Context, orderId, currency string) (pricing.Money, error)
+	HasGiftCard(ctx context.Context, paymentId string) (bool, error)
+	ApplyEvent(ctx context.Context, provider string, event *Event) (bool, error)
+	Transition(ctx context.Context, id, eventType string) (bool, error)
+	Touch(ctx context.Context, id string) error
+	RequestRefund(ctx context.Context, id string, amount pricing.Money) (pricing.Money, error)
+	CancelRefund(ctx context.Context, id string, amount pricing.Money) error
+}
+
+// Orders reads the orders payments are made for.
+type Orders interface {
+	ReadOrder(ctx context.Context, userId, role, id string) (*entities.Order, error)
+}
+
+type paymentService struct {
+	repo     PaymentRepository
+	orders   Orders
+	provider Provider
+}
+
+func NewPaymentService(repo PaymentRepository, orders Orders, provider Provider) PaymentService {
+	return &paymentService{repo: repo, orders: orders, provider: provider}
+}
+
+// CreatePayment opens a payment intent at the provider for one of the
+// user's pending orders. The amount is what the order still owes after gift
+// card redemptions, in the order currency. The returned client secret is
+// what the storefront hands to the provider's checkout widget.
+func (s *paymentService) CreatePayment(ctx context.Context, userId, orderId string) (*entities.Payment, string, error) {
+	if orderId == "" {
+		return nil, "", ErrInvalidOrder
+	}
+
+	order, err := s.orders.ReadOrder(ctx, userId, "client", orderId)
+	if err != nil {
+		if errors.Is(err, orderservice.ErrOrderNotFound) {
+			return nil, "", ErrOrderNotFound
+		}
+		return nil, "", err
+	}
+
+	if !orderservice.AwaitsPayment(order.Status) {
+		return nil, "", ErrOrderNotPending
+	}
+
+	settled, err := s.repo.HasSettled(ctx, order.Id)
+	if err != nil {
+		return nil, "", err
+	}
+	if settled {
+		return nil, "", ErrOrderNotPending
+	}
+
+	redeemed, err := s.repo.ReadRedeemed(ctx, order.Id, order.Currency)
+	if err != nil {
+		return nil, "", err
+	}
+
+	amount := pricing.FromFloat(order.Total) - redeemed
+	if amount <= 0 {
+		return nil, "", ErrNothingToPay
+	}
+
+	payment := &entities.Payment{
+		Id:       uuid.NewString(),
+		OrderId:  order.Id,
+		UserId:   &userId,
+		Amount:   amount.Float(),
+		Currency: order.Currency,
+	}
+
+	intent, err := s.provider.CreateIntent(ctx, amount, payment.Currency, payment.Id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+
+	payment.Provider = s.provider.Name()
+	payment.ProviderId = intent.ProviderId
+	payment.Status = StatusPending
+	payment.CreatedAt = now
+	payment.UpdatedAt = now
+
+	if err = s.repo.Create(ctx, payment); err != nil {
+		return nil, "", err
+	}
+
+	return payment, intent.ClientSecret, nil
+}
+
+// ReadPayment returns a payment. Clients may only read their own.
+func (s *paymentService) ReadPayment(ctx context.Context, userId, role, id string) (*entities.Payment, error) {
+	payment, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == "client" && (payment.UserId == nil || *payment.UserId != userId) {
+		return nil, ErrPaymentNotFound
+	}
+
+	return payment, nil
+}
+
+func (s *paymentService) Capture(ctx context.Context, id string) (*entities.Payment, error) {
+	payment, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status != StatusAuthorized {
+		return nil, ErrInvalidTransition
+	}
+
+	if err = s.provider.Capture(ctx, payment.ProviderId); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// Refund returns amount of a paid payment to the customer; zero refunds
+// whatever has not been refunded yet. Partial refunds leave the payment paid.
+// A payment that has bought a gift card cannot be refunded: the card keeps
+// its value. The amount is set aside before the provider is asked, so it
+// cannot be refunded again before the provider's webhook confirms it.
+func (s *paymentService) Refund(ctx context.Context, id string, amount pricing.Money) (*entities.Payment, error) {
+	payment, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status != StatusPaid {
+		return nil, ErrInvalidTransition
+	}
+
+	spent, err := s.repo.HasGiftCard(ctx, payment.Id)
+	if err != nil {
+		return nil, err
+	}
+	if spent {
+		return nil, ErrGiftCardBought
+	}
+
+	amount, err = s.repo.RequestRefund(ctx, payment.Id, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.provider.Refund(ctx, payment.ProviderId, amount); err != nil {
+		if cancelErr := s.repo.CancelRefund(ctx, payment.Id, amount); cancelErr != nil {
+			log.Printf("failed to cancel refund of payment %s: %v", payment.Id, cancelErr)
+		}
+		return nil, err
+	}
+
+	payment.RefundPending = (pricing.FromFloat(payment.RefundPending) + amount).Float()
+	return payment, nil
+}
+
+// HandleWebhook verifies and applies a webhook from the named provider.
+// Redelivered events are accepted and ignored.
+func (s *paymentService) HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error {
+	if provider != s.provider.Name() {
+		return ErrUnknownProvider
+	}
+
+	event, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repo.ApplyEvent(ctx, provider, event)
+	return err
+}
+
+// Reconcile asks the provider about payments that have been waiting for a
+// webhook for too long, and applies whatever state it reports.
+func (s *paymentService) Reconcile(ctx context.Context) (int, error) {
+	payments, err := s.repo.ReadOpen(ctx, time.Now().Add(-reconcileAfter), reconcileBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, payment := range payments {
+		if payment.Provider != s.provider.Name() {
+			continue
+		}
+
+		// An intent the provider does not know may only be lagging behind,
+		// so the payment is left open rather than failed. It is moved to the
+		// back of the queue so that it does not hold up the others.
+		status, err := s.provider.Status(ctx, payment.ProviderId)
+		if err != nil {
+			if errors.Is(err, ErrIntentNotFound) {
+				log.Printf("payment %s: intent %s not found at %s", payment.Id, payment.ProviderId, payment.Provider)
+				if err = s.repo.Touch(ctx, payment.Id); err != nil {
+					return updated, err
+				}
+			} else {
+				log.Printf("failed to reconcile payment %s: %v", payment.Id, err)
+			}
+			continue
+		}
+
+		if status == "" {
+			continue
+		}
+
+		changed, err := s.repo.Transition(ctx, payment.Id, status)
+		if err != nil {
+			return updated, err
+		}
+		if changed {
+			updated++
+		}
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,19 @@
+package preorderservice
+
+import "errors"
+
+var (
+	ErrPreorderNotFound  = errors.New("pre-order not found")
+	ErrBookNotFound      = errors.New("book not found")
+	ErrNotOpen           = errors.New("book is not open for pre-order")
+	ErrPreorderExists    = errors.New("book is already pre-ordered")
+	ErrInvalidQuantity   = errors.New("quantity must be between 1 and 10")
+	ErrInvalidReceipt    = errors.New("received quantity must be between 1 and 100000")
+	ErrNotStocked        = errors.New("e-books have no stock")
+	ErrInvalidTransition = errors.New("pre-order is not in a state that allows this operation")
+	ErrNotPaid           = errors.New("pre-order is not paid")
+	ErrInvalidStatus     = errors.New("invalid status")
+	ErrAccessDenied      = errors.New("access denied")
+	ErrInvalidPage       = errors.New("invalid page")
+	ErrInvalidLimit      = errors.New("invalid limit")
+)
@@ -0,0 +1,325 @@
+package preorderservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/services/currencyservice"
+	"story-book/internal/services/deliveryservice"
+	"story-book/internal/services/orderservice"
+	"story-book/internal/services/promoservice"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PreorderService interface {
+	CreatePreorder(ctx context.Context, userId string, placement Placement) (*entities.Preorder, error)
+	ReadPreorders(ctx context.Context, userId, role, bookId, status string, page, limit int) ([]entities.Preorder, error)
+	ReadPreorder(ctx context.Context, userId, role, id string) (*entities.Preorder, error)
+	CancelPreorder(ctx context.Context, userId, role, id string) (*entities.Preorder, error)
+	ShipPreorder(ctx context.Context, id string) (*entities.Preorder, error)
+	ReceiveStock(ctx context.Context, actorId, bookId string, quantity int) (*entities.StockReceipt, *entities.Book, error)
+	NotifyDelay(ctx context.Context, book *entities.Book) (int, error)
+}
+
+type PreorderHandler struct {
+	service PreorderService
+}
+
+func NewPreorderHandler(service PreorderService) *PreorderHandler {
+	return &PreorderHandler{service: service}
+}
+
+// CreatePreorder
+// @Summary Оформить предзаказ книги
+// @Description Книга должна быть открыта для предзаказа; одна активная заявка на книгу у пользователя. Вместе с предзаказом оформляется заказ, который рассчитывается и оплачивается как обычный; экземпляры со склада он не списывает, их распределяет очередь предзаказов
+// @Tags preorders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.PreorderRequest true "Книга, количество, промокод, валюта, способ доставки и адрес"
+// @Success 201 {object} dto.PreorderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /preorders [post]
+func (h *PreorderHandler) CreatePreorder(c echo.Context) error {
+	var request dto.PreorderRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	preorder, err := h.service.CreatePreorder(ctx, c.Get("id").(string), Placement{
+		BookId:           request.BookId,
+		Quantity:         request.Quantity,
+		Code:             request.Code,
+		Currency:         request.Currency,
+		DeliveryMethodId: request.DeliveryMethodId,
+		AddressId:        request.AddressId,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound),
+			errors.Is(err, orderservice.ErrBookNotFound),
+			errors.Is(err, orderservice.ErrMethodNotFound),
+			errors.Is(err, orderservice.ErrAddressNotFound),
+			errors.Is(err, deliveryservice.ErrBookNotFound),
+			errors.Is(err, promoservice.ErrBookNotFound),
+			errors.Is(err, promoservice.ErrPromoCodeNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrNotOpen), errors.Is(err, ErrPreorderExists):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidQuantity),
+			errors.Is(err, orderservice.ErrDeliveryRequired),
+			errors.Is(err, orderservice.ErrMethodUnavailable),
+			errors.Is(err, orderservice.ErrAddressRequired),
+			errors.Is(err, currencyservice.ErrUnknownCurrency),
+			errors.Is(err, currencyservice.ErrInvalidCurrency),
+			errors.Is(err, promoservice.ErrPromoCodeInactive),
+			errors.Is(err, promoservice.ErrPromoCodeExhausted),
+			errors.Is(err, promoservice.ErrPromoCodeUserLimit),
+			errors.Is(err, promoservice.ErrMinOrderAmount):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toPreorderResponse(preorder))
+}
+
+// ReadPreorders
+// @Summary Получить список предзаказов
+// @Description Клиент видит только свои предзаказы
+// @Tags preorders
+// @Security BearerAuth
+// @Param book_id query string false "ID книги"
+// @Param status query string false "Статус: queued, allocated, shipped, cancelled"
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Produce json
+// @Success 200 {array} dto.PreorderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /preorders [get]
+func (h *PreorderHandler) ReadPreorders(c echo.Context) error {
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidPage.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	preorders, err := h.service.ReadPreorders(ctx, userId, role, c.QueryParam("book_id"), c.QueryParam("status"), page, limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.PreorderResponse, 0, len(preorders))
+	for i := range preorders {
+		response = append(response, toPreorderResponse(&preorders[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReadPreorder
+// @Summary Получить предзаказ
+// @Tags preorders
+// @Security BearerAuth
+// @Param id path string true "ID предзаказа"
+// @Produce json
+// @Success 200 {object} dto.PreorderResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /preorders/{id} [get]
+func (h *PreorderHandler) ReadPreorder(c echo.Context) error {
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	preorder, err := h.service.ReadPreorder(ctx, userId, role, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, ErrPreorderNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toPreorderResponse(preorder))
+}
+
+// CancelPreorder
+// @Summary Отменить предзаказ
+// @Description Отменить можно только предзаказ, ожидающий в очереди, пока его заказ не оплачен и по нему нет незавершённого платежа. Заказ отменяется вместе с предзаказом
+// @Tags preorders
+// @Security BearerAuth
+// @Param id path string true "ID предзаказа"
+// @Produce json
+// @Success 200 {object} dto.PreorderResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /preorders/{id}/cancel [post]
+func (h *PreorderHandler) CancelPreorder(c echo.Context) error {
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	preorder, err := h.service.CancelPreorder(ctx, userId, role, c.Param("id"))
+	if err != nil {
+		return preorderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toPreorderResponse(preorder))
+}
+
+// ShipPreorder
+// @Summary Отметить предзаказ отправленным
+// @Description Заказ предзаказа должен быть оплачен. Покупатель получает уведомление об отправке
+// @Tags preorders
+// @Security BearerAuth
+// @Param id path string true "ID предзаказа"
+// @Produce json
+// @Success 200 {object} dto.PreorderResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /preorders/{id}/ship [post]
+func (h *PreorderHandler) ShipPreorder(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	preorder, err := h.service.ShipPreorder(ctx, c.Param("id"))
+	if err != nil {
+		return preorderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toPreorderResponse(preorder))
+}
+
+// ReceiveStock
+// @Summary Оприходовать поступление книги на склад
+// @Description Поступившие экземпляры распределяются по очереди предзаказов в порядке оформления. Остаток остаётся на складе, но пока в очереди есть предзаказы, он удерживается для них и в продажу не поступает
+// @Tags preorders
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Accept json
+// @Produce json
+// @Param request body dto.StockReceiptRequest true "Количество поступивших экземпляров"
+// @Success 201 {object} dto.StockReceiptResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/stock-receipts [post]
+func (h *PreorderHandler) ReceiveStock(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.StockReceiptRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	receipt, book, err := h.service.ReceiveStock(ctx, c.Get("id").(string), c.Param("id"), request.Quantity)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidReceipt):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrNotStocked):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, dto.StockReceiptResponse{
+		Id:        receipt.Id,
+		BookId:    receipt.BookId,
+		Quantity:  receipt.Quantity,
+		Allocated: receipt.Allocated,
+		Held:      receipt.Held,
+		Amount:    book.Amount,
+		Preorder:  book.Preorder != nil && *book.Preorder,
+		CreatedAt: receipt.CreatedAt,
+	})
+}
+
+func preorderError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrPreorderNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidTransition),
+		errors.Is(err, ErrNotPaid),
+		errors.Is(err, orderservice.ErrInvalidTransition),
+		errors.Is(err, orderservice.ErrPaymentInProgress):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func toPreorderResponse(preorder *entities.Preorder) dto.PreorderResponse {
+	return dto.PreorderResponse{
+		Id:          preorder.Id,
+		BookId:      preorder.BookId,
+		UserId:      preorder.UserId,
+		Quantity:    preorder.Quantity,
+		Status:      preorder.Status,
+		OrderId:     preorder.OrderId,
+		AllocatedAt: preorder.AllocatedAt,
+		ShippedAt:   preorder.ShippedAt,
+		CreatedAt:   preorder.CreatedAt,
+		UpdatedAt:   preorder.UpdatedAt,
+	}
+}
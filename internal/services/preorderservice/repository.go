@@ -0,0 +1,250 @@
+package preorderservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uniqueViolationCode = "23505"
+
+type preorderRepository struct {
+	db *gorm.DB
+}
+
+func NewPreorderRepository(db *gorm.DB) PreorderRepository {
+	return &preorderRepository{db: db}
+}
+
+func (r *preorderRepository) ReadBook(ctx context.Context, id string) (*entities.Book, error) {
+	var book entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Select("id", "title", "author", "amount", "release_date", "preorder").
+		Where("id = ?", id).
+		First(&book).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *preorderRepository) Create(ctx context.Context, preorder *entities.Preorder) error {
+	if err := r.db.WithContext(ctx).Create(preorder).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrPreorderExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *preorderRepository) ReadAll(ctx context.Context, userId, bookId, status string, offset, limit int) ([]entities.Preorder, error) {
+	query := r.db.WithContext(ctx)
+
+	if userId != "" {
+		query = query.Where("user_id = ?", userId)
+	}
+	if bookId != "" {
+		query = query.Where("book_id = ?", bookId)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var preorders []entities.Preorder
+	if err := query.
+		Order("seq DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&preorders).Error; err != nil {
+		return nil, err
+	}
+	return preorders, nil
+}
+
+func (r *preorderRepository) ReadById(ctx context.Context, id string) (*entities.Preorder, error) {
+	return readPreorder(r.db.WithContext(ctx), id)
+}
+
+// ReadWaiting returns the pre-orders of a book that have not shipped yet.
+func (r *preorderRepository) ReadWaiting(ctx context.Context, bookId string) ([]entities.Preorder, error) {
+	var preorders []entities.Preorder
+	if err := r.db.
+		WithContext(ctx).
+		Where("book_id = ? AND status IN ?", bookId, []string{StatusQueued, StatusAllocated}).
+		Order("seq").
+		Find(&preorders).Error; err != nil {
+		return nil, err
+	}
+	return preorders, nil
+}
+
+// ChangeStatus moves a pre-order from one status to another and queues the
+// customer's notification, if any, in the same transaction.
+func (r *preorderRepository) ChangeStatus(ctx context.Context, id, from, to string, now time.Time, notification *entities.AlertNotification) (*entities.Preorder, error) {
+	var preorder *entities.Preorder
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		preorder, err = readPreorder(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+		if err != nil {
+			return err
+		}
+
+		if preorder.Status != from {
+			return ErrInvalidTransition
+		}
+
+		updates := map[string]any{"status": to, "updated_at": now}
+		if to == StatusShipped {
+			updates["shipped_at"] = now
+			preorder.ShippedAt = &now
+		}
+
+		if err = tx.
+			Model(&entities.Preorder{}).
+			Where("id = ?", id).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+
+		preorder.Status = to
+		preorder.UpdatedAt = now
+
+		if notification == nil {
+			return nil
+		}
+		_, err = queue(tx, []entities.AlertNotification{*notification})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preorder, nil
+}
+
+// ReceiveStock posts a stock receipt and hands the new copies to queued
+// pre-orders in the order they were placed. Allocation stops at the first
+// pre-order that does not fit, so nobody is overtaken by a later, smaller
+// one. What is left over stays in stock but is held for the pre-orders
+// still queued: orders cannot take it until the queue is served. The book
+// and its queue are locked for the whole transaction.
+func (r *preorderRepository) ReceiveStock(ctx context.Context, receipt *entities.StockReceipt) (*entities.Book, error) {
+	var book entities.Book
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "amount", "preorder", "format").
+			Where("id = ?", receipt.BookId).
+			First(&book).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookNotFound
+			}
+			return err
+		}
+
+		if book.Format != nil && *book.Format == entities.FormatEbook {
+			return ErrNotStocked
+		}
+
+		var queued []entities.Preorder
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("book_id = ? AND status = ?", receipt.BookId, StatusQueued).
+			Order("seq").
+			Find(&queued).Error; err != nil {
+			return err
+		}
+
+		available := book.Amount + receipt.Quantity
+		allocated := 0
+		for _, preorder := range queued {
+			if preorder.Quantity > available {
+				break
+			}
+
+			if err := tx.
+				Model(&entities.Preorder{}).
+				Where("id = ?", preorder.Id).
+				Updates(map[string]any{
+					"status":       StatusAllocated,
+					"allocated_at": receipt.CreatedAt,
+					"updated_at":   receipt.CreatedAt,
+				}).Error; err != nil {
+				return err
+			}
+
+			available -= preorder.Quantity
+			allocated++
+		}
+
+		waiting := 0
+		for _, preorder := range queued[allocated:] {
+			waiting += preorder.Quantity
+		}
+
+		// The pre-order window closes once the whole queue is served.
+		closed := allocated == len(queued)
+		preorder := book.Preorder != nil && *book.Preorder && !closed
+		book.Amount = available
+		book.Preorder = &preorder
+
+		if err := tx.
+			Model(&entities.Book{}).
+			Where("id = ?", book.Id).
+			Updates(map[string]any{"amount": book.Amount, "preorder": preorder}).Error; err != nil {
+			return err
+		}
+
+		receipt.Allocated = allocated
+		receipt.Held = min(available, waiting)
+		return tx.Create(receipt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *preorderRepository) Notify(ctx context.Context, notifications []entities.AlertNotification) (int, error) {
+	return queue(r.db.WithContext(ctx), notifications)
+}
+
+// queue adds notifications to the alert outbox, which the alert worker
+// delivers. A notification already queued under the same dedup key is
+// skipped.
+func queue(db *gorm.DB, notifications []entities.AlertNotification) (int, error) {
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+
+	res := db.
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "dedup_key"}}, DoNothing: true}).
+		Create(&notifications)
+	return int(res.RowsAffected), res.Error
+}
+
+func readPreorder(db *gorm.DB, id string) (*entities.Preorder, error) {
+	var preorder entities.Preorder
+	if err := db.
+		Where("id = ?", id).
+		First(&preorder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPreorderNotFound
+		}
+		return nil, err
+	}
+	return &preorder, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,284 @@
+package preorderservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"story-book/internal/entities"
+	"story-book/internal/services/orderservice"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Pre-order statuses. A pre-order waits in the queue until a stock receipt
+// allocates copies to it, and is shipped by staff once its order is paid.
+// Only queued pre-orders can be cancelled.
+const (
+	StatusQueued    = "queued"
+	StatusAllocated = "allocated"
+	StatusShipped   = "shipped"
+	StatusCancelled = "cancelled"
+)
+
+// Notification kinds queued in the alert outbox.
+const (
+	KindShipped = "preorder_shipped"
+	KindDelayed = "preorder_delayed"
+)
+
+const (
+	maxQuantity = 10
+	maxReceipt  = 100000
+	dateLayout  = "2006-01-02"
+)
+
+var statuses = map[string]bool{
+	StatusQueued:    true,
+	StatusAllocated: true,
+	StatusShipped:   true,
+	StatusCancelled: true,
+}
+
+type PreorderRepository interface {
+	ReadBook(ctx context.Context, id string) (*entities.Book, error)
+	Create(ctx context.Context, preorder *entities.Preorder) error
+	ReadAll(ctx context.Context, userId, bookId, status string, offset, limit int) ([]entities.Preorder, error)
+	ReadById(ctx context.Context, id string) (*entities.Preorder, error)
+	ReadWaiting(ctx context.Context, bookId string) ([]entities.Preorder, error)
+	ChangeStatus(ctx context.Context, id, from, to string, now time.Time, notification *entities.AlertNotification) (*entities.Preorder, error)
+	ReceiveStock(ctx context.Context, receipt *entities.StockReceipt) (*entities.Book, error)
+	Notify(ctx context.Context, notifications []entities.AlertNotification) (int, error)
+}
+
+// Orders places, reads and cancels the orders pre-orders are paid through.
+type Orders interface {
+	CreatePreorderOrder(ctx context.Context, userId string, checkout orderservice.Checkout) (*entities.Order, error)
+	ReadOrder(ctx context.Context, userId, role, id string) (*entities.Order, error)
+	CancelOrder(ctx context.Context, userId, role, id string) (*entities.Order, error)
+}
+
+// Placement is what the customer submits to pre-order a book: how many
+// copies, and how the order paying for them is priced and delivered.
+type Placement struct {
+	BookId           string
+	Quantity         int
+	Code             string
+	Currency         string
+	DeliveryMethodId string
+	AddressId        string
+}
+
+type preorderService struct {
+	repo   PreorderRepository
+	orders Orders
+}
+
+func NewPreorderService(repo PreorderRepository, orders Orders) PreorderService {
+	return &preorderService{repo: repo, orders: orders}
+}
+
+// CreatePreorder puts the user in the queue of a book open for pre-order
+// and places the order the pre-order is paid through, priced like any
+// other order. A user has at most one queued pre-order per book.
+func (s *preorderService) CreatePreorder(ctx context.Context, userId string, placement Placement) (*entities.Preorder, error) {
+	if placement.Quantity < 1 || placement.Quantity > maxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+
+	book, err := s.repo.ReadBook(ctx, placement.BookId)
+	if err != nil {
+		return nil, err
+	}
+
+	if book.Preorder == nil || !*book.Preorder {
+		return nil, ErrNotOpen
+	}
+
+	queued, err := s.repo.ReadAll(ctx, userId, book.Id, StatusQueued, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(queued) > 0 {
+		return nil, ErrPreorderExists
+	}
+
+	order, err := s.orders.CreatePreorderOrder(ctx, userId, orderservice.Checkout{
+		Items:            []orderservice.Item{{BookId: book.Id, Quantity: placement.Quantity}},
+		Code:             placement.Code,
+		Currency:         placement.Currency,
+		DeliveryMethodId: placement.DeliveryMethodId,
+		AddressId:        placement.AddressId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	preorder := &entities.Preorder{
+		Id:        uuid.NewString(),
+		BookId:    book.Id,
+		UserId:    userId,
+		Quantity:  placement.Quantity,
+		Status:    StatusQueued,
+		OrderId:   &order.Id,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err = s.repo.Create(ctx, preorder); err != nil {
+		// The order must not outlive a pre-order that was never queued.
+		if _, cancelErr := s.orders.CancelOrder(ctx, userId, "client", order.Id); cancelErr != nil {
+			log.Printf("failed to cancel order %s of pre-order: %v", order.Id, cancelErr)
+		}
+		return nil, err
+	}
+
+	return preorder, nil
+}
+
+// ReadPreorders lists pre-orders, newest first. Clients see only their own.
+func (s *preorderService) ReadPreorders(ctx context.Context, userId, role, bookId, status string, page, limit int) ([]entities.Preorder, error) {
+	if status != "" && !statuses[status] {
+		return nil, ErrInvalidStatus
+	}
+
+	if role != "client" {
+		userId = ""
+	}
+
+	return s.repo.ReadAll(ctx, userId, bookId, status, (page-1)*limit, limit)
+}
+
+func (s *preorderService) ReadPreorder(ctx context.Context, userId, role, id string) (*entities.Preorder, error) {
+	preorder, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == "client" && preorder.UserId != userId {
+		return nil, ErrPreorderNotFound
+	}
+
+	return preorder, nil
+}
+
+// CancelPreorder takes a queued pre-order out of the queue by cancelling
+// its order, which cancels the pre-order with it. An order that is paid or
+// being paid cannot be cancelled. Clients may cancel only their own.
+func (s *preorderService) CancelPreorder(ctx context.Context, userId, role, id string) (*entities.Preorder, error) {
+	preorder, err := s.ReadPreorder(ctx, userId, role, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if preorder.OrderId == nil {
+		return s.repo.ChangeStatus(ctx, id, StatusQueued, StatusCancelled, time.Now(), nil)
+	}
+
+	if preorder.Status != StatusQueued {
+		return nil, ErrInvalidTransition
+	}
+
+	if _, err = s.orders.CancelOrder(ctx, userId, role, *preorder.OrderId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadById(ctx, id)
+}
+
+// ShipPreorder marks an allocated pre-order as shipped and notifies the
+// customer. Its order must have been paid.
+func (s *preorderService) ShipPreorder(ctx context.Context, id string) (*entities.Preorder, error) {
+	preorder, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if preorder.OrderId != nil {
+		order, err := s.orders.ReadOrder(ctx, preorder.UserId, "staff", *preorder.OrderId)
+		if err != nil {
+			if errors.Is(err, orderservice.ErrOrderNotFound) {
+				return nil, ErrNotPaid
+			}
+			return nil, err
+		}
+		if order.Status != orderservice.StatusPaid {
+			return nil, ErrNotPaid
+		}
+	}
+
+	book, err := s.repo.ReadBook(ctx, preorder.BookId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	notification := &entities.AlertNotification{
+		Id:        uuid.NewString(),
+		UserId:    preorder.UserId,
+		BookId:    preorder.BookId,
+		Kind:      KindShipped,
+		Message:   fmt.Sprintf("Your pre-order of %q by %s has shipped.", book.Title, book.Author),
+		DedupKey:  "preorder:" + preorder.Id + ":shipped",
+		CreatedAt: now,
+	}
+
+	return s.repo.ChangeStatus(ctx, id, StatusAllocated, StatusShipped, now, notification)
+}
+
+// ReceiveStock posts a stock receipt for a book and allocates the new copies
+// to its pre-order queue.
+func (s *preorderService) ReceiveStock(ctx context.Context, actorId, bookId string, quantity int) (*entities.StockReceipt, *entities.Book, error) {
+	if quantity < 1 || quantity > maxReceipt {
+		return nil, nil, ErrInvalidReceipt
+	}
+
+	receipt := &entities.StockReceipt{
+		Id:        uuid.NewString(),
+		BookId:    bookId,
+		Quantity:  quantity,
+		ActorId:   &actorId,
+		CreatedAt: time.Now(),
+	}
+
+	book, err := s.repo.ReceiveStock(ctx, receipt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return receipt, book, nil
+}
+
+// NotifyDelay tells everyone still waiting for a book that its release date
+// has moved. Each new date is announced once per pre-order.
+func (s *preorderService) NotifyDelay(ctx context.Context, book *entities.Book) (int, error) {
+	if book.ReleaseDate == nil {
+		return 0, nil
+	}
+
+	waiting, err := s.repo.ReadWaiting(ctx, book.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	date := book.ReleaseDate.Format(dateLayout)
+	now := time.Now()
+
+	notifications := make([]entities.AlertNotification, 0, len(waiting))
+	for _, preorder := range waiting {
+		notifications = append(notifications, entities.AlertNotification{
+			Id:        uuid.NewString(),
+			UserId:    preorder.UserId,
+			BookId:    book.Id,
+			Kind:      KindDelayed,
+			Message:   fmt.Sprintf("Your pre-order of %q by %s is delayed: the book is now expected on %s.", book.Title, book.Author, date),
+			DedupKey:  "preorder:" + preorder.Id + ":delayed:" + date,
+			CreatedAt: now,
+		})
+	}
+
+	return s.repo.Notify(ctx, notifications)
+}
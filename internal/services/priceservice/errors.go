@@ -0,0 +1,12 @@
+package priceservice
+
+import "errors"
+
+var (
+	ErrBookNotFound            = errors.New("book not found")
+	ErrScheduledChangeNotFound = errors.New("scheduled price change not found")
+	ErrEmptyPriceChange        = errors.New("cost or discount must be set")
+	ErrInvalidCost             = errors.New("cost must be positive")
+	ErrInvalidDiscount         = errors.New("discount must be between 0 and 100")
+	ErrEffectiveAtNotInFuture  = errors.New("effective_at must be in the future")
+)
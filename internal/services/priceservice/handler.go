@@ -0,0 +1,190 @@
+package priceservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PriceService interface {
+	ReadTimeline(ctx context.Context, bookId string) ([]entities.BookPrice, []entities.ScheduledPriceChange, error)
+	SchedulePriceChange(ctx context.Context, change *entities.ScheduledPriceChange) (*entities.ScheduledPriceChange, error)
+	CancelScheduledChange(ctx context.Context, bookId, id string) error
+	ApplyDueChanges(ctx context.Context) (int, error)
+}
+
+type PriceHandler struct {
+	service PriceService
+}
+
+func NewPriceHandler(service PriceService) *PriceHandler {
+	return &PriceHandler{service: service}
+}
+
+// ReadTimeline
+// @Summary Получить историю цен книги
+// @Tags prices
+// @Param id path string true "ID книги"
+// @Produce json
+// @Success 200 {object} dto.PriceTimelineResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/prices [get]
+func (h *PriceHandler) ReadTimeline(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	history, pending, err := h.service.ReadTimeline(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrBookNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := dto.PriceTimelineResponse{
+		BookId:    id,
+		History:   make([]dto.PricePointResponse, 0, len(history)),
+		Scheduled: make([]dto.ScheduledPriceResponse, 0, len(pending)),
+	}
+
+	for _, price := range history {
+		response.History = append(response.History, dto.PricePointResponse{
+			Cost:        price.Cost,
+			Discount:    validate(price.Discount),
+			EffectiveAt: price.EffectiveAt,
+		})
+	}
+
+	for _, change := range pending {
+		response.Scheduled = append(response.Scheduled, toScheduledPriceResponse(&change))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// SchedulePriceChange
+// @Summary Запланировать изменение цены
+// @Tags prices
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Accept json
+// @Produce json
+// @Param request body dto.ScheduledPriceRequest true "Новая цена и/или скидка"
+// @Success 201 {object} dto.ScheduledPriceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/prices/scheduled [post]
+func (h *PriceHandler) SchedulePriceChange(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	var request dto.ScheduledPriceRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	change, err := h.service.SchedulePriceChange(ctx, &entities.ScheduledPriceChange{
+		BookId:      id,
+		Cost:        request.Cost,
+		Discount:    request.Discount,
+		EffectiveAt: request.EffectiveAt,
+		CreatedBy:   &userId,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrEmptyPriceChange),
+			errors.Is(err, ErrInvalidCost),
+			errors.Is(err, ErrInvalidDiscount),
+			errors.Is(err, ErrEffectiveAtNotInFuture):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toScheduledPriceResponse(change))
+}
+
+// CancelScheduledChange
+// @Summary Отменить запланированное изменение цены
+// @Tags prices
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Param changeId path string true "ID запланированного изменения"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/prices/scheduled/{changeId} [delete]
+func (h *PriceHandler) CancelScheduledChange(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	changeId := c.Param("changeId")
+	if id == "" || changeId == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.CancelScheduledChange(ctx, id, changeId)
+	if err != nil {
+		if errors.Is(err, ErrScheduledChangeNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toScheduledPriceResponse(change *entities.ScheduledPriceChange) dto.ScheduledPriceResponse {
+	return dto.ScheduledPriceResponse{
+		Id:          change.Id,
+		Cost:        change.Cost,
+		Discount:    change.Discount,
+		EffectiveAt: change.EffectiveAt,
+	}
+}
+
+func validate[T any](t *T) T {
+	if t != nil {
+		return *t
+	}
+
+	var zero T
+	return zero
+}
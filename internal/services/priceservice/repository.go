@@ -0,0 +1,152 @@
+package priceservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type priceRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceRepository(db *gorm.DB) PriceRepository {
+	return &priceRepository{db: db}
+}
+
+func (r *priceRepository) BookExists(ctx context.Context, bookId string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Book{}).
+		Where("id = ?", bookId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *priceRepository) ReadHistory(ctx context.Context, bookId string) ([]entities.BookPrice, error) {
+	var prices []entities.BookPrice
+	if err := r.db.
+		WithContext(ctx).
+		Where("book_id = ?", bookId).
+		Order("effective_at").
+		Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (r *priceRepository) ReadPending(ctx context.Context, bookId string) ([]entities.ScheduledPriceChange, error) {
+	var changes []entities.ScheduledPriceChange
+	if err := r.db.
+		WithContext(ctx).
+		Where("book_id = ? AND applied_at IS NULL", bookId).
+		Order("effective_at").
+		Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *priceRepository) CreateScheduled(ctx context.Context, change *entities.ScheduledPriceChange) error {
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+func (r *priceRepository) DeleteScheduled(ctx context.Context, bookId, id string) error {
+	res := r.db.
+		WithContext(ctx).
+		Where("id = ? AND book_id = ? AND applied_at IS NULL", id, bookId).
+		Delete(&entities.ScheduledPriceChange{})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrScheduledChangeNotFound
+	}
+
+	return nil
+}
+
+// ApplyDue applies every pending change whose effective time has passed and
+// returns the ones written to a book. Rows are locked with SKIP LOCKED so
+// several instances can run the worker.
+func (r *priceRepository) ApplyDue(ctx context.Context, now time.Time) ([]AppliedChange, error) {
+	var applied []AppliedChange
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var changes []entities.ScheduledPriceChange
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("applied_at IS NULL AND effective_at <= ?", now).
+			Order("effective_at").
+			Find(&changes).Error; err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			var before entities.Book
+			err := tx.
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("id", "cost", "discount").
+				Where("id = ?", change.BookId).
+				First(&before).Error
+
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				// A book in the trash keeps its price; the change is still
+				// marked as applied so it is not retried forever.
+			case err != nil:
+				return err
+			default:
+				after := before
+				if change.Cost != nil {
+					after.Cost = *change.Cost
+				}
+				if change.Discount != nil {
+					after.Discount = change.Discount
+				}
+
+				if err := tx.
+					Model(&entities.Book{}).
+					Where("id = ?", change.BookId).
+					Updates(map[string]any{"cost": after.Cost, "discount": after.Discount}).Error; err != nil {
+					return err
+				}
+
+				if err := tx.Create(&entities.BookPrice{
+					Id:          uuid.NewString(),
+					BookId:      change.BookId,
+					Cost:        after.Cost,
+					Discount:    after.Discount,
+					EffectiveAt: change.EffectiveAt,
+				}).Error; err != nil {
+					return err
+				}
+				applied = append(applied, AppliedChange{Change: change, Before: before, After: after})
+			}
+
+			if err := tx.
+				Model(&entities.ScheduledPriceChange{}).
+				Where("id = ?", change.Id).
+				Update("applied_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,39 @@
+package priceservice
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunScheduler applies scheduled price changes once their effective time has
+// come. It blocks until ctx is done.
+func RunScheduler(ctx context.Context, service PriceService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		apply(ctx, service)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func apply(ctx context.Context, service PriceService) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	applied, err := service.ApplyDueChanges(ctx)
+	if err != nil {
+		log.Printf("scheduled price changes failed: %v", err)
+		return
+	}
+
+	if applied > 0 {
+		log.Printf("applied %d scheduled price changes", applied)
+	}
+}
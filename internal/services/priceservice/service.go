@@ -0,0 +1,118 @@
+package priceservice
+
+import (
+	"context"
+	"log"
+	"story-book/internal/entities"
+	"story-book/internal/services/auditservice"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PriceRepository interface {
+	BookExists(ctx context.Context, bookId string) (bool, error)
+	ReadHistory(ctx context.Context, bookId string) ([]entities.BookPrice, error)
+	ReadPending(ctx context.Context, bookId string) ([]entities.ScheduledPriceChange, error)
+	CreateScheduled(ctx context.Context, change *entities.ScheduledPriceChange) error
+	DeleteScheduled(ctx context.Context, bookId, id string) error
+	ApplyDue(ctx context.Context, now time.Time) ([]AppliedChange, error)
+}
+
+type Auditor interface {
+	Record(ctx context.Context, action, entityType, entityId string, before, after any) error
+}
+
+// AppliedChange is a scheduled change written to a book, with the book's
+// price before and after it.
+type AppliedChange struct {
+	Change entities.ScheduledPriceChange
+	Before entities.Book
+	After  entities.Book
+}
+
+type priceService struct {
+	repo  PriceRepository
+	audit Auditor
+}
+
+func NewPriceService(repo PriceRepository, audit Auditor) PriceService {
+	return &priceService{repo: repo, audit: audit}
+}
+
+func (s *priceService) ReadTimeline(ctx context.Context, bookId string) ([]entities.BookPrice, []entities.ScheduledPriceChange, error) {
+	exists, err := s.repo.BookExists(ctx, bookId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, ErrBookNotFound
+	}
+
+	history, err := s.repo.ReadHistory(ctx, bookId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending, err := s.repo.ReadPending(ctx, bookId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return history, pending, nil
+}
+
+func (s *priceService) SchedulePriceChange(ctx context.Context, change *entities.ScheduledPriceChange) (*entities.ScheduledPriceChange, error) {
+	if change.Cost == nil && change.Discount == nil {
+		return nil, ErrEmptyPriceChange
+	}
+	if change.Cost != nil && *change.Cost <= 0 {
+		return nil, ErrInvalidCost
+	}
+	if change.Discount != nil && (*change.Discount < 0 || *change.Discount > 100) {
+		return nil, ErrInvalidDiscount
+	}
+	if !change.EffectiveAt.After(time.Now()) {
+		return nil, ErrEffectiveAtNotInFuture
+	}
+
+	exists, err := s.repo.BookExists(ctx, change.BookId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrBookNotFound
+	}
+
+	change.Id = uuid.NewString()
+
+	err = s.repo.CreateScheduled(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (s *priceService) CancelScheduledChange(ctx context.Context, bookId, id string) error {
+	return s.repo.DeleteScheduled(ctx, bookId, id)
+}
+
+// ApplyDueChanges applies the scheduled changes that are due and records each
+// in the audit log as an update made by the system.
+func (s *priceService) ApplyDueChanges(ctx context.Context) (int, error) {
+	applied, err := s.repo.ApplyDue(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, change := range applied {
+		actor := auditservice.Actor{Role: auditservice.RoleSystem, RequestId: "price-change-" + change.Change.Id}
+		err = s.audit.Record(auditservice.WithActor(ctx, actor), auditservice.ActionUpdate, auditservice.EntityBook, change.Before.Id, &change.Before, &change.After)
+		if err != nil {
+			log.Printf("failed to record audit of scheduled price change %s: %v", change.Change.Id, err)
+		}
+	}
+
+	return len(applied), nil
+}
@@ -0,0 +1,88 @@
+package promoservice
+
+import (
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"time"
+)
+
+// Item is a book with its genres and the quantity being bought.
+type Item struct {
+	Book     *entities.Book
+	Genres   []string
+	Quantity int
+}
+
+type Line struct {
+	BookId    string
+	Quantity  int
+	UnitPrice pricing.Money
+	UnitFinal pricing.Money
+	Campaigns []string
+	// CodeDiscount is the line's share of the promo code discount.
+	CodeDiscount pricing.Money
+}
+
+type Quote struct {
+	Lines            []Line
+	Subtotal         pricing.Money
+	CampaignDiscount pricing.Money
+	CodeDiscount     pricing.Money
+	Total            pricing.Money
+	Code             string
+}
+
+// Evaluate prices items with the active campaigns and an optional promo code.
+// It is shared by every place that needs a final amount, so carts and orders
+// always agree on the result.
+//
+// Lines are priced by the pricing engine. A promo code is applied to the
+// discounted subtotal; a non-combinable code only covers lines that no
+// campaign has touched.
+func Evaluate(engine pricing.Engine, items []Item, campaigns []entities.Campaign, code *entities.PromoCode, now time.Time) (*Quote, error) {
+	quote := &Quote{Lines: make([]Line, 0, len(items))}
+
+	for _, item := range items {
+		breakdown := engine.PriceBook(item.Book, item.Genres, campaigns, now)
+
+		line := Line{
+			BookId:    item.Book.Id,
+			Quantity:  item.Quantity,
+			UnitPrice: breakdown.Price,
+			UnitFinal: breakdown.FinalPrice,
+			Campaigns: breakdown.Campaigns,
+		}
+		quote.Lines = append(quote.Lines, line)
+
+		quote.Subtotal += line.UnitPrice.Mul(line.Quantity)
+		quote.CampaignDiscount += breakdown.DiscountAmount.Mul(line.Quantity)
+	}
+
+	discounted := quote.Subtotal - quote.CampaignDiscount
+
+	if code != nil {
+		if discounted < pricing.FromFloat(code.MinOrderAmount) {
+			return nil, ErrMinOrderAmount
+		}
+
+		var eligible pricing.Money
+		weights := make([]pricing.Money, len(quote.Lines))
+		for i, line := range quote.Lines {
+			if code.Combinable || len(line.Campaigns) == 0 {
+				weights[i] = line.UnitFinal.Mul(line.Quantity)
+				eligible += weights[i]
+			}
+		}
+
+		quote.CodeDiscount = engine.Discount(eligible, code.DiscountType, code.DiscountValue)
+		quote.Code = code.Code
+
+		for i, share := range pricing.Allocate(quote.CodeDiscount, weights) {
+			quote.Lines[i].CodeDiscount = share
+		}
+	}
+
+	quote.Total = discounted - quote.CodeDiscount
+
+	return quote, nil
+}
@@ -0,0 +1,27 @@
+package promoservice
+
+import "errors"
+
+var (
+	ErrInvalidPage           = errors.New("invalid page")
+	ErrInvalidLimit          = errors.New("invalid limit")
+	ErrCampaignNotFound      = errors.New("campaign not found")
+	ErrPromoCodeNotFound     = errors.New("promo code not found")
+	ErrPromoCodeExists       = errors.New("promo code already exists")
+	ErrPromoCodeInactive     = errors.New("promo code is not active")
+	ErrPromoCodeExhausted    = errors.New("promo code usage limit reached")
+	ErrPromoCodeUserLimit    = errors.New("promo code already used the maximum number of times")
+	ErrMinOrderAmount        = errors.New("order amount is below the promo code minimum")
+	ErrBookNotFound          = errors.New("book not found")
+	ErrEmptyQuote            = errors.New("quote must contain at least one item")
+	ErrInvalidQuantity       = errors.New("quantity must be positive")
+	ErrInvalidName           = errors.New("name is required")
+	ErrInvalidCode           = errors.New("code is required")
+	ErrInvalidDiscountType   = errors.New("discount_type must be percent or fixed")
+	ErrInvalidDiscountValue  = errors.New("invalid discount_value")
+	ErrInvalidPeriod         = errors.New("ends_at must be after starts_at")
+	ErrInvalidTarget         = errors.New("target kind must be genre, author, publisher or book; author and publisher targets must be IDs")
+	ErrNoTargets             = errors.New("campaign must have at least one target")
+	ErrInvalidMinOrderAmount = errors.New("min_order_amount must not be negative")
+	ErrInvalidUsageLimit     = errors.New("usage limits must be positive")
+)
@@ -0,0 +1,531 @@
+package promoservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PromoService interface {
+	CreateCampaign(ctx context.Context, campaign *entities.Campaign) (*entities.Campaign, error)
+	ReadCampaigns(ctx context.Context, page, limit int) ([]entities.Campaign, error)
+	ReadCampaignById(ctx context.Context, id string) (*entities.Campaign, error)
+	UpdateCampaign(ctx context.Context, campaign *entities.Campaign) (*entities.Campaign, error)
+	DeleteCampaign(ctx context.Context, id string) error
+	CreatePromoCode(ctx context.Context, code *entities.PromoCode) (*entities.PromoCode, error)
+	ReadPromoCodes(ctx context.Context, page, limit int) ([]entities.PromoCode, error)
+	DeletePromoCode(ctx context.Context, id string) error
+	Quote(ctx context.Context, userId string, items []QuoteItem, code string) (*Quote, error)
+	PriceBooks(ctx context.Context, books []entities.Book) (map[string]pricing.Breakdown, error)
+}
+
+type PromoHandler struct {
+	service PromoService
+}
+
+func NewPromoHandler(service PromoService) *PromoHandler {
+	return &PromoHandler{service: service}
+}
+
+// CreateCampaign
+// @Summary Создать акцию
+// @Tags promo
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CampaignRequest true "Данные акции"
+// @Success 201 {object} dto.CampaignResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promo/campaigns [post]
+func (h *PromoHandler) CreateCampaign(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	var request dto.CampaignRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	campaign, err := h.service.CreateCampaign(ctx, toCampaign("", &request))
+	if err != nil {
+		if isValidationError(err) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toCampaignResponse(campaign))
+}
+
+// ReadCampaigns
+// @Summary Получить акции
+// @Tags promo
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Success 200 {array} dto.CampaignResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promo/campaigns [get]
+func (h *PromoHandler) ReadCampaigns(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	page, limit, err := pagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	response, err := h.service.ReadCampaigns(ctx, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	campaigns := make([]dto.CampaignResponse, 0, len(response))
+	for i := range response {
+		campaigns = append(campaigns, toCampaignResponse(&response[i]))
+	}
+
+	return c.JSON(http.StatusOK, campaigns)
+}
+
+// ReadCampaign
+// @Summary Получить акцию по ID
+// @Tags promo
+// @Security BearerAuth
+// @Param id path string true "ID акции"
+// @Produce json
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promo/campaigns/{id} [get]
+func (h *PromoHandler) ReadCampaign(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	campaign, err := h.service.ReadCampaignById(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrCampaignNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toCampaignResponse(campaign))
+}
+
+// UpdateCampaign
+// @Summary Обновить акцию
+// @Tags promo
+// @Security BearerAuth
+// @Param id path string true "ID акции"
+// @Accept json
+// @Produce json
+// @Param request body dto.CampaignRequest true "Данные акции"
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promo/campaigns/{id} [put]
+func (h *PromoHandler) UpdateCampaign(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	var request dto.CampaignRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	campaign, err := h.service.UpdateCampaign(ctx, toCampaign(id, &request))
+	if err != nil {
+		if errors.Is(err, ErrCampaignNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		if isValidationError(err) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toCampaignResponse(campaign))
+}
+
+// DeleteCampaign
+// @Summary Удалить акцию
+// @Tags promo
+// @Security BearerAuth
+// @Param id path string true "ID акции"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promo/campaigns/{id} [delete]
+func (h *PromoHandler) DeleteCampaign(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.DeleteCampaign(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrCampaignNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreatePromoCode
+// @Summary Создать промокод
+// @Tags promo
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.PromoCodeRequest true "Данные промокода"
+// @Success 201 {object} dto.PromoCodeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promo/codes [post]
+func (h *PromoHandler) CreatePromoCode(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	var request dto.PromoCodeRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	code, err := h.service.CreatePromoCode(ctx, &entities.PromoCode{
+		Code:           request.Code,
+		Description:    request.Description,
+		DiscountType:   request.DiscountType,
+		DiscountValue:  request.DiscountValue,
+		MinOrderAmount: request.MinOrderAmount,
+		MaxUses:        request.MaxUses,
+		MaxUsesPerUser: request.MaxUsesPerUser,
+		Combinable:     request.Combinable,
+		StartsAt:       request.StartsAt,
+		EndsAt:         request.EndsAt,
+	})
+	if err != nil {
+		if errors.Is(err, ErrPromoCodeExists) {
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		if isValidationError(err) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toPromoCodeResponse(code))
+}
+
+// ReadPromoCodes
+// @Summary Получить промокоды
+// @Tags promo
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Success 200 {array} dto.PromoCodeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promo/codes [get]
+func (h *PromoHandler) ReadPromoCodes(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	page, limit, err := pagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	response, err := h.service.ReadPromoCodes(ctx, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	codes := make([]dto.PromoCodeResponse, 0, len(response))
+	for i := range response {
+		codes = append(codes, toPromoCodeResponse(&response[i]))
+	}
+
+	return c.JSON(http.StatusOK, codes)
+}
+
+// DeletePromoCode
+// @Summary Удалить промокод
+// @Tags promo
+// @Security BearerAuth
+// @Param id path string true "ID промокода"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promo/codes/{id} [delete]
+func (h *PromoHandler) DeletePromoCode(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.DeletePromoCode(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrPromoCodeNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Quote
+// @Summary Рассчитать стоимость с учётом акций и промокода
+// @Tags promo
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.QuoteRequest true "Книги и промокод"
+// @Success 200 {object} dto.QuoteResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promo/quote [post]
+func (h *PromoHandler) Quote(c echo.Context) error {
+	var request dto.QuoteRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	items := make([]QuoteItem, 0, len(request.Items))
+	for _, item := range request.Items {
+		items = append(items, QuoteItem{BookId: item.BookId, Quantity: item.Quantity})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	quote, err := h.service.Quote(ctx, userId, items, request.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound), errors.Is(err, ErrPromoCodeNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrEmptyQuote),
+			errors.Is(err, ErrInvalidQuantity),
+			errors.Is(err, ErrPromoCodeInactive),
+			errors.Is(err, ErrPromoCodeExhausted),
+			errors.Is(err, ErrPromoCodeUserLimit),
+			errors.Is(err, ErrMinOrderAmount):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	lines := make([]dto.QuoteLineResponse, 0, len(quote.Lines))
+	for _, line := range quote.Lines {
+		lines = append(lines, dto.QuoteLineResponse{
+			BookId:         line.BookId,
+			Quantity:       line.Quantity,
+			UnitPrice:      line.UnitPrice,
+			UnitFinalPrice: line.UnitFinal,
+			Total:          line.UnitFinal.Mul(line.Quantity),
+			Campaigns:      line.Campaigns,
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.QuoteResponse{
+		Lines:            lines,
+		Subtotal:         quote.Subtotal,
+		CampaignDiscount: quote.CampaignDiscount,
+		CodeDiscount:     quote.CodeDiscount,
+		Total:            quote.Total,
+		Code:             quote.Code,
+	})
+}
+
+func toCampaign(id string, request *dto.CampaignRequest) *entities.Campaign {
+	targets := make([]entities.CampaignTarget, 0, len(request.Targets))
+	for _, target := range request.Targets {
+		targets = append(targets, entities.CampaignTarget{Kind: target.Kind, Value: target.Value})
+	}
+
+	return &entities.Campaign{
+		Id:            id,
+		Name:          request.Name,
+		Description:   request.Description,
+		DiscountType:  request.DiscountType,
+		DiscountValue: request.DiscountValue,
+		Priority:      request.Priority,
+		Stackable:     request.Stackable,
+		StartsAt:      request.StartsAt,
+		EndsAt:        request.EndsAt,
+		Targets:       targets,
+	}
+}
+
+func toCampaignResponse(campaign *entities.Campaign) dto.CampaignResponse {
+	targets := make([]dto.CampaignTargetResponse, 0, len(campaign.Targets))
+	for _, target := range campaign.Targets {
+		targets = append(targets, dto.CampaignTargetResponse{Kind: target.Kind, Value: target.Value})
+	}
+
+	return dto.CampaignResponse{
+		Id:            campaign.Id,
+		Name:          campaign.Name,
+		Description:   validate(campaign.Description),
+		DiscountType:  campaign.DiscountType,
+		DiscountValue: campaign.DiscountValue,
+		Priority:      campaign.Priority,
+		Stackable:     campaign.Stackable,
+		StartsAt:      campaign.StartsAt,
+		EndsAt:        campaign.EndsAt,
+		Targets:       targets,
+	}
+}
+
+func toPromoCodeResponse(code *entities.PromoCode) dto.PromoCodeResponse {
+	return dto.PromoCodeResponse{
+		Id:             code.Id,
+		Code:           code.Code,
+		Description:    validate(code.Description),
+		DiscountType:   code.DiscountType,
+		DiscountValue:  code.DiscountValue,
+		MinOrderAmount: code.MinOrderAmount,
+		MaxUses:        code.MaxUses,
+		MaxUsesPerUser: code.MaxUsesPerUser,
+		UsedCount:      code.UsedCount,
+		Combinable:     code.Combinable,
+		StartsAt:       code.StartsAt,
+		EndsAt:         code.EndsAt,
+	}
+}
+
+func isValidationError(err error) bool {
+	return errors.Is(err, ErrInvalidName) ||
+		errors.Is(err, ErrInvalidCode) ||
+		errors.Is(err, ErrInvalidDiscountType) ||
+		errors.Is(err, ErrInvalidDiscountValue) ||
+		errors.Is(err, ErrInvalidPeriod) ||
+		errors.Is(err, ErrInvalidTarget) ||
+		errors.Is(err, ErrNoTargets) ||
+		errors.Is(err, ErrInvalidMinOrderAmount) ||
+		errors.Is(err, ErrInvalidUsageLimit)
+}
+
+func pagination(c echo.Context) (int, int, error) {
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return 0, 0, ErrInvalidPage
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return 0, 0, ErrInvalidLimit
+		}
+	}
+
+	return page, limit, nil
+}
+
+func validate[T any](t *T) T {
+	if t != nil {
+		return *t
+	}
+
+	var zero T
+	return zero
+}
@@ -0,0 +1,221 @@
+package promoservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const uniqueViolationCode = "23505"
+
+type promoRepository struct {
+	db *gorm.DB
+}
+
+func NewPromoRepository(db *gorm.DB) PromoRepository {
+	return &promoRepository{db: db}
+}
+
+func (r *promoRepository) CreateCampaign(ctx context.Context, campaign *entities.Campaign) error {
+	return r.db.WithContext(ctx).Create(campaign).Error
+}
+
+func (r *promoRepository) ReadCampaigns(ctx context.Context, offset, limit int) ([]entities.Campaign, error) {
+	var campaigns []entities.Campaign
+	if err := r.db.
+		WithContext(ctx).
+		Preload("Targets").
+		Order("starts_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&campaigns).Error; err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+func (r *promoRepository) ReadActiveCampaigns(ctx context.Context, now time.Time) ([]entities.Campaign, error) {
+	var campaigns []entities.Campaign
+	if err := r.db.
+		WithContext(ctx).
+		Preload("Targets").
+		Where("starts_at <= ? AND ends_at > ?", now, now).
+		Find(&campaigns).Error; err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+func (r *promoRepository) ReadCampaignById(ctx context.Context, id string) (*entities.Campaign, error) {
+	var campaign entities.Campaign
+	if err := r.db.
+		WithContext(ctx).
+		Preload("Targets").
+		Where("id = ?", id).
+		First(&campaign).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *promoRepository) UpdateCampaign(ctx context.Context, campaign *entities.Campaign) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.
+			Model(&entities.Campaign{}).
+			Where("id = ?", campaign.Id).
+			Select("name", "description", "discount_type", "discount_value", "priority", "stackable", "starts_at", "ends_at").
+			Updates(campaign)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrCampaignNotFound
+		}
+
+		if err := tx.
+			Where("campaign_id = ?", campaign.Id).
+			Delete(&entities.CampaignTarget{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&campaign.Targets).Error
+	})
+}
+
+func (r *promoRepository) DeleteCampaign(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Delete(&entities.Campaign{Id: id})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrCampaignNotFound
+	}
+
+	return nil
+}
+
+func (r *promoRepository) CreatePromoCode(ctx context.Context, code *entities.PromoCode) error {
+	err := r.db.WithContext(ctx).Create(code).Error
+	if isUniqueViolation(err) {
+		return ErrPromoCodeExists
+	}
+	return err
+}
+
+func (r *promoRepository) ReadPromoCodes(ctx context.Context, offset, limit int) ([]entities.PromoCode, error) {
+	var codes []entities.PromoCode
+	if err := r.db.
+		WithContext(ctx).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (r *promoRepository) ReadPromoCode(ctx context.Context, code string) (*entities.PromoCode, error) {
+	var promoCode entities.PromoCode
+	if err := r.db.
+		WithContext(ctx).
+		Where("code = ?", code).
+		First(&promoCode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromoCodeNotFound
+		}
+		return nil, err
+	}
+	return &promoCode, nil
+}
+
+func (r *promoRepository) DeletePromoCode(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Delete(&entities.PromoCode{Id: id})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrPromoCodeNotFound
+	}
+
+	return nil
+}
+
+func (r *promoRepository) CountRedemptions(ctx context.Context, promoCodeId, userId string) (int64, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.PromoCodeRedemption{}).
+		Where("promo_code_id = ? AND user_id = ?", promoCodeId, userId).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *promoRepository) ReadBooks(ctx context.Context, ids []string) ([]entities.Book, error) {
+	var books []entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Omit("image_data").
+		Preload("Authors").
+		Where("id IN ?", ids).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (r *promoRepository) ReadGenres(ctx context.Context, bookIds []string) (map[string][]string, error) {
+	var genres []entities.GenreOfBook
+	if err := r.db.
+		WithContext(ctx).
+		Where("book_id IN ?", bookIds).
+		Find(&genres).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string, len(bookIds))
+	for _, genre := range genres {
+		result[genre.BookId] = append(result[genre.BookId], genre.Genre)
+	}
+	return result, nil
+}
+
+// ReadCredits returns the credited authors and the publisher of each book,
+// which author and publisher campaigns are matched against, by book ID.
+func (r *promoRepository) ReadCredits(ctx context.Context, bookIds []string) (map[string]*entities.Book, error) {
+	var books []entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Select("id", "publisher_id").
+		Preload("Authors").
+		Where("id IN ?", bookIds).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*entities.Book, len(books))
+	for i := range books {
+		result[books[i].Id] = &books[i]
+	}
+	return result, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
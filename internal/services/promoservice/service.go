@@ -0,0 +1,325 @@
+package promoservice
+
+import (
+	"context"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromoRepository interface {
+	CreateCampaign(ctx context.Context, campaign *entities.Campaign) error
+	ReadCampaigns(ctx context.Context, offset, limit int) ([]entities.Campaign, error)
+	ReadActiveCampaigns(ctx context.Context, now time.Time) ([]entities.Campaign, error)
+	ReadCampaignById(ctx context.Context, id string) (*entities.Campaign, error)
+	UpdateCampaign(ctx context.Context, campaign *entities.Campaign) error
+	DeleteCampaign(ctx context.Context, id string) error
+	CreatePromoCode(ctx context.Context, code *entities.PromoCode) error
+	ReadPromoCodes(ctx context.Context, offset, limit int) ([]entities.PromoCode, error)
+	ReadPromoCode(ctx context.Context, code string) (*entities.PromoCode, error)
+	DeletePromoCode(ctx context.Context, id string) error
+	CountRedemptions(ctx context.Context, promoCodeId, userId string) (int64, error)
+	ReadBooks(ctx context.Context, ids []string) ([]entities.Book, error)
+	ReadGenres(ctx context.Context, bookIds []string) (map[string][]string, error)
+	ReadCredits(ctx context.Context, bookIds []string) (map[string]*entities.Book, error)
+}
+
+type QuoteItem struct {
+	BookId   string
+	Quantity int
+}
+
+type promoService struct {
+	repo   PromoRepository
+	engine pricing.Engine
+}
+
+func NewPromoService(repo PromoRepository, engine pricing.Engine) PromoService {
+	return &promoService{repo: repo, engine: engine}
+}
+
+func (s *promoService) CreateCampaign(ctx context.Context, campaign *entities.Campaign) (*entities.Campaign, error) {
+	if err := validateCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	campaign.Id = uuid.NewString()
+	for i := range campaign.Targets {
+		campaign.Targets[i].Id = uuid.NewString()
+		campaign.Targets[i].CampaignId = campaign.Id
+	}
+
+	err := s.repo.CreateCampaign(ctx, campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (s *promoService) ReadCampaigns(ctx context.Context, page, limit int) ([]entities.Campaign, error) {
+	return s.repo.ReadCampaigns(ctx, (page-1)*limit, limit)
+}
+
+func (s *promoService) ReadCampaignById(ctx context.Context, id string) (*entities.Campaign, error) {
+	return s.repo.ReadCampaignById(ctx, id)
+}
+
+func (s *promoService) UpdateCampaign(ctx context.Context, campaign *entities.Campaign) (*entities.Campaign, error) {
+	if err := validateCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	for i := range campaign.Targets {
+		campaign.Targets[i].Id = uuid.NewString()
+		campaign.Targets[i].CampaignId = campaign.Id
+	}
+
+	err := s.repo.UpdateCampaign(ctx, campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadCampaignById(ctx, campaign.Id)
+}
+
+func (s *promoService) DeleteCampaign(ctx context.Context, id string) error {
+	return s.repo.DeleteCampaign(ctx, id)
+}
+
+func (s *promoService) CreatePromoCode(ctx context.Context, code *entities.PromoCode) (*entities.PromoCode, error) {
+	code.Code = normalizeCode(code.Code)
+
+	if err := validatePromoCode(code); err != nil {
+		return nil, err
+	}
+
+	code.Id = uuid.NewString()
+	code.UsedCount = 0
+
+	err := s.repo.CreatePromoCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
+
+func (s *promoService) ReadPromoCodes(ctx context.Context, page, limit int) ([]entities.PromoCode, error) {
+	return s.repo.ReadPromoCodes(ctx, (page-1)*limit, limit)
+}
+
+func (s *promoService) DeletePromoCode(ctx context.Context, id string) error {
+	return s.repo.DeletePromoCode(ctx, id)
+}
+
+// Quote prices the items for the user. It checks every promo code limit but
+// does not consume a use: placing the order does, in the same transaction
+// that saves it.
+func (s *promoService) Quote(ctx context.Context, userId string, items []QuoteItem, code string) (*Quote, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyQuote
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Quantity < 1 {
+			return nil, ErrInvalidQuantity
+		}
+		ids = append(ids, item.BookId)
+	}
+
+	books, err := s.repo.ReadBooks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]*entities.Book, len(books))
+	for i := range books {
+		byId[books[i].Id] = &books[i]
+	}
+
+	genres, err := s.repo.ReadGenres(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluated := make([]Item, 0, len(items))
+	for _, item := range items {
+		book, ok := byId[item.BookId]
+		if !ok {
+			return nil, ErrBookNotFound
+		}
+		evaluated = append(evaluated, Item{Book: book, Genres: genres[book.Id], Quantity: item.Quantity})
+	}
+
+	now := time.Now()
+
+	campaigns, err := s.repo.ReadActiveCampaigns(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var promoCode *entities.PromoCode
+	if code != "" {
+		promoCode, err = s.usableCode(ctx, userId, code, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return Evaluate(s.engine, evaluated, campaigns, promoCode, now)
+}
+
+// PriceBooks returns the unit price breakdown of each book under the
+// campaigns running now, keyed by book ID. The books' author credits and
+// publisher are read here, whatever the caller loaded.
+func (s *promoService) PriceBooks(ctx context.Context, books []entities.Book) (map[string]pricing.Breakdown, error) {
+	if len(books) == 0 {
+		return map[string]pricing.Breakdown{}, nil
+	}
+
+	ids := make([]string, 0, len(books))
+	for _, book := range books {
+		ids = append(ids, book.Id)
+	}
+
+	genres, err := s.repo.ReadGenres(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	credits, err := s.repo.ReadCredits(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	campaigns, err := s.repo.ReadActiveCampaigns(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]pricing.Breakdown, len(books))
+	for i := range books {
+		book := books[i]
+		if credited, ok := credits[book.Id]; ok {
+			book.Authors = credited.Authors
+			book.PublisherId = credited.PublisherId
+		}
+		prices[book.Id] = s.engine.PriceBook(&book, genres[book.Id], campaigns, now)
+	}
+
+	return prices, nil
+}
+
+func (s *promoService) usableCode(ctx context.Context, userId, code string, now time.Time) (*entities.PromoCode, error) {
+	promoCode, err := s.repo.ReadPromoCode(ctx, normalizeCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	if now.Before(promoCode.StartsAt) || !now.Before(promoCode.EndsAt) {
+		return nil, ErrPromoCodeInactive
+	}
+
+	if promoCode.MaxUses != nil && promoCode.UsedCount >= *promoCode.MaxUses {
+		return nil, ErrPromoCodeExhausted
+	}
+
+	if promoCode.MaxUsesPerUser != nil {
+		used, err := s.repo.CountRedemptions(ctx, promoCode.Id, userId)
+		if err != nil {
+			return nil, err
+		}
+		if used >= int64(*promoCode.MaxUsesPerUser) {
+			return nil, ErrPromoCodeUserLimit
+		}
+	}
+
+	return promoCode, nil
+}
+
+func validateCampaign(campaign *entities.Campaign) error {
+	if strings.TrimSpace(campaign.Name) == "" {
+		return ErrInvalidName
+	}
+
+	if err := validateDiscount(campaign.DiscountType, campaign.DiscountValue); err != nil {
+		return err
+	}
+
+	if !campaign.EndsAt.After(campaign.StartsAt) {
+		return ErrInvalidPeriod
+	}
+
+	if len(campaign.Targets) == 0 {
+		return ErrNoTargets
+	}
+
+	for _, target := range campaign.Targets {
+		switch target.Kind {
+		case pricing.TargetGenre, pricing.TargetAuthor, pricing.TargetPublisher, pricing.TargetBook:
+		default:
+			return ErrInvalidTarget
+		}
+		if strings.TrimSpace(target.Value) == "" {
+			return ErrInvalidTarget
+		}
+		if target.Kind == pricing.TargetAuthor || target.Kind == pricing.TargetPublisher {
+			if _, err := uuid.Parse(target.Value); err != nil {
+				return ErrInvalidTarget
+			}
+		}
+	}
+
+	return nil
+}
+
+func validatePromoCode(code *entities.PromoCode) error {
+	if code.Code == "" {
+		return ErrInvalidCode
+	}
+
+	if err := validateDiscount(code.DiscountType, code.DiscountValue); err != nil {
+		return err
+	}
+
+	if !code.EndsAt.After(code.StartsAt) {
+		return ErrInvalidPeriod
+	}
+
+	if code.MinOrderAmount < 0 {
+		return ErrInvalidMinOrderAmount
+	}
+
+	if (code.MaxUses != nil && *code.MaxUses < 1) || (code.MaxUsesPerUser != nil && *code.MaxUsesPerUser < 1) {
+		return ErrInvalidUsageLimit
+	}
+
+	return nil
+}
+
+func validateDiscount(discountType string, value float64) error {
+	switch discountType {
+	case pricing.DiscountPercent:
+		if value <= 0 || value > 100 {
+			return ErrInvalidDiscountValue
+		}
+	case pricing.DiscountFixed:
+		if value <= 0 {
+			return ErrInvalidDiscountValue
+		}
+	default:
+		return ErrInvalidDiscountType
+	}
+	return nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,17 @@
+package publisherservice
+
+import "errors"
+
+var (
+	ErrPublisherNotFound  = errors.New("publisher not found")
+	ErrInvalidName        = errors.New("name must be between 1 and 200 characters")
+	ErrInvalidAlias       = errors.New("aliases must be distinct, at most 20, each between 1 and 200 characters")
+	ErrDescriptionTooLong = errors.New("description is too long")
+	ErrInvalidLogo        = errors.New("invalid logo")
+	ErrNameTaken          = errors.New("name or alias is already used by another publisher")
+	ErrPublisherInUse     = errors.New("publisher has books; merge it into another publisher instead")
+	ErrInvalidMerge       = errors.New("a publisher cannot be merged into itself")
+	ErrAccessDenied       = errors.New("access denied")
+	ErrInvalidPage        = errors.New("invalid page")
+	ErrInvalidLimit       = errors.New("invalid limit")
+)
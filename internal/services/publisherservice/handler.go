@@ -0,0 +1,333 @@
+package publisherservice
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PublisherService interface {
+	CreatePublisher(ctx context.Context, publisher *entities.Publisher) (*entities.Publisher, error)
+	ReadPublishers(ctx context.Context, query string, page, limit int) ([]entities.Publisher, error)
+	ReadPublisher(ctx context.Context, id string) (*entities.Publisher, error)
+	UpdatePublisher(ctx context.Context, publisher *entities.Publisher) (*entities.Publisher, error)
+	DeletePublisher(ctx context.Context, id string) error
+	MergePublishers(ctx context.Context, targetId, sourceId string) (*entities.Publisher, error)
+}
+
+type PublisherHandler struct {
+	service PublisherService
+}
+
+func NewPublisherHandler(service PublisherService) *PublisherHandler {
+	return &PublisherHandler{service: service}
+}
+
+// CreatePublisher
+// @Summary Создать издательство
+// @Tags publishers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.PublisherRequest true "Название, описание, логотип и другие написания названия"
+// @Success 201 {object} dto.PublisherResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /publishers [post]
+func (h *PublisherHandler) CreatePublisher(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.PublisherRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	publisher, err := fromPublisherRequest(&request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	publisher, err = h.service.CreatePublisher(ctx, publisher)
+	if err != nil {
+		return publisherError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toPublisherResponse(publisher))
+}
+
+// ReadPublishers
+// @Summary Получить издательства
+// @Tags publishers
+// @Param q query string false "Поиск по названию и другим написаниям названия"
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Produce json
+// @Success 200 {array} dto.PublisherResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /publishers [get]
+func (h *PublisherHandler) ReadPublishers(c echo.Context) error {
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidPage.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	publishers, err := h.service.ReadPublishers(ctx, c.QueryParam("q"), page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.PublisherResponse, 0, len(publishers))
+	for i := range publishers {
+		response = append(response, toPublisherResponse(&publishers[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReadPublisher
+// @Summary Получить издательство
+// @Description Книги издательства — GET /books?publisher_id={id}
+// @Tags publishers
+// @Param id path string true "ID издательства"
+// @Produce json
+// @Success 200 {object} dto.PublisherResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /publishers/{id} [get]
+func (h *PublisherHandler) ReadPublisher(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	publisher, err := h.service.ReadPublisher(ctx, c.Param("id"))
+	if err != nil {
+		return publisherError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toPublisherResponse(publisher))
+}
+
+// UpdatePublisher
+// @Summary Изменить издательство
+// @Description Другие написания названия заменяются целиком; логотип меняется, только если передан новый
+// @Tags publishers
+// @Security BearerAuth
+// @Param id path string true "ID издательства"
+// @Accept json
+// @Produce json
+// @Param request body dto.PublisherRequest true "Название, описание, логотип и другие написания названия"
+// @Success 200 {object} dto.PublisherResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /publishers/{id} [put]
+func (h *PublisherHandler) UpdatePublisher(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.PublisherRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	publisher, err := fromPublisherRequest(&request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	publisher.Id = c.Param("id")
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	publisher, err = h.service.UpdatePublisher(ctx, publisher)
+	if err != nil {
+		return publisherError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toPublisherResponse(publisher))
+}
+
+// DeletePublisher
+// @Summary Удалить издательство
+// @Description Издательство, указанное в книгах, удалить нельзя — его можно объединить с другим
+// @Tags publishers
+// @Security BearerAuth
+// @Param id path string true "ID издательства"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /publishers/{id} [delete]
+func (h *PublisherHandler) DeletePublisher(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeletePublisher(ctx, c.Param("id")); err != nil {
+		return publisherError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MergePublishers
+// @Summary Объединить издательство-дубликат с издательством
+// @Description Книги дубликата переходят к издательству, название дубликата сохраняется как другое написание названия, дубликат удаляется
+// @Tags publishers
+// @Security BearerAuth
+// @Param id path string true "ID издательства, которое остаётся"
+// @Accept json
+// @Produce json
+// @Param request body dto.MergeRequest true "ID дубликата"
+// @Success 200 {object} dto.PublisherResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /publishers/{id}/merge [post]
+func (h *PublisherHandler) MergePublishers(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.MergeRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	publisher, err := h.service.MergePublishers(ctx, c.Param("id"), request.SourceId)
+	if err != nil {
+		return publisherError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toPublisherResponse(publisher))
+}
+
+func publisherError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrPublisherNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrNameTaken), errors.Is(err, ErrPublisherInUse):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidName), errors.Is(err, ErrInvalidAlias), errors.Is(err, ErrDescriptionTooLong),
+		errors.Is(err, ErrInvalidMerge):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func fromPublisherRequest(request *dto.PublisherRequest) (*entities.Publisher, error) {
+	publisher := &entities.Publisher{
+		Name:        request.Name,
+		Description: request.Description,
+	}
+
+	for _, alias := range request.Aliases {
+		publisher.Aliases = append(publisher.Aliases, entities.PublisherAlias{Alias: alias})
+	}
+
+	if request.Logo != nil && *request.Logo != "" {
+		data, mime, err := fromDataUrl(*request.Logo)
+		if err != nil {
+			return nil, ErrInvalidLogo
+		}
+		publisher.LogoData = data
+		publisher.LogoMime = mime
+	}
+
+	return publisher, nil
+}
+
+func toPublisherResponse(publisher *entities.Publisher) dto.PublisherResponse {
+	response := dto.PublisherResponse{
+		Id:        publisher.Id,
+		Name:      publisher.Name,
+		Logo:      toDataUrl(publisher.LogoData, publisher.LogoMime),
+		Aliases:   make([]string, 0, len(publisher.Aliases)),
+		CreatedAt: publisher.CreatedAt,
+		UpdatedAt: publisher.UpdatedAt,
+	}
+
+	if publisher.Description != nil {
+		response.Description = *publisher.Description
+	}
+	for _, alias := range publisher.Aliases {
+		response.Aliases = append(response.Aliases, alias.Alias)
+	}
+
+	return response
+}
+
+func toDataUrl(data []byte, mime string) string {
+	if data == nil {
+		return ""
+	}
+	if mime == "" {
+		mime = "image/png"
+	}
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// fromDataUrl accepts a data URL or bare base64, like book images.
+func fromDataUrl(str string) ([]byte, string, error) {
+	meta, data, found := strings.Cut(str, ",")
+	if !found || !strings.HasPrefix(meta, "data:") {
+		b, err := base64.StdEncoding.DecodeString(str)
+		return b, "", err
+	}
+
+	mime, _, _ := strings.Cut(strings.TrimPrefix(meta, "data:"), ";")
+
+	b, err := base64.StdEncoding.DecodeString(data)
+	return b, mime, err
+}
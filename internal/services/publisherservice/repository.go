@@ -0,0 +1,232 @@
+package publisherservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uniqueViolationCode = "23505"
+
+type publisherRepository struct {
+	db *gorm.DB
+}
+
+func NewPublisherRepository(db *gorm.DB) PublisherRepository {
+	return &publisherRepository{db: db}
+}
+
+func (r *publisherRepository) Create(ctx context.Context, publisher *entities.Publisher) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(publisher).Error; err != nil {
+			return err
+		}
+
+		return saveAliases(tx, publisher)
+	})
+	if isUniqueViolation(err) {
+		return ErrNameTaken
+	}
+	return err
+}
+
+// ReadAll lists publishers by name. A query matches names and aliases.
+func (r *publisherRepository) ReadAll(ctx context.Context, query string, offset, limit int) ([]entities.Publisher, error) {
+	db := r.db.
+		WithContext(ctx).
+		Preload("Aliases")
+
+	if query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(query)) + "%"
+		db = db.Where(
+			"LOWER(name) LIKE ? OR id IN (SELECT publisher_id FROM publisher_aliases WHERE LOWER(alias) LIKE ?)",
+			pattern, pattern,
+		)
+	}
+
+	var publishers []entities.Publisher
+	if err := db.
+		Order("name, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&publishers).Error; err != nil {
+		return nil, err
+	}
+	return publishers, nil
+}
+
+func (r *publisherRepository) ReadById(ctx context.Context, id string) (*entities.Publisher, error) {
+	return readPublisher(r.db.WithContext(ctx), id)
+}
+
+// Update replaces a publisher's name, description and aliases, and the logo
+// when a new one is given. Books of the publisher show the new name.
+func (r *publisherRepository) Update(ctx context.Context, publisher *entities.Publisher) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{
+			"name":        publisher.Name,
+			"description": publisher.Description,
+			"updated_at":  publisher.UpdatedAt,
+		}
+		if publisher.LogoData != nil {
+			updates["logo_data"] = publisher.LogoData
+			updates["logo_mime"] = publisher.LogoMime
+		}
+
+		res := tx.
+			Model(&entities.Publisher{}).
+			Where("id = ?", publisher.Id).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrPublisherNotFound
+		}
+
+		if err := tx.
+			Where("publisher_id = ?", publisher.Id).
+			Delete(&entities.PublisherAlias{}).Error; err != nil {
+			return err
+		}
+
+		if err := saveAliases(tx, publisher); err != nil {
+			return err
+		}
+
+		// Raw SQL so books in the trash show the new name too.
+		return tx.Exec("UPDATE books SET publisher = ? WHERE publisher_id = ?", publisher.Name, publisher.Id).Error
+	})
+	if isUniqueViolation(err) {
+		return ErrNameTaken
+	}
+	return err
+}
+
+// Delete removes a publisher no book, trashed ones included, refers to.
+func (r *publisherRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var books int64
+		if err := tx.
+			Unscoped().
+			Model(&entities.Book{}).
+			Where("publisher_id = ?", id).
+			Count(&books).Error; err != nil {
+			return err
+		}
+		if books > 0 {
+			return ErrPublisherInUse
+		}
+
+		res := tx.
+			Where("id = ?", id).
+			Delete(&entities.Publisher{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrPublisherNotFound
+		}
+		return nil
+	})
+}
+
+// Merge folds a duplicate publisher into the target: the duplicate's books
+// move to the target, its name and aliases become the target's aliases and
+// the duplicate is deleted.
+func (r *publisherRepository) Merge(ctx context.Context, targetId, sourceId string) (*entities.Publisher, error) {
+	var target *entities.Publisher
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		target, err = readPublisher(tx.Clauses(clause.Locking{Strength: "UPDATE"}), targetId)
+		if err != nil {
+			return err
+		}
+
+		source, err := readPublisher(tx.Clauses(clause.Locking{Strength: "UPDATE"}), sourceId)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Exec(
+			"UPDATE books SET publisher_id = ?, publisher = ? WHERE publisher_id = ?",
+			targetId, target.Name, sourceId,
+		).Error; err != nil {
+			return err
+		}
+
+		names := []string{source.Name}
+		for _, alias := range source.Aliases {
+			names = append(names, alias.Alias)
+		}
+
+		// The source goes first: its aliases are unique across publishers.
+		if err = tx.
+			Where("id = ?", sourceId).
+			Delete(&entities.Publisher{}).Error; err != nil {
+			return err
+		}
+
+		known := map[string]bool{strings.ToLower(target.Name): true}
+		for _, alias := range target.Aliases {
+			known[strings.ToLower(alias.Alias)] = true
+		}
+		for _, name := range names {
+			if known[strings.ToLower(name)] {
+				continue
+			}
+			known[strings.ToLower(name)] = true
+
+			alias := entities.PublisherAlias{PublisherId: targetId, Alias: name}
+			if err = tx.Create(&alias).Error; err != nil {
+				return err
+			}
+			target.Aliases = append(target.Aliases, alias)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func saveAliases(tx *gorm.DB, publisher *entities.Publisher) error {
+	if len(publisher.Aliases) == 0 {
+		return nil
+	}
+
+	for i := range publisher.Aliases {
+		publisher.Aliases[i].PublisherId = publisher.Id
+	}
+	return tx.Create(&publisher.Aliases).Error
+}
+
+func readPublisher(db *gorm.DB, id string) (*entities.Publisher, error) {
+	var publisher entities.Publisher
+	if err := db.
+		Preload("Aliases").
+		Where("id = ?", id).
+		First(&publisher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPublisherNotFound
+		}
+		return nil, err
+	}
+	return &publisher, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,127 @@
+package publisherservice
+
+import (
+	"context"
+	"story-book/internal/entities"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxNameLength        = 200
+	maxDescriptionLength = 10000
+	maxAliases           = 20
+)
+
+type PublisherRepository interface {
+	Create(ctx context.Context, publisher *entities.Publisher) error
+	ReadAll(ctx context.Context, query string, offset, limit int) ([]entities.Publisher, error)
+	ReadById(ctx context.Context, id string) (*entities.Publisher, error)
+	Update(ctx context.Context, publisher *entities.Publisher) error
+	Delete(ctx context.Context, id string) error
+	Merge(ctx context.Context, targetId, sourceId string) (*entities.Publisher, error)
+}
+
+type publisherService struct {
+	repo PublisherRepository
+}
+
+func NewPublisherService(repo PublisherRepository) PublisherService {
+	return &publisherService{repo: repo}
+}
+
+func (s *publisherService) CreatePublisher(ctx context.Context, publisher *entities.Publisher) (*entities.Publisher, error) {
+	if err := validatePublisher(publisher); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	publisher.Id = uuid.NewString()
+	publisher.CreatedAt = now
+	publisher.UpdatedAt = now
+
+	if err := s.repo.Create(ctx, publisher); err != nil {
+		return nil, err
+	}
+
+	return publisher, nil
+}
+
+func (s *publisherService) ReadPublishers(ctx context.Context, query string, page, limit int) ([]entities.Publisher, error) {
+	return s.repo.ReadAll(ctx, strings.TrimSpace(query), (page-1)*limit, limit)
+}
+
+func (s *publisherService) ReadPublisher(ctx context.Context, id string) (*entities.Publisher, error) {
+	return s.repo.ReadById(ctx, id)
+}
+
+func (s *publisherService) UpdatePublisher(ctx context.Context, publisher *entities.Publisher) (*entities.Publisher, error) {
+	if err := validatePublisher(publisher); err != nil {
+		return nil, err
+	}
+
+	publisher.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, publisher); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadById(ctx, publisher.Id)
+}
+
+func (s *publisherService) DeletePublisher(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// MergePublishers folds a duplicate into the target publisher. The
+// duplicate's name is kept as an alias, so books saved under it later still
+// find the target.
+func (s *publisherService) MergePublishers(ctx context.Context, targetId, sourceId string) (*entities.Publisher, error) {
+	if targetId == sourceId {
+		return nil, ErrInvalidMerge
+	}
+
+	return s.repo.Merge(ctx, targetId, sourceId)
+}
+
+func validatePublisher(publisher *entities.Publisher) error {
+	publisher.Name = normalizeName(publisher.Name)
+	if publisher.Name == "" || utf8.RuneCountInString(publisher.Name) > maxNameLength {
+		return ErrInvalidName
+	}
+
+	if publisher.Description != nil {
+		description := strings.TrimSpace(*publisher.Description)
+		if utf8.RuneCountInString(description) > maxDescriptionLength {
+			return ErrDescriptionTooLong
+		}
+		publisher.Description = &description
+		if description == "" {
+			publisher.Description = nil
+		}
+	}
+
+	if len(publisher.Aliases) > maxAliases {
+		return ErrInvalidAlias
+	}
+
+	seen := map[string]bool{strings.ToLower(publisher.Name): true}
+	for i := range publisher.Aliases {
+		alias := normalizeName(publisher.Aliases[i].Alias)
+		if alias == "" || utf8.RuneCountInString(alias) > maxNameLength || seen[strings.ToLower(alias)] {
+			return ErrInvalidAlias
+		}
+		seen[strings.ToLower(alias)] = true
+		publisher.Aliases[i].Alias = alias
+	}
+
+	return nil
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
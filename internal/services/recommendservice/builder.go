@@ -0,0 +1,33 @@
+package recommendservice
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunBuilder recomputes related books and user recommendations. It blocks
+// until ctx is done.
+func RunBuilder(ctx context.Context, service RecommendService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		build(ctx, service)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func build(ctx context.Context, service RecommendService) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	if err := service.Rebuild(ctx); err != nil {
+		log.Printf("recommendation rebuild failed: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package recommendservice
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds the memory held by the cache: one entry is kept per
+// book and per user asked about.
+const maxCacheEntries = 10000
+
+type cacheEntry struct {
+	value     []Recommendation
+	expiresAt time.Time
+}
+
+// cache keeps recent results in memory for ttl. It is cleared whenever the
+// tables are rebuilt. Once full, expired entries are dropped to make room,
+// then the oldest one.
+type cache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *cache) get(key string) ([]Recommendation, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *cache) set(key string, value []Recommendation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxCacheEntries {
+		c.evict(now)
+	}
+
+	c.entries[key] = cacheEntry{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// evict drops the expired entries or, if none has expired, the one that
+// expires first. The caller holds the write lock.
+func (c *cache) evict(now time.Time) {
+	oldest := ""
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldest == "" || entry.expiresAt.Before(c.entries[oldest].expiresAt) {
+			oldest = key
+		}
+	}
+
+	if len(c.entries) >= maxCacheEntries {
+		delete(c.entries, oldest)
+	}
+}
+
+func (c *cache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]cacheEntry)
+}
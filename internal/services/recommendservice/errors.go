@@ -0,0 +1,8 @@
+package recommendservice
+
+import "errors"
+
+var (
+	ErrBookNotFound = errors.New("book not found")
+	ErrInvalidLimit = errors.New("invalid limit")
+)
@@ -0,0 +1,115 @@
+package recommendservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxLimit = 50
+
+type RecommendService interface {
+	ReadRelated(ctx context.Context, bookId string, limit int) ([]Recommendation, error)
+	ReadForUser(ctx context.Context, userId string, limit int) ([]Recommendation, error)
+	Rebuild(ctx context.Context) error
+}
+
+type RecommendHandler struct {
+	service RecommendService
+}
+
+func NewRecommendHandler(service RecommendService) *RecommendHandler {
+	return &RecommendHandler{service: service}
+}
+
+// ReadRelated
+// @Summary Получить похожие книги
+// @Tags recommendations
+// @Param id path string true "ID книги"
+// @Param limit query int false "Количество книг (по умолчанию 10, не больше 50)"
+// @Produce json
+// @Success 200 {array} dto.RecommendedBookResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/related [get]
+func (h *RecommendHandler) ReadRelated(c echo.Context) error {
+	limit, err := parseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.service.ReadRelated(ctx, c.Param("id"), limit)
+	if err != nil {
+		if errors.Is(err, ErrBookNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toRecommendedBookResponses(result))
+}
+
+// ReadForUser
+// @Summary Получить персональные рекомендации
+// @Tags recommendations
+// @Security BearerAuth
+// @Param limit query int false "Количество книг (по умолчанию 10, не больше 50)"
+// @Produce json
+// @Success 200 {array} dto.RecommendedBookResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/recommendations [get]
+func (h *RecommendHandler) ReadForUser(c echo.Context) error {
+	limit, err := parseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.service.ReadForUser(ctx, userId, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toRecommendedBookResponses(result))
+}
+
+func parseLimit(limitStr string) (int, error) {
+	if limitStr == "" {
+		return 10, nil
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, ErrInvalidLimit
+	}
+	return limit, nil
+}
+
+func toRecommendedBookResponses(result []Recommendation) []dto.RecommendedBookResponse {
+	response := make([]dto.RecommendedBookResponse, 0, len(result))
+	for _, item := range result {
+		response = append(response, dto.RecommendedBookResponse{
+			BookId: item.BookId,
+			Title:  item.Title,
+			Author: item.Author,
+			Rating: item.Rating,
+			Score:  item.Score,
+		})
+	}
+	return response
+}
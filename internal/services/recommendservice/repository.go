@@ -0,0 +1,179 @@
+package recommendservice
+
+import (
+	"context"
+	"story-book/internal/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Weights of each signal in a book relation score.
+const (
+	genreWeight    = 1.0
+	authorWeight   = 2.0
+	coOrderWeight  = 3.0
+	relatedPerBook = 20
+	recommendedPer = 20
+)
+
+// bestsellerWindow is how far back sales count for the bestseller fallback.
+const bestsellerWindow = 30 * 24 * time.Hour
+
+type recommendRepository struct {
+	db *gorm.DB
+}
+
+func NewRecommendRepository(db *gorm.DB) RecommendRepository {
+	return &recommendRepository{db: db}
+}
+
+func (r *recommendRepository) BookExists(ctx context.Context, bookId string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Book{}).
+		Where("id = ?", bookId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *recommendRepository) ReadRelated(ctx context.Context, bookId string, limit int) ([]Recommendation, error) {
+	return r.read(ctx, r.db.
+		Table("book_relations AS rel").
+		Joins("JOIN books ON books.id = rel.related_id AND books.deleted_at IS NULL").
+		Where("rel.book_id = ?", bookId).
+		Select("books.id AS book_id, books.title, books.author, books.rating, rel.score").
+		Order("rel.score DESC, books.id").
+		Limit(limit))
+}
+
+func (r *recommendRepository) ReadForUser(ctx context.Context, userId string, limit int) ([]Recommendation, error) {
+	return r.read(ctx, r.db.
+		Table("user_recommendations AS rec").
+		Joins("JOIN books ON books.id = rec.book_id AND books.deleted_at IS NULL").
+		Where("rec.user_id = ?", userId).
+		Select("books.id AS book_id, books.title, books.author, books.rating, rec.score").
+		Order("rec.score DESC, books.id").
+		Limit(limit))
+}
+
+// ReadBestsellers is the fallback for books and users without precomputed
+// results: the books sold most in paid orders lately, then the top rated
+// ones, so a store without sales still gets suggestions. Books in exclude
+// are left out.
+func (r *recommendRepository) ReadBestsellers(ctx context.Context, exclude []string, limit int) ([]Recommendation, error) {
+	query := r.db.
+		Model(&entities.Book{}).
+		Joins(`LEFT JOIN (
+			SELECT oi.book_id, SUM(oi.quantity) AS sold
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE o.status = 'paid' AND o.created_at >= ?
+			GROUP BY oi.book_id
+		) sales ON sales.book_id = books.id`, time.Now().Add(-bestsellerWindow)).
+		Select("books.id AS book_id, books.title, books.author, books.rating, COALESCE(sales.sold, 0) AS score").
+		Order("score DESC, books.rating DESC, books.review_count DESC, books.created_at DESC, books.id").
+		Limit(limit)
+
+	if len(exclude) > 0 {
+		query = query.Where("books.id NOT IN ?", exclude)
+	}
+
+	return r.read(ctx, query)
+}
+
+// Rebuild recomputes both tables from scratch in one transaction, so readers
+// always see a complete snapshot.
+//
+// Two books are related by every genre they share, by sharing an author,
+// and by every paid order that contains both ("customers also bought"). A
+// user's recommendations are the books most related to what they bought,
+// shelved or rated 4 and above, minus those books.
+func (r *recommendRepository) Rebuild(ctx context.Context) error {
+	args := map[string]any{
+		"genre":       genreWeight,
+		"author":      authorWeight,
+		"order":       coOrderWeight,
+		"related":     relatedPerBook,
+		"recommended": recommendedPer,
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM book_relations").Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			INSERT INTO book_relations (book_id, related_id, score)
+			SELECT book_id, related_id, score FROM (
+				SELECT pairs.book_id, pairs.related_id, SUM(pairs.weight) AS score,
+					ROW_NUMBER() OVER (PARTITION BY pairs.book_id ORDER BY SUM(pairs.weight) DESC, pairs.related_id) AS rank
+				FROM (
+					SELECT g1.book_id, g2.book_id AS related_id, CAST(@genre AS double precision) AS weight
+					FROM genre_of_books g1
+					JOIN genre_of_books g2 ON LOWER(g1.genre) = LOWER(g2.genre) AND g1.book_id <> g2.book_id
+					WHERE g1.deleted_at IS NULL AND g2.deleted_at IS NULL
+					UNION ALL
+					SELECT DISTINCT a1.book_id, a2.book_id, CAST(@author AS double precision)
+					FROM book_authors a1
+					JOIN book_authors a2 ON a1.author_id = a2.author_id AND a1.book_id <> a2.book_id
+					WHERE a1.role = 'author' AND a2.role = 'author'
+					UNION ALL
+					SELECT o1.book_id, o2.book_id, CAST(@order AS double precision)
+					FROM order_items o1
+					JOIN order_items o2 ON o1.order_id = o2.order_id AND o1.book_id <> o2.book_id
+					JOIN orders ON orders.id = o1.order_id AND orders.status = 'paid'
+				) pairs
+				JOIN books a ON a.id = pairs.book_id AND a.deleted_at IS NULL
+				JOIN books b ON b.id = pairs.related_id AND b.deleted_at IS NULL
+				GROUP BY pairs.book_id, pairs.related_id
+			) ranked
+			WHERE rank <= @related`, args).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM user_recommendations").Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			WITH seeds AS (
+				SELECT orders.user_id, order_items.book_id
+				FROM order_items
+				JOIN orders ON orders.id = order_items.order_id
+				WHERE orders.status = 'paid' AND orders.user_id IS NOT NULL AND order_items.book_id IS NOT NULL
+				UNION
+				SELECT user_id, book_id FROM ebook_purchases
+				UNION
+				SELECT shelves.user_id, shelf_items.book_id
+				FROM shelf_items
+				JOIN shelves ON shelves.id = shelf_items.shelf_id
+				UNION
+				SELECT user_id, book_id FROM reviews WHERE rating >= 4
+			)
+			INSERT INTO user_recommendations (user_id, book_id, score)
+			SELECT user_id, book_id, score FROM (
+				SELECT seeds.user_id, rel.related_id AS book_id, SUM(rel.score) AS score,
+					ROW_NUMBER() OVER (PARTITION BY seeds.user_id ORDER BY SUM(rel.score) DESC, rel.related_id) AS rank
+				FROM seeds
+				JOIN book_relations rel ON rel.book_id = seeds.book_id
+				WHERE NOT EXISTS (
+					SELECT 1 FROM seeds own
+					WHERE own.user_id = seeds.user_id AND own.book_id = rel.related_id
+				)
+				GROUP BY seeds.user_id, rel.related_id
+			) ranked
+			WHERE rank <= @recommended`, args).Error
+	})
+}
+
+func (r *recommendRepository) read(ctx context.Context, query *gorm.DB) ([]Recommendation, error) {
+	var result []Recommendation
+	if err := query.WithContext(ctx).Scan(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
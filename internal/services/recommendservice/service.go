@@ -0,0 +1,97 @@
+package recommendservice
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+type RecommendRepository interface {
+	BookExists(ctx context.Context, bookId string) (bool, error)
+	ReadRelated(ctx context.Context, bookId string, limit int) ([]Recommendation, error)
+	ReadForUser(ctx context.Context, userId string, limit int) ([]Recommendation, error)
+	ReadBestsellers(ctx context.Context, exclude []string, limit int) ([]Recommendation, error)
+	Rebuild(ctx context.Context) error
+}
+
+type Recommendation struct {
+	BookId string
+	Title  string
+	Author string
+	Rating float64
+	Score  float64
+}
+
+type recommendService struct {
+	repo  RecommendRepository
+	cache *cache
+}
+
+func NewRecommendService(repo RecommendRepository, cacheTTL time.Duration) RecommendService {
+	return &recommendService{repo: repo, cache: newCache(cacheTTL)}
+}
+
+// ReadRelated returns books related to bookId, or the bestsellers when
+// nothing has been computed for it yet.
+func (s *recommendService) ReadRelated(ctx context.Context, bookId string, limit int) ([]Recommendation, error) {
+	key := "book:" + bookId + ":" + strconv.Itoa(limit)
+	if cached, ok := s.cache.get(key); ok {
+		return cached, nil
+	}
+
+	exists, err := s.repo.BookExists(ctx, bookId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrBookNotFound
+	}
+
+	result, err := s.repo.ReadRelated(ctx, bookId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		result, err = s.repo.ReadBestsellers(ctx, []string{bookId}, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.cache.set(key, result)
+	return result, nil
+}
+
+// ReadForUser returns the user's recommendations, or the bestsellers for
+// new users with no purchases, shelves or reviews yet.
+func (s *recommendService) ReadForUser(ctx context.Context, userId string, limit int) ([]Recommendation, error) {
+	key := "user:" + userId + ":" + strconv.Itoa(limit)
+	if cached, ok := s.cache.get(key); ok {
+		return cached, nil
+	}
+
+	result, err := s.repo.ReadForUser(ctx, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		result, err = s.repo.ReadBestsellers(ctx, nil, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.cache.set(key, result)
+	return result, nil
+}
+
+func (s *recommendService) Rebuild(ctx context.Context) error {
+	if err := s.repo.Rebuild(ctx); err != nil {
+		return err
+	}
+
+	s.cache.clear()
+	return nil
+}
@@ -0,0 +1,28 @@
+package returnservice
+
+import "errors"
+
+var (
+	ErrReturnNotFound    = errors.New("return not found")
+	ErrBookNotFound      = errors.New("book not found")
+	ErrBookNotInOrder    = errors.New("book is not in the paid order")
+	ErrQuantityExceeded  = errors.New("quantity exceeds what was bought and not returned yet")
+	ErrEbookDownloaded   = errors.New("a downloaded e-book cannot be returned")
+	ErrNothingToRefund   = errors.New("payment has nothing left to refund")
+	ErrPaymentNotFound   = errors.New("payment not found")
+	ErrPaymentNotPaid    = errors.New("only paid payments can be returned")
+	ErrReturnExists      = errors.New("payment already has an open return")
+	ErrNoItems           = errors.New("return must list at least one book")
+	ErrTooManyItems      = errors.New("too many books in one return")
+	ErrDuplicateItem     = errors.New("each book may be listed only once")
+	ErrInvalidQuantity   = errors.New("quantity must be between 1 and 100")
+	ErrInvalidReason     = errors.New("reason must be one of damaged, wrong_item, not_as_described, changed_mind, other")
+	ErrInvalidRefund     = errors.New("refund amount must be positive and not exceed what the payment has left to refund")
+	ErrCommentTooLong    = errors.New("comment is too long")
+	ErrInvalidTransition = errors.New("return is not in a state that allows this operation")
+	ErrRefundFailed      = errors.New("refund failed")
+	ErrInvalidStatus     = errors.New("invalid status")
+	ErrAccessDenied      = errors.New("access denied")
+	ErrInvalidPage       = errors.New("invalid page")
+	ErrInvalidLimit      = errors.New("invalid limit")
+)
@@ -0,0 +1,324 @@
+package returnservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReturnService interface {
+	CreateReturn(ctx context.Context, request *entities.ReturnRequest) (*entities.ReturnRequest, error)
+	ReadReturns(ctx context.Context, userId, role, status string, page, limit int) ([]entities.ReturnRequest, error)
+	ReadReturn(ctx context.Context, userId, role, id string) (*entities.ReturnRequest, []entities.ReturnStatusChange, error)
+	Approve(ctx context.Context, actorId, id string, refundAmount *pricing.Money, toStoreCredit bool, comment *string) (*entities.ReturnRequest, error)
+	Reject(ctx context.Context, actorId, id string, comment *string) (*entities.ReturnRequest, error)
+	RetryRefund(ctx context.Context, actorId, id string) (*entities.ReturnRequest, error)
+}
+
+type ReturnHandler struct {
+	service ReturnService
+}
+
+func NewReturnHandler(service ReturnService) *ReturnHandler {
+	return &ReturnHandler{service: service}
+}
+
+// CreateReturn
+// @Summary Оформить возврат книг
+// @Description Вернуть можно только книги оплаченного заказа, не больше купленного и ещё не возвращённого; электронные книги — только до первого скачивания. Сумма считается по оплаченной цене строки заказа. Причины: damaged, wrong_item, not_as_described, changed_mind, other
+// @Tags returns
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ReturnRequest true "Платёж, книги и причины возврата"
+// @Success 201 {object} dto.ReturnResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /returns [post]
+func (h *ReturnHandler) CreateReturn(c echo.Context) error {
+	var request dto.ReturnRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	items := make([]entities.ReturnItem, 0, len(request.Items))
+	for _, item := range request.Items {
+		items = append(items, entities.ReturnItem{
+			BookId:   item.BookId,
+			Quantity: item.Quantity,
+			Reason:   item.Reason,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	created, err := h.service.CreateReturn(ctx, &entities.ReturnRequest{
+		UserId:    c.Get("id").(string),
+		PaymentId: request.PaymentId,
+		Comment:   request.Comment,
+		Items:     items,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPaymentNotFound), errors.Is(err, ErrBookNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrReturnExists),
+			errors.Is(err, ErrPaymentNotPaid),
+			errors.Is(err, ErrNothingToRefund),
+			errors.Is(err, ErrEbookDownloaded):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrNoItems), errors.Is(err, ErrTooManyItems), errors.Is(err, ErrDuplicateItem),
+			errors.Is(err, ErrInvalidQuantity), errors.Is(err, ErrInvalidReason), errors.Is(err, ErrCommentTooLong),
+			errors.Is(err, ErrBookNotInOrder), errors.Is(err, ErrQuantityExceeded):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toReturnResponse(created, nil))
+}
+
+// ReadReturns
+// @Summary Получить список возвратов
+// @Description Клиент видит только свои возвраты
+// @Tags returns
+// @Security BearerAuth
+// @Param status query string false "Статус: requested, approved, rejected, refunded"
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Produce json
+// @Success 200 {array} dto.ReturnResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /returns [get]
+func (h *ReturnHandler) ReadReturns(c echo.Context) error {
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidPage.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	requests, err := h.service.ReadReturns(ctx, userId, role, c.QueryParam("status"), page, limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.ReturnResponse, 0, len(requests))
+	for i := range requests {
+		response = append(response, toReturnResponse(&requests[i], nil))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReadReturn
+// @Summary Получить возврат с историей статусов
+// @Tags returns
+// @Security BearerAuth
+// @Param id path string true "ID возврата"
+// @Produce json
+// @Success 200 {object} dto.ReturnResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /returns/{id} [get]
+func (h *ReturnHandler) ReadReturn(c echo.Context) error {
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, history, err := h.service.ReadReturn(ctx, userId, role, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, ErrReturnNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toReturnResponse(request, history))
+}
+
+// Approve
+// @Summary Одобрить возврат
+// @Description Книги возвращаются на склад, полностью возвращённые электронные книги перестают быть доступны для скачивания, начисленные за них баллы списываются, деньги возвращаются через платёжного провайдера или на внутренний счёт магазина, не больше остатка платежа
+// @Tags returns
+// @Security BearerAuth
+// @Param id path string true "ID возврата"
+// @Accept json
+// @Produce json
+// @Param request body dto.ReturnDecisionRequest false "Сумма и способ возврата, комментарий"
+// @Success 200 {object} dto.ReturnResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 502 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /returns/{id}/approve [post]
+func (h *ReturnHandler) Approve(c echo.Context) error {
+	var request dto.ReturnDecisionRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	return h.decide(c, func(ctx context.Context, actorId, id string) (*entities.ReturnRequest, error) {
+		return h.service.Approve(ctx, actorId, id, request.RefundAmount, request.StoreCredit, request.Comment)
+	})
+}
+
+// Reject
+// @Summary Отклонить возврат
+// @Tags returns
+// @Security BearerAuth
+// @Param id path string true "ID возврата"
+// @Accept json
+// @Produce json
+// @Param request body dto.ReturnDecisionRequest false "Комментарий"
+// @Success 200 {object} dto.ReturnResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /returns/{id}/reject [post]
+func (h *ReturnHandler) Reject(c echo.Context) error {
+	var request dto.ReturnDecisionRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	return h.decide(c, func(ctx context.Context, actorId, id string) (*entities.ReturnRequest, error) {
+		return h.service.Reject(ctx, actorId, id, request.Comment)
+	})
+}
+
+// RetryRefund
+// @Summary Повторить возврат денег по одобренному возврату
+// @Tags returns
+// @Security BearerAuth
+// @Param id path string true "ID возврата"
+// @Produce json
+// @Success 200 {object} dto.ReturnResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 502 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /returns/{id}/refund [post]
+func (h *ReturnHandler) RetryRefund(c echo.Context) error {
+	return h.decide(c, h.service.RetryRefund)
+}
+
+func (h *ReturnHandler) decide(c echo.Context, action func(ctx context.Context, actorId, id string) (*entities.ReturnRequest, error)) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	request, err := action(ctx, c.Get("id").(string), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrReturnNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidTransition),
+			errors.Is(err, ErrPaymentNotPaid),
+			errors.Is(err, ErrNothingToRefund),
+			errors.Is(err, ErrEbookDownloaded):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidRefund), errors.Is(err, ErrCommentTooLong):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrRefundFailed):
+			return c.JSON(http.StatusBadGateway, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toReturnResponse(request, nil))
+}
+
+func toReturnResponse(request *entities.ReturnRequest, history []entities.ReturnStatusChange) dto.ReturnResponse {
+	response := dto.ReturnResponse{
+		Id:           request.Id,
+		UserId:       request.UserId,
+		PaymentId:    request.PaymentId,
+		Status:       request.Status,
+		RefundAmount: pricing.FromFloat(request.RefundAmount),
+		RefundMethod: request.RefundMethod,
+		Currency:     request.Currency,
+		Items:        make([]dto.ReturnItemResponse, 0, len(request.Items)),
+		CreatedAt:    request.CreatedAt,
+		UpdatedAt:    request.UpdatedAt,
+	}
+
+	if request.Comment != nil {
+		response.Comment = *request.Comment
+	}
+
+	for _, item := range request.Items {
+		response.Items = append(response.Items, dto.ReturnItemResponse{
+			BookId:    item.BookId,
+			Quantity:  item.Quantity,
+			Reason:    item.Reason,
+			UnitPrice: pricing.FromFloat(item.UnitPrice),
+			Points:    item.Points,
+		})
+	}
+
+	for _, change := range history {
+		entry := dto.ReturnStatusChangeResponse{
+			Status:    change.Status,
+			CreatedAt: change.CreatedAt,
+		}
+		if change.ActorId != nil {
+			entry.ActorId = *change.ActorId
+		}
+		if change.Comment != nil {
+			entry.Comment = *change.Comment
+		}
+		response.History = append(response.History, entry)
+	}
+
+	return response
+}
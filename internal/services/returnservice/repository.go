@@ -0,0 +1,434 @@
+package returnservice
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uniqueViolationCode = "23505"
+
+type returnRepository struct {
+	db *gorm.DB
+}
+
+func NewReturnRepository(db *gorm.DB) ReturnRepository {
+	return &returnRepository{db: db}
+}
+
+// ReadLines returns the lines of an order by book. Lines whose book has been
+// deleted cannot be returned and are left out.
+func (r *returnRepository) ReadLines(ctx context.Context, orderId string) (map[string]Line, error) {
+	db := r.db.WithContext(ctx)
+
+	var items []entities.OrderItem
+	if err := db.
+		Where("order_id = ? AND book_id IS NOT NULL", orderId).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	returned, err := readReturned(db, orderId, "", []string{StatusRequested, StatusApproved, StatusRefunded})
+	if err != nil {
+		return nil, err
+	}
+
+	var downloaded []string
+	if err = db.
+		Model(&entities.EbookPurchase{}).
+		Where("order_id = ? AND downloads > 0", orderId).
+		Pluck("book_id", &downloaded).Error; err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]Line, len(items))
+	for _, item := range items {
+		lines[*item.BookId] = Line{
+			BookId:     *item.BookId,
+			Quantity:   item.Quantity,
+			Returned:   returned[*item.BookId],
+			Total:      pricing.FromFloat(item.Total),
+			Downloaded: slices.Contains(downloaded, *item.BookId),
+		}
+	}
+	return lines, nil
+}
+
+func (r *returnRepository) ReadRefundable(ctx context.Context, payment *entities.Payment, exceptId string) (pricing.Money, error) {
+	return refundable(r.db.WithContext(ctx), payment, exceptId)
+}
+
+func (r *returnRepository) Create(ctx context.Context, request *entities.ReturnRequest, change *entities.ReturnStatusChange) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(request).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrReturnExists
+			}
+			return err
+		}
+
+		return tx.Create(change).Error
+	})
+}
+
+func (r *returnRepository) ReadAll(ctx context.Context, userId, status string, offset, limit int) ([]entities.ReturnRequest, error) {
+	query := r.db.
+		WithContext(ctx).
+		Preload("Items")
+
+	if userId != "" {
+		query = query.Where("user_id = ?", userId)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var requests []entities.ReturnRequest
+	if err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (r *returnRepository) ReadById(ctx context.Context, id string) (*entities.ReturnRequest, error) {
+	return readReturn(r.db.WithContext(ctx), id)
+}
+
+func (r *returnRepository) ReadHistory(ctx context.Context, id string) ([]entities.ReturnStatusChange, error) {
+	var history []entities.ReturnStatusChange
+	if err := r.db.
+		WithContext(ctx).
+		Where("return_id = ?", id).
+		Order("created_at").
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// Approve moves a requested return to approved and puts its books back into
+// stock, recording a restock entry per book. E-books returned in full lose
+// their purchase. Everything happens in one transaction, so a return is
+// never half restocked.
+func (r *returnRepository) Approve(ctx context.Context, id string, refundAmount *pricing.Money, refundMethod string, change *entities.ReturnStatusChange) (*entities.ReturnRequest, error) {
+	var request *entities.ReturnRequest
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		request, err = readReturn(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+		if err != nil {
+			return err
+		}
+
+		if request.Status != StatusRequested {
+			return ErrInvalidTransition
+		}
+
+		var payment entities.Payment
+		if err = tx.
+			Where("id = ?", request.PaymentId).
+			First(&payment).Error; err != nil {
+			return err
+		}
+
+		left, err := refundable(tx, &payment, request.Id)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case refundAmount != nil && *refundAmount > left:
+			return ErrInvalidRefund
+		case refundAmount != nil:
+			request.RefundAmount = refundAmount.Float()
+		case pricing.FromFloat(request.RefundAmount) > left:
+			request.RefundAmount = left.Float()
+		}
+		if request.RefundAmount <= 0 {
+			return ErrNothingToRefund
+		}
+
+		if err = reversePoints(tx, request, payment.OrderId); err != nil {
+			return err
+		}
+
+		if err = revokeEbooks(tx, request, payment.OrderId); err != nil {
+			return err
+		}
+
+		for _, item := range request.Items {
+			// Raw SQL so books moved to the trash are restocked too.
+			// E-books have no stock to go back to.
+			result := tx.Exec(
+				"UPDATE books SET amount = amount + ? WHERE id = ? AND format IS DISTINCT FROM ?",
+				item.Quantity, item.BookId, entities.FormatEbook,
+			)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			if err = tx.Create(&entities.RestockEntry{
+				Id:        uuid.NewString(),
+				BookId:    item.BookId,
+				Quantity:  item.Quantity,
+				ReturnId:  &request.Id,
+				CreatedAt: change.CreatedAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		request.Status = StatusApproved
+		request.RefundMethod = refundMethod
+		request.UpdatedAt = change.CreatedAt
+
+		if err = tx.
+			Model(&entities.ReturnRequest{}).
+			Where("id = ?", request.Id).
+			Updates(map[string]any{
+				"status":        request.Status,
+				"refund_amount": request.RefundAmount,
+				"refund_method": request.RefundMethod,
+				"updated_at":    request.UpdatedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ChangeStatus moves a return from one status to change.Status and records
+// the change.
+func (r *returnRepository) ChangeStatus(ctx context.Context, id, from string, change *entities.ReturnStatusChange) (*entities.ReturnRequest, error) {
+	var request *entities.ReturnRequest
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		request, err = readReturn(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+		if err != nil {
+			return err
+		}
+
+		if request.Status != from {
+			return ErrInvalidTransition
+		}
+
+		request.Status = change.Status
+		request.UpdatedAt = change.CreatedAt
+
+		if err = tx.
+			Model(&entities.ReturnRequest{}).
+			Where("id = ?", request.Id).
+			Updates(map[string]any{
+				"status":     request.Status,
+				"updated_at": request.UpdatedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// refundable returns what a payment has left to refund: its amount less
+// what the provider has refunded or been asked to refund and what other
+// returns will refund or have refunded to store credit.
+func refundable(db *gorm.DB, payment *entities.Payment, exceptId string) (pricing.Money, error) {
+	query := db.
+		Model(&entities.ReturnRequest{}).
+		Where("payment_id = ?", payment.Id).
+		Where("status = ? OR (status = ? AND refund_method = ?)", StatusApproved, StatusRefunded, RefundStoreCredit)
+	if exceptId != "" {
+		query = query.Where("id <> ?", exceptId)
+	}
+
+	var committed float64
+	if err := query.
+		Select("COALESCE(SUM(refund_amount), 0)").
+		Scan(&committed).Error; err != nil {
+		return 0, err
+	}
+
+	return pricing.FromFloat(payment.Amount) -
+		pricing.FromFloat(payment.RefundedAmount) -
+		pricing.FromFloat(payment.RefundPending) -
+		pricing.FromFloat(committed), nil
+}
+
+// revokeEbooks deletes the purchases of e-books whose order line the return
+// completes, so they can no longer be downloaded. The purchases are locked,
+// and an e-book downloaded since the return was filed cannot be returned.
+func revokeEbooks(tx *gorm.DB, request *entities.ReturnRequest, orderId string) error {
+	bookIds := make([]string, 0, len(request.Items))
+	for _, item := range request.Items {
+		bookIds = append(bookIds, item.BookId)
+	}
+
+	var purchases []entities.EbookPurchase
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND book_id IN ?", orderId, bookIds).
+		Find(&purchases).Error; err != nil {
+		return err
+	}
+	if len(purchases) == 0 {
+		return nil
+	}
+
+	var items []entities.OrderItem
+	if err := tx.
+		Where("order_id = ? AND book_id IN ?", orderId, bookIds).
+		Find(&items).Error; err != nil {
+		return err
+	}
+	bought := make(map[string]int, len(items))
+	for _, item := range items {
+		bought[*item.BookId] = item.Quantity
+	}
+
+	returned, err := readReturned(tx, orderId, request.Id, []string{StatusApproved, StatusRefunded})
+	if err != nil {
+		return err
+	}
+
+	for _, purchase := range purchases {
+		if purchase.Downloads > 0 {
+			return ErrEbookDownloaded
+		}
+
+		for _, item := range request.Items {
+			if item.BookId != purchase.BookId || returned[item.BookId]+item.Quantity < bought[item.BookId] {
+				continue
+			}
+			if err = tx.Delete(&entities.EbookPurchase{}, "id = ?", purchase.Id).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readReturned returns how many books of each order line are in the order's
+// returns with one of statuses, other than the return exceptId.
+func readReturned(db *gorm.DB, orderId, exceptId string, statuses []string) (map[string]int, error) {
+	query := db.
+		Table("return_items AS i").
+		Joins("JOIN return_requests AS r ON r.id = i.return_id").
+		Joins("JOIN payments AS p ON p.id = r.payment_id").
+		Where("p.order_id = ? AND r.status IN ?", orderId, statuses)
+	if exceptId != "" {
+		query = query.Where("r.id <> ?", exceptId)
+	}
+
+	var rows []struct {
+		BookId   string
+		Returned int
+	}
+	if err := query.
+		Select("i.book_id, SUM(i.quantity) AS returned").
+		Group("i.book_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	returned := make(map[string]int, len(rows))
+	for _, row := range rows {
+		returned[row.BookId] = row.Returned
+	}
+	return returned, nil
+}
+
+// reversePoints takes back the loyalty points the returned books earned. A
+// line's points are split between its books the way its total is, and the
+// balance never goes below zero.
+func reversePoints(tx *gorm.DB, request *entities.ReturnRequest, orderId string) error {
+	var items []entities.OrderItem
+	if err := tx.
+		Where("order_id = ? AND book_id IS NOT NULL", orderId).
+		Find(&items).Error; err != nil {
+		return err
+	}
+
+	lines := make(map[string]entities.OrderItem, len(items))
+	for _, item := range items {
+		lines[*item.BookId] = item
+	}
+
+	returned, err := readReturned(tx, orderId, request.Id, []string{StatusApproved, StatusRefunded})
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for i := range request.Items {
+		item := &request.Items[i]
+
+		line, ok := lines[item.BookId]
+		if !ok || line.Points == 0 {
+			continue
+		}
+
+		item.Points = sharePoints(line.Points, returned[item.BookId], item.Quantity, line.Quantity)
+		if item.Points == 0 {
+			continue
+		}
+
+		if err = tx.
+			Model(&entities.ReturnItem{}).
+			Where("return_id = ? AND book_id = ?", item.ReturnId, item.BookId).
+			Update("points", item.Points).Error; err != nil {
+			return err
+		}
+		total += item.Points
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	return tx.
+		Model(&entities.User{}).
+		Where("id = ?", request.UserId).
+		Update("points", gorm.Expr("GREATEST(COALESCE(points, 0) - ?, 0)", total)).Error
+}
+
+func readReturn(db *gorm.DB, id string) (*entities.ReturnRequest, error) {
+	var request entities.ReturnRequest
+	if err := db.
+		Preload("Items").
+		Where("id = ?", id).
+		First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReturnNotFound
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
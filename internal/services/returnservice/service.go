@@ -0,0 +1,365 @@
+package returnservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"story-book/internal/services/paymentservice"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Return statuses. A return is requested by the customer, then approved or
+// rejected by staff; an approved return becomes refunded once the payment
+// provider has accepted the refund.
+const (
+	StatusRequested = "requested"
+	StatusApproved  = "approved"
+	StatusRejected  = "rejected"
+	StatusRefunded  = "refunded"
+)
+
+// Refund methods: back through the payment provider or to the customer's
+// store credit.
+const (
+	RefundPayment     = "payment"
+	RefundStoreCredit = "store_credit"
+)
+
+const (
+	maxItems         = 50
+	maxQuantity      = 100
+	maxCommentLength = 1000
+)
+
+var reasons = map[string]bool{
+	"damaged":          true,
+	"wrong_item":       true,
+	"not_as_described": true,
+	"changed_mind":     true,
+	"other":            true,
+}
+
+var statuses = map[string]bool{
+	StatusRequested: true,
+	StatusApproved:  true,
+	StatusRejected:  true,
+	StatusRefunded:  true,
+}
+
+// Line is a line of the order a payment was made for, with how many of its
+// books are already in returns that were not rejected. Downloaded is set
+// for an e-book the buyer has downloaded.
+type Line struct {
+	BookId     string
+	Quantity   int
+	Returned   int
+	Total      pricing.Money
+	Downloaded bool
+}
+
+type ReturnRepository interface {
+	ReadLines(ctx context.Context, orderId string) (map[string]Line, error)
+	ReadRefundable(ctx context.Context, payment *entities.Payment, exceptId string) (pricing.Money, error)
+	Create(ctx context.Context, request *entities.ReturnRequest, change *entities.ReturnStatusChange) error
+	ReadAll(ctx context.Context, userId, status string, offset, limit int) ([]entities.ReturnRequest, error)
+	ReadById(ctx context.Context, id string) (*entities.ReturnRequest, error)
+	ReadHistory(ctx context.Context, id string) ([]entities.ReturnStatusChange, error)
+	Approve(ctx context.Context, id string, refundAmount *pricing.Money, refundMethod string, change *entities.ReturnStatusChange) (*entities.ReturnRequest, error)
+	ChangeStatus(ctx context.Context, id, from string, change *entities.ReturnStatusChange) (*entities.ReturnRequest, error)
+}
+
+// Payments is the part of the payment service returns rely on.
+type Payments interface {
+	ReadPayment(ctx context.Context, userId, role, id string) (*entities.Payment, error)
+	Refund(ctx context.Context, id string, amount pricing.Money) (*entities.Payment, error)
+}
+
+// Credits is the part of the gift card service refunds to store credit
+// rely on.
+type Credits interface {
+	CreditReturn(ctx context.Context, userId, returnId string, amount pricing.Money, currency string) error
+}
+
+type returnService struct {
+	repo     ReturnRepository
+	payments Payments
+	credits  Credits
+}
+
+func NewReturnService(repo ReturnRepository, payments Payments, credits Credits) ReturnService {
+	return &returnService{repo: repo, payments: payments, credits: credits}
+}
+
+// CreateReturn files a return against one of the user's paid payments. Only
+// books of the paid order can be returned, no more of each than was bought
+// and not returned yet, and e-books only until they are downloaded. The refund is the returned share of each order
+// line as paid, in the payment currency, capped at what the payment has
+// left to refund.
+func (s *returnService) CreateReturn(ctx context.Context, request *entities.ReturnRequest) (*entities.ReturnRequest, error) {
+	if err := validateItems(request.Items); err != nil {
+		return nil, err
+	}
+
+	comment, err := normalizeComment(request.Comment)
+	if err != nil {
+		return nil, err
+	}
+	request.Comment = comment
+
+	payment, err := s.payments.ReadPayment(ctx, request.UserId, "client", request.PaymentId)
+	if err != nil {
+		if errors.Is(err, paymentservice.ErrPaymentNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	if payment.Status != paymentservice.StatusPaid {
+		return nil, ErrPaymentNotPaid
+	}
+
+	lines, err := s.repo.ReadLines(ctx, payment.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	var total pricing.Money
+	for i := range request.Items {
+		item := &request.Items[i]
+
+		line, ok := lines[item.BookId]
+		if !ok {
+			return nil, ErrBookNotInOrder
+		}
+		if item.Quantity > line.Quantity-line.Returned {
+			return nil, ErrQuantityExceeded
+		}
+		if line.Downloaded {
+			return nil, ErrEbookDownloaded
+		}
+
+		refund := share(line.Total, line.Returned, item.Quantity, line.Quantity)
+		item.UnitPrice = refund.Portion(1, item.Quantity).Float()
+		total += refund
+	}
+
+	// Earlier refunds of the payment cannot be refunded again.
+	left, err := s.repo.ReadRefundable(ctx, payment, "")
+	if err != nil {
+		return nil, err
+	}
+	if total > left {
+		total = left
+	}
+	if total <= 0 {
+		return nil, ErrNothingToRefund
+	}
+
+	now := time.Now()
+
+	request.Id = uuid.NewString()
+	request.Status = StatusRequested
+	request.RefundAmount = total.Float()
+	request.RefundMethod = RefundPayment
+	request.Currency = payment.Currency
+	request.CreatedAt = now
+	request.UpdatedAt = now
+
+	for i := range request.Items {
+		request.Items[i].ReturnId = request.Id
+	}
+
+	change := newChange(request.Id, StatusRequested, request.UserId, request.Comment)
+
+	if err = s.repo.Create(ctx, request, change); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// ReadReturns lists returns, newest first. Clients only see their own; staff
+// may filter by status.
+func (s *returnService) ReadReturns(ctx context.Context, userId, role, status string, page, limit int) ([]entities.ReturnRequest, error) {
+	if status != "" && !statuses[status] {
+		return nil, ErrInvalidStatus
+	}
+
+	owner := ""
+	if role == "client" {
+		owner = userId
+	}
+
+	return s.repo.ReadAll(ctx, owner, status, (page-1)*limit, limit)
+}
+
+// ReadReturn returns a return with its status history.
+func (s *returnService) ReadReturn(ctx context.Context, userId, role, id string) (*entities.ReturnRequest, []entities.ReturnStatusChange, error) {
+	request, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if role == "client" && request.UserId != userId {
+		return nil, nil, ErrReturnNotFound
+	}
+
+	history, err := s.repo.ReadHistory(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return request, history, nil
+}
+
+// Approve accepts a return: the books go back into stock, e-books returned
+// in full lose their download, the loyalty points they earned are taken
+// back and the refund is sent to the payment
+// provider, or to the customer's store credit when toStoreCredit is set. The
+// refund never exceeds what the payment has left to refund. Restocking is
+// committed first, so a failed refund leaves the return approved and can be
+// retried with RetryRefund.
+func (s *returnService) Approve(ctx context.Context, actorId, id string, refundAmount *pricing.Money, toStoreCredit bool, comment *string) (*entities.ReturnRequest, error) {
+	comment, err := normalizeComment(comment)
+	if err != nil {
+		return nil, err
+	}
+
+	if refundAmount != nil && *refundAmount <= 0 {
+		return nil, ErrInvalidRefund
+	}
+
+	refundMethod := RefundPayment
+	if toStoreCredit {
+		refundMethod = RefundStoreCredit
+	}
+
+	request, err := s.repo.Approve(ctx, id, refundAmount, refundMethod, newChange(id, StatusApproved, actorId, comment))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.refund(ctx, actorId, request)
+}
+
+func (s *returnService) Reject(ctx context.Context, actorId, id string, comment *string) (*entities.ReturnRequest, error) {
+	comment, err := normalizeComment(comment)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ChangeStatus(ctx, id, StatusRequested, newChange(id, StatusRejected, actorId, comment))
+}
+
+// RetryRefund sends the refund again for an approved return whose refund
+// failed.
+func (s *returnService) RetryRefund(ctx context.Context, actorId, id string) (*entities.ReturnRequest, error) {
+	request, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Status != StatusApproved {
+		return nil, ErrInvalidTransition
+	}
+
+	return s.refund(ctx, actorId, request)
+}
+
+func (s *returnService) refund(ctx context.Context, actorId string, request *entities.ReturnRequest) (*entities.ReturnRequest, error) {
+	amount := pricing.FromFloat(request.RefundAmount)
+
+	if request.RefundMethod == RefundStoreCredit {
+		if err := s.credits.CreditReturn(ctx, request.UserId, request.Id, amount, request.Currency); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+		}
+		return s.repo.ChangeStatus(ctx, request.Id, StatusApproved, newChange(request.Id, StatusRefunded, actorId, nil))
+	}
+
+	_, err := s.payments.Refund(ctx, request.PaymentId, amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, paymentservice.ErrInvalidAmount):
+			return nil, ErrInvalidRefund
+		case errors.Is(err, paymentservice.ErrInvalidTransition):
+			return nil, ErrPaymentNotPaid
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	}
+
+	return s.repo.ChangeStatus(ctx, request.Id, StatusApproved, newChange(request.Id, StatusRefunded, actorId, nil))
+}
+
+// share returns what count more of a line's quantity units are worth when
+// before of them have already been returned. Shares of successive returns
+// of one line add up to its total exactly.
+func share(total pricing.Money, before, count, quantity int) pricing.Money {
+	return total.Portion(before+count, quantity) - total.Portion(before, quantity)
+}
+
+// sharePoints splits a line's loyalty points the way share splits its total.
+func sharePoints(points, before, count, quantity int) int {
+	return points*(before+count)/quantity - points*before/quantity
+}
+
+func newChange(returnId, status, actorId string, comment *string) *entities.ReturnStatusChange {
+	return &entities.ReturnStatusChange{
+		Id:        uuid.NewString(),
+		ReturnId:  returnId,
+		Status:    status,
+		ActorId:   &actorId,
+		Comment:   comment,
+		CreatedAt: time.Now(),
+	}
+}
+
+func validateItems(items []entities.ReturnItem) error {
+	if len(items) == 0 {
+		return ErrNoItems
+	}
+	if len(items) > maxItems {
+		return ErrTooManyItems
+	}
+
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if seen[item.BookId] {
+			return ErrDuplicateItem
+		}
+		seen[item.BookId] = true
+
+		if item.BookId == "" {
+			return ErrBookNotFound
+		}
+		if item.Quantity < 1 || item.Quantity > maxQuantity {
+			return ErrInvalidQuantity
+		}
+		if !reasons[item.Reason] {
+			return ErrInvalidReason
+		}
+	}
+
+	return nil
+}
+
+func normalizeComment(comment *string) (*string, error) {
+	if comment == nil {
+		return nil, nil
+	}
+
+	text := strings.TrimSpace(*comment)
+	if text == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(text) > maxCommentLength {
+		return nil, ErrCommentTooLong
+	}
+
+	return &text, nil
+}
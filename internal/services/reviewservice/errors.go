@@ -0,0 +1,14 @@
+package reviewservice
+
+import "errors"
+
+var (
+	ErrBookNotFound   = errors.New("book not found")
+	ErrReviewNotFound = errors.New("review not found")
+	ErrReviewExists   = errors.New("you have already reviewed this book")
+	ErrInvalidRating  = errors.New("rating must be between 1 and 5")
+	ErrTextTooLong    = errors.New("review text is too long")
+	ErrAccessDenied   = errors.New("access denied")
+	ErrInvalidPage    = errors.New("invalid page")
+	ErrInvalidLimit   = errors.New("invalid limit")
+)
@@ -0,0 +1,296 @@
+package reviewservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReviewService interface {
+	CreateReview(ctx context.Context, review *entities.Review) (*entities.Review, error)
+	ReadReviews(ctx context.Context, bookId string, withHidden bool, page, limit int) ([]entities.Review, error)
+	UpdateReview(ctx context.Context, userId string, review *entities.Review) (*entities.Review, error)
+	DeleteReview(ctx context.Context, userId, role, id string) error
+	SetHidden(ctx context.Context, id string, hidden bool) (*entities.Review, error)
+}
+
+type ReviewHandler struct {
+	service ReviewService
+}
+
+func NewReviewHandler(service ReviewService) *ReviewHandler {
+	return &ReviewHandler{service: service}
+}
+
+// CreateReview
+// @Summary Оставить отзыв о книге
+// @Tags reviews
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Accept json
+// @Produce json
+// @Param request body dto.ReviewRequest true "Оценка и текст отзыва"
+// @Success 201 {object} dto.ReviewResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/reviews [post]
+func (h *ReviewHandler) CreateReview(c echo.Context) error {
+	var request dto.ReviewRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	bookId := c.Param("id")
+	if bookId == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	review, err := h.service.CreateReview(ctx, &entities.Review{
+		BookId: bookId,
+		UserId: userId,
+		Rating: request.Rating,
+		Text:   request.Text,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrReviewExists):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidRating), errors.Is(err, ErrTextTooLong):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toReviewResponse(review))
+}
+
+// ReadReviews
+// @Summary Получить отзывы о книге
+// @Tags reviews
+// @Param id path string true "ID книги"
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Produce json
+// @Success 200 {array} dto.ReviewResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /books/{id}/reviews [get]
+func (h *ReviewHandler) ReadReviews(c echo.Context) error {
+	bookId := c.Param("id")
+	if bookId == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidPage.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	role, _ := c.Get("role").(string)
+	withHidden := role != "" && role != "client"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reviews, err := h.service.ReadReviews(ctx, bookId, withHidden, page, limit)
+	if err != nil {
+		if errors.Is(err, ErrBookNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.ReviewResponse, 0, len(reviews))
+	for i := range reviews {
+		response = append(response, toReviewResponse(&reviews[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// UpdateReview
+// @Summary Изменить свой отзыв
+// @Tags reviews
+// @Security BearerAuth
+// @Param id path string true "ID отзыва"
+// @Accept json
+// @Produce json
+// @Param request body dto.ReviewRequest true "Оценка и текст отзыва"
+// @Success 200 {object} dto.ReviewResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /reviews/{id} [put]
+func (h *ReviewHandler) UpdateReview(c echo.Context) error {
+	var request dto.ReviewRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	review, err := h.service.UpdateReview(ctx, userId, &entities.Review{
+		Id:     id,
+		Rating: request.Rating,
+		Text:   request.Text,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrReviewNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrAccessDenied):
+			return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidRating), errors.Is(err, ErrTextTooLong):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toReviewResponse(review))
+}
+
+// DeleteReview
+// @Summary Удалить отзыв
+// @Tags reviews
+// @Security BearerAuth
+// @Param id path string true "ID отзыва"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /reviews/{id} [delete]
+func (h *ReviewHandler) DeleteReview(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+	role := c.Get("role").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.DeleteReview(ctx, userId, role, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrReviewNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrAccessDenied):
+			return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetVisibility
+// @Summary Скрыть или показать отзыв
+// @Tags reviews
+// @Security BearerAuth
+// @Param id path string true "ID отзыва"
+// @Accept json
+// @Produce json
+// @Param request body dto.ReviewVisibilityRequest true "Скрыть отзыв"
+// @Success 200 {object} dto.ReviewResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /reviews/{id}/visibility [patch]
+func (h *ReviewHandler) SetVisibility(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	var request dto.ReviewVisibilityRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	review, err := h.service.SetHidden(ctx, id, request.Hidden)
+	if err != nil {
+		if errors.Is(err, ErrReviewNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toReviewResponse(review))
+}
+
+func toReviewResponse(review *entities.Review) dto.ReviewResponse {
+	return dto.ReviewResponse{
+		Id:               review.Id,
+		BookId:           review.BookId,
+		UserId:           review.UserId,
+		Rating:           review.Rating,
+		Text:             validate(review.Text),
+		VerifiedPurchase: review.VerifiedPurchase,
+		Hidden:           review.Hidden,
+		CreatedAt:        review.CreatedAt,
+		UpdatedAt:        review.UpdatedAt,
+	}
+}
+
+func validate[T any](t *T) T {
+	if t != nil {
+		return *t
+	}
+
+	var zero T
+	return zero
+}
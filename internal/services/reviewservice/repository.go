@@ -0,0 +1,175 @@
+package reviewservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const uniqueViolationCode = "23505"
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+func (r *reviewRepository) BookExists(ctx context.Context, bookId string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Book{}).
+		Where("id = ?", bookId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// HasPurchased reports whether the user has paid for the book, either in an
+// order or as an e-book.
+func (r *reviewRepository) HasPurchased(ctx context.Context, userId, bookId string) (bool, error) {
+	var purchased bool
+	if err := r.db.
+		WithContext(ctx).
+		Raw(`
+			SELECT EXISTS (
+				SELECT 1 FROM order_items oi
+				JOIN orders o ON o.id = oi.order_id
+				WHERE o.user_id = @user AND o.status = 'paid' AND oi.book_id = @book
+			) OR EXISTS (
+				SELECT 1 FROM ebook_purchases WHERE user_id = @user AND book_id = @book
+			)`,
+			map[string]any{"user": userId, "book": bookId},
+		).
+		Scan(&purchased).Error; err != nil {
+		return false, err
+	}
+	return purchased, nil
+}
+
+func (r *reviewRepository) Create(ctx context.Context, review *entities.Review) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockBook(tx, review.BookId); err != nil {
+			return err
+		}
+
+		if err := tx.Create(review).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrReviewExists
+			}
+			return err
+		}
+
+		return refreshRating(tx, review.BookId)
+	})
+}
+
+func (r *reviewRepository) ReadByBook(ctx context.Context, bookId string, withHidden bool, offset, limit int) ([]entities.Review, error) {
+	query := r.db.
+		WithContext(ctx).
+		Where("book_id = ?", bookId)
+
+	if !withHidden {
+		query = query.Where("hidden = false")
+	}
+
+	var reviews []entities.Review
+	if err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (r *reviewRepository) ReadById(ctx context.Context, id string) (*entities.Review, error) {
+	var review entities.Review
+	if err := r.db.
+		WithContext(ctx).
+		Where("id = ?", id).
+		First(&review).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewRepository) Update(ctx context.Context, review *entities.Review) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockBook(tx, review.BookId); err != nil {
+			return err
+		}
+
+		res := tx.
+			Model(&entities.Review{}).
+			Where("id = ?", review.Id).
+			Select("rating", "text", "hidden", "updated_at").
+			Updates(review)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrReviewNotFound
+		}
+
+		return refreshRating(tx, review.BookId)
+	})
+}
+
+func (r *reviewRepository) Delete(ctx context.Context, review *entities.Review) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockBook(tx, review.BookId); err != nil {
+			return err
+		}
+
+		res := tx.Delete(&entities.Review{Id: review.Id})
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrReviewNotFound
+		}
+
+		return refreshRating(tx, review.BookId)
+	})
+}
+
+// lockBook serializes review changes of a book. Without it two reviews
+// written at once would each recompute the rating from a snapshot missing the
+// other, and the last one to commit would leave a stale average.
+func lockBook(tx *gorm.DB, bookId string) error {
+	return tx.Exec("SELECT 1 FROM books WHERE id = ? FOR UPDATE", bookId).Error
+}
+
+// refreshRating recomputes the book's average rating and review count from
+// its visible reviews. Keeping them on the book row lets GET /books sort by
+// rating without joining reviews. It runs in the same transaction as the
+// change and after lockBook, so the recount sees every committed review.
+func refreshRating(tx *gorm.DB, bookId string) error {
+	return tx.Exec(`
+		UPDATE books SET
+			rating = COALESCE((SELECT ROUND(AVG(rating), 2) FROM reviews WHERE book_id = @id AND hidden = false), 0),
+			review_count = (SELECT COUNT(*) FROM reviews WHERE book_id = @id AND hidden = false)
+		WHERE id = @id`,
+		map[string]any{"id": bookId},
+	).Error
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,154 @@
+package reviewservice
+
+import (
+	"context"
+	"story-book/internal/entities"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const maxTextLength = 5000
+
+type ReviewRepository interface {
+	BookExists(ctx context.Context, bookId string) (bool, error)
+	HasPurchased(ctx context.Context, userId, bookId string) (bool, error)
+	Create(ctx context.Context, review *entities.Review) error
+	ReadByBook(ctx context.Context, bookId string, withHidden bool, offset, limit int) ([]entities.Review, error)
+	ReadById(ctx context.Context, id string) (*entities.Review, error)
+	Update(ctx context.Context, review *entities.Review) error
+	Delete(ctx context.Context, review *entities.Review) error
+}
+
+type reviewService struct {
+	repo ReviewRepository
+}
+
+func NewReviewService(repo ReviewRepository) ReviewService {
+	return &reviewService{repo: repo}
+}
+
+func (s *reviewService) CreateReview(ctx context.Context, review *entities.Review) (*entities.Review, error) {
+	if err := validateReview(review); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.repo.BookExists(ctx, review.BookId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrBookNotFound
+	}
+
+	purchased, err := s.repo.HasPurchased(ctx, review.UserId, review.BookId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	review.Id = uuid.NewString()
+	review.Hidden = false
+	review.VerifiedPurchase = purchased
+	review.CreatedAt = now
+	review.UpdatedAt = now
+
+	if err = s.repo.Create(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *reviewService) ReadReviews(ctx context.Context, bookId string, withHidden bool, page, limit int) ([]entities.Review, error) {
+	exists, err := s.repo.BookExists(ctx, bookId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrBookNotFound
+	}
+
+	return s.repo.ReadByBook(ctx, bookId, withHidden, (page-1)*limit, limit)
+}
+
+// UpdateReview changes the rating and text of the user's own review.
+func (s *reviewService) UpdateReview(ctx context.Context, userId string, review *entities.Review) (*entities.Review, error) {
+	if err := validateReview(review); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.ReadById(ctx, review.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.UserId != userId {
+		return nil, ErrAccessDenied
+	}
+
+	current.Rating = review.Rating
+	current.Text = review.Text
+	current.UpdatedAt = time.Now()
+
+	if err = s.repo.Update(ctx, current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// DeleteReview removes a review. Clients may only delete their own.
+func (s *reviewService) DeleteReview(ctx context.Context, userId, role, id string) error {
+	review, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if role == "client" && review.UserId != userId {
+		return ErrAccessDenied
+	}
+
+	return s.repo.Delete(ctx, review)
+}
+
+// SetHidden hides a review from the public list and from the book's rating,
+// or brings it back.
+func (s *reviewService) SetHidden(ctx context.Context, id string, hidden bool) (*entities.Review, error) {
+	review, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Hidden = hidden
+	review.UpdatedAt = time.Now()
+
+	if err = s.repo.Update(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func validateReview(review *entities.Review) error {
+	if review.Rating < 1 || review.Rating > 5 {
+		return ErrInvalidRating
+	}
+
+	if review.Text != nil {
+		text := strings.TrimSpace(*review.Text)
+		if utf8.RuneCountInString(text) > maxTextLength {
+			return ErrTextTooLong
+		}
+		if text == "" {
+			review.Text = nil
+		} else {
+			review.Text = &text
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package seriesservice
+
+import "errors"
+
+var (
+	ErrSeriesNotFound     = errors.New("series not found")
+	ErrWorkNotFound       = errors.New("work not found")
+	ErrInvalidName        = errors.New("name must be between 1 and 200 characters")
+	ErrInvalidTitle       = errors.New("title must be between 1 and 200 characters")
+	ErrDescriptionTooLong = errors.New("description is too long")
+	ErrInvalidVolume      = errors.New("volume must be positive and given together with series_id")
+	ErrNameTaken          = errors.New("a series with this name already exists")
+	ErrVolumeTaken        = errors.New("this volume of the series is already taken")
+	ErrWorkInUse          = errors.New("work has editions; move them to another work first")
+	ErrInvalidOrder       = errors.New("work_ids must list every volume of the series exactly once")
+	ErrAccessDenied       = errors.New("access denied")
+	ErrInvalidPage        = errors.New("invalid page")
+	ErrInvalidLimit       = errors.New("invalid limit")
+)
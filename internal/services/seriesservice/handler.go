@@ -0,0 +1,475 @@
+package seriesservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SeriesService interface {
+	CreateSeries(ctx context.Context, series *entities.Series) (*entities.Series, error)
+	ReadAllSeries(ctx context.Context, query string, page, limit int) ([]entities.Series, error)
+	ReadSeries(ctx context.Context, id string) (*entities.Series, error)
+	UpdateSeries(ctx context.Context, series *entities.Series) (*entities.Series, error)
+	DeleteSeries(ctx context.Context, id string) error
+	ReorderVolumes(ctx context.Context, seriesId string, workIds []string) (*entities.Series, error)
+	CreateWork(ctx context.Context, work *entities.Work) (*entities.Work, error)
+	ReadWork(ctx context.Context, id string) (*entities.Work, error)
+	UpdateWork(ctx context.Context, work *entities.Work) (*entities.Work, error)
+	DeleteWork(ctx context.Context, id string) error
+}
+
+type SeriesHandler struct {
+	service SeriesService
+}
+
+func NewSeriesHandler(service SeriesService) *SeriesHandler {
+	return &SeriesHandler{service: service}
+}
+
+// CreateSeries
+// @Summary Создать серию
+// @Tags series
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.SeriesRequest true "Название и описание серии"
+// @Success 201 {object} dto.SeriesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /series [post]
+func (h *SeriesHandler) CreateSeries(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.SeriesRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	series, err := h.service.CreateSeries(ctx, &entities.Series{Name: request.Name, Description: request.Description})
+	if err != nil {
+		return seriesError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toSeriesResponse(series))
+}
+
+// ReadAllSeries
+// @Summary Получить серии
+// @Tags series
+// @Param q query string false "Поиск по названию"
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Produce json
+// @Success 200 {array} dto.SeriesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /series [get]
+func (h *SeriesHandler) ReadAllSeries(c echo.Context) error {
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidPage.Error()})
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: ErrInvalidLimit.Error()})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	series, err := h.service.ReadAllSeries(ctx, c.QueryParam("q"), page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.SeriesResponse, 0, len(series))
+	for i := range series {
+		response = append(response, toSeriesResponse(&series[i]))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReadSeries
+// @Summary Получить серию с томами
+// @Tags series
+// @Param id path string true "ID серии"
+// @Produce json
+// @Success 200 {object} dto.SeriesResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /series/{id} [get]
+func (h *SeriesHandler) ReadSeries(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	series, err := h.service.ReadSeries(ctx, c.Param("id"))
+	if err != nil {
+		return seriesError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toSeriesResponse(series))
+}
+
+// UpdateSeries
+// @Summary Изменить серию
+// @Tags series
+// @Security BearerAuth
+// @Param id path string true "ID серии"
+// @Accept json
+// @Produce json
+// @Param request body dto.SeriesRequest true "Название и описание серии"
+// @Success 200 {object} dto.SeriesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /series/{id} [put]
+func (h *SeriesHandler) UpdateSeries(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.SeriesRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	series, err := h.service.UpdateSeries(ctx, &entities.Series{
+		Id:          c.Param("id"),
+		Name:        request.Name,
+		Description: request.Description,
+	})
+	if err != nil {
+		return seriesError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toSeriesResponse(series))
+}
+
+// DeleteSeries
+// @Summary Удалить серию
+// @Description Произведения серии остаются в каталоге без серии
+// @Tags series
+// @Security BearerAuth
+// @Param id path string true "ID серии"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /series/{id} [delete]
+func (h *SeriesHandler) DeleteSeries(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteSeries(ctx, c.Param("id")); err != nil {
+		return seriesError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReorderVolumes
+// @Summary Изменить порядок томов серии
+// @Description Тома нумеруются с 1 в переданном порядке; нужно перечислить все произведения серии
+// @Tags series
+// @Security BearerAuth
+// @Param id path string true "ID серии"
+// @Accept json
+// @Produce json
+// @Param request body dto.VolumeOrderRequest true "ID произведений в новом порядке"
+// @Success 200 {object} dto.SeriesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /series/{id}/volumes [put]
+func (h *SeriesHandler) ReorderVolumes(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.VolumeOrderRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	series, err := h.service.ReorderVolumes(ctx, c.Param("id"), request.WorkIds)
+	if err != nil {
+		return seriesError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toSeriesResponse(series))
+}
+
+// CreateWork
+// @Summary Создать произведение
+// @Description Издания произведения — книги с его work_id
+// @Tags works
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.WorkRequest true "Название, серия и номер тома"
+// @Success 201 {object} dto.WorkResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /works [post]
+func (h *SeriesHandler) CreateWork(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.WorkRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	work, err := h.service.CreateWork(ctx, &entities.Work{
+		Title:    request.Title,
+		SeriesId: request.SeriesId,
+		Volume:   request.Volume,
+	})
+	if err != nil {
+		return seriesError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toWorkResponse(work))
+}
+
+// ReadWork
+// @Summary Получить произведение с изданиями
+// @Tags works
+// @Param id path string true "ID произведения"
+// @Produce json
+// @Success 200 {object} dto.WorkResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /works/{id} [get]
+func (h *SeriesHandler) ReadWork(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	work, err := h.service.ReadWork(ctx, c.Param("id"))
+	if err != nil {
+		return seriesError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toWorkResponse(work))
+}
+
+// UpdateWork
+// @Summary Изменить произведение
+// @Description Без series_id и volume произведение убирается из серии
+// @Tags works
+// @Security BearerAuth
+// @Param id path string true "ID произведения"
+// @Accept json
+// @Produce json
+// @Param request body dto.WorkRequest true "Название, серия и номер тома"
+// @Success 200 {object} dto.WorkResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /works/{id} [put]
+func (h *SeriesHandler) UpdateWork(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	var request dto.WorkRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	work, err := h.service.UpdateWork(ctx, &entities.Work{
+		Id:       c.Param("id"),
+		Title:    request.Title,
+		SeriesId: request.SeriesId,
+		Volume:   request.Volume,
+	})
+	if err != nil {
+		return seriesError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toWorkResponse(work))
+}
+
+// DeleteWork
+// @Summary Удалить произведение
+// @Description Произведение с изданиями, в том числе в корзине, удалить нельзя
+// @Tags works
+// @Security BearerAuth
+// @Param id path string true "ID произведения"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /works/{id} [delete]
+func (h *SeriesHandler) DeleteWork(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteWork(ctx, c.Param("id")); err != nil {
+		return seriesError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func seriesError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrSeriesNotFound), errors.Is(err, ErrWorkNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrNameTaken), errors.Is(err, ErrVolumeTaken), errors.Is(err, ErrWorkInUse):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidName), errors.Is(err, ErrInvalidTitle), errors.Is(err, ErrDescriptionTooLong),
+		errors.Is(err, ErrInvalidVolume), errors.Is(err, ErrInvalidOrder):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func toSeriesResponse(series *entities.Series) dto.SeriesResponse {
+	response := dto.SeriesResponse{
+		Id:        series.Id,
+		Name:      series.Name,
+		CreatedAt: series.CreatedAt,
+		UpdatedAt: series.UpdatedAt,
+	}
+
+	if series.Description != nil {
+		response.Description = *series.Description
+	}
+
+	for _, work := range series.Works {
+		volume := dto.VolumeResponse{
+			WorkId:  work.Id,
+			Title:   work.Title,
+			BookIds: make([]string, 0, len(work.Editions)),
+		}
+		if work.Volume != nil {
+			volume.Volume = *work.Volume
+		}
+		for _, edition := range work.Editions {
+			volume.BookIds = append(volume.BookIds, edition.Id)
+		}
+		response.Volumes = append(response.Volumes, volume)
+	}
+
+	return response
+}
+
+func toWorkResponse(work *entities.Work) dto.WorkResponse {
+	response := dto.WorkResponse{
+		Id:        work.Id,
+		Title:     work.Title,
+		Editions:  make([]dto.EditionResponse, 0, len(work.Editions)),
+		CreatedAt: work.CreatedAt,
+		UpdatedAt: work.UpdatedAt,
+	}
+
+	if work.Series != nil {
+		response.SeriesId = work.Series.Id
+		response.SeriesName = work.Series.Name
+	}
+	if work.Volume != nil {
+		response.Volume = *work.Volume
+	}
+
+	for i := range work.Editions {
+		response.Editions = append(response.Editions, toEditionResponse(&work.Editions[i]))
+	}
+
+	return response
+}
+
+func toEditionResponse(book *entities.Book) dto.EditionResponse {
+	response := dto.EditionResponse{
+		Id:        book.Id,
+		Title:     book.Title,
+		Publisher: book.Publisher,
+		Year:      book.Year,
+		Amount:    book.Amount,
+		Cost:      book.Cost,
+	}
+
+	if book.Format != nil {
+		response.Format = *book.Format
+	}
+	if book.Language != nil {
+		response.Language = *book.Language
+	}
+	if book.Pages != nil {
+		response.Pages = *book.Pages
+	}
+	if book.Isbn != nil {
+		response.Isbn13 = *book.Isbn
+	}
+
+	return response
+}
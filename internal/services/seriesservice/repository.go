@@ -0,0 +1,258 @@
+package seriesservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uniqueViolationCode = "23505"
+
+type seriesRepository struct {
+	db *gorm.DB
+}
+
+func NewSeriesRepository(db *gorm.DB) SeriesRepository {
+	return &seriesRepository{db: db}
+}
+
+func (r *seriesRepository) CreateSeries(ctx context.Context, series *entities.Series) error {
+	err := r.db.WithContext(ctx).Omit(clause.Associations).Create(series).Error
+	if isUniqueViolation(err) {
+		return ErrNameTaken
+	}
+	return err
+}
+
+// ReadAllSeries lists series by name. A query matches part of the name.
+func (r *seriesRepository) ReadAllSeries(ctx context.Context, query string, offset, limit int) ([]entities.Series, error) {
+	db := r.db.WithContext(ctx)
+
+	if query != "" {
+		db = db.Where("LOWER(name) LIKE ?", "%"+escapeLike(strings.ToLower(query))+"%")
+	}
+
+	var series []entities.Series
+	if err := db.
+		Order("name, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&series).Error; err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// ReadSeries reads a series with its works in volume order and the ids of
+// their editions.
+func (r *seriesRepository) ReadSeries(ctx context.Context, id string) (*entities.Series, error) {
+	var series entities.Series
+	if err := r.db.
+		WithContext(ctx).
+		Preload("Works", func(db *gorm.DB) *gorm.DB {
+			return db.Order("volume")
+		}).
+		Preload("Works.Editions", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "work_id").Order("created_at, id")
+		}).
+		Where("id = ?", id).
+		First(&series).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *seriesRepository) UpdateSeries(ctx context.Context, series *entities.Series) error {
+	res := r.db.
+		WithContext(ctx).
+		Model(&entities.Series{}).
+		Where("id = ?", series.Id).
+		Updates(map[string]any{
+			"name":        series.Name,
+			"description": series.Description,
+			"updated_at":  series.UpdatedAt,
+		})
+	if isUniqueViolation(res.Error) {
+		return ErrNameTaken
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrSeriesNotFound
+	}
+	return nil
+}
+
+// DeleteSeries removes a series and leaves its works standalone.
+func (r *seriesRepository) DeleteSeries(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&entities.Work{}).
+			Where("series_id = ?", id).
+			Updates(map[string]any{"series_id": nil, "volume": nil, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+
+		res := tx.
+			Where("id = ?", id).
+			Delete(&entities.Series{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrSeriesNotFound
+		}
+		return nil
+	})
+}
+
+// ReorderVolumes sets each work's volume to its index in workIds, counting
+// from 1. The unique volume constraint is deferred, so works can swap
+// numbers within the transaction.
+func (r *seriesRepository) ReorderVolumes(ctx context.Context, seriesId string, workIds []string) error {
+	now := time.Now()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, workId := range workIds {
+			if err := tx.
+				Model(&entities.Work{}).
+				Where("series_id = ? AND id = ?", seriesId, workId).
+				Updates(map[string]any{"volume": i + 1, "updated_at": now}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.
+			Model(&entities.Series{}).
+			Where("id = ?", seriesId).
+			Update("updated_at", now).Error
+	})
+}
+
+func (r *seriesRepository) CreateWork(ctx context.Context, work *entities.Work) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkSeries(tx, work.SeriesId); err != nil {
+			return err
+		}
+
+		return tx.Omit(clause.Associations).Create(work).Error
+	})
+	if isUniqueViolation(err) {
+		return ErrVolumeTaken
+	}
+	return err
+}
+
+// ReadWork reads a work with its series and editions, oldest first.
+func (r *seriesRepository) ReadWork(ctx context.Context, id string) (*entities.Work, error) {
+	var work entities.Work
+	if err := r.db.
+		WithContext(ctx).
+		Preload("Series").
+		Preload("Editions", func(db *gorm.DB) *gorm.DB {
+			return db.Omit("image_data").Order("created_at, id")
+		}).
+		Where("id = ?", id).
+		First(&work).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkNotFound
+		}
+		return nil, err
+	}
+	return &work, nil
+}
+
+func (r *seriesRepository) UpdateWork(ctx context.Context, work *entities.Work) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkSeries(tx, work.SeriesId); err != nil {
+			return err
+		}
+
+		res := tx.
+			Model(&entities.Work{}).
+			Where("id = ?", work.Id).
+			Updates(map[string]any{
+				"title":      work.Title,
+				"series_id":  work.SeriesId,
+				"volume":     work.Volume,
+				"updated_at": work.UpdatedAt,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWorkNotFound
+		}
+		return nil
+	})
+	if isUniqueViolation(err) {
+		return ErrVolumeTaken
+	}
+	return err
+}
+
+// DeleteWork removes a work without editions. Books in the trash count as
+// editions until they are purged.
+func (r *seriesRepository) DeleteWork(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var editions int64
+		if err := tx.
+			Unscoped().
+			Model(&entities.Book{}).
+			Where("work_id = ?", id).
+			Count(&editions).Error; err != nil {
+			return err
+		}
+		if editions > 0 {
+			return ErrWorkInUse
+		}
+
+		res := tx.
+			Where("id = ?", id).
+			Delete(&entities.Work{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWorkNotFound
+		}
+		return nil
+	})
+}
+
+func checkSeries(tx *gorm.DB, seriesId *string) error {
+	if seriesId == nil {
+		return nil
+	}
+
+	var series int64
+	if err := tx.
+		Model(&entities.Series{}).
+		Where("id = ?", *seriesId).
+		Count(&series).Error; err != nil {
+		return err
+	}
+	if series == 0 {
+		return ErrSeriesNotFound
+	}
+	return nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,196 @@
+package seriesservice
+
+import (
+	"context"
+	"story-book/internal/entities"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxNameLength        = 200
+	maxDescriptionLength = 10000
+)
+
+type SeriesRepository interface {
+	CreateSeries(ctx context.Context, series *entities.Series) error
+	ReadAllSeries(ctx context.Context, query string, offset, limit int) ([]entities.Series, error)
+	ReadSeries(ctx context.Context, id string) (*entities.Series, error)
+	UpdateSeries(ctx context.Context, series *entities.Series) error
+	DeleteSeries(ctx context.Context, id string) error
+	ReorderVolumes(ctx context.Context, seriesId string, workIds []string) error
+	CreateWork(ctx context.Context, work *entities.Work) error
+	ReadWork(ctx context.Context, id string) (*entities.Work, error)
+	UpdateWork(ctx context.Context, work *entities.Work) error
+	DeleteWork(ctx context.Context, id string) error
+}
+
+type seriesService struct {
+	repo SeriesRepository
+}
+
+func NewSeriesService(repo SeriesRepository) SeriesService {
+	return &seriesService{repo: repo}
+}
+
+func (s *seriesService) CreateSeries(ctx context.Context, series *entities.Series) (*entities.Series, error) {
+	if err := validateSeries(series); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	series.Id = uuid.NewString()
+	series.CreatedAt = now
+	series.UpdatedAt = now
+
+	if err := s.repo.CreateSeries(ctx, series); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (s *seriesService) ReadAllSeries(ctx context.Context, query string, page, limit int) ([]entities.Series, error) {
+	return s.repo.ReadAllSeries(ctx, strings.TrimSpace(query), (page-1)*limit, limit)
+}
+
+func (s *seriesService) ReadSeries(ctx context.Context, id string) (*entities.Series, error) {
+	return s.repo.ReadSeries(ctx, id)
+}
+
+func (s *seriesService) UpdateSeries(ctx context.Context, series *entities.Series) (*entities.Series, error) {
+	if err := validateSeries(series); err != nil {
+		return nil, err
+	}
+
+	series.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateSeries(ctx, series); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadSeries(ctx, series.Id)
+}
+
+// DeleteSeries removes a series. Its works stay in the catalogue as
+// standalone works.
+func (s *seriesService) DeleteSeries(ctx context.Context, id string) error {
+	return s.repo.DeleteSeries(ctx, id)
+}
+
+// ReorderVolumes renumbers the works of a series from 1 in the given order.
+// The order must name every work of the series once.
+func (s *seriesService) ReorderVolumes(ctx context.Context, seriesId string, workIds []string) (*entities.Series, error) {
+	series, err := s.repo.ReadSeries(ctx, seriesId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(series.Works) != len(workIds) {
+		return nil, ErrInvalidOrder
+	}
+
+	inSeries := make(map[string]bool, len(series.Works))
+	for _, work := range series.Works {
+		inSeries[work.Id] = true
+	}
+	for _, workId := range workIds {
+		if !inSeries[workId] {
+			return nil, ErrInvalidOrder
+		}
+		delete(inSeries, workId)
+	}
+
+	if err = s.repo.ReorderVolumes(ctx, seriesId, workIds); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadSeries(ctx, seriesId)
+}
+
+func (s *seriesService) CreateWork(ctx context.Context, work *entities.Work) (*entities.Work, error) {
+	if err := validateWork(work); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	work.Id = uuid.NewString()
+	work.CreatedAt = now
+	work.UpdatedAt = now
+
+	if err := s.repo.CreateWork(ctx, work); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadWork(ctx, work.Id)
+}
+
+func (s *seriesService) ReadWork(ctx context.Context, id string) (*entities.Work, error) {
+	return s.repo.ReadWork(ctx, id)
+}
+
+// UpdateWork renames a work and places it in a series, or takes it out of
+// one when series_id is empty.
+func (s *seriesService) UpdateWork(ctx context.Context, work *entities.Work) (*entities.Work, error) {
+	if err := validateWork(work); err != nil {
+		return nil, err
+	}
+
+	work.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateWork(ctx, work); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadWork(ctx, work.Id)
+}
+
+// DeleteWork removes a work no edition belongs to.
+func (s *seriesService) DeleteWork(ctx context.Context, id string) error {
+	return s.repo.DeleteWork(ctx, id)
+}
+
+func validateSeries(series *entities.Series) error {
+	series.Name = normalizeName(series.Name)
+	if series.Name == "" || utf8.RuneCountInString(series.Name) > maxNameLength {
+		return ErrInvalidName
+	}
+
+	if series.Description != nil {
+		description := strings.TrimSpace(*series.Description)
+		if utf8.RuneCountInString(description) > maxDescriptionLength {
+			return ErrDescriptionTooLong
+		}
+		series.Description = &description
+		if description == "" {
+			series.Description = nil
+		}
+	}
+
+	return nil
+}
+
+func validateWork(work *entities.Work) error {
+	work.Title = normalizeName(work.Title)
+	if work.Title == "" || utf8.RuneCountInString(work.Title) > maxNameLength {
+		return ErrInvalidTitle
+	}
+
+	if work.SeriesId != nil && *work.SeriesId == "" {
+		work.SeriesId = nil
+	}
+	if (work.SeriesId == nil) != (work.Volume == nil) || (work.Volume != nil && *work.Volume < 1) {
+		return ErrInvalidVolume
+	}
+
+	return nil
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
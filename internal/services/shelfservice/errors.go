@@ -0,0 +1,15 @@
+package shelfservice
+
+import "errors"
+
+var (
+	ErrShelfNotFound  = errors.New("shelf not found")
+	ErrBookNotFound   = errors.New("book not found")
+	ErrShelfExists    = errors.New("shelf with the same name already exists")
+	ErrBookOnShelf    = errors.New("book is already on the shelf")
+	ErrBookNotOnShelf = errors.New("book is not on the shelf")
+	ErrInvalidName    = errors.New("shelf name must be between 1 and 50 characters")
+	ErrDefaultShelf   = errors.New("the default wishlist cannot be deleted or renamed")
+	ErrTooManyShelves = errors.New("too many shelves")
+	ErrInvalidOrder   = errors.New("order must list every book on the shelf exactly once")
+)
@@ -0,0 +1,369 @@
+package shelfservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ShelfService interface {
+	ReadShelves(ctx context.Context, userId string) ([]entities.Shelf, error)
+	CreateShelf(ctx context.Context, shelf *entities.Shelf) (*entities.Shelf, error)
+	ReadShelf(ctx context.Context, userId, id string) (*entities.Shelf, []ShelfBook, error)
+	ReadPublicShelf(ctx context.Context, slug string) (*entities.Shelf, []ShelfBook, error)
+	UpdateShelf(ctx context.Context, userId string, shelf *entities.Shelf) (*entities.Shelf, error)
+	DeleteShelf(ctx context.Context, userId, id string) error
+	AddBook(ctx context.Context, userId, id, bookId string) error
+	RemoveBook(ctx context.Context, userId, id, bookId string) error
+	ReorderBooks(ctx context.Context, userId, id string, bookIds []string) error
+}
+
+type ShelfHandler struct {
+	service ShelfService
+}
+
+func NewShelfHandler(service ShelfService) *ShelfHandler {
+	return &ShelfHandler{service: service}
+}
+
+// ReadShelves
+// @Summary Получить свои списки книг
+// @Tags shelves
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.ShelfResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shelves [get]
+func (h *ShelfHandler) ReadShelves(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	shelves, err := h.service.ReadShelves(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.ShelfResponse, 0, len(shelves))
+	for i := range shelves {
+		response = append(response, toShelfResponse(&shelves[i], nil))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// CreateShelf
+// @Summary Создать список книг
+// @Tags shelves
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ShelfRequest true "Название и видимость списка"
+// @Success 201 {object} dto.ShelfResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shelves [post]
+func (h *ShelfHandler) CreateShelf(c echo.Context) error {
+	var request dto.ShelfRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	shelf, err := h.service.CreateShelf(ctx, &entities.Shelf{
+		UserId: userId,
+		Name:   request.Name,
+		Public: request.Public,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrShelfExists):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidName), errors.Is(err, ErrTooManyShelves):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toShelfResponse(shelf, nil))
+}
+
+// ReadShelf
+// @Summary Получить свой список книг
+// @Tags shelves
+// @Security BearerAuth
+// @Param id path string true "ID списка"
+// @Produce json
+// @Success 200 {object} dto.ShelfResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shelves/{id} [get]
+func (h *ShelfHandler) ReadShelf(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	shelf, books, err := h.service.ReadShelf(ctx, userId, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, ErrShelfNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toShelfResponse(shelf, books))
+}
+
+// ReadPublicShelf
+// @Summary Получить публичный список книг
+// @Tags shelves
+// @Param slug path string true "Публичная ссылка списка"
+// @Produce json
+// @Success 200 {object} dto.ShelfResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shelves/shared/{slug} [get]
+func (h *ShelfHandler) ReadPublicShelf(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	shelf, books, err := h.service.ReadPublicShelf(ctx, c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, ErrShelfNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := toShelfResponse(shelf, books)
+	response.Id = ""
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// UpdateShelf
+// @Summary Изменить список книг
+// @Tags shelves
+// @Security BearerAuth
+// @Param id path string true "ID списка"
+// @Accept json
+// @Produce json
+// @Param request body dto.ShelfRequest true "Название и видимость списка"
+// @Success 200 {object} dto.ShelfResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shelves/{id} [put]
+func (h *ShelfHandler) UpdateShelf(c echo.Context) error {
+	var request dto.ShelfRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	shelf, err := h.service.UpdateShelf(ctx, userId, &entities.Shelf{
+		Id:     c.Param("id"),
+		Name:   request.Name,
+		Public: request.Public,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrShelfNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrShelfExists):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidName), errors.Is(err, ErrDefaultShelf):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toShelfResponse(shelf, nil))
+}
+
+// DeleteShelf
+// @Summary Удалить список книг
+// @Tags shelves
+// @Security BearerAuth
+// @Param id path string true "ID списка"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shelves/{id} [delete]
+func (h *ShelfHandler) DeleteShelf(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.DeleteShelf(ctx, userId, c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrShelfNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrDefaultShelf):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AddBook
+// @Summary Добавить книгу в список
+// @Tags shelves
+// @Security BearerAuth
+// @Param id path string true "ID списка"
+// @Accept json
+// @Param request body dto.ShelfBookRequest true "ID книги"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shelves/{id}/books [post]
+func (h *ShelfHandler) AddBook(c echo.Context) error {
+	var request dto.ShelfBookRequest
+	if err := c.Bind(&request); err != nil || request.BookId == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.AddBook(ctx, userId, c.Param("id"), request.BookId)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrShelfNotFound), errors.Is(err, ErrBookNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrBookOnShelf):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveBook
+// @Summary Убрать книгу из списка
+// @Tags shelves
+// @Security BearerAuth
+// @Param id path string true "ID списка"
+// @Param bookId path string true "ID книги"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shelves/{id}/books/{bookId} [delete]
+func (h *ShelfHandler) RemoveBook(c echo.Context) error {
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.RemoveBook(ctx, userId, c.Param("id"), c.Param("bookId"))
+	if err != nil {
+		if errors.Is(err, ErrShelfNotFound) || errors.Is(err, ErrBookNotOnShelf) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReorderBooks
+// @Summary Изменить порядок книг в списке
+// @Tags shelves
+// @Security BearerAuth
+// @Param id path string true "ID списка"
+// @Accept json
+// @Param request body dto.ShelfOrderRequest true "Все ID книг списка в новом порядке"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /shelves/{id}/books/order [put]
+func (h *ShelfHandler) ReorderBooks(c echo.Context) error {
+	var request dto.ShelfOrderRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	userId := c.Get("id").(string)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.ReorderBooks(ctx, userId, c.Param("id"), request.BookIds)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrShelfNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidOrder):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toShelfResponse(shelf *entities.Shelf, books []ShelfBook) dto.ShelfResponse {
+	response := dto.ShelfResponse{
+		Id:        shelf.Id,
+		Name:      shelf.Name,
+		IsDefault: shelf.IsDefault,
+		Public:    shelf.Public,
+		CreatedAt: shelf.CreatedAt,
+		UpdatedAt: shelf.UpdatedAt,
+	}
+
+	if shelf.Public && shelf.Slug != nil {
+		response.Slug = *shelf.Slug
+	}
+
+	if books != nil {
+		response.Books = make([]dto.ShelfBookResponse, 0, len(books))
+		for _, book := range books {
+			response.Books = append(response.Books, dto.ShelfBookResponse{
+				BookId:    book.BookId,
+				Title:     book.Title,
+				Author:    book.Author,
+				Available: book.Available,
+				AddedAt:   book.AddedAt,
+			})
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,229 @@
+package shelfservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uniqueViolationCode = "23505"
+
+type shelfRepository struct {
+	db *gorm.DB
+}
+
+func NewShelfRepository(db *gorm.DB) ShelfRepository {
+	return &shelfRepository{db: db}
+}
+
+func (r *shelfRepository) Create(ctx context.Context, shelf *entities.Shelf) error {
+	if err := r.db.WithContext(ctx).Create(shelf).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrShelfExists
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateDefault inserts the default wishlist unless the user already has one.
+func (r *shelfRepository) CreateDefault(ctx context.Context, shelf *entities.Shelf) error {
+	return r.db.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(shelf).Error
+}
+
+func (r *shelfRepository) ReadByUser(ctx context.Context, userId string) ([]entities.Shelf, error) {
+	var shelves []entities.Shelf
+	if err := r.db.
+		WithContext(ctx).
+		Where("user_id = ?", userId).
+		Order("is_default DESC, created_at").
+		Find(&shelves).Error; err != nil {
+		return nil, err
+	}
+	return shelves, nil
+}
+
+func (r *shelfRepository) ReadById(ctx context.Context, id string) (*entities.Shelf, error) {
+	return r.readOne(ctx, "id = ?", id)
+}
+
+func (r *shelfRepository) ReadBySlug(ctx context.Context, slug string) (*entities.Shelf, error) {
+	return r.readOne(ctx, "slug = ? AND public = true", slug)
+}
+
+func (r *shelfRepository) CountByUser(ctx context.Context, userId string) (int64, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Shelf{}).
+		Where("user_id = ?", userId).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *shelfRepository) Update(ctx context.Context, shelf *entities.Shelf) error {
+	res := r.db.
+		WithContext(ctx).
+		Model(&entities.Shelf{}).
+		Where("id = ?", shelf.Id).
+		Select("name", "public", "slug", "updated_at").
+		Updates(shelf)
+
+	if res.Error != nil {
+		if isUniqueViolation(res.Error) {
+			return ErrShelfExists
+		}
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrShelfNotFound
+	}
+
+	return nil
+}
+
+func (r *shelfRepository) Delete(ctx context.Context, id string) error {
+	res := r.db.
+		WithContext(ctx).
+		Delete(&entities.Shelf{Id: id})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrShelfNotFound
+	}
+
+	return nil
+}
+
+// ReadBooks lists the books on a shelf in order. Soft-deleted books are kept
+// and reported as unavailable.
+func (r *shelfRepository) ReadBooks(ctx context.Context, shelfId string) ([]ShelfBook, error) {
+	var books []ShelfBook
+	if err := r.db.
+		WithContext(ctx).
+		Table("shelf_items").
+		Select("shelf_items.book_id, books.title, books.author, books.deleted_at IS NULL AS available, shelf_items.added_at").
+		Joins("JOIN books ON books.id = shelf_items.book_id").
+		Where("shelf_items.shelf_id = ?", shelfId).
+		Order("shelf_items.position, shelf_items.added_at").
+		Scan(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (r *shelfRepository) BookExists(ctx context.Context, bookId string) (bool, error) {
+	var count int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.Book{}).
+		Where("id = ?", bookId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// AddBook appends the book to the end of the shelf.
+func (r *shelfRepository) AddBook(ctx context.Context, shelfId, bookId string) error {
+	err := r.db.WithContext(ctx).Exec(`
+		INSERT INTO shelf_items (shelf_id, book_id, position, added_at)
+		SELECT @shelf, @book, COALESCE(MAX(position), 0) + 1, @now
+		FROM shelf_items WHERE shelf_id = @shelf`,
+		map[string]any{"shelf": shelfId, "book": bookId, "now": time.Now()},
+	).Error
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrBookOnShelf
+		}
+		return err
+	}
+
+	return r.touch(r.db.WithContext(ctx), shelfId)
+}
+
+func (r *shelfRepository) RemoveBook(ctx context.Context, shelfId, bookId string) error {
+	res := r.db.
+		WithContext(ctx).
+		Where("shelf_id = ? AND book_id = ?", shelfId, bookId).
+		Delete(&entities.ShelfItem{})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrBookNotOnShelf
+	}
+
+	return r.touch(r.db.WithContext(ctx), shelfId)
+}
+
+func (r *shelfRepository) ReadBookIds(ctx context.Context, shelfId string) ([]string, error) {
+	var ids []string
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.ShelfItem{}).
+		Where("shelf_id = ?", shelfId).
+		Pluck("book_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Reorder sets each book's position to its index in bookIds.
+func (r *shelfRepository) Reorder(ctx context.Context, shelfId string, bookIds []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, bookId := range bookIds {
+			if err := tx.
+				Model(&entities.ShelfItem{}).
+				Where("shelf_id = ? AND book_id = ?", shelfId, bookId).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return r.touch(tx, shelfId)
+	})
+}
+
+func (r *shelfRepository) touch(db *gorm.DB, shelfId string) error {
+	return db.
+		Model(&entities.Shelf{}).
+		Where("id = ?", shelfId).
+		Update("updated_at", time.Now()).Error
+}
+
+func (r *shelfRepository) readOne(ctx context.Context, query string, args ...any) (*entities.Shelf, error) {
+	var shelf entities.Shelf
+	if err := r.db.
+		WithContext(ctx).
+		Where(query, args...).
+		First(&shelf).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShelfNotFound
+		}
+		return nil, err
+	}
+	return &shelf, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,265 @@
+package shelfservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"story-book/internal/entities"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultShelfName = "wishlist"
+	maxShelves       = 50
+	maxNameLength    = 50
+)
+
+type ShelfRepository interface {
+	Create(ctx context.Context, shelf *entities.Shelf) error
+	CreateDefault(ctx context.Context, shelf *entities.Shelf) error
+	ReadByUser(ctx context.Context, userId string) ([]entities.Shelf, error)
+	ReadById(ctx context.Context, id string) (*entities.Shelf, error)
+	ReadBySlug(ctx context.Context, slug string) (*entities.Shelf, error)
+	CountByUser(ctx context.Context, userId string) (int64, error)
+	Update(ctx context.Context, shelf *entities.Shelf) error
+	Delete(ctx context.Context, id string) error
+	ReadBooks(ctx context.Context, shelfId string) ([]ShelfBook, error)
+	BookExists(ctx context.Context, bookId string) (bool, error)
+	AddBook(ctx context.Context, shelfId, bookId string) error
+	RemoveBook(ctx context.Context, shelfId, bookId string) error
+	ReadBookIds(ctx context.Context, shelfId string) ([]string, error)
+	Reorder(ctx context.Context, shelfId string, bookIds []string) error
+}
+
+// ShelfBook is a book as it appears on a shelf.
+type ShelfBook struct {
+	BookId    string
+	Title     string
+	Author    string
+	Available bool
+	AddedAt   time.Time
+}
+
+type shelfService struct {
+	repo ShelfRepository
+}
+
+func NewShelfService(repo ShelfRepository) ShelfService {
+	return &shelfService{repo: repo}
+}
+
+// ReadShelves returns the user's shelves, creating the default wishlist on
+// first use.
+func (s *shelfService) ReadShelves(ctx context.Context, userId string) ([]entities.Shelf, error) {
+	now := time.Now()
+
+	err := s.repo.CreateDefault(ctx, &entities.Shelf{
+		Id:        uuid.NewString(),
+		UserId:    userId,
+		Name:      defaultShelfName,
+		IsDefault: true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReadByUser(ctx, userId)
+}
+
+func (s *shelfService) CreateShelf(ctx context.Context, shelf *entities.Shelf) (*entities.Shelf, error) {
+	name, err := validateName(shelf.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByUser(ctx, shelf.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxShelves {
+		return nil, ErrTooManyShelves
+	}
+
+	now := time.Now()
+
+	shelf.Id = uuid.NewString()
+	shelf.Name = name
+	shelf.IsDefault = false
+	shelf.CreatedAt = now
+	shelf.UpdatedAt = now
+
+	if shelf.Public {
+		shelf.Slug = newSlug()
+	}
+
+	if err = s.repo.Create(ctx, shelf); err != nil {
+		return nil, err
+	}
+
+	return shelf, nil
+}
+
+func (s *shelfService) ReadShelf(ctx context.Context, userId, id string) (*entities.Shelf, []ShelfBook, error) {
+	shelf, err := s.owned(ctx, userId, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	books, err := s.repo.ReadBooks(ctx, shelf.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return shelf, books, nil
+}
+
+func (s *shelfService) ReadPublicShelf(ctx context.Context, slug string) (*entities.Shelf, []ShelfBook, error) {
+	shelf, err := s.repo.ReadBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	books, err := s.repo.ReadBooks(ctx, shelf.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return shelf, books, nil
+}
+
+// UpdateShelf renames a shelf and toggles its visibility. A shelf keeps its
+// slug once it has been shared, so links stay valid if it is made public again.
+func (s *shelfService) UpdateShelf(ctx context.Context, userId string, shelf *entities.Shelf) (*entities.Shelf, error) {
+	name, err := validateName(shelf.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.owned(ctx, userId, shelf.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.IsDefault && name != current.Name {
+		return nil, ErrDefaultShelf
+	}
+
+	current.Name = name
+	current.Public = shelf.Public
+	current.UpdatedAt = time.Now()
+
+	if current.Public && current.Slug == nil {
+		current.Slug = newSlug()
+	}
+
+	if err = s.repo.Update(ctx, current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *shelfService) DeleteShelf(ctx context.Context, userId, id string) error {
+	shelf, err := s.owned(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	if shelf.IsDefault {
+		return ErrDefaultShelf
+	}
+
+	return s.repo.Delete(ctx, shelf.Id)
+}
+
+func (s *shelfService) AddBook(ctx context.Context, userId, id, bookId string) error {
+	shelf, err := s.owned(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.repo.BookExists(ctx, bookId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrBookNotFound
+	}
+
+	return s.repo.AddBook(ctx, shelf.Id, bookId)
+}
+
+func (s *shelfService) RemoveBook(ctx context.Context, userId, id, bookId string) error {
+	shelf, err := s.owned(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.RemoveBook(ctx, shelf.Id, bookId)
+}
+
+// ReorderBooks takes the complete list of books on the shelf in their new
+// order.
+func (s *shelfService) ReorderBooks(ctx context.Context, userId, id string, bookIds []string) error {
+	shelf, err := s.owned(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.repo.ReadBookIds(ctx, shelf.Id)
+	if err != nil {
+		return err
+	}
+
+	if len(current) != len(bookIds) {
+		return ErrInvalidOrder
+	}
+
+	onShelf := make(map[string]bool, len(current))
+	for _, bookId := range current {
+		onShelf[bookId] = true
+	}
+	for _, bookId := range bookIds {
+		if !onShelf[bookId] {
+			return ErrInvalidOrder
+		}
+		delete(onShelf, bookId)
+	}
+
+	return s.repo.Reorder(ctx, shelf.Id, bookIds)
+}
+
+// owned reads a shelf and hides other users' shelves behind not found.
+func (s *shelfService) owned(ctx context.Context, userId, id string) (*entities.Shelf, error) {
+	shelf, err := s.repo.ReadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if shelf.UserId != userId {
+		return nil, ErrShelfNotFound
+	}
+
+	return shelf, nil
+}
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
+
+func newSlug() *string {
+	b := make([]byte, 10)
+	_, _ = rand.Read(b)
+	slug := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+	return &slug
+}
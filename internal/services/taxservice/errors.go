@@ -0,0 +1,14 @@
+package taxservice
+
+import "errors"
+
+var (
+	ErrRulesFile       = errors.New("invalid tax rules file")
+	ErrNoRulesFile     = errors.New("no tax rules file configured")
+	ErrInvalidRegion   = errors.New("region must be an ISO 3166 code such as RU or RU-MOW")
+	ErrInvalidCategory = errors.New("category must be one of book, ebook, other")
+	ErrInvalidRate     = errors.New("rate must be a percentage between 0 and 100 with at most two decimals")
+	ErrInvalidPeriod   = errors.New("effective_to must be after effective_from")
+	ErrOverlap         = errors.New("tax rules of the same region and category overlap")
+	ErrAccessDenied    = errors.New("access denied")
+)
@@ -0,0 +1,101 @@
+package taxservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TaxService interface {
+	LoadRules(ctx context.Context) (int, error)
+	ReadRules(ctx context.Context, region string) ([]entities.TaxRule, error)
+	PriceBooks(ctx context.Context, books []entities.Book, currency, userId string) (map[string]pricing.Breakdown, error)
+	Tax(ctx context.Context, category string, price pricing.Money, at time.Time) (*pricing.Tax, error)
+	TaxIncluded(ctx context.Context, region, category string, gross pricing.Money, at time.Time) (*pricing.Tax, error)
+}
+
+type TaxHandler struct {
+	service TaxService
+}
+
+func NewTaxHandler(service TaxService) *TaxHandler {
+	return &TaxHandler{service: service}
+}
+
+// ReadRules
+// @Summary Получить налоговые правила
+// @Tags taxes
+// @Param region query string false "Регион, например RU или RU-MOW"
+// @Produce json
+// @Success 200 {array} dto.TaxRuleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tax/rules [get]
+func (h *TaxHandler) ReadRules(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rules, err := h.service.ReadRules(ctx, c.QueryParam("region"))
+	if err != nil {
+		if errors.Is(err, ErrInvalidRegion) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	response := make([]dto.TaxRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, dto.TaxRuleResponse{
+			Id:            rule.Id,
+			Region:        rule.Region,
+			Category:      rule.Category,
+			Rate:          rule.Rate,
+			Inclusive:     rule.Inclusive,
+			EffectiveFrom: rule.EffectiveFrom,
+			EffectiveTo:   rule.EffectiveTo,
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReloadRules
+// @Summary Перечитать налоговые правила из YAML-файла
+// @Description Файл задаётся переменной TAX_RULES_FILE и заменяет все правила целиком
+// @Tags taxes
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.TaxReloadResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/tax-rules/reload [post]
+func (h *TaxHandler) ReloadRules(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: ErrAccessDenied.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	loaded, err := h.service.LoadRules(ctx)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRulesFile), errors.Is(err, ErrNoRulesFile), errors.Is(err, ErrInvalidRegion),
+			errors.Is(err, ErrInvalidCategory), errors.Is(err, ErrInvalidRate), errors.Is(err, ErrInvalidPeriod),
+			errors.Is(err, ErrOverlap):
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, dto.TaxReloadResponse{Loaded: loaded})
+}
@@ -0,0 +1,62 @@
+package taxservice
+
+import (
+	"context"
+	"story-book/internal/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type taxRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRepository(db *gorm.DB) TaxRepository {
+	return &taxRepository{db: db}
+}
+
+// ReplaceRules swaps the whole rule set in one transaction: the file is the
+// source of truth, so rules missing from it are dropped.
+func (r *taxRepository) ReplaceRules(ctx context.Context, rules []entities.TaxRule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&entities.TaxRule{}).Error; err != nil {
+			return err
+		}
+
+		if len(rules) == 0 {
+			return nil
+		}
+
+		return tx.Create(&rules).Error
+	})
+}
+
+func (r *taxRepository) ReadRules(ctx context.Context, region string) ([]entities.TaxRule, error) {
+	query := r.db.WithContext(ctx)
+
+	if region != "" {
+		query = query.Where("region = ?", region)
+	}
+
+	var rules []entities.TaxRule
+	if err := query.
+		Order("region, category, effective_from").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ReadEffective returns the rules of a region in force at the given moment,
+// at most one per category.
+func (r *taxRepository) ReadEffective(ctx context.Context, region string, at time.Time) ([]entities.TaxRule, error) {
+	var rules []entities.TaxRule
+	if err := r.db.
+		WithContext(ctx).
+		Where("region = ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", region, at, at).
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
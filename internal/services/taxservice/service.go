@@ -0,0 +1,264 @@
+package taxservice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"story-book/internal/entities"
+	"story-book/internal/pricing"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.yaml.in/yaml/v3"
+)
+
+const dateLayout = "2006-01-02"
+
+var regionRegex = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+
+type TaxRepository interface {
+	ReplaceRules(ctx context.Context, rules []entities.TaxRule) error
+	ReadRules(ctx context.Context, region string) ([]entities.TaxRule, error)
+	ReadEffective(ctx context.Context, region string, at time.Time) ([]entities.TaxRule, error)
+}
+
+// Pricer is the pricing step that runs before tax: promotions and currency
+// conversion.
+type Pricer interface {
+	PriceBooks(ctx context.Context, books []entities.Book, currency, userId string) (map[string]pricing.Breakdown, error)
+}
+
+type taxService struct {
+	repo      TaxRepository
+	pricer    Pricer
+	region    string
+	rounding  pricing.Rounding
+	rulesFile string
+}
+
+// NewTaxService taxes prices by the rules of region, the store's own, which
+// are read from rulesFile.
+func NewTaxService(repo TaxRepository, pricer Pricer, region string, rounding pricing.Rounding, rulesFile string) TaxService {
+	return &taxService{repo: repo, pricer: pricer, region: region, rounding: rounding, rulesFile: rulesFile}
+}
+
+// LoadRules replaces the tax rules with those of the configured YAML file:
+//
+//	rules:
+//	  - region: RU
+//	    category: book
+//	    rate: 10
+//	    inclusive: true
+//	    from: 2019-01-01
+//	    to: 2027-01-01   # optional, exclusive
+//
+// The file is validated as a whole; on any error nothing is changed.
+func (s *taxService) LoadRules(ctx context.Context) (int, error) {
+	if s.rulesFile == "" {
+		return 0, ErrNoRulesFile
+	}
+
+	data, err := os.ReadFile(s.rulesFile)
+	if err != nil {
+		return 0, err
+	}
+
+	var file struct {
+		Rules []struct {
+			Region    string `yaml:"region"`
+			Category  string `yaml:"category"`
+			Rate      string `yaml:"rate"`
+			Inclusive bool   `yaml:"inclusive"`
+			From      string `yaml:"from"`
+			To        string `yaml:"to"`
+		} `yaml:"rules"`
+	}
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrRulesFile, err)
+	}
+
+	rules := make([]entities.TaxRule, 0, len(file.Rules))
+	for i, raw := range file.Rules {
+		rule := entities.TaxRule{
+			Id:        uuid.NewString(),
+			Region:    strings.ToUpper(strings.TrimSpace(raw.Region)),
+			Category:  strings.TrimSpace(raw.Category),
+			Inclusive: raw.Inclusive,
+		}
+
+		if !regionRegex.MatchString(rule.Region) {
+			return 0, fmt.Errorf("rule %d: %w", i+1, ErrInvalidRegion)
+		}
+
+		if !pricing.IsTaxCategory(rule.Category) {
+			return 0, fmt.Errorf("rule %d: %w", i+1, ErrInvalidCategory)
+		}
+
+		// Percent has two decimals, exactly like Money.
+		rate, err := pricing.Parse(raw.Rate)
+		if err != nil || rate < 0 || rate > 100*100 {
+			return 0, fmt.Errorf("rule %d: %w", i+1, ErrInvalidRate)
+		}
+		rule.Rate = rate.Float()
+
+		rule.EffectiveFrom, err = time.Parse(dateLayout, strings.TrimSpace(raw.From))
+		if err != nil {
+			return 0, fmt.Errorf("rule %d: %w", i+1, ErrInvalidPeriod)
+		}
+
+		if to := strings.TrimSpace(raw.To); to != "" {
+			effectiveTo, err := time.Parse(dateLayout, to)
+			if err != nil || !effectiveTo.After(rule.EffectiveFrom) {
+				return 0, fmt.Errorf("rule %d: %w", i+1, ErrInvalidPeriod)
+			}
+			rule.EffectiveTo = &effectiveTo
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err = checkOverlaps(rules); err != nil {
+		return 0, err
+	}
+
+	if err = s.repo.ReplaceRules(ctx, rules); err != nil {
+		return 0, err
+	}
+
+	return len(rules), nil
+}
+
+func (s *taxService) ReadRules(ctx context.Context, region string) ([]entities.TaxRule, error) {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if region != "" && !regionRegex.MatchString(region) {
+		return nil, ErrInvalidRegion
+	}
+
+	return s.repo.ReadRules(ctx, region)
+}
+
+// PriceBooks runs the rest of the pricing pipeline and adds the tax of each
+// book's category. Books whose category has no rule in force are left
+// untaxed.
+func (s *taxService) PriceBooks(ctx context.Context, books []entities.Book, currency, userId string) (map[string]pricing.Breakdown, error) {
+	prices, err := s.pricer.PriceBooks(ctx, books, currency, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.effective(ctx, s.region, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, book := range books {
+		rule, ok := rules[book.TaxCategory]
+		if !ok {
+			continue
+		}
+
+		price := prices[book.Id]
+		tax := s.apply(rule, price.FinalPrice)
+		price.Tax = &tax
+		prices[book.Id] = price
+	}
+
+	return prices, nil
+}
+
+// Tax taxes a price of a category by the rule in force at a given moment, or
+// returns nil if there is none.
+func (s *taxService) Tax(ctx context.Context, category string, price pricing.Money, at time.Time) (*pricing.Tax, error) {
+	rules, err := s.effective(ctx, s.region, at)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, ok := rules[category]
+	if !ok {
+		return nil, nil
+	}
+
+	tax := s.apply(rule, price)
+	return &tax, nil
+}
+
+// TaxIncluded returns the tax contained in an amount already paid for a
+// category delivered to region at a given moment, or nil if no rule was in
+// force. Paid amounts are gross whatever the rule says about catalogue
+// prices.
+func (s *taxService) TaxIncluded(ctx context.Context, region, category string, gross pricing.Money, at time.Time) (*pricing.Tax, error) {
+	rules, err := s.effective(ctx, s.regionOf(region), at)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, ok := rules[category]
+	if !ok {
+		return nil, nil
+	}
+
+	rule.Inclusive = true
+	tax := s.apply(rule, gross)
+	return &tax, nil
+}
+
+// regionOf returns the region whose rules apply to goods delivered to
+// country: the store's own when there is no country or the store is in
+// it, so that the rules of a subdivision such as RU-MOW still apply at
+// home.
+func (s *taxService) regionOf(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" || country == s.region || strings.HasPrefix(s.region, country+"-") {
+		return s.region
+	}
+	return country
+}
+
+func (s *taxService) effective(ctx context.Context, region string, at time.Time) (map[string]entities.TaxRule, error) {
+	rules, err := s.repo.ReadEffective(ctx, region, at)
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[string]entities.TaxRule, len(rules))
+	for _, rule := range rules {
+		byCategory[rule.Category] = rule
+	}
+	return byCategory, nil
+}
+
+func (s *taxService) apply(rule entities.TaxRule, amount pricing.Money) pricing.Tax {
+	tax := pricing.ApplyTax(amount, pricing.PercentFromFloat(rule.Rate), rule.Inclusive, s.rounding)
+	tax.Region = rule.Region
+	tax.Category = rule.Category
+	return tax
+}
+
+// checkOverlaps rejects rule sets where two rules of the same region and
+// category are in force on the same day.
+func checkOverlaps(rules []entities.TaxRule) error {
+	groups := make(map[string][]entities.TaxRule)
+	for _, rule := range rules {
+		key := rule.Region + "/" + rule.Category
+		groups[key] = append(groups[key], rule)
+	}
+
+	for key, group := range groups {
+		slices.SortFunc(group, func(a, b entities.TaxRule) int {
+			return a.EffectiveFrom.Compare(b.EffectiveFrom)
+		})
+
+		for i := 1; i < len(group); i++ {
+			previous := group[i-1]
+			if previous.EffectiveTo == nil || previous.EffectiveTo.After(group[i].EffectiveFrom) {
+				return fmt.Errorf("%s from %s: %w", key, group[i].EffectiveFrom.Format(dateLayout), ErrOverlap)
+			}
+		}
+	}
+
+	return nil
+}
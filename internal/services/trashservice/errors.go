@@ -0,0 +1,13 @@
+package trashservice
+
+import "errors"
+
+var (
+	ErrBookNotFound   = errors.New("book not found")
+	ErrBookReferenced = errors.New("book is referenced by orders, returns or e-book purchases and cannot be deleted")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrUserReferenced = errors.New("user has bought e-books and cannot be deleted")
+	ErrUserConflict   = errors.New("active user with the same email or phone already exists")
+	ErrInvalidPage    = errors.New("invalid page")
+	ErrInvalidLimit   = errors.New("invalid limit")
+)
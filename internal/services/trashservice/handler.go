@@ -0,0 +1,305 @@
+package trashservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"story-book/internal/dto"
+	"story-book/internal/entities"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TrashService interface {
+	ReadDeletedBooks(ctx context.Context, page, limit int) ([]entities.Book, error)
+	ReadDeletedUsers(ctx context.Context, page, limit int) ([]entities.User, error)
+	RestoreBook(ctx context.Context, id string) error
+	RestoreUser(ctx context.Context, id string) error
+	DeleteBook(ctx context.Context, id string) error
+	DeleteUser(ctx context.Context, id string) error
+	Purge(ctx context.Context) (int64, int64, error)
+}
+
+type TrashHandler struct {
+	service TrashService
+}
+
+func NewTrashHandler(service TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+// ReadDeletedBooks
+// @Summary Получить удалённые книги
+// @Tags trash
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Success 200 {array} dto.DeletedBookResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /trash/books [get]
+func (h *TrashHandler) ReadDeletedBooks(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	page, limit, err := pagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	response, err := h.service.ReadDeletedBooks(ctx, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	books := make([]dto.DeletedBookResponse, 0, len(response))
+	for _, book := range response {
+		books = append(books, dto.DeletedBookResponse{
+			Id:        book.Id,
+			Title:     book.Title,
+			Author:    book.Author,
+			Publisher: book.Publisher,
+			Year:      book.Year,
+			DeletedAt: book.DeletedAt.Time,
+		})
+	}
+
+	return c.JSON(http.StatusOK, books)
+}
+
+// ReadDeletedUsers
+// @Summary Получить удалённых пользователей
+// @Tags trash
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Номер страницы (по умолчанию 1)"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Success 200 {array} dto.DeletedUserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /trash/users [get]
+func (h *TrashHandler) ReadDeletedUsers(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	page, limit, err := pagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	response, err := h.service.ReadDeletedUsers(ctx, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	users := make([]dto.DeletedUserResponse, 0, len(response))
+	for _, user := range response {
+		users = append(users, dto.DeletedUserResponse{
+			Id:        user.Id,
+			Name:      user.Name,
+			Surname:   user.Surname,
+			Email:     user.Email,
+			Phone:     user.Phone,
+			Role:      user.Role,
+			DeletedAt: user.DeletedAt.Time,
+		})
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+// RestoreBook
+// @Summary Восстановить удалённую книгу
+// @Tags trash
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /trash/books/{id}/restore [post]
+func (h *TrashHandler) RestoreBook(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.RestoreBook(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrBookNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RestoreUser
+// @Summary Восстановить удалённого пользователя
+// @Tags trash
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /trash/users/{id}/restore [post]
+func (h *TrashHandler) RestoreUser(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.RestoreUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		}
+		if errors.Is(err, ErrUserConflict) {
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteBook
+// @Summary Удалить книгу навсегда
+// @Tags trash
+// @Security BearerAuth
+// @Param id path string true "ID книги"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /trash/books/{id} [delete]
+func (h *TrashHandler) DeleteBook(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.DeleteBook(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrBookReferenced):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteUser
+// @Summary Удалить пользователя навсегда
+// @Tags trash
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /trash/users/{id} [delete]
+func (h *TrashHandler) DeleteUser(c echo.Context) error {
+	role := c.Get("role").(string)
+	if role == "client" {
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "access denied"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.service.DeleteUser(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrUserReferenced):
+			return c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func pagination(c echo.Context) (int, int, error) {
+	page := 1
+	limit := 10
+
+	var err error
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return 0, 0, ErrInvalidPage
+		}
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return 0, 0, ErrInvalidLimit
+		}
+	}
+
+	return page, limit, nil
+}
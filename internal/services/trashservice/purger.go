@@ -0,0 +1,39 @@
+package trashservice
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunPurger permanently removes soft-deleted books and users that have been
+// in the trash longer than the configured retention. It blocks until ctx is done.
+func RunPurger(ctx context.Context, service TrashService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purge(ctx, service)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purge(ctx context.Context, service TrashService) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	books, users, err := service.Purge(ctx)
+	if err != nil {
+		log.Printf("trash purge failed: %v", err)
+		return
+	}
+
+	if books > 0 || users > 0 {
+		log.Printf("trash purge removed %d books and %d users", books, users)
+	}
+}
@@ -0,0 +1,237 @@
+package trashservice
+
+import (
+	"context"
+	"errors"
+	"story-book/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+)
+
+type trashRepository struct {
+	db *gorm.DB
+}
+
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+func (r *trashRepository) ReadDeletedBooks(ctx context.Context, offset, limit int) ([]entities.Book, error) {
+	var books []entities.Book
+	if err := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Omit("image_data").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (r *trashRepository) ReadDeletedUsers(ctx context.Context, offset, limit int) ([]entities.User, error) {
+	var users []entities.User
+	if err := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *trashRepository) RestoreBook(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var book entities.Book
+		if err := tx.
+			Unscoped().
+			Select("id", "deleted_at").
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			First(&book).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookNotFound
+			}
+			return err
+		}
+
+		if err := tx.
+			Unscoped().
+			Model(&entities.GenreOfBook{}).
+			Where("book_id = ? AND deleted_at = ?", id, book.DeletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.
+			Unscoped().
+			Model(&entities.Book{}).
+			Where("id = ?", id).
+			Update("deleted_at", nil).Error
+	})
+}
+
+func (r *trashRepository) RestoreUser(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user entities.User
+		if err := tx.
+			Unscoped().
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		var conflicts int64
+		if err := tx.
+			Model(&entities.User{}).
+			Where("id <> ? AND (email = ? OR phone = ?)", id, user.Email, user.Phone).
+			Count(&conflicts).Error; err != nil {
+			return err
+		}
+		if conflicts > 0 {
+			return ErrUserConflict
+		}
+
+		err := tx.
+			Unscoped().
+			Model(&entities.User{}).
+			Where("id = ?", id).
+			Update("deleted_at", nil).Error
+		if isUniqueViolation(err) {
+			return ErrUserConflict
+		}
+		return err
+	})
+}
+
+// DeleteBook deletes a trashed book for good. Order lines would only lose
+// their link to it, so they are checked here rather than by the database.
+func (r *trashRepository) DeleteBook(ctx context.Context, id string) error {
+	var ordered int64
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entities.OrderItem{}).
+		Where("book_id = ?", id).
+		Count(&ordered).Error; err != nil {
+		return err
+	}
+	if ordered > 0 {
+		return ErrBookReferenced
+	}
+
+	res := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Delete(&entities.Book{})
+
+	if res.Error != nil {
+		if isForeignKeyViolation(res.Error) {
+			return ErrBookReferenced
+		}
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrBookNotFound
+	}
+
+	return nil
+}
+
+func (r *trashRepository) DeleteUser(ctx context.Context, id string) error {
+	res := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Delete(&entities.User{})
+
+	if res.Error != nil {
+		if isForeignKeyViolation(res.Error) {
+			return ErrUserReferenced
+		}
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// PurgeBooks deletes books trashed before a moment for good. Books that
+// orders, returns or e-book purchases still refer to are kept in the trash,
+// so that sales history survives and one of them cannot make the whole
+// purge fail.
+func (r *trashRepository) PurgeBooks(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Unscoped().
+			Where("book_id IN (?)", purgeableBooks(tx, before)).
+			Delete(&entities.GenreOfBook{}).Error; err != nil {
+			return err
+		}
+
+		res := tx.
+			Unscoped().
+			Where("id IN (?)", purgeableBooks(tx, before)).
+			Delete(&entities.Book{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, err
+}
+
+// PurgeUsers deletes users trashed before a moment for good. Users who
+// bought e-books are kept in the trash: their downloads are what a leaked
+// copy is traced back to.
+func (r *trashRepository) PurgeUsers(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Where("deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM ebook_purchases WHERE ebook_purchases.user_id = users.id)").
+		Delete(&entities.User{})
+	return res.RowsAffected, res.Error
+}
+
+// purgeableBooks selects the IDs of books trashed before a moment that no
+// order line, return line or e-book purchase refers to.
+func purgeableBooks(tx *gorm.DB, before time.Time) *gorm.DB {
+	return tx.
+		Unscoped().
+		Model(&entities.Book{}).
+		Select("id").
+		Where("deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM return_items WHERE return_items.book_id = books.id)").
+		Where("NOT EXISTS (SELECT 1 FROM order_items WHERE order_items.book_id = books.id)").
+		Where("NOT EXISTS (SELECT 1 FROM ebook_purchases WHERE ebook_purchases.book_id = books.id)")
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,109 @@
+package trashservice
+
+import (
+	"context"
+	"log"
+	"story-book/internal/entities"
+	"story-book/internal/services/auditservice"
+	"time"
+)
+
+type TrashRepository interface {
+	ReadDeletedBooks(ctx context.Context, offset, limit int) ([]entities.Book, error)
+	ReadDeletedUsers(ctx context.Context, offset, limit int) ([]entities.User, error)
+	RestoreBook(ctx context.Context, id string) error
+	RestoreUser(ctx context.Context, id string) error
+	DeleteBook(ctx context.Context, id string) error
+	DeleteUser(ctx context.Context, id string) error
+	PurgeBooks(ctx context.Context, before time.Time) (int64, error)
+	PurgeUsers(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Auditor interface {
+	Record(ctx context.Context, action, entityType, entityId string, before, after any) error
+}
+
+type trashService struct {
+	repo      TrashRepository
+	audit     Auditor
+	retention time.Duration
+}
+
+func NewTrashService(repo TrashRepository, audit Auditor, retention time.Duration) TrashService {
+	return &trashService{repo: repo, audit: audit, retention: retention}
+}
+
+func (s *trashService) ReadDeletedBooks(ctx context.Context, page, limit int) ([]entities.Book, error) {
+	return s.repo.ReadDeletedBooks(ctx, (page-1)*limit, limit)
+}
+
+func (s *trashService) ReadDeletedUsers(ctx context.Context, page, limit int) ([]entities.User, error) {
+	return s.repo.ReadDeletedUsers(ctx, (page-1)*limit, limit)
+}
+
+func (s *trashService) RestoreBook(ctx context.Context, id string) error {
+	err := s.repo.RestoreBook(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.record(ctx, auditservice.ActionRestore, auditservice.EntityBook, id)
+
+	return nil
+}
+
+func (s *trashService) RestoreUser(ctx context.Context, id string) error {
+	err := s.repo.RestoreUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.record(ctx, auditservice.ActionRestore, auditservice.EntityUser, id)
+
+	return nil
+}
+
+func (s *trashService) DeleteBook(ctx context.Context, id string) error {
+	err := s.repo.DeleteBook(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.record(ctx, auditservice.ActionHardDelete, auditservice.EntityBook, id)
+
+	return nil
+}
+
+func (s *trashService) DeleteUser(ctx context.Context, id string) error {
+	err := s.repo.DeleteUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.record(ctx, auditservice.ActionHardDelete, auditservice.EntityUser, id)
+
+	return nil
+}
+
+func (s *trashService) Purge(ctx context.Context) (int64, int64, error) {
+	before := time.Now().Add(-s.retention)
+
+	books, err := s.repo.PurgeBooks(ctx, before)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	users, err := s.repo.PurgeUsers(ctx, before)
+	if err != nil {
+		return books, 0, err
+	}
+
+	return books, users, nil
+}
+
+func (s *trashService) record(ctx context.Context, action, entityType, id string) {
+	err := s.audit.Record(ctx, action, entityType, id, nil, nil)
+	if err != nil {
+		log.Printf("failed to record audit %s of %s %s: %v", action, entityType, id, err)
+	}
+}
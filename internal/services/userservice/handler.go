@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"story-book/internal/dto"
 	"story-book/internal/entities"
+	"story-book/package/services/validateservice"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -80,7 +81,7 @@ func (h *UserHandler) SignUp(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
 	user, accessToken, refreshToken, err := h.service.SignUp(ctx, &entities.User{
@@ -196,6 +197,7 @@ func (h *UserHandler) ReadSelf(c echo.Context) error {
 		Role:     user.Role,
 		Question: user.Question,
 		Points:   user.Points,
+		Currency: validate(user.Currency),
 	})
 }
 
@@ -272,7 +274,7 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
 	user, err := h.service.UpdateUser(ctx, &entities.User{
@@ -284,11 +286,15 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 		Question: request.Question,
 		Answer:   request.Answer,
 		Points:   0,
+		Currency: request.Currency,
 	})
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
 			return c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
 		}
+		if errors.Is(err, validateservice.ErrBadCurrency) {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 	}
 
@@ -301,6 +307,7 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 		Role:     user.Role,
 		Question: user.Question,
 		Points:   user.Points,
+		Currency: validate(user.Currency),
 	})
 }
 
@@ -326,7 +333,7 @@ func (h *UserHandler) ChangePassword(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
 	err := h.service.UpdatePassword(ctx, &entities.User{
@@ -355,7 +362,7 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid request"})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
 	err := h.service.DeleteUser(ctx, id)
@@ -368,3 +375,12 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+func validate[T any](t *T) T {
+	if t != nil {
+		return *t
+	}
+
+	var zero T
+	return zero
+}
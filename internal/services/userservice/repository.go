@@ -16,8 +16,14 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(ctx context.Context, user *entities.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *entities.User, audit Audit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(tx, audit, user)
+	})
 }
 
 func (r *userRepository) ReadByEmail(ctx context.Context, email string) (*entities.User, error) {
@@ -47,13 +53,18 @@ func (r *userRepository) ReadById(ctx context.Context, id string) (*entities.Use
 	return &user, nil
 }
 
-func (r *userRepository) Update(ctx context.Context, user *entities.User) (*entities.User, error) {
-	err := r.db.
-		WithContext(ctx).
-		Model(&entities.User{}).
-		Where("id = ?", user.Id).
-		Updates(user).
-		Scan(&user).Error
+func (r *userRepository) Update(ctx context.Context, user *entities.User, audit Audit) (*entities.User, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&entities.User{}).
+			Where("id = ?", user.Id).
+			Updates(user).
+			Scan(&user).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(tx, audit, user)
+	})
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -66,6 +77,22 @@ func (r *userRepository) Update(ctx context.Context, user *entities.User) (*enti
 	return user, nil
 }
 
-func (r *userRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&entities.User{Id: id}).Error
+func (r *userRepository) Delete(ctx context.Context, id string, audit Audit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entities.User{Id: id}).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(tx, audit, nil)
+	})
+}
+
+// writeAudit saves the audit entry of a user change in the same transaction.
+func writeAudit(tx *gorm.DB, audit Audit, after *entities.User) error {
+	record, err := audit(after)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(record).Error
 }
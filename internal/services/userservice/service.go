@@ -4,30 +4,41 @@ import (
 	"context"
 	"errors"
 	"story-book/internal/entities"
+	"story-book/internal/services/auditservice"
 	"story-book/package/services/encryptservice"
 	"story-book/package/services/jwtservice"
 	"story-book/package/services/validateservice"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
 type UserRepository interface {
-	Create(ctx context.Context, user *entities.User) error
+	Create(ctx context.Context, user *entities.User, audit Audit) error
 	ReadByEmail(ctx context.Context, email string) (*entities.User, error)
 	ReadById(ctx context.Context, id string) (*entities.User, error)
-	Update(ctx context.Context, user *entities.User) (*entities.User, error)
-	Delete(ctx context.Context, id string) error
+	Update(ctx context.Context, user *entities.User, audit Audit) (*entities.User, error)
+	Delete(ctx context.Context, id string, audit Audit) error
 }
 
+type Auditor interface {
+	NewRecord(ctx context.Context, action, entityType, entityId string, before, after any) (*entities.AuditRecord, error)
+}
+
+// Audit builds the audit entry of a change given the user after it, nil for
+// a delete.
+type Audit func(after *entities.User) (*entities.AuditRecord, error)
+
 type userService struct {
 	repo     UserRepository
 	jwt      jwtservice.JWTService
 	encrypt  encryptservice.EncryptService
 	validate validateservice.ValidationService
+	audit    Auditor
 }
 
-func NewUserService(repo UserRepository, jwt jwtservice.JWTService, encrypt encryptservice.EncryptService, validate validateservice.ValidationService) UserService {
-	return &userService{repo: repo, jwt: jwt, encrypt: encrypt, validate: validate}
+func NewUserService(repo UserRepository, jwt jwtservice.JWTService, encrypt encryptservice.EncryptService, validate validateservice.ValidationService, audit Auditor) UserService {
+	return &userService{repo: repo, jwt: jwt, encrypt: encrypt, validate: validate, audit: audit}
 }
 
 func (s *userService) Login(ctx context.Context, email, password string) (string, string, error) {
@@ -91,7 +102,11 @@ func (s *userService) SignUp(ctx context.Context, user *entities.User) (*entitie
 	user.Salt = salt
 	user.Role = "admin"
 
-	err = s.repo.Create(ctx, user)
+	actor := auditservice.ActorFromContext(ctx)
+	actor.Id = user.Id
+	actor.Role = user.Role
+
+	err = s.repo.Create(ctx, user, s.record(auditservice.WithActor(ctx, actor), auditservice.ActionCreate, user.Id, nil))
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -123,9 +138,20 @@ func (s *userService) ReedUserById(ctx context.Context, id string) (*entities.Us
 }
 
 func (s *userService) UpdateUser(ctx context.Context, user *entities.User) (*entities.User, error) {
-	var err error
+	if user.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*user.Currency))
+		if err := s.validate.IsValidCurrency(currency); err != nil {
+			return nil, err
+		}
+		user.Currency = &currency
+	}
 
-	updatedUser, err := s.repo.Update(ctx, user)
+	before, err := s.repo.ReadById(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedUser, err := s.repo.Update(ctx, user, s.record(ctx, auditservice.ActionUpdate, user.Id, before))
 	if err != nil {
 		return nil, err
 	}
@@ -144,21 +170,22 @@ func (s *userService) UpdatePassword(ctx context.Context, user *entities.User) e
 		return err
 	}
 
-	_, err = s.repo.Update(ctx, user)
+	before, err := s.repo.ReadById(ctx, user.Id)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = s.repo.Update(ctx, user, s.record(ctx, auditservice.ActionUpdate, user.Id, before))
+	return err
 }
 
 func (s *userService) DeleteUser(ctx context.Context, id string) error {
-	err := s.repo.Delete(ctx, id)
+	before, err := s.repo.ReadById(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.repo.Delete(ctx, id, s.record(ctx, auditservice.ActionDelete, id, before))
 }
 
 func (s *userService) RefreshTokens(id, role string) (string, string, error) {
@@ -193,3 +220,9 @@ func (s *userService) ResetPassword(ctx context.Context, id, answer string) erro
 
 	return ErrWrongAnswer
 }
+
+func (s *userService) record(ctx context.Context, action, id string, before *entities.User) Audit {
+	return func(after *entities.User) (*entities.AuditRecord, error) {
+		return s.audit.NewRecord(ctx, action, auditservice.EntityUser, id, before, after)
+	}
+}
@@ -0,0 +1,9 @@
+package sheet
+
+import "errors"
+
+var (
+	ErrUnsupportedFormat = errors.New("file must be .csv or .xlsx")
+	ErrInvalidFile       = errors.New("file cannot be read as a spreadsheet")
+	ErrEmpty             = errors.New("file has no header row")
+)
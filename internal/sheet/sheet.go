@@ -0,0 +1,91 @@
+// Package sheet reads the first sheet of a CSV or XLSX file as rows of text
+// cells. It covers supplier price lists: values are read as displayed text,
+// without formulas or styles.
+package sheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatCsv  = "csv"
+	FormatXlsx = "xlsx"
+)
+
+// Format tells the format of a file by its extension.
+func Format(name string) (string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCsv, nil
+	case ".xlsx":
+		return FormatXlsx, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// Read returns the rows of a file, the header first. Every row is as long as
+// the header and trailing blank rows are dropped.
+func Read(format string, data []byte) ([][]string, error) {
+	var rows [][]string
+	var err error
+
+	switch format {
+	case FormatCsv:
+		rows, err = readCsv(data)
+	case FormatXlsx:
+		rows, err = readXlsx(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for len(rows) > 0 && blank(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmpty
+	}
+
+	width := len(rows[0])
+	for i, row := range rows {
+		if len(row) < width {
+			rows[i] = append(row, make([]string, width-len(row))...)
+		}
+	}
+
+	return rows, nil
+}
+
+// readCsv accepts comma and semicolon separated files, as spreadsheet
+// programs write either depending on the locale, with or without a BOM.
+func readCsv(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+	return rows, nil
+}
+
+func blank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
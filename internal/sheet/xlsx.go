@@ -0,0 +1,186 @@
+package sheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const maxPartSize = 64 << 20
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelId string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		Id     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxText is a string that is either plain or made of formatted runs.
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string    `xml:"r,attr"`
+			Type   string    `xml:"t,attr"`
+			Value  string    `xml:"v"`
+			Inline *xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXlsx reads the first worksheet of a workbook. Cells keep their stored
+// value: shared and inline strings as text, numbers as written by the
+// program, booleans as 0 or 1.
+func readXlsx(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	var workbook xlsxWorkbook
+	if err = readPart(archive, "xl/workbook.xml", &workbook); err != nil || len(workbook.Sheets) == 0 {
+		return nil, ErrInvalidFile
+	}
+
+	var relationships xlsxRelationships
+	if err = readPart(archive, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	sheetPath := ""
+	for _, relationship := range relationships.Relationships {
+		if relationship.Id == workbook.Sheets[0].RelId {
+			sheetPath = relationship.Target
+		}
+	}
+	if sheetPath == "" {
+		return nil, ErrInvalidFile
+	}
+	if strings.HasPrefix(sheetPath, "/") {
+		sheetPath = strings.TrimPrefix(sheetPath, "/")
+	} else {
+		sheetPath = path.Join("xl", sheetPath)
+	}
+
+	// A workbook without text cells has no shared strings part.
+	var shared xlsxSharedStrings
+	if err = readPart(archive, "xl/sharedStrings.xml", &shared); err != nil && !errors.Is(err, errNoPart) {
+		return nil, ErrInvalidFile
+	}
+
+	var worksheet xlsxWorksheet
+	if err = readPart(archive, sheetPath, &worksheet); err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	rows := make([][]string, 0, len(worksheet.Rows))
+	for _, sheetRow := range worksheet.Rows {
+		// Empty rows are left out of the file; keep the numbering that the
+		// spreadsheet program shows.
+		for sheetRow.Number > len(rows)+1 {
+			rows = append(rows, nil)
+		}
+
+		var row []string
+		for i, cell := range sheetRow.Cells {
+			column := i
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, ErrInvalidFile
+				}
+			}
+			for len(row) <= column {
+				row = append(row, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, ErrInvalidFile
+				}
+				row[column] = shared.Items[index].String()
+			case "inlineStr":
+				if cell.Inline != nil {
+					row[column] = cell.Inline.String()
+				}
+			default:
+				row[column] = cell.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+var errNoPart = errors.New("part not found")
+
+func readPart(archive *zip.Reader, name string, v any) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		return xml.NewDecoder(io.LimitReader(reader, maxPartSize)).Decode(v)
+	}
+	return errNoPart
+}
+
+// columnIndex turns the letters of a cell reference such as "AB12" into a
+// zero-based column number.
+func columnIndex(ref string) (int, error) {
+	column := 0
+	letters := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		column = column*26 + int(c-'A'+1)
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return 0, ErrInvalidFile
+	}
+	return column - 1, nil
+}
@@ -0,0 +1,8 @@
+package storage
+
+import "errors"
+
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrInvalidKey = errors.New("invalid file key")
+)
@@ -0,0 +1,28 @@
+// Package storage keeps uploaded files behind an interface, so that the
+// filesystem backend can be swapped for an object store.
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// Storage keeps files by key. Keys are slash-separated relative paths, such
+// as "ebooks/<book id>.epub".
+type Storage interface {
+	// Put stores the contents of r under key, replacing any file there. A
+	// reader of the old file never sees a half-written new one.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open returns the file under key, or ErrNotFound.
+	Open(ctx context.Context, key string) (File, error)
+	// Delete removes the file under key. A missing file is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// File is an open stored file. It can be read in full, served with range
+// requests, and read at random as a zip archive needs.
+type File interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+	Size() int64
+}
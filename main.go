@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 	"story-book/internal/app"
 	"story-book/internal/config"
 )
@@ -17,6 +18,21 @@ import (
 func main() {
 	cfg := config.Load()
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			if err := app.Import(cfg, os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "export":
+			if err := app.Export(cfg, os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	if err := app.Run(cfg); err != nil {
 		log.Fatal(err)
 	}
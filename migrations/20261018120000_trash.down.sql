@@ -0,0 +1,7 @@
+drop index if exists users_deleted_at_idx;
+drop index if exists books_deleted_at_idx;
+
+alter table genre_of_books
+    drop constraint genre_of_books_book_id_fkey,
+    add constraint genre_of_books_book_id_fkey
+        foreign key (book_id) references books (id);
@@ -0,0 +1,12 @@
+alter table genre_of_books
+    drop constraint genre_of_books_book_id_fkey,
+    add constraint genre_of_books_book_id_fkey
+        foreign key (book_id) references books (id) on delete cascade;
+
+create index books_deleted_at_idx
+    on books (deleted_at)
+    where deleted_at is not null;
+
+create index users_deleted_at_idx
+    on users (deleted_at)
+    where deleted_at is not null;
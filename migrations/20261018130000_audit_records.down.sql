@@ -0,0 +1,2 @@
+drop table if exists audit_records;
+drop function if exists forbid_audit_records_mutation();
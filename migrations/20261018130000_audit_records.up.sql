@@ -0,0 +1,42 @@
+create table audit_records
+(
+    id          uuid primary key,
+    actor_id    uuid,
+    actor_role  varchar(20),
+    action      varchar(20) not null,
+    entity_type varchar(30) not null,
+    entity_id   uuid        not null,
+    before      jsonb,
+    after       jsonb,
+    ip          varchar(45),
+    request_id  varchar(64),
+    created_at  timestamp default current_timestamp
+);
+
+create index audit_records_entity_idx
+    on audit_records (entity_type, entity_id);
+
+create index audit_records_actor_idx
+    on audit_records (actor_id);
+
+create index audit_records_created_at_idx
+    on audit_records (created_at);
+
+create function forbid_audit_records_mutation() returns trigger as
+$$
+begin
+    raise exception 'audit_records is append-only';
+end;
+$$ language plpgsql;
+
+create trigger audit_records_append_only
+    before update or delete
+    on audit_records
+    for each row
+execute function forbid_audit_records_mutation();
+
+create trigger audit_records_no_truncate
+    before truncate
+    on audit_records
+    for each statement
+execute function forbid_audit_records_mutation();
@@ -0,0 +1,2 @@
+drop table if exists scheduled_price_changes;
+drop table if exists book_prices;
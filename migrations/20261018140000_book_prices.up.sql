@@ -0,0 +1,33 @@
+create table book_prices
+(
+    id           uuid primary key,
+    book_id      uuid references books (id) on delete cascade not null,
+    cost         numeric(10, 2)                               not null,
+    discount     smallint,
+    effective_at timestamp                                    not null,
+    created_at   timestamp default current_timestamp
+);
+
+create index book_prices_book_id_effective_at_idx
+    on book_prices (book_id, effective_at);
+
+insert into book_prices (id, book_id, cost, discount, effective_at)
+select gen_random_uuid(), id, cost, discount, created_at
+from books;
+
+create table scheduled_price_changes
+(
+    id           uuid primary key,
+    book_id      uuid references books (id) on delete cascade not null,
+    cost         numeric(10, 2),
+    discount     smallint,
+    effective_at timestamp                                    not null,
+    applied_at   timestamp default null,
+    created_by   uuid,
+    created_at   timestamp default current_timestamp,
+    deleted_at   timestamp default null
+);
+
+create index scheduled_price_changes_pending_idx
+    on scheduled_price_changes (effective_at)
+    where applied_at is null and deleted_at is null;
@@ -0,0 +1,4 @@
+drop table if exists promo_code_redemptions;
+drop table if exists promo_codes;
+drop table if exists campaign_targets;
+drop table if exists campaigns;
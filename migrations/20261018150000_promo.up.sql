@@ -0,0 +1,63 @@
+create table campaigns
+(
+    id             uuid primary key,
+    name           varchar(100)   not null,
+    description    text,
+    discount_type  varchar(10)    not null,
+    discount_value numeric(10, 2) not null,
+    priority       int            not null default 0,
+    stackable      boolean        not null default false,
+    starts_at      timestamp      not null,
+    ends_at        timestamp      not null,
+    created_at     timestamp               default current_timestamp,
+    deleted_at     timestamp               default null
+);
+
+create index campaigns_active_idx
+    on campaigns (starts_at, ends_at)
+    where deleted_at is null;
+
+create table campaign_targets
+(
+    id          uuid primary key,
+    campaign_id uuid references campaigns (id) on delete cascade not null,
+    kind        varchar(20)                                      not null,
+    value       varchar(100)                                     not null
+);
+
+create index campaign_targets_campaign_id_idx
+    on campaign_targets (campaign_id);
+
+create table promo_codes
+(
+    id                uuid primary key,
+    code              varchar(30)    not null,
+    description       text,
+    discount_type     varchar(10)    not null,
+    discount_value    numeric(10, 2) not null,
+    min_order_amount  numeric(10, 2) not null default 0,
+    max_uses          int,
+    max_uses_per_user int,
+    used_count        int            not null default 0,
+    combinable        boolean        not null default false,
+    starts_at         timestamp      not null,
+    ends_at           timestamp      not null,
+    created_at        timestamp               default current_timestamp,
+    deleted_at        timestamp               default null
+);
+
+create unique index promo_codes_code_unique_active
+    on promo_codes (code)
+    where deleted_at is null;
+
+create table promo_code_redemptions
+(
+    id            uuid primary key,
+    promo_code_id uuid references promo_codes (id) not null,
+    user_id       uuid references users (id) on delete cascade not null,
+    order_id      uuid,
+    created_at    timestamp default current_timestamp
+);
+
+create index promo_code_redemptions_code_user_idx
+    on promo_code_redemptions (promo_code_id, user_id);
@@ -0,0 +1,5 @@
+alter table users
+    drop column if exists currency;
+
+drop table if exists book_currency_prices;
+drop table if exists exchange_rates;
@@ -0,0 +1,18 @@
+create table exchange_rates
+(
+    currency   varchar(3) primary key,
+    rate       numeric(18, 6) not null,
+    updated_at timestamp default current_timestamp
+);
+
+create table book_currency_prices
+(
+    book_id    uuid references books (id) on delete cascade not null,
+    currency   varchar(3)                                   not null,
+    cost       numeric(10, 2)                               not null,
+    updated_at timestamp default current_timestamp,
+    primary key (book_id, currency)
+);
+
+alter table users
+    add column currency varchar(3) default null;
@@ -0,0 +1,7 @@
+drop index if exists books_rating_idx;
+
+alter table books
+    drop column if exists review_count,
+    drop column if exists rating;
+
+drop table if exists reviews;
@@ -0,0 +1,25 @@
+create table reviews
+(
+    id                uuid primary key,
+    book_id           uuid references books (id) on delete cascade not null,
+    user_id           uuid references users (id) on delete cascade not null,
+    rating            smallint                                     not null check (rating between 1 and 5),
+    text              text,
+    verified_purchase boolean                                      not null default false,
+    hidden            boolean                                      not null default false,
+    created_at        timestamp default current_timestamp,
+    updated_at        timestamp default current_timestamp,
+    unique (book_id, user_id)
+);
+
+create index reviews_book_id_idx
+    on reviews (book_id)
+    where hidden = false;
+
+alter table books
+    add column rating       numeric(3, 2) not null default 0,
+    add column review_count int           not null default 0;
+
+create index books_rating_idx
+    on books (rating desc, review_count desc)
+    where deleted_at is null;
@@ -0,0 +1,2 @@
+drop table if exists shelf_items;
+drop table if exists shelves;
@@ -0,0 +1,25 @@
+create table shelves
+(
+    id         uuid primary key,
+    user_id    uuid references users (id) on delete cascade not null,
+    name       varchar(50)                                  not null,
+    is_default boolean                                      not null default false,
+    public     boolean                                      not null default false,
+    slug       varchar(32) unique,
+    created_at timestamp default current_timestamp,
+    updated_at timestamp default current_timestamp,
+    unique (user_id, name)
+);
+
+create unique index shelves_default_idx
+    on shelves (user_id)
+    where is_default;
+
+create table shelf_items
+(
+    shelf_id uuid references shelves (id) on delete cascade not null,
+    book_id  uuid references books (id) on delete cascade   not null,
+    position int                                            not null,
+    added_at timestamp default current_timestamp,
+    primary key (shelf_id, book_id)
+);
@@ -0,0 +1,2 @@
+drop table if exists alert_notifications;
+drop table if exists book_alerts;
@@ -0,0 +1,33 @@
+create table book_alerts
+(
+    id                uuid primary key,
+    user_id           uuid references users (id) on delete cascade not null,
+    book_id           uuid references books (id) on delete cascade not null,
+    kind              varchar(20)                                  not null,
+    threshold         numeric(10, 2),
+    unsubscribe_token varchar(64)                                  not null unique,
+    armed             boolean                                      not null default true,
+    armed_at          timestamp                                    not null default current_timestamp,
+    created_at        timestamp default current_timestamp,
+    unique (user_id, book_id, kind)
+);
+
+create index book_alerts_book_id_idx
+    on book_alerts (book_id);
+
+create table alert_notifications
+(
+    id         uuid primary key,
+    alert_id   uuid references book_alerts (id) on delete cascade not null,
+    user_id    uuid                                                not null,
+    book_id    uuid                                                not null,
+    kind       varchar(20)                                         not null,
+    message    text                                                not null,
+    dedup_key  varchar(100)                                        not null unique,
+    created_at timestamp default current_timestamp,
+    sent_at    timestamp default null
+);
+
+create index alert_notifications_pending_idx
+    on alert_notifications (created_at)
+    where sent_at is null;
@@ -0,0 +1,2 @@
+drop table if exists user_recommendations;
+drop table if exists book_relations;
@@ -0,0 +1,21 @@
+create table book_relations
+(
+    book_id    uuid references books (id) on delete cascade not null,
+    related_id uuid references books (id) on delete cascade not null,
+    score      double precision                             not null,
+    primary key (book_id, related_id)
+);
+
+create index book_relations_score_idx
+    on book_relations (book_id, score desc);
+
+create table user_recommendations
+(
+    user_id uuid references users (id) on delete cascade not null,
+    book_id uuid references books (id) on delete cascade not null,
+    score   double precision                             not null,
+    primary key (user_id, book_id)
+);
+
+create index user_recommendations_score_idx
+    on user_recommendations (user_id, score desc);
@@ -0,0 +1,3 @@
+drop index if exists books_created_at_idx;
+drop table if exists collection_items;
+drop table if exists collections;
@@ -0,0 +1,25 @@
+create table collections
+(
+    id              uuid primary key,
+    slug            varchar(100) not null unique,
+    title           varchar(200) not null,
+    description     text,
+    cover_data      bytea,
+    cover_mime      varchar(20),
+    published_from  timestamp default null,
+    published_until timestamp default null,
+    created_at      timestamp default current_timestamp,
+    updated_at      timestamp default current_timestamp
+);
+
+create table collection_items
+(
+    collection_id uuid references collections (id) on delete cascade not null,
+    book_id       uuid references books (id) on delete cascade       not null,
+    position      int                                                not null,
+    primary key (collection_id, book_id)
+);
+
+create index books_created_at_idx
+    on books (created_at desc)
+    where deleted_at is null;
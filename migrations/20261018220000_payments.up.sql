@@ -0,0 +1,31 @@
+create table payments
+(
+    id          uuid primary key,
+    order_id    uuid                                         not null,
+    user_id     uuid references users (id) on delete set null,
+    provider    varchar(20)                                  not null,
+    provider_id varchar(100)                                 not null,
+    amount      numeric(10, 2)                               not null,
+    currency    varchar(3)                                   not null,
+    status      varchar(20)                                  not null,
+    created_at  timestamp default current_timestamp,
+    updated_at  timestamp default current_timestamp,
+    unique (provider, provider_id)
+);
+
+create index payments_order_id_idx
+    on payments (order_id);
+
+create index payments_open_idx
+    on payments (updated_at)
+    where status in ('pending', 'authorized');
+
+create table payment_events
+(
+    provider    varchar(20)  not null,
+    event_id    varchar(100) not null,
+    payment_id  uuid references payments (id) on delete cascade,
+    type        varchar(50)  not null,
+    received_at timestamp default current_timestamp,
+    primary key (provider, event_id)
+);
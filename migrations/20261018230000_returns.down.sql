@@ -0,0 +1,4 @@
+drop table if exists restock_entries;
+drop table if exists return_status_changes;
+drop table if exists return_items;
+drop table if exists return_requests;
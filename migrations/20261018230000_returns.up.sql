@@ -0,0 +1,58 @@
+create table return_requests
+(
+    id            uuid primary key,
+    user_id       uuid references users (id) on delete cascade    not null,
+    payment_id    uuid references payments (id) on delete restrict not null,
+    status        varchar(20)                                      not null,
+    refund_amount numeric(10, 2)                                   not null default 0,
+    currency      varchar(3)                                       not null,
+    comment       text,
+    created_at    timestamp default current_timestamp,
+    updated_at    timestamp default current_timestamp
+);
+
+create index return_requests_user_id_idx
+    on return_requests (user_id, created_at desc);
+
+create index return_requests_status_idx
+    on return_requests (status, created_at);
+
+-- One open return per payment: the provider refunds a payment only once.
+create unique index return_requests_open_payment_idx
+    on return_requests (payment_id)
+    where status in ('requested', 'approved');
+
+create table return_items
+(
+    return_id  uuid references return_requests (id) on delete cascade not null,
+    book_id    uuid references books (id) on delete restrict           not null,
+    quantity   int                                                     not null check (quantity > 0),
+    reason     varchar(30)                                             not null,
+    unit_price numeric(10, 2)                                          not null,
+    primary key (return_id, book_id)
+);
+
+create table return_status_changes
+(
+    id         uuid primary key,
+    return_id  uuid references return_requests (id) on delete cascade not null,
+    status     varchar(20)                                            not null,
+    actor_id   uuid references users (id) on delete set null,
+    comment    text,
+    created_at timestamp default current_timestamp
+);
+
+create index return_status_changes_return_id_idx
+    on return_status_changes (return_id, created_at);
+
+create table restock_entries
+(
+    id         uuid primary key,
+    book_id    uuid references books (id) on delete cascade not null,
+    quantity   int                                          not null check (quantity > 0),
+    return_id  uuid references return_requests (id) on delete set null,
+    created_at timestamp default current_timestamp
+);
+
+create index restock_entries_book_id_idx
+    on restock_entries (book_id, created_at desc);
@@ -0,0 +1,8 @@
+drop table if exists delivery_methods;
+drop table if exists addresses;
+
+alter table books
+    drop column if exists weight_grams,
+    drop column if exists width_mm,
+    drop column if exists height_mm,
+    drop column if exists depth_mm;
@@ -0,0 +1,44 @@
+alter table books
+    add column weight_grams int check (weight_grams > 0),
+    add column width_mm     int check (width_mm > 0),
+    add column height_mm    int check (height_mm > 0),
+    add column depth_mm     int check (depth_mm > 0);
+
+create table addresses
+(
+    id          uuid primary key,
+    user_id     uuid references users (id) on delete cascade not null,
+    label       varchar(50),
+    recipient   varchar(100)                                 not null,
+    phone       varchar(20)                                  not null,
+    country     varchar(2)                                   not null,
+    city        varchar(100)                                 not null,
+    postal_code varchar(10)                                  not null,
+    line1       varchar(200)                                 not null,
+    line2       varchar(200),
+    is_default  boolean                                      not null default false,
+    created_at  timestamp default current_timestamp,
+    updated_at  timestamp default current_timestamp
+);
+
+create index addresses_user_id_idx
+    on addresses (user_id, created_at);
+
+create unique index addresses_default_idx
+    on addresses (user_id)
+    where is_default;
+
+create table delivery_methods
+(
+    id               uuid primary key,
+    code             varchar(30)    not null unique,
+    kind             varchar(20)    not null check (kind in ('courier', 'pickup', 'post')),
+    name             varchar(100)   not null,
+    base_cost        numeric(10, 2) not null check (base_cost >= 0),
+    cost_per_kg      numeric(10, 2) not null default 0 check (cost_per_kg >= 0),
+    free_from        numeric(10, 2) check (free_from > 0),
+    max_weight_grams int check (max_weight_grams > 0),
+    active           boolean        not null default true,
+    created_at       timestamp default current_timestamp,
+    updated_at       timestamp default current_timestamp
+);
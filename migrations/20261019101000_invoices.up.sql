@@ -0,0 +1,7 @@
+create table invoices
+(
+    order_id  uuid primary key,
+    number    bigserial not null unique,
+    user_id   uuid references users (id) on delete set null,
+    issued_at timestamp default current_timestamp
+);
@@ -0,0 +1,4 @@
+drop table if exists tax_rules;
+
+alter table books
+    drop column if exists tax_category;
@@ -0,0 +1,16 @@
+alter table books
+    add column tax_category varchar(20) not null default 'book'
+        check (tax_category in ('book', 'ebook', 'other'));
+
+create table tax_rules
+(
+    id             uuid primary key,
+    region         varchar(10)  not null,
+    category       varchar(20)  not null check (category in ('book', 'ebook', 'other')),
+    rate           numeric(5, 2) not null check (rate >= 0 and rate <= 100),
+    inclusive      boolean      not null,
+    effective_from date         not null,
+    effective_to   date,
+    check (effective_to is null or effective_to > effective_from),
+    unique (region, category, effective_from)
+);
@@ -0,0 +1,11 @@
+drop index if exists return_requests_payment_idx;
+
+create unique index return_requests_open_payment_idx
+    on return_requests (payment_id)
+    where status in ('requested', 'approved');
+
+alter table return_requests
+    drop column if exists refund_method;
+
+drop table if exists gift_card_transactions;
+drop table if exists gift_cards;
@@ -0,0 +1,55 @@
+create table gift_cards
+(
+    id              uuid primary key,
+    code            varchar(19)    not null unique,
+    kind            varchar(10)    not null check (kind in ('gift', 'credit')),
+    owner_id        uuid references users (id) on delete cascade,
+    purchased_by    uuid references users (id) on delete set null,
+    issued_by       uuid references users (id) on delete set null,
+    payment_id      uuid unique references payments (id) on delete restrict,
+    initial_balance numeric(10, 2) not null check (initial_balance >= 0),
+    -- The check is the last line against double-spend: a redemption that
+    -- would overdraw the card fails even if the application lets it through.
+    balance         numeric(10, 2) not null check (balance >= 0),
+    currency        varchar(3)     not null,
+    expires_at      timestamp,
+    created_at      timestamp default current_timestamp,
+    updated_at      timestamp default current_timestamp
+);
+
+-- Store credit is one card per user and currency.
+create unique index gift_cards_credit_owner_idx
+    on gift_cards (owner_id, currency)
+    where kind = 'credit';
+
+create table gift_card_transactions
+(
+    id            uuid primary key,
+    gift_card_id  uuid references gift_cards (id) on delete cascade not null,
+    kind          varchar(10)                                     not null,
+    amount        numeric(10, 2)                                  not null,
+    balance_after numeric(10, 2)                                  not null,
+    order_id      varchar(100),
+    return_id     uuid references return_requests (id) on delete set null,
+    actor_id      uuid references users (id) on delete set null,
+    created_at    timestamp default current_timestamp
+);
+
+create index gift_card_transactions_card_id_idx
+    on gift_card_transactions (gift_card_id, created_at);
+
+-- A return is credited at most once, however often the refund is retried.
+create unique index gift_card_transactions_return_id_idx
+    on gift_card_transactions (return_id)
+    where return_id is not null;
+
+alter table return_requests
+    add column refund_method varchar(20) not null default 'payment';
+
+-- A refund to store credit leaves the payment paid, so a payment may have
+-- only one return that is not rejected, not just one open return.
+drop index return_requests_open_payment_idx;
+
+create unique index return_requests_payment_idx
+    on return_requests (payment_id)
+    where status <> 'rejected';
@@ -0,0 +1,11 @@
+drop table if exists stock_receipts;
+drop table if exists preorders;
+
+delete from alert_notifications where alert_id is null;
+
+alter table alert_notifications
+    alter column alert_id set not null;
+
+alter table books
+    drop column if exists preorder,
+    drop column if exists release_date;
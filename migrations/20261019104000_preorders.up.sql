@@ -0,0 +1,46 @@
+alter table books
+    add column release_date date,
+    add column preorder     boolean not null default false;
+
+-- Pre-order notices share the alert outbox but belong to no alert.
+alter table alert_notifications
+    alter column alert_id drop not null;
+
+create table preorders
+(
+    id           uuid primary key,
+    -- seq is the place in the queue: allocation is first come, first served.
+    seq          bigserial unique,
+    book_id      uuid references books (id) on delete cascade not null,
+    user_id      uuid references users (id) on delete cascade not null,
+    quantity     int                                          not null check (quantity > 0),
+    status       varchar(20)                                  not null,
+    allocated_at timestamp,
+    shipped_at   timestamp,
+    created_at   timestamp default current_timestamp,
+    updated_at   timestamp default current_timestamp
+);
+
+create index preorders_queue_idx
+    on preorders (book_id, seq)
+    where status = 'queued';
+
+create index preorders_user_id_idx
+    on preorders (user_id, created_at desc);
+
+create unique index preorders_queued_user_idx
+    on preorders (book_id, user_id)
+    where status = 'queued';
+
+create table stock_receipts
+(
+    id         uuid primary key,
+    book_id    uuid references books (id) on delete cascade not null,
+    quantity   int                                          not null check (quantity > 0),
+    allocated  int                                          not null default 0,
+    actor_id   uuid references users (id) on delete set null,
+    created_at timestamp default current_timestamp
+);
+
+create index stock_receipts_book_id_idx
+    on stock_receipts (book_id, created_at desc);
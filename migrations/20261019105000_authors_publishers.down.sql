@@ -0,0 +1,16 @@
+drop index if exists books_publisher_id_idx;
+
+alter table books
+    drop column if exists publisher_id;
+
+update books
+set author = left(author, 100);
+
+alter table books
+    alter column author type varchar(100);
+
+drop table if exists book_authors;
+drop table if exists publisher_aliases;
+drop table if exists publishers;
+drop table if exists author_aliases;
+drop table if exists authors;
@@ -0,0 +1,101 @@
+create table authors
+(
+    id         uuid primary key,
+    name       varchar(200) not null,
+    biography  text,
+    photo_data bytea,
+    photo_mime varchar(20),
+    created_at timestamp default current_timestamp,
+    updated_at timestamp default current_timestamp
+);
+
+create unique index authors_name_idx
+    on authors (lower(name));
+
+create table author_aliases
+(
+    author_id uuid references authors (id) on delete cascade not null,
+    alias     varchar(200)                                  not null,
+    primary key (author_id, alias)
+);
+
+create unique index author_aliases_alias_idx
+    on author_aliases (lower(alias));
+
+create table publishers
+(
+    id          uuid primary key,
+    name        varchar(200) not null,
+    description text,
+    logo_data   bytea,
+    logo_mime   varchar(20),
+    created_at  timestamp default current_timestamp,
+    updated_at  timestamp default current_timestamp
+);
+
+create unique index publishers_name_idx
+    on publishers (lower(name));
+
+create table publisher_aliases
+(
+    publisher_id uuid references publishers (id) on delete cascade not null,
+    alias        varchar(200)                                     not null,
+    primary key (publisher_id, alias)
+);
+
+create unique index publisher_aliases_alias_idx
+    on publisher_aliases (lower(alias));
+
+create table book_authors
+(
+    book_id   uuid references books (id) on delete cascade     not null,
+    author_id uuid references authors (id) on delete restrict not null,
+    role      varchar(20)                                     not null check (role in ('author', 'translator', 'illustrator')),
+    position  smallint                                        not null default 0,
+    primary key (book_id, author_id, role)
+);
+
+create index book_authors_author_id_idx
+    on book_authors (author_id);
+
+-- books.author and books.publisher stay as display names kept in step with
+-- the linked entities; several authors need more room.
+alter table books
+    alter column author type varchar(500),
+    add column publisher_id uuid references publishers (id) on delete restrict;
+
+create index books_publisher_id_idx
+    on books (publisher_id);
+
+-- Deduplicate the free-text values: spellings that differ only in case or
+-- whitespace become one entity named after the most common spelling.
+-- Genuinely different spellings of one person ("Tolstoy", "Толстой Л.Н.")
+-- cannot be told apart here and are merged by staff through
+-- POST /authors/{id}/merge, which keeps the merged name as an alias.
+insert into authors (id, name)
+select gen_random_uuid(), spelling
+from (select spelling,
+             row_number() over (partition by lower(spelling) order by count(*) desc, spelling) as rank
+      from (select regexp_replace(trim(author), '\s+', ' ', 'g') as spelling from books) s
+      where spelling <> ''
+      group by spelling) ranked
+where rank = 1;
+
+insert into book_authors (book_id, author_id, role, position)
+select b.id, a.id, 'author', 0
+from books b
+         join authors a on lower(a.name) = lower(regexp_replace(trim(b.author), '\s+', ' ', 'g'));
+
+insert into publishers (id, name)
+select gen_random_uuid(), spelling
+from (select spelling,
+             row_number() over (partition by lower(spelling) order by count(*) desc, spelling) as rank
+      from (select regexp_replace(trim(publisher), '\s+', ' ', 'g') as spelling from books) s
+      where spelling <> ''
+      group by spelling) ranked
+where rank = 1;
+
+update books
+set publisher_id = p.id
+from publishers p
+where lower(p.name) = lower(regexp_replace(trim(books.publisher), '\s+', ' ', 'g'));
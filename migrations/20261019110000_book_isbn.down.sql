@@ -0,0 +1,4 @@
+drop index if exists books_isbn_idx;
+
+alter table books
+    drop column if exists isbn;
@@ -0,0 +1,7 @@
+-- isbn is stored as ISBN-13 without separators; the ISBN-10 form is derived.
+-- The index also covers books in the trash so that a restore never clashes.
+alter table books
+    add column isbn varchar(13);
+
+create unique index books_isbn_idx
+    on books (isbn);
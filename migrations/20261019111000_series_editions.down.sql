@@ -0,0 +1,11 @@
+drop index if exists books_work_id_idx;
+
+alter table books
+    drop constraint if exists books_work_id_fkey,
+    drop column if exists pages,
+    drop column if exists language,
+    drop column if exists format,
+    drop column if exists work_id;
+
+drop table if exists works;
+drop table if exists series;
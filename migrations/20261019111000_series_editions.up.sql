@@ -0,0 +1,47 @@
+create table series
+(
+    id          uuid primary key,
+    name        varchar(200) not null,
+    description text,
+    created_at  timestamp default current_timestamp,
+    updated_at  timestamp default current_timestamp
+);
+
+create unique index series_name_idx
+    on series (lower(name));
+
+-- A work is what the author wrote; each book row is one edition of it, with
+-- its own format, language, ISBN, stock and price.
+create table works
+(
+    id         uuid primary key,
+    title      varchar(200) not null,
+    series_id  uuid references series (id),
+    volume     int check (volume > 0),
+    created_at timestamp default current_timestamp,
+    updated_at timestamp default current_timestamp,
+    check ((series_id is null) = (volume is null)),
+    -- Deferred so that volumes can be renumbered within one transaction.
+    constraint works_series_volume_key unique (series_id, volume) deferrable initially deferred
+);
+
+alter table books
+    add column work_id  uuid,
+    add column format   varchar(20) check (format in ('hardcover', 'paperback', 'ebook', 'audiobook')),
+    add column language varchar(2),
+    add column pages    int check (pages > 0);
+
+-- Every existing book becomes the only edition of its own work.
+update books
+set work_id = gen_random_uuid();
+
+insert into works (id, title, created_at, updated_at)
+select work_id, title, created_at, created_at
+from books;
+
+alter table books
+    alter column work_id set not null,
+    add constraint books_work_id_fkey foreign key (work_id) references works (id);
+
+create index books_work_id_idx
+    on books (work_id);
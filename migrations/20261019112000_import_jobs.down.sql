@@ -0,0 +1,2 @@
+drop table if exists import_errors;
+drop table if exists import_jobs;
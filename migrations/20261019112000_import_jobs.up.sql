@@ -0,0 +1,43 @@
+create table import_jobs
+(
+    id             uuid primary key,
+    -- user_id is empty for imports started from the command line.
+    user_id        uuid references users (id) on delete set null,
+    user_role      varchar(20),
+    file_name      varchar(255) not null,
+    format         varchar(10)  not null check (format in ('csv', 'xlsx')),
+    -- data is the uploaded file, dropped once the job has finished.
+    data           bytea,
+    mapping        jsonb        not null default '{}',
+    dry_run        boolean      not null default false,
+    status         varchar(20)  not null check (status in ('queued', 'running', 'done', 'failed')),
+    total_rows     int          not null default 0,
+    processed_rows int          not null default 0,
+    created_rows   int          not null default 0,
+    updated_rows   int          not null default 0,
+    failed_rows    int          not null default 0,
+    error          text,
+    created_at     timestamp default current_timestamp,
+    updated_at     timestamp default current_timestamp,
+    started_at     timestamp,
+    finished_at    timestamp
+);
+
+create index import_jobs_queue_idx
+    on import_jobs (created_at)
+    where status in ('queued', 'running');
+
+create table import_errors
+(
+    id      bigserial primary key,
+    job_id  uuid references import_jobs (id) on delete cascade not null,
+    -- row is the line number shown by the spreadsheet program, the header
+    -- being row 1.
+    row     int                                             not null,
+    field   varchar(50)                                     not null,
+    value   text,
+    message text                                            not null
+);
+
+create index import_errors_job_id_idx
+    on import_errors (job_id, row, id);
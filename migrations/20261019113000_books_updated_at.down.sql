@@ -0,0 +1,6 @@
+drop index if exists books_updated_at_idx;
+drop trigger if exists books_touch_updated_at on books;
+drop function if exists touch_books_updated_at();
+
+alter table books
+    drop column if exists updated_at;
@@ -0,0 +1,28 @@
+alter table books
+    add column updated_at timestamp;
+
+update books
+set updated_at = coalesce(deleted_at, created_at);
+
+alter table books
+    alter column updated_at set default current_timestamp,
+    alter column updated_at set not null;
+
+-- Books are changed from many places, raw SQL included; the trigger keeps
+-- updated_at right for incremental catalogue exports whatever the writer.
+create function touch_books_updated_at() returns trigger as
+$$
+begin
+    new.updated_at = current_timestamp;
+    return new;
+end;
+$$ language plpgsql;
+
+create trigger books_touch_updated_at
+    before update
+    on books
+    for each row
+execute function touch_books_updated_at();
+
+create index books_updated_at_idx
+    on books (updated_at, id);
@@ -0,0 +1,2 @@
+drop table if exists ebook_purchases;
+drop table if exists ebook_files;
@@ -0,0 +1,26 @@
+create table ebook_files
+(
+    book_id     uuid references books (id) on delete cascade not null,
+    file_format varchar(4)                                   not null check (file_format in ('epub', 'pdf')),
+    storage_key varchar(200)                                 not null,
+    size        bigint                                       not null,
+    sha256      varchar(64)                                  not null,
+    uploaded_by uuid references users (id) on delete set null,
+    created_at  timestamp default current_timestamp,
+    primary key (book_id, file_format)
+);
+
+create table ebook_purchases
+(
+    id             uuid primary key,
+    user_id        uuid references users (id) on delete cascade not null,
+    book_id        uuid references books (id) on delete restrict not null,
+    order_id       uuid                                         not null,
+    download_limit int                                          not null check (download_limit > 0),
+    downloads      int                                          not null default 0 check (downloads <= download_limit),
+    created_at     timestamp default current_timestamp,
+    unique (user_id, book_id, order_id)
+);
+
+create index ebook_purchases_book_id_idx
+    on ebook_purchases (book_id);
@@ -0,0 +1 @@
+drop table if exists ebook_downloads;
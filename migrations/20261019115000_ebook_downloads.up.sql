@@ -0,0 +1,13 @@
+-- Every download is a copy with its own watermark; the id of this row is
+-- stamped into the copy, so a leaked file leads back to its buyer.
+create table ebook_downloads
+(
+    id          uuid primary key,
+    purchase_id uuid references ebook_purchases (id) on delete cascade not null,
+    file_format varchar(4)                                            not null,
+    ip          varchar(45),
+    created_at  timestamp default current_timestamp
+);
+
+create index ebook_downloads_purchase_id_idx
+    on ebook_downloads (purchase_id);
@@ -0,0 +1,42 @@
+-- Orders keep the currency and exchange rate they were priced in, and every
+-- line keeps its own price, discount and tax, so later changes to the
+-- catalogue, the rates or the tax rules never alter a placed order.
+create table orders
+(
+    id            uuid primary key,
+    user_id       uuid references users (id) on delete set null,
+    status        varchar(20)    not null check (status in ('pending', 'paid', 'cancelled')),
+    currency      varchar(3)     not null,
+    exchange_rate numeric(18, 6) not null check (exchange_rate > 0),
+    promo_code    varchar(30),
+    subtotal      numeric(10, 2) not null,
+    discount      numeric(10, 2) not null,
+    tax           numeric(10, 2) not null,
+    total         numeric(10, 2) not null,
+    created_at    timestamp default current_timestamp,
+    updated_at    timestamp default current_timestamp
+);
+
+create index orders_user_id_idx
+    on orders (user_id, created_at desc);
+
+create table order_items
+(
+    id           uuid primary key,
+    order_id     uuid references orders (id) on delete cascade not null,
+    book_id      uuid references books (id) on delete set null,
+    title        varchar(100)                                  not null,
+    quantity     int                                           not null check (quantity > 0),
+    unit_price   numeric(10, 2)                                not null,
+    discount     numeric(10, 2)                                not null,
+    tax_category varchar(20)                                   not null,
+    tax_rate     numeric(5, 2),
+    tax          numeric(10, 2)                                not null,
+    total        numeric(10, 2)                                not null
+);
+
+create index order_items_order_id_idx
+    on order_items (order_id);
+
+create index order_items_book_id_idx
+    on order_items (book_id);
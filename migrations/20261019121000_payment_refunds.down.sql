@@ -0,0 +1,2 @@
+alter table payments
+    drop column if exists refunded_amount;
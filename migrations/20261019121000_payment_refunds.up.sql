@@ -0,0 +1,8 @@
+-- Refunds may be partial: a payment stays paid until everything is refunded.
+alter table payments
+    add column refunded_amount numeric(10, 2) not null default 0
+        check (refunded_amount >= 0 and refunded_amount <= amount);
+
+update payments
+set refunded_amount = amount
+where status = 'refunded';
@@ -0,0 +1,13 @@
+alter table orders
+    drop column if exists delivery_method_id,
+    drop column if exists delivery_code,
+    drop column if exists delivery_name,
+    drop column if exists delivery_cost,
+    drop column if exists address_id,
+    drop column if exists recipient,
+    drop column if exists phone,
+    drop column if exists country,
+    drop column if exists city,
+    drop column if exists postal_code,
+    drop column if exists line1,
+    drop column if exists line2;
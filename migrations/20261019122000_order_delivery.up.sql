@@ -0,0 +1,16 @@
+-- Orders keep a copy of the delivery method and address they were placed
+-- with. Editing or deleting either later does not change where an order
+-- goes or what its delivery cost.
+alter table orders
+    add column delivery_method_id uuid references delivery_methods (id) on delete set null,
+    add column delivery_code      varchar(30),
+    add column delivery_name      varchar(100),
+    add column delivery_cost      numeric(10, 2) not null default 0,
+    add column address_id         uuid references addresses (id) on delete set null,
+    add column recipient          varchar(100),
+    add column phone              varchar(20),
+    add column country            varchar(2),
+    add column city               varchar(100),
+    add column postal_code        varchar(10),
+    add column line1              varchar(200),
+    add column line2              varchar(200);
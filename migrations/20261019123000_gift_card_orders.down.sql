@@ -0,0 +1,8 @@
+drop index if exists gift_card_transactions_order_id_idx;
+
+alter table gift_card_transactions
+    drop constraint if exists gift_card_transactions_order_id_fkey,
+    alter column order_id type varchar(100) using order_id::text;
+
+alter table orders
+    drop column if exists kind;
@@ -0,0 +1,21 @@
+-- Gift cards are bought through orders of their own, with no lines.
+alter table orders
+    add column kind varchar(20) not null default 'books'
+        check (kind in ('books', 'gift_card'));
+
+-- Redemptions now always name an order of the user. Earlier free-form
+-- references that match no order are dropped from the ledger rows.
+update gift_card_transactions
+set order_id = null
+where order_id is not null
+  and (order_id !~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$'
+    or not exists (select 1 from orders where orders.id::text = lower(gift_card_transactions.order_id)));
+
+alter table gift_card_transactions
+    alter column order_id type uuid using order_id::uuid,
+    add constraint gift_card_transactions_order_id_fkey
+        foreign key (order_id) references orders (id) on delete set null;
+
+create index gift_card_transactions_order_id_idx
+    on gift_card_transactions (order_id)
+    where order_id is not null;
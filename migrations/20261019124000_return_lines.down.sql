@@ -0,0 +1,5 @@
+alter table return_items
+    drop column if exists points;
+
+alter table order_items
+    drop column if exists points;
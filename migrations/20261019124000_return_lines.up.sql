@@ -0,0 +1,7 @@
+-- Paid order lines keep the loyalty points they earned, and approved
+-- returns the points they took back.
+alter table order_items
+    add column points int not null default 0 check (points >= 0);
+
+alter table return_items
+    add column points int not null default 0 check (points >= 0);
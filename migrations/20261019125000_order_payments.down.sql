@@ -0,0 +1,11 @@
+alter table payments
+    drop constraint if exists payments_order_id_fkey;
+
+update orders
+set status = 'pending'
+where status = 'failed';
+
+alter table orders
+    drop constraint if exists orders_status_check,
+    add constraint orders_status_check
+        check (status in ('pending', 'paid', 'cancelled'));
@@ -0,0 +1,11 @@
+-- An order whose payment failed is failed until a new payment is opened.
+alter table orders
+    drop constraint if exists orders_status_check,
+    add constraint orders_status_check
+        check (status in ('pending', 'paid', 'failed', 'cancelled'));
+
+-- Payments predate orders, so earlier rows may name orders that never
+-- existed. They are kept as they are; new payments must name an order.
+alter table payments
+    add constraint payments_order_id_fkey
+        foreign key (order_id) references orders (id) on delete restrict not valid;
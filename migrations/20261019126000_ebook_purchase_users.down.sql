@@ -0,0 +1,4 @@
+alter table ebook_purchases
+    drop constraint if exists ebook_purchases_user_id_fkey,
+    add constraint ebook_purchases_user_id_fkey
+        foreign key (user_id) references users (id) on delete cascade;
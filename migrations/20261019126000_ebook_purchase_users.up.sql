@@ -0,0 +1,6 @@
+-- Downloads trace leaked copies back to their buyer, so deleting a user no
+-- longer takes their purchases and downloads along.
+alter table ebook_purchases
+    drop constraint if exists ebook_purchases_user_id_fkey,
+    add constraint ebook_purchases_user_id_fkey
+        foreign key (user_id) references users (id) on delete restrict;
@@ -0,0 +1,5 @@
+-- Refunds asked of the provider that its webhook has not confirmed yet.
+-- They count against what a payment has left to refund.
+alter table payments
+    add column refund_pending numeric(10, 2) not null default 0
+        check (refund_pending >= 0);
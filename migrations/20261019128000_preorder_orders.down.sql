@@ -0,0 +1,16 @@
+alter table stock_receipts
+    drop column if exists held;
+
+drop index if exists preorders_order_id_idx;
+
+alter table preorders
+    drop column if exists order_id;
+
+update orders
+set kind = 'books'
+where kind = 'preorder';
+
+alter table orders
+    drop constraint if exists orders_kind_check,
+    add constraint orders_kind_check
+        check (kind in ('books', 'gift_card'));
@@ -0,0 +1,16 @@
+-- A pre-order is paid for through an order of its own, placed with it.
+alter table orders
+    drop constraint if exists orders_kind_check,
+    add constraint orders_kind_check
+        check (kind in ('books', 'gift_card', 'preorder'));
+
+alter table preorders
+    add column order_id uuid references orders (id) on delete restrict;
+
+create index preorders_order_id_idx
+    on preorders (order_id);
+
+-- Copies left over after allocation stay in stock but are held for the
+-- pre-orders still queued.
+alter table stock_receipts
+    add column held int not null default 0;
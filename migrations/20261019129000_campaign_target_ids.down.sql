@@ -0,0 +1,11 @@
+update campaign_targets t
+set value = a.name
+from authors a
+where t.kind = 'author'
+  and t.value = a.id::text;
+
+update campaign_targets t
+set value = p.name
+from publishers p
+where t.kind = 'publisher'
+  and t.value = p.id::text;
@@ -0,0 +1,22 @@
+-- Author and publisher campaign targets name the entity by ID. Targets
+-- naming an author or publisher, or one of their aliases, are converted;
+-- the rest are left as they are and no longer match any book.
+update campaign_targets t
+set value = a.id::text
+from authors a
+where t.kind = 'author'
+  and (lower(a.name) = lower(t.value)
+    or exists (select 1
+               from author_aliases aa
+               where aa.author_id = a.id
+                 and lower(aa.alias) = lower(t.value)));
+
+update campaign_targets t
+set value = p.id::text
+from publishers p
+where t.kind = 'publisher'
+  and (lower(p.name) = lower(t.value)
+    or exists (select 1
+               from publisher_aliases pa
+               where pa.publisher_id = p.id
+                 and lower(pa.alias) = lower(t.value)));
@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrBadEmail    = errors.New("bad email")
 	ErrBadPassword = errors.New("bad password")
+	ErrBadCurrency = errors.New("bad currency")
 )
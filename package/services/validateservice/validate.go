@@ -9,9 +9,12 @@ var emailRegex = regexp.MustCompile(
 	`^[a-zA-Z0-9._%+-]+@([a-zA-Z0-9-]+\.)+[a-zA-Z]{2,}$`,
 )
 
+var currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
 type ValidationService interface {
 	IsValidEmail(email string) error
 	IsStrongPassword(password string) error
+	IsValidCurrency(currency string) error
 }
 
 type validationService struct {
@@ -53,3 +56,11 @@ func (v *validationService) IsStrongPassword(password string) error {
 
 	return ErrBadPassword
 }
+
+func (v *validationService) IsValidCurrency(currency string) error {
+	if currencyRegex.MatchString(currency) {
+		return nil
+	}
+
+	return ErrBadCurrency
+}